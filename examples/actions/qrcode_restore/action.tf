@@ -0,0 +1,7 @@
+action "qrcode_restore" "default" {
+  config {
+    file           = qrcode_generate.example.filename
+    sha256         = qrcode_generate.example.sha256
+    content_base64 = qrcode_generate.example.content_base64
+  }
+}
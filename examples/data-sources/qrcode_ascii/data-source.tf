@@ -0,0 +1,3 @@
+data "qrcode_ascii" "default" {
+  text = "qrcode"
+}
@@ -0,0 +1,9 @@
+data "qrcode_image" "wifi" {
+  text   = "WIFI:T:WPA;S:guest;P:welcome;;"
+  format = "svg"
+}
+
+# Embed the image in an HTML page without writing a file
+output "wifi_img" {
+  value = "<img src=\"${data.qrcode_image.wifi.data_uri}\" alt=\"Guest Wi-Fi\">"
+}
@@ -0,0 +1,14 @@
+# assets.csv:
+# serial,room
+# A-1001,101
+# A-1002,204
+data "qrcode_payloads" "assets" {
+  file             = "${path.module}/assets.csv"
+  key_column       = "serial"
+  content_template = "https://assets.example.com/{serial}?room={room}"
+}
+
+resource "qrcode_directory" "labels" {
+  directory = "${path.module}/labels"
+  contents  = data.qrcode_payloads.assets.payloads
+}
@@ -0,0 +1,8 @@
+data "qrcode_scan_directory" "labels" {
+  directory = "/srv/labels"
+  recursive = true
+}
+
+output "label_contents" {
+  value = { for name, code in data.qrcode_scan_directory.labels.codes : name => code.content }
+}
@@ -0,0 +1,16 @@
+resource "qrcode_generate" "payment" {
+  text = "https://pay.example.com/invoice/42"
+  file = "${path.module}/payment.png"
+}
+
+data "qrcode_verify" "payment" {
+  file             = qrcode_generate.payment.filename
+  expected_content = "https://pay.example.com/invoice/42"
+}
+
+check "payment_qrcode" {
+  assert {
+    condition     = data.qrcode_verify.payment.matches
+    error_message = "The payment QR code encodes ${coalesce(data.qrcode_verify.payment.content, "no readable text")}."
+  }
+}
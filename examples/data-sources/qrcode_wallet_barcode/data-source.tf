@@ -0,0 +1,15 @@
+data "qrcode_wallet_barcode" "ticket" {
+  message  = "https://tickets.example.com/t/8f3a2c?seat=12A"
+  alt_text = "Seat 12A"
+}
+
+# Render the same message with the bytes the wallet apps encode
+resource "qrcode_generate" "ticket" {
+  text         = data.qrcode_wallet_barcode.ticket.message
+  byte_charset = data.qrcode_wallet_barcode.ticket.byte_charset
+  file         = "${path.module}/ticket.png"
+}
+
+output "pass_barcodes" {
+  value = jsondecode(data.qrcode_wallet_barcode.ticket.barcodes_json)
+}
@@ -0,0 +1,10 @@
+resource "qrcode_generate" "wifi" {
+  text = "WIFI:T:WPA;S:guest;P:welcome;;"
+}
+
+check "wifi_qrcode" {
+  assert {
+    condition     = provider::qrcode::decode(qrcode_generate.wifi.content_base64) == "WIFI:T:WPA;S:guest;P:welcome;;"
+    error_message = "The WiFi QR code does not encode the expected network."
+  }
+}
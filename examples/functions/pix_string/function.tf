@@ -0,0 +1,4 @@
+resource "qrcode_generate" "payment" {
+  text = provider::qrcode::pix_string("123e4567-e12b-12d1-a456-426655440000", "Fulano de Tal", "BRASILIA", 10.5, "PEDIDO42")
+  file = "${path.module}/payment.png"
+}
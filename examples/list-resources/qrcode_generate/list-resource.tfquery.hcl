@@ -0,0 +1,8 @@
+list "qrcode_generate" "all" {
+  provider = qrcode
+
+  config {
+    directory = "/srv/qrcodes"
+    recursive = true
+  }
+}
@@ -0,0 +1,17 @@
+locals {
+  attendees = {
+    ada   = { name = "Ada Lovelace", title = "Analytical Engines", photo = "photos/ada.jpg" }
+    grace = { name = "Grace Hopper", title = "Compilers", photo = null }
+  }
+}
+
+resource "qrcode_badge" "attendee" {
+  for_each = local.attendees
+
+  text   = "https://example.com/attendees/${each.key}"
+  name   = each.value.name
+  title  = each.value.title
+  photo  = each.value.photo
+  format = "pdf"
+  file   = "/tmp/badges/${each.key}.pdf"
+}
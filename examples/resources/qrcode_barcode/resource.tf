@@ -0,0 +1,12 @@
+resource "qrcode_barcode" "default" {
+  file      = "/tmp/barcode.png"
+  symbology = "ean13"
+  value     = "400638133393"
+}
+
+resource "qrcode_barcode" "carton" {
+  file        = "/tmp/carton.png"
+  symbology   = "itf14"
+  value       = "1540014128876"
+  bearer_bars = true
+}
@@ -0,0 +1,7 @@
+resource "qrcode_directory" "default" {
+  directory = "/tmp/qrcodes"
+  contents = {
+    wifi    = "WIFI:S:office;T:WPA;P:secret;;"
+    website = "https://example.com"
+  }
+}
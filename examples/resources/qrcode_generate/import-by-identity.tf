@@ -0,0 +1,6 @@
+import {
+  to = qrcode_generate.default
+  identity = {
+    file = "/tmp/qrcode.png"
+  }
+}
@@ -0,0 +1 @@
+terraform import qrcode_generate.default /tmp/qrcode.png
@@ -0,0 +1,5 @@
+resource "qrcode_structured_append" "default" {
+  directory   = "/tmp/ca-bundle"
+  content     = file("ca-bundle.pem")
+  max_version = 15
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ action.Action              = &qrcodeRestoreAction{}
+	_ action.ActionWithConfigure = &qrcodeRestoreAction{}
+)
+
+// qrcodeRestoreAction is the action implementation.
+type qrcodeRestoreAction struct {
+	fs afero.Fs
+
+	// writeOptions control how files are written.
+	writeOptions writeOptions
+}
+
+// NewQRCodeRestoreAction creates a new QR code restore action instance.
+func NewQRCodeRestoreAction() action.Action {
+	return &qrcodeRestoreAction{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the action type name.
+func (a *qrcodeRestoreAction) Metadata(_ context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_restore"
+}
+
+// Configure receives the provider-level filesystem.
+func (a *qrcodeRestoreAction) Configure(_ context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	a.fs = data.Filesystem
+	a.writeOptions = data.WriteOptions
+}
+
+// Schema defines the action schema.
+func (a *qrcodeRestoreAction) Schema(_ context.Context, _ action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_restore` action checks on demand that the file written by a `qrcode_generate` resource still has the checksum recorded in its state, and restores the recorded image when the file was changed or removed outside Terraform, without tainting or replacing the resource. Actions cannot change resource state, so the action does not render the image again: it writes back, byte for byte, the image that the resource recorded, which neither the next plan nor the resource's checks before deleting its files can tell apart. To render the image with a new configuration, change the resource instead. It can be invoked with `terraform apply -invoke` or from a resource `action_trigger`. Actions require Terraform 1.14 or later.",
+		Attributes: map[string]schema.Attribute{
+			"file": schema.StringAttribute{
+				Required:    true,
+				Description: "Path of the file written by the resource, such as its `filename`.",
+			},
+			"sha256": schema.StringAttribute{
+				Required:    true,
+				Description: "SHA-256 checksum the file was written with, such as the resource's `sha256`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(sha256Pattern, "must be a lowercase hex-encoded SHA-256 checksum"),
+				},
+			},
+			"content_base64": schema.StringAttribute{
+				Optional:    true,
+				Description: "Base64-encoded image to restore the file with, such as the resource's `content_base64`. It must have the checksum in `sha256`. When null, a changed or missing file is only reported.",
+			},
+		},
+	}
+}
+
+// qrcodeRestoreActionModel describes the action configuration.
+type qrcodeRestoreActionModel struct {
+	File          types.String `tfsdk:"file"`
+	SHA256        types.String `tfsdk:"sha256"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+}
+
+// Invoke checks the file against its recorded checksum and restores the recorded image when it
+// differs.
+func (a *qrcodeRestoreAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var config qrcodeRestoreActionModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := config.File.ValueString()
+	expected := config.SHA256.ValueString()
+
+	actual, err := fileSHA256(a.fs, filePath)
+	if err == nil && actual == expected {
+		a.sendProgress(resp, fmt.Sprintf("Verified %s (sha256 %s)", filePath, expected))
+		return
+	}
+
+	found := fmt.Sprintf("has SHA-256 checksum %s", actual)
+	if err != nil {
+		found = fmt.Sprintf("cannot be read: %s", err)
+	}
+
+	if config.ContentBase64.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("file"),
+			"QR Code File Changed",
+			fmt.Sprintf("The file %s %s, but was written with %s. Set content_base64 to restore it, or apply the resource to render it again.", filePath, found, expected),
+		)
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(config.ContentBase64.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid Base64 Content", err.Error())
+		return
+	}
+	if checksum := computeSHA256(string(data)); checksum != expected {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content_base64"),
+			"Content Does Not Match Checksum",
+			fmt.Sprintf("The content has SHA-256 checksum %s, but the file was written with %s.", checksum, expected),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Restoring QR code file", map[string]interface{}{
+		"file":   filePath,
+		"sha256": expected,
+	})
+
+	started := time.Now()
+	resp.Diagnostics.Append(saveQRCodeFile(ctx, a.fs, a.writeOptions, filePath, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(a.writeOptions.metrics.record(ctx, filePath, 1, time.Since(started))...)
+
+	a.sendProgress(resp, fmt.Sprintf("Restored %s (sha256 %s)", filePath, expected))
+}
+
+// sendProgress reports message as a progress event when Terraform accepts them.
+func (a *qrcodeRestoreAction) sendProgress(resp *action.InvokeResponse, message string) {
+	if resp.SendProgress != nil {
+		resp.SendProgress(action.InvokeProgressEvent{Message: message})
+	}
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestQRCodeRestoreAction verifies that invoking qrcode_restore leaves a file with the
+// recorded checksum alone and restores the recorded image over a changed or missing file.
+func TestQRCodeRestoreAction(t *testing.T) {
+	ctx := context.Background()
+
+	image := []byte("recorded image")
+	checksum := computeSHA256(string(image))
+
+	testCases := map[string]struct {
+		file          []byte
+		contentBase64 tftypes.Value
+		expectError   bool
+	}{
+		"unchanged": {
+			file:          image,
+			contentBase64: tftypes.NewValue(tftypes.String, nil),
+		},
+		"changed": {
+			file:          []byte("edited"),
+			contentBase64: tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString(image)),
+		},
+		"missing": {
+			contentBase64: tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString(image)),
+		},
+		"changed without content": {
+			file:          []byte("edited"),
+			contentBase64: tftypes.NewValue(tftypes.String, nil),
+			expectError:   true,
+		},
+		"content with another checksum": {
+			file:          []byte("edited"),
+			contentBase64: tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString([]byte("other image"))),
+			expectError:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			a := &qrcodeRestoreAction{fs: afero.NewMemMapFs()}
+			if testCase.file != nil {
+				if err := afero.WriteFile(a.fs, "/out/qrcode.png", testCase.file, 0o644); err != nil {
+					t.Fatalf("failed to write the file: %s", err)
+				}
+			}
+
+			schemaResp := &action.SchemaResponse{}
+			a.Schema(ctx, action.SchemaRequest{}, schemaResp)
+
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+					"file":           tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+					"sha256":         tftypes.NewValue(tftypes.String, checksum),
+					"content_base64": testCase.contentBase64,
+				}),
+			}
+
+			var progress []string
+			resp := &action.InvokeResponse{
+				SendProgress: func(event action.InvokeProgressEvent) {
+					progress = append(progress, event.Message)
+				},
+			}
+			a.Invoke(ctx, action.InvokeRequest{Config: config}, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Fatalf("expected error %t, got %v", testCase.expectError, resp.Diagnostics)
+			}
+			if testCase.expectError {
+				return
+			}
+
+			actual, err := fileSHA256(a.fs, "/out/qrcode.png")
+			if err != nil {
+				t.Fatalf("failed to calculate SHA-256 checksum: %s", err)
+			}
+			if actual != checksum {
+				t.Errorf("expected SHA-256 checksum %s, got %s", checksum, actual)
+			}
+			if len(progress) != 1 {
+				t.Errorf("expected 1 progress event, got %d", len(progress))
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // Register the JPEG decoder for badge photos.
+	"image/png"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Badge formats.
+const (
+	badgeFormatPNG = "png"
+	badgeFormatPDF = "pdf"
+)
+
+// Width limits for badges, in pixels.
+const (
+	defaultBadgeWidth = 600
+	minBadgeWidth     = 200
+	maxBadgeWidth     = 4000
+)
+
+// badgeContent is what a badge shows, from top to bottom.
+type badgeContent struct {
+	// Photo is drawn at the top, or nil for badges without a photo.
+	Photo image.Image
+
+	// Name is drawn in large text below the photo.
+	Name string
+
+	// Title is drawn in smaller text below the name, or empty.
+	Title string
+
+	// Symbol is the QR code drawn at the bottom.
+	Symbol *qrgen.Symbol
+}
+
+// renderBadge composes a badge of the given width in pixels, with the photo, the name, the title
+// and the QR code centered on white in that order. The height follows from the content. The name
+// and title are scaled up to fill the badge, and fail when they do not fit it at their smallest.
+func renderBadge(content badgeContent, width int) (*image.RGBA, error) {
+	margin := width / 20
+	inner := width - 2*margin
+
+	var rows []image.Image
+	if content.Photo != nil {
+		rows = append(rows, fitImage(content.Photo, width/2))
+	}
+
+	name, err := renderFittedLabel(content.Name, inner, max(1, width/120))
+	if err != nil {
+		return nil, fmt.Errorf("name %w", err)
+	}
+	rows = append(rows, name)
+
+	if content.Title != "" {
+		title, err := renderFittedLabel(content.Title, inner, max(1, width/240))
+		if err != nil {
+			return nil, fmt.Errorf("title %w", err)
+		}
+		rows = append(rows, title)
+	}
+
+	qrData, err := content.Symbol.PNG(width/2, qrgen.DefaultColors)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := png.Decode(bytes.NewReader(qrData))
+	if err != nil {
+		return nil, err
+	}
+	rows = append(rows, qr)
+
+	height := margin
+	for _, row := range rows {
+		height += row.Bounds().Dy() + margin
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	y := margin
+	for _, row := range rows {
+		offset := image.Pt((width-row.Bounds().Dx())/2, y)
+		draw.Draw(img, row.Bounds().Sub(row.Bounds().Min).Add(offset), row, row.Bounds().Min, draw.Src)
+		y += row.Bounds().Dy() + margin
+	}
+
+	return img, nil
+}
+
+// renderFittedLabel draws text with renderLabel at the largest scale up to maxScale that fits
+// maxWidth.
+func renderFittedLabel(text string, maxWidth, maxScale int) (*image.Gray, error) {
+	width := font.MeasureString(basicfont.Face7x13, text).Ceil()
+	if width > maxWidth {
+		return nil, fmt.Errorf("%q is %d pixels wide, which does not fit the %d pixels inside the badge margins", text, width, maxWidth)
+	}
+
+	return renderLabel(text, min(maxScale, maxWidth/max(1, width))), nil
+}
+
+// fitImage scales img to fit a square of the given size, keeping its aspect ratio.
+func fitImage(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	scale := math.Min(float64(size)/float64(bounds.Dx()), float64(size)/float64(bounds.Dy()))
+
+	scaled := image.NewRGBA(image.Rect(0, 0, max(1, int(float64(bounds.Dx())*scale)), max(1, int(float64(bounds.Dy())*scale))))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, xdraw.Src, nil)
+
+	return scaled
+}
+
+// encodeBadge encodes a badge in the given format. PDF pages are sized at dpi, so that the badge
+// prints at its intended size.
+func encodeBadge(img image.Image, format string, dpi int) ([]byte, error) {
+	if format == badgeFormatPDF {
+		points := func(pixels int) int {
+			return int(math.Round(float64(pixels) * qrgen.PDFPointsPerInch / float64(dpi)))
+		}
+		return qrgen.ImagePDF(img, points(img.Bounds().Dx()), points(img.Bounds().Dy()))
+	}
+
+	return qrgen.EncodePNG(img)
+}
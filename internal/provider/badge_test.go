@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"testing"
+
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// TestRenderBadge verifies that badges stack the photo, name, title and a readable QR code, and
+// that names that do not fit the badge are rejected.
+func TestRenderBadge(t *testing.T) {
+	symbol, err := qrgen.Encode("https://example.com/attendees/42", qrgen.Options{Level: qrgen.Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	photo := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	draw.Draw(photo, photo.Bounds(), image.NewUniform(color.RGBA{R: 0xc0, A: 0xff}), image.Point{}, draw.Src)
+
+	content := badgeContent{Name: "Ada Lovelace", Title: "Analytical Engines", Symbol: symbol}
+	plain, err := renderBadge(content, defaultBadgeWidth)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	content.Photo = photo
+	badge, err := renderBadge(content, defaultBadgeWidth)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	// The photo is scaled to half the width, keeping its aspect ratio
+	if badge.Bounds().Dx() != defaultBadgeWidth || badge.Bounds().Dy() != plain.Bounds().Dy()+defaultBadgeWidth/4+defaultBadgeWidth/20 {
+		t.Errorf("expected the photo to add a row, got %v and %v", plain.Bounds(), badge.Bounds())
+	}
+	if r, g, _, _ := badge.At(defaultBadgeWidth/2, defaultBadgeWidth/20+10).RGBA(); r>>8 != 0xc0 || g != 0 {
+		t.Errorf("expected the photo at the top")
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, badge); err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if text, err := decodeQRCodeImage(buf.Bytes()); err != nil || text != "https://example.com/attendees/42" {
+		t.Errorf("expected the QR code to decode, got %q: %v", text, err)
+	}
+
+	content.Name = strings.Repeat("W", 64)
+	if _, err := renderBadge(content, minBadgeWidth); err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Errorf("expected a name wider than the badge to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/twooffive"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Supported 1D barcode symbologies.
+const (
+	symbologyEAN13  = "ean13"
+	symbologyUPCA   = "upca"
+	symbologyCode39 = "code39"
+	symbologyITF14  = "itf14"
+)
+
+// barcodeQuietZoneModules is the number of blank modules rendered on each side of a 1D barcode.
+const barcodeQuietZoneModules = 10
+
+// gtinCheckDigit computes the GS1 modulo-10 check digit for digits, which must not include the check digit.
+func gtinCheckDigit(digits string) int {
+	sum := 0
+	// Weights alternate 3, 1, ... starting from the rightmost digit
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			sum += digit * 3
+		} else {
+			sum += digit
+		}
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// normalizeGTIN validates value as a GTIN of the given length, appending the check digit when it is omitted.
+func normalizeGTIN(value string, length int) (string, error) {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("value %q must contain only digits", value)
+		}
+	}
+
+	switch len(value) {
+	case length - 1:
+		return value + strconv.Itoa(gtinCheckDigit(value)), nil
+	case length:
+		expected := gtinCheckDigit(value[:length-1])
+		if int(value[length-1]-'0') != expected {
+			return "", fmt.Errorf("value %q has check digit %c, expected %d", value, value[length-1], expected)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("value %q must have %d digits, or %d digits to compute the check digit", value, length, length-1)
+	}
+}
+
+// encodeBarcode encodes value in the given symbology and returns the barcode together with the
+// human-readable text printed below it.
+func encodeBarcode(symbology, value string) (barcode.Barcode, string, error) {
+	switch symbology {
+	case symbologyEAN13:
+		code, err := normalizeGTIN(value, 13)
+		if err != nil {
+			return nil, "", err
+		}
+		bc, err := ean.Encode(code)
+		return bc, code, err
+	case symbologyUPCA:
+		code, err := normalizeGTIN(value, 12)
+		if err != nil {
+			return nil, "", err
+		}
+		// UPC-A is the subset of EAN-13 with a leading zero
+		bc, err := ean.Encode("0" + code)
+		return bc, code, err
+	case symbologyCode39:
+		bc, err := code39.Encode(value, false, false)
+		if err != nil {
+			return nil, "", err
+		}
+		// Code 39 is conventionally printed with its start/stop asterisks
+		return bc, "*" + value + "*", nil
+	case symbologyITF14:
+		code, err := normalizeGTIN(value, 14)
+		if err != nil {
+			return nil, "", err
+		}
+		bc, err := twooffive.Encode(code, true)
+		return bc, code, err
+	default:
+		return nil, "", fmt.Errorf("unsupported symbology %q", symbology)
+	}
+}
+
+// barcodeRenderOptions controls how a 1D barcode is rendered.
+type barcodeRenderOptions struct {
+	// ModuleWidth is the width of the narrowest bar in pixels.
+	ModuleWidth int
+
+	// BarHeight is the height of the bars in pixels.
+	BarHeight int
+
+	// HumanReadable prints the text below the bars.
+	HumanReadable bool
+
+	// BearerBars frames the bars and quiet zones with a rectangle, as used by ITF-14.
+	BearerBars bool
+}
+
+// renderBarcodePNG renders a 1D barcode as a PNG image, optionally printing text below the bars.
+func renderBarcodePNG(bc barcode.Barcode, text string, opts barcodeRenderOptions) ([]byte, error) {
+	modules := bc.Bounds().Dx()
+	moduleWidth := opts.ModuleWidth
+	quietZone := barcodeQuietZoneModules * moduleWidth
+
+	// Bearer bars are twice the narrow bar width and enclose the quiet zones
+	bearer := 0
+	if opts.BearerBars {
+		bearer = 2 * moduleWidth
+	}
+
+	// Scale the built-in bitmap font with the module width so the digits stay legible
+	textScale := max(1, moduleWidth-1)
+	textHeight := 0
+	if opts.HumanReadable {
+		textHeight = (basicfont.Face7x13.Height + 4) * textScale
+	}
+
+	width := modules*moduleWidth + 2*quietZone + 2*bearer
+	barsTop := quietZone/4 + bearer
+	img := image.NewGray(image.Rect(0, 0, width, barsTop+opts.BarHeight+bearer+textHeight+quietZone/4))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for x := 0; x < modules; x++ {
+		if !isDark(bc.At(bc.Bounds().Min.X+x, bc.Bounds().Min.Y)) {
+			continue
+		}
+		left := bearer + quietZone + x*moduleWidth
+		bar := image.Rect(left, barsTop, left+moduleWidth, barsTop+opts.BarHeight)
+		draw.Draw(img, bar, image.Black, image.Point{}, draw.Src)
+	}
+
+	if opts.BearerBars {
+		frame := image.Rect(0, barsTop-bearer, width, barsTop+opts.BarHeight+bearer)
+		for _, side := range []image.Rectangle{
+			image.Rect(frame.Min.X, frame.Min.Y, frame.Max.X, frame.Min.Y+bearer),
+			image.Rect(frame.Min.X, frame.Max.Y-bearer, frame.Max.X, frame.Max.Y),
+			image.Rect(frame.Min.X, frame.Min.Y, frame.Min.X+bearer, frame.Max.Y),
+			image.Rect(frame.Max.X-bearer, frame.Min.Y, frame.Max.X, frame.Max.Y),
+		} {
+			draw.Draw(img, side, image.Black, image.Point{}, draw.Src)
+		}
+	}
+
+	if opts.HumanReadable {
+		label := renderLabel(text, textScale)
+		offset := image.Pt((width-label.Bounds().Dx())/2, barsTop+opts.BarHeight+bearer+2*textScale)
+		draw.Draw(img, label.Bounds().Add(offset), label, image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderLabel draws text with the built-in bitmap font, scaled by an integer factor.
+func renderLabel(text string, scale int) *image.Gray {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+
+	small := image.NewGray(image.Rect(0, 0, width, face.Height))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+
+	drawer := font.Drawer{
+		Dst:  small,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	drawer.DrawString(text)
+
+	// Nearest-neighbor scaling keeps the glyph edges crisp
+	scaled := image.NewGray(image.Rect(0, 0, width*scale, face.Height*scale))
+	for y := 0; y < scaled.Bounds().Dy(); y++ {
+		for x := 0; x < scaled.Bounds().Dx(); x++ {
+			scaled.SetGray(x, y, small.GrayAt(x/scale, y/scale))
+		}
+	}
+
+	return scaled
+}
+
+// isDark reports whether c should be rendered as a dark module.
+func isDark(c color.Color) bool {
+	gray, ok := color.GrayModel.Convert(c).(color.Gray)
+	return ok && gray.Y < 0x80
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+// TestNormalizeGTIN verifies check digit computation and validation.
+func TestNormalizeGTIN(t *testing.T) {
+	testCases := map[string]struct {
+		value    string
+		length   int
+		expected string
+		wantErr  bool
+	}{
+		"ean13 computed":  {value: "400638133393", length: 13, expected: "4006381333931"},
+		"ean13 valid":     {value: "4006381333931", length: 13, expected: "4006381333931"},
+		"ean13 invalid":   {value: "4006381333932", length: 13, wantErr: true},
+		"upca computed":   {value: "03600029145", length: 12, expected: "036000291452"},
+		"upca valid":      {value: "036000291452", length: 12, expected: "036000291452"},
+		"non-digit":       {value: "40063813339a", length: 13, wantErr: true},
+		"too short":       {value: "12345", length: 13, wantErr: true},
+		"check digit 0":   {value: "978030640615", length: 13, expected: "9780306406157"},
+		"all zero digits": {value: "00000000000", length: 12, expected: "000000000000"},
+		"itf14 computed":  {value: "1540014128876", length: 14, expected: "15400141288763"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := normalizeGTIN(testCase.value, testCase.length)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+// TestRenderBarcodePNG verifies the rendered image dimensions for each symbology.
+func TestRenderBarcodePNG(t *testing.T) {
+	testCases := map[string]struct {
+		symbology  string
+		value      string
+		modules    int
+		bearerBars bool
+	}{
+		"ean13":  {symbology: symbologyEAN13, value: "400638133393", modules: 95},
+		"upca":   {symbology: symbologyUPCA, value: "03600029145", modules: 95},
+		"code39": {symbology: symbologyCode39, value: "ABC-123", modules: 116},
+		"itf14":  {symbology: symbologyITF14, value: "1540014128876", modules: 135, bearerBars: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bc, text, err := encodeBarcode(testCase.symbology, testCase.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			pngData, err := renderBarcodePNG(bc, text, barcodeRenderOptions{
+				ModuleWidth:   2,
+				BarHeight:     50,
+				HumanReadable: true,
+				BearerBars:    testCase.bearerBars,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(pngData))
+			if err != nil {
+				t.Fatalf("failed to decode PNG: %s", err)
+			}
+
+			expectedWidth := (testCase.modules + 2*barcodeQuietZoneModules) * 2
+			if testCase.bearerBars {
+				expectedWidth += 2 * 2 * 2
+			}
+			if img.Bounds().Dx() != expectedWidth {
+				t.Errorf("expected width %d, got %d", expectedWidth, img.Bounds().Dx())
+			}
+		})
+	}
+}
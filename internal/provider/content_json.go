@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// canonicalJSON serializes a Terraform value as canonical JSON: object keys sorted, set elements
+// sorted by their serialization, no insignificant whitespace and numbers in their shortest exact
+// form, so that equal values always encode the same. It reports false when any part of the value
+// is unknown.
+func canonicalJSON(value attr.Value) (string, bool) {
+	v, ok := jsonValue(value)
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", false
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// jsonValue converts a Terraform value to the value encoding/json serializes, with objects and
+// maps as Go maps, whose keys it sorts.
+func jsonValue(value attr.Value) (interface{}, bool) {
+	if value.IsUnknown() {
+		return nil, false
+	}
+	if value.IsNull() {
+		return nil, true
+	}
+
+	switch v := value.(type) {
+	case basetypes.DynamicValue:
+		return jsonValue(v.UnderlyingValue())
+	case basetypes.StringValue:
+		return v.ValueString(), true
+	case basetypes.BoolValue:
+		return v.ValueBool(), true
+	case basetypes.NumberValue:
+		number := v.ValueBigFloat()
+		if number.IsInt() {
+			return json.Number(number.Text('f', 0)), true
+		}
+		return json.Number(number.Text('g', -1)), true
+	case basetypes.ObjectValue:
+		return jsonObject(v.Attributes())
+	case basetypes.MapValue:
+		return jsonObject(v.Elements())
+	case basetypes.ListValue:
+		return jsonArray(v.Elements(), false)
+	case basetypes.TupleValue:
+		return jsonArray(v.Elements(), false)
+	case basetypes.SetValue:
+		return jsonArray(v.Elements(), true)
+	default:
+		return nil, false
+	}
+}
+
+// jsonObject converts the attributes of an object or the elements of a map.
+func jsonObject(elements map[string]attr.Value) (interface{}, bool) {
+	object := make(map[string]interface{}, len(elements))
+	for key, element := range elements {
+		v, ok := jsonValue(element)
+		if !ok {
+			return nil, false
+		}
+		object[key] = v
+	}
+
+	return object, true
+}
+
+// jsonArray converts the elements of a list, tuple or set. Sets have no order, so their elements
+// are sorted by their serialization.
+func jsonArray(elements []attr.Value, sorted bool) (interface{}, bool) {
+	array := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		v, ok := jsonValue(element)
+		if !ok {
+			return nil, false
+		}
+		array = append(array, v)
+	}
+
+	if sorted {
+		sort.SliceStable(array, func(i, j int) bool {
+			a, _ := json.Marshal(array[i])
+			b, _ := json.Marshal(array[j])
+			return string(a) < string(b)
+		})
+	}
+
+	return array, true
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestCanonicalJSON verifies that values serialize with sorted keys, compact separators and exact
+// numbers, and that unknown values are reported.
+func TestCanonicalJSON(t *testing.T) {
+	object := func(attributes map[string]attr.Value) attr.Value {
+		attributeTypes := make(map[string]attr.Type, len(attributes))
+		for name, value := range attributes {
+			attributeTypes[name] = value.Type(context.Background())
+		}
+		return types.ObjectValueMust(attributeTypes, attributes)
+	}
+
+	testCases := map[string]struct {
+		value    attr.Value
+		expected string
+		unknown  bool
+	}{
+		"sorted keys": {
+			value: types.DynamicValue(object(map[string]attr.Value{
+				"ssid":     types.StringValue("Guest"),
+				"hidden":   types.BoolValue(false),
+				"channels": types.TupleValueMust([]attr.Type{types.NumberType, types.NumberType}, []attr.Value{types.NumberValue(big.NewFloat(6)), types.NumberValue(big.NewFloat(11))}),
+				"extra":    types.StringNull(),
+			})),
+			expected: `{"channels":[6,11],"extra":null,"hidden":false,"ssid":"Guest"}`,
+		},
+		"numbers": {
+			value:    types.ListValueMust(types.NumberType, []attr.Value{types.NumberValue(big.NewFloat(1000000)), types.NumberValue(big.NewFloat(0.5)), types.NumberValue(big.NewFloat(-3))}),
+			expected: `[1000000,0.5,-3]`,
+		},
+		"unescaped html": {
+			value:    types.MapValueMust(types.StringType, map[string]attr.Value{"url": types.StringValue("https://example.com/?a=1&b=<2>")}),
+			expected: `{"url":"https://example.com/?a=1&b=<2>"}`,
+		},
+		"sorted set": {
+			value:    types.SetValueMust(types.StringType, []attr.Value{types.StringValue("b"), types.StringValue("a")}),
+			expected: `["a","b"]`,
+		},
+		"nested unknown": {
+			value:   types.DynamicValue(object(map[string]attr.Value{"ssid": types.StringUnknown()})),
+			unknown: true,
+		},
+		"unknown": {
+			value:   types.DynamicUnknown(),
+			unknown: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, ok := canonicalJSON(testCase.value)
+			if ok == testCase.unknown {
+				t.Fatalf("expected known %t, got %t", !testCase.unknown, ok)
+			}
+			if actual != testCase.expected {
+				t.Errorf("expected %s, got %s", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceCreateContentJSON verifies that content_json encodes the canonical JSON of the
+// configured object.
+func TestQRCodeResourceCreateContentJSON(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"b": tftypes.Number, "a": tftypes.String}}
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"content_json": tftypes.NewValue(objectType, map[string]tftypes.Value{
+			"b": tftypes.NewValue(tftypes.Number, 2),
+			"a": tftypes.NewValue(tftypes.String, "x"),
+		}),
+	})}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	resp.State.Get(ctx, &state)
+	data, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode content_base64: %s", err)
+	}
+	if text, err := decodeQRCodeImage(data); err != nil || text != `{"a":"x","b":2}` {
+		t.Errorf("expected the canonical JSON, got %q: %v", text, err)
+	}
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.ConfigValidator   = contentSourcesValidator{}
+	_ datasource.ConfigValidator = contentSourcesValidator{}
+)
+
+// contentSourcesValidator requires exactly one of the attributes that give a QR code its content
+// to be configured. Unlike resourcevalidator.ExactlyOneOf, it reports every conflicting attribute
+// at its own path, naming the attribute it conflicts with, so that a module setting both text and
+// sensitive_text is pointed at the line to remove.
+type contentSourcesValidator struct {
+	// attributes are the root attributes and blocks that give the content, in the order of the
+	// schema documentation.
+	attributes []string
+}
+
+// Description returns a plain text description of the validator.
+func (v contentSourcesValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("Exactly one of %s must be configured.", v.list())
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v contentSourcesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateResource checks the configuration of a resource.
+func (v contentSourcesValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(v.validate(ctx, req.Config)...)
+}
+
+// ValidateDataSource checks the configuration of a data source.
+func (v contentSourcesValidator) ValidateDataSource(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(v.validate(ctx, req.Config)...)
+}
+
+// validate reports every configured attribute after the first as conflicting with it, and a
+// configuration without any of the attributes as missing its content. Attributes that are not
+// known yet may turn out to be null, so they neither conflict nor count as content.
+func (v contentSourcesValidator) validate(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	configured := ""
+	unknown := false
+	for _, attribute := range v.attributes {
+		var value attr.Value
+		if getDiags := config.GetAttribute(ctx, path.Root(attribute), &value); getDiags.HasError() {
+			diags.Append(getDiags...)
+			return diags
+		}
+
+		switch {
+		case value.IsUnknown():
+			unknown = true
+		case value.IsNull():
+		case configured == "":
+			configured = attribute
+		default:
+			diags.AddAttributeError(
+				path.Root(attribute),
+				"Conflicting QR Code Content",
+				fmt.Sprintf("%s cannot be set together with %s. The content of a QR code comes from exactly one of %s, so remove one of them.", attribute, configured, v.list()),
+			)
+		}
+	}
+
+	if configured == "" && !unknown {
+		diags.AddError(
+			"Missing QR Code Content",
+			fmt.Sprintf("None of %s is set. Set exactly one of them to give the QR code its content.", v.list()),
+		)
+	}
+
+	return diags
+}
+
+// list returns the attributes as an English list, such as "text, sensitive_text or content_file".
+func (v contentSourcesValidator) list() string {
+	if len(v.attributes) < 2 {
+		return strings.Join(v.attributes, "")
+	}
+	return strings.Join(v.attributes[:len(v.attributes)-1], ", ") + " or " + v.attributes[len(v.attributes)-1]
+}
+
+// emptyContentDiagnostics reports empty content unless allowEmpty is set, so that a failed lookup
+// upstream fails loudly rather than yielding a blank QR code. Content of only whitespace is still
+// encoded, as it was before allow_empty was added.
+func emptyContentDiagnostics(content string, allowEmpty types.Bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if allowEmpty.ValueBool() || content != "" {
+		return diags
+	}
+
+	diags.AddError(
+		"Empty QR Code Content",
+		"The QR code content is empty, so the QR code would not encode anything useful. Check the value the content comes from, or set allow_empty to true to generate the QR code anyway.",
+	)
+	return diags
+}
+
+// readContentFile reads the content_file of a data source, which is read on the machine running
+// Terraform.
+func readContentFile(name string) (string, error) {
+	data, err := os.ReadFile(hostPath(name))
+	if err != nil {
+		return "", fmt.Errorf("could not read content_file: %w", err)
+	}
+	return string(data), nil
+}
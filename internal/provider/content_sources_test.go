@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestContentSourcesValidator verifies that every attribute conflicting with the content is
+// reported at its own path, and that a configuration without content is rejected.
+func TestContentSourcesValidator(t *testing.T) {
+	ctx := context.Background()
+
+	schemaResp := &fwresource.SchemaResponse{}
+	(&qrcodeResource{}).Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	var validator contentSourcesValidator
+	for _, v := range (&qrcodeResource{}).ConfigValidators(ctx) {
+		if contentValidator, ok := v.(contentSourcesValidator); ok {
+			validator = contentValidator
+		}
+	}
+	if len(validator.attributes) == 0 {
+		t.Fatal("expected the resource to validate its content sources")
+	}
+
+	text := tftypes.NewValue(tftypes.String, "https://example.com")
+	testCases := map[string]struct {
+		config        map[string]tftypes.Value
+		expectedPaths []path.Path
+		expectMissing bool
+	}{
+		"text": {
+			config: map[string]tftypes.Value{"text": text},
+		},
+		"unknown text": {
+			config: map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, tftypes.UnknownValue)},
+		},
+		"no content": {
+			config:        map[string]tftypes.Value{},
+			expectMissing: true,
+		},
+		"text and sensitive_text": {
+			config: map[string]tftypes.Value{
+				"text":           text,
+				"sensitive_text": text,
+			},
+			expectedPaths: []path.Path{path.Root("sensitive_text")},
+		},
+		"text and content_file": {
+			config: map[string]tftypes.Value{
+				"text":         text,
+				"content_file": tftypes.NewValue(tftypes.String, "contact.vcf"),
+			},
+			expectedPaths: []path.Path{path.Root("content_file")},
+		},
+		"three sources": {
+			config: map[string]tftypes.Value{
+				"text":                text,
+				"sensitive_text_env":  tftypes.NewValue(tftypes.String, "QR_TEXT"),
+				"sensitive_text_path": tftypes.NewValue(tftypes.String, "/run/secrets/qr"),
+			},
+			expectedPaths: []path.Path{path.Root("sensitive_text_env"), path.Root("sensitive_text_path")},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)},
+			}
+			resp := &fwresource.ValidateConfigResponse{}
+			validator.ValidateResource(ctx, req, resp)
+
+			var paths []path.Path
+			missing := false
+			for _, d := range resp.Diagnostics.Errors() {
+				if withPath, ok := d.(interface{ Path() path.Path }); ok {
+					paths = append(paths, withPath.Path())
+				} else if d.Summary() == "Missing QR Code Content" {
+					missing = true
+				}
+			}
+
+			if missing != testCase.expectMissing {
+				t.Errorf("expected missing content %t, got diagnostics: %v", testCase.expectMissing, resp.Diagnostics)
+			}
+			if len(paths) != len(testCase.expectedPaths) {
+				t.Fatalf("expected errors at %v, got diagnostics: %v", testCase.expectedPaths, resp.Diagnostics)
+			}
+			for i, expected := range testCase.expectedPaths {
+				if !paths[i].Equal(expected) {
+					t.Errorf("expected an error at %s, got %s", expected, paths[i])
+				}
+			}
+		})
+	}
+}
+
+// TestQRCodeDataSourceContentFile verifies that the data sources encode the content of
+// content_file as they encode the same text.
+func TestQRCodeDataSourceContentFile(t *testing.T) {
+	ctx := context.Background()
+
+	contentFile := filepath.Join(t.TempDir(), "contact.vcf")
+	if err := os.WriteFile(contentFile, []byte("BEGIN:VCARD\nEND:VCARD\n"), 0o600); err != nil {
+		t.Fatalf("failed to write the content file: %v", err)
+	}
+
+	for name, testCase := range map[string]struct {
+		dataSource datasource.DataSource
+		checksum   string
+	}{
+		"ascii": {dataSource: NewQRCodeASCIIDataSource(), checksum: "ascii_sha256"},
+		"image": {dataSource: NewQRCodeImageDataSource(), checksum: "sha256"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := testCase.dataSource
+			schemaResp := &datasource.SchemaResponse{}
+			d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+			read := func(values map[string]tftypes.Value) types.String {
+				t.Helper()
+
+				config := tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+				resp := &datasource.ReadResponse{
+					State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+				}
+				d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+				}
+
+				var checksum types.String
+				resp.State.GetAttribute(ctx, path.Root(testCase.checksum), &checksum)
+				return checksum
+			}
+
+			fromFile := read(map[string]tftypes.Value{"content_file": tftypes.NewValue(tftypes.String, contentFile)})
+			fromText := read(map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, "BEGIN:VCARD\nEND:VCARD\n")})
+			if fromFile.IsNull() || !fromFile.Equal(fromText) {
+				t.Errorf("expected content_file to encode like its text, got %s and %s", fromFile, fromText)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceContentFile verifies that the resource encodes the content of content_file as
+// it encodes the same text.
+func TestQRCodeResourceContentFile(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	contentFile := filepath.Join(t.TempDir(), "contact.vcf")
+	if err := os.WriteFile(contentFile, []byte("BEGIN:VCARD\nEND:VCARD\n"), 0o600); err != nil {
+		t.Fatalf("failed to write the content file: %v", err)
+	}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	create := func(values map[string]tftypes.Value) qrcodeResourceModel {
+		t.Helper()
+
+		plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+		resp := &fwresource.CreateResponse{
+			State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+			Identity: newIdentity(),
+		}
+		r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		var state qrcodeResourceModel
+		resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+		return state
+	}
+
+	fromFile := create(map[string]tftypes.Value{"content_file": tftypes.NewValue(tftypes.String, contentFile)})
+	fromText := create(map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, "BEGIN:VCARD\nEND:VCARD\n")})
+	if fromFile.SHA256.IsNull() || !fromFile.SHA256.Equal(fromText.SHA256) {
+		t.Errorf("expected content_file to encode like its text, got %s and %s", fromFile.SHA256, fromText.SHA256)
+	}
+}
+
+// TestQRCodeResourceContentFileChanged verifies that a plan regenerates the image when content_file
+// is edited outside Terraform, and that an apply refuses content that changed since the plan.
+func TestQRCodeResourceContentFileChanged(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	contentFile := filepath.Join(t.TempDir(), "contact.vcf")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(contentFile, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write the content file: %v", err)
+		}
+	}
+	write("BEGIN:VCARD\nFN:Alice\nEND:VCARD\n")
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"content_file": tftypes.NewValue(tftypes.String, contentFile),
+	})}
+	createResp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: config.Raw}}, createResp, "")
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", createResp.Diagnostics)
+	}
+
+	// Terraform proposes the state as is, as the configuration did not change
+	write("BEGIN:VCARD\nFN:Bob\nEND:VCARD\n")
+	planReq := fwresource.ModifyPlanRequest{
+		Config: config,
+		State:  createResp.State,
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: createResp.State.Raw},
+	}
+	planResp := &fwresource.ModifyPlanResponse{Plan: planReq.Plan}
+	r.ModifyPlan(ctx, planReq, planResp)
+	if planResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", planResp.Diagnostics)
+	}
+
+	var planned qrcodeResourceModel
+	planResp.Diagnostics.Append(planResp.Plan.Get(ctx, &planned)...)
+	if planResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", planResp.Diagnostics)
+	}
+	if expected := computeSHA256("BEGIN:VCARD\nFN:Bob\nEND:VCARD\n"); planned.ContentFileSHA256.ValueString() != expected {
+		t.Errorf("expected content_file_sha256 %s, got %s", expected, planned.ContentFileSHA256)
+	}
+	if !planned.SHA256.IsUnknown() {
+		t.Errorf("expected the image to be regenerated, got sha256 %s", planned.SHA256)
+	}
+
+	write("BEGIN:VCARD\nFN:Carol\nEND:VCARD\n")
+	updateResp := &fwresource.UpdateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: planResp.Plan.Raw},
+		Identity: createResp.Identity,
+	}
+	r.Update(ctx, fwresource.UpdateRequest{Plan: planResp.Plan, State: createResp.State}, updateResp)
+	if !updateResp.Diagnostics.HasError() {
+		t.Error("expected content changed after the plan to fail the apply")
+	}
+}
+
+// TestQRCodeResourceEmptyContent verifies that empty content fails the plan unless allow_empty is
+// set, and that content of only whitespace is still encoded.
+func TestQRCodeResourceEmptyContent(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config      map[string]tftypes.Value
+		expectError bool
+	}{
+		"text": {
+			config: map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, "https://example.com")},
+		},
+		"empty text": {
+			config:      map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, "")},
+			expectError: true,
+		},
+		"whitespace": {
+			config: map[string]tftypes.Value{"sensitive_text": tftypes.NewValue(tftypes.String, " \n")},
+		},
+		"empty text allowed": {
+			config: map[string]tftypes.Value{
+				"text":        tftypes.NewValue(tftypes.String, ""),
+				"allow_empty": tftypes.NewValue(tftypes.Bool, true),
+			},
+		},
+		"unknown text": {
+			config: map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, tftypes.UnknownValue)},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+
+			r.ModifyPlan(ctx, req, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Errorf("expected error %t, got diagnostics: %v", testCase.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// TestQRCodeDataSourceEmptyContent verifies that the data sources reject empty content unless
+// allow_empty is set.
+func TestQRCodeDataSourceEmptyContent(t *testing.T) {
+	ctx := context.Background()
+
+	for name, d := range map[string]datasource.DataSource{
+		"ascii": NewQRCodeASCIIDataSource(),
+		"image": NewQRCodeImageDataSource(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			schemaResp := &datasource.SchemaResponse{}
+			d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+			for _, allowEmpty := range []bool{false, true} {
+				config := tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+					"text":        tftypes.NewValue(tftypes.String, ""),
+					"allow_empty": tftypes.NewValue(tftypes.Bool, allowEmpty),
+				})}
+				resp := &datasource.ReadResponse{
+					State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+				}
+				d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+				if resp.Diagnostics.HasError() == allowEmpty {
+					t.Errorf("allow_empty %t: unexpected diagnostics: %v", allowEmpty, resp.Diagnostics)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource                     = &qrcodeImageDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &qrcodeImageDataSource{}
+	_ datasource.DataSourceWithValidateConfig   = &qrcodeImageDataSource{}
+	_ datasource.DataSourceWithConfigure        = &qrcodeImageDataSource{}
+)
+
+// imageMediaTypes maps the formats of the qrcode_image data source to the media types of their
+// data URIs.
+var imageMediaTypes = map[string]string{
+	imageFormatPNG: "image/png",
+	imageFormatSVG: "image/svg+xml",
+}
+
+// qrcodeImageDataSource is the data source implementation.
+type qrcodeImageDataSource struct {
+	// sizeLimits are the sizes that are rendered, as set by the provider min_size and max_size.
+	sizeLimits sizeLimits
+}
+
+// qrcodeImageDataSourceModel maps the qrcode_image data source schema data.
+type qrcodeImageDataSourceModel struct {
+	Text                types.String `tfsdk:"text"`
+	SensitiveText       types.String `tfsdk:"sensitive_text"`
+	ContentFile         types.String `tfsdk:"content_file"`
+	AllowEmpty          types.Bool   `tfsdk:"allow_empty"`
+	ErrorCorrection     types.String `tfsdk:"error_correction"`
+	Format              types.String `tfsdk:"format"`
+	Size                types.Int64  `tfsdk:"size"`
+	ForegroundColor     types.String `tfsdk:"foreground_color"`
+	BackgroundColor     types.String `tfsdk:"background_color"`
+	QuietZone           types.Int64  `tfsdk:"quiet_zone"`
+	LogoFile            types.String `tfsdk:"logo_file"`
+	LogoSizePercent     types.Int64  `tfsdk:"logo_size_percent"`
+	ContentBase64       types.String `tfsdk:"content_base64"`
+	DataURI             types.String `tfsdk:"data_uri"`
+	SHA256              types.String `tfsdk:"sha256"`
+	QRVersion           types.Int64  `tfsdk:"qr_version"`
+	ModuleCount         types.Int64  `tfsdk:"module_count"`
+	ErrorCorrectionUsed types.String `tfsdk:"error_correction_used"`
+}
+
+// NewQRCodeImageDataSource is a helper function to simplify the provider implementation.
+func NewQRCodeImageDataSource() datasource.DataSource {
+	return &qrcodeImageDataSource{}
+}
+
+// Metadata returns the data source type name.
+func (d *qrcodeImageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image"
+}
+
+// Configure receives the provider size limits.
+func (d *qrcodeImageDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.sizeLimits = data.SizeLimits
+}
+
+// Schema defines the schema for the data source.
+func (d *qrcodeImageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_image` data source renders a QR code as a PNG or SVG image without writing a file, for embedding in HTML templates, emails or other resources through `content_base64` or `data_uri`. Use the `qrcode_generate` resource to write image files, and the `qrcode_ascii` data source for text renderings.",
+
+		Attributes: map[string]schema.Attribute{
+			"text": schema.StringAttribute{
+				Optional:    true,
+				Description: "The text to encode as a QR code. Exactly one of `text`, `sensitive_text` or `content_file` must be set.",
+			},
+			"sensitive_text": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Sensitive text to encode as a QR code. Error and warning messages that would quote it give its length and SHA-256 checksum instead. The image is not marked sensitive, so anyone who can read the state can scan it.",
+			},
+			"content_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a file whose content is encoded as a QR code, such as a vCard, read on the machine running Terraform.",
+			},
+			"allow_empty": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to generate a QR code of empty content. By default, empty content, such as the result of a lookup that found nothing, fails the plan, so that a blank QR code is never printed. Content of only whitespace is encoded as is.",
+			},
+			"error_correction": schema.StringAttribute{
+				Optional:    true,
+				Description: "Error correction level: L (low), M (medium, default), Q (high), H (highest), or `auto_max` for the highest level that still fits the text in the version that M needs. The level used is exported in `error_correction_used`.",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive("L", "M", "Q", "H", errorCorrectionAutoMax),
+				},
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Description: "Image format: `png` or `svg`. Defaults to `png`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(imageFormatPNG, imageFormatSVG),
+				},
+			},
+			"size": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Size of the PNG image in pixels, from %d to %d unless the provider sets `min_size` or `max_size`. Defaults to `%d`. SVG images scale to the size they are shown at, so `size` cannot be set when `format` is `svg`.", minSize, maxSize, defaultSize),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"foreground_color": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the dark modules, as a `#RRGGBB` hex color. Defaults to `#000000`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"background_color": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the light modules and the quiet zone, as a `#RRGGBB` hex color. Defaults to `#ffffff`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"quiet_zone": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Width of the light border around the QR code, in modules. Defaults to `4`, which the QR code specification requires.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"logo_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a PNG or JPEG logo drawn over the center of the QR code on a box of `background_color`, read on the machine running Terraform. The box hides the modules under it, which the error correction restores, and reading the data source fails when it hides more than the `error_correction` level restores. Can only be used with the `png` format.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"logo_size_percent": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Width of the box that `logo_file` is drawn on, as a share of the image width in percent, from %d to %d. Defaults to `%d`.", minLogoSizePercent, maxLogoSizePercent, defaultLogoSizePercent),
+				Validators: []validator.Int64{
+					int64validator.Between(minLogoSizePercent, maxLogoSizePercent),
+					int64validator.AlsoRequires(path.MatchRoot("logo_file")),
+				},
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:    true,
+				Description: "Base64-encoded image.",
+			},
+			"data_uri": schema.StringAttribute{
+				Computed:    true,
+				Description: "The image as a `data:` URI, such as `data:image/png;base64,...`, for the `src` of HTML `img` elements.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the image.",
+			},
+			"qr_version": schema.Int64Attribute{
+				Computed:    true,
+				Description: "QR code version of the symbol, from 1 to 40. Each version adds 4 modules to the width of the symbol.",
+			},
+			"module_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Width of the symbol in modules, without the border.",
+			},
+			"error_correction_used": schema.StringAttribute{
+				Computed:    true,
+				Description: "Error correction level of the symbol: L, M, Q or H. Differs from `error_correction` when it is `auto_max`.",
+			},
+		},
+	}
+}
+
+// ConfigValidators returns the cross-attribute validations for the data source configuration.
+func (d *qrcodeImageDataSource) ConfigValidators(_ context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		contentSourcesValidator{
+			attributes: []string{"text", "sensitive_text", "content_file"},
+		},
+	}
+}
+
+// ValidateConfig requires size not to be set for SVG images, and logos to be drawn on PNG images.
+func (d *qrcodeImageDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config qrcodeImageDataSourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	svg := config.Format.ValueString() == imageFormatSVG
+	if svg && !config.Size.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("size"),
+			"Invalid Attribute Combination",
+			"SVG images are vector graphics that scale to the size they are shown at, so size cannot be set with the svg format.",
+		)
+	}
+	if svg && !config.LogoFile.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("logo_file"),
+			"Invalid Attribute Combination",
+			"A logo can only be drawn on the png format, got svg.",
+		)
+	}
+}
+
+// Read renders the QR code image.
+func (d *qrcodeImageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data qrcodeImageDataSourceModel
+
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Never encode unknown content as an empty string
+	if data.Text.IsUnknown() || data.SensitiveText.IsUnknown() || data.ContentFile.IsUnknown() {
+		if req.ClientCapabilities.DeferralAllowed {
+			tflog.Debug(ctx, "Deferring QR code generation until its content is known")
+			resp.Deferred = &datasource.Deferred{
+				Reason: datasource.DeferredReasonDataSourceConfigUnknown,
+			}
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"QR Code Content Unknown",
+			"The QR code content is not known yet and deferred actions are not enabled, so the QR code cannot be generated.",
+		)
+		return
+	}
+
+	level, autoMax, ok := parseErrorCorrection(data.ErrorCorrection.ValueString())
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("error_correction"),
+			"Invalid Error Correction Level",
+			"Supported values: L (low), M (medium), Q (high), H (highest), auto_max.",
+		)
+		return
+	}
+
+	var qrText string
+	var err error
+	switch {
+	case !data.Text.IsNull():
+		qrText = data.Text.ValueString()
+	case !data.ContentFile.IsNull():
+		qrText, err = readContentFile(data.ContentFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content_file"), "QR Code Generation Failed", err.Error())
+			return
+		}
+	default:
+		qrText = data.SensitiveText.ValueString()
+
+		// Errors that echo sensitive text are reported with its length and checksum instead
+		redactor := newRedactor(qrText)
+		defer func() {
+			resp.Diagnostics = redactor.diagnostics(resp.Diagnostics)
+		}()
+	}
+
+	if !data.AllowEmpty.IsUnknown() {
+		resp.Diagnostics.Append(emptyContentDiagnostics(qrText, data.AllowEmpty)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	start := time.Now()
+	symbol, level, err := encodeAtErrorCorrection(qrText, level, autoMax)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"QR Code Generation Failed",
+			"Could not generate QR code: "+err.Error(),
+		)
+		return
+	}
+	if !data.QuietZone.IsNull() {
+		symbol = symbol.WithQuietZone(int(data.QuietZone.ValueInt64()))
+	}
+
+	colors := qrgen.DefaultColors
+	if !data.ForegroundColor.IsNull() {
+		if colors.Dark, err = qrgen.ParseHexColor(data.ForegroundColor.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("foreground_color"), "Invalid Color", err.Error())
+			return
+		}
+	}
+	if !data.BackgroundColor.IsNull() {
+		if colors.Light, err = qrgen.ParseHexColor(data.BackgroundColor.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("background_color"), "Invalid Color", err.Error())
+			return
+		}
+	}
+
+	size := defaultSize
+	if !data.Size.IsNull() {
+		size = int(data.Size.ValueInt64())
+	}
+	if !d.sizeLimits.contains(int64(size)) {
+		smallest, largest := d.sizeLimits.bounds()
+		resp.Diagnostics.AddAttributeError(
+			path.Root("size"),
+			"Invalid Size",
+			fmt.Sprintf("Size must be between %d and %d pixels, got %d. The limits are set by the min_size and max_size provider attributes.", smallest, largest, size),
+		)
+		return
+	}
+
+	format := imageFormatPNG
+	if !data.Format.IsNull() {
+		format = data.Format.ValueString()
+	}
+
+	var image []byte
+	switch format {
+	case imageFormatSVG:
+		image = symbol.SVG(size, colors, "", false)
+	default:
+		image, err = symbol.PNG(size, colors)
+		if err != nil {
+			resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+			return
+		}
+		if !data.LogoFile.IsNull() {
+			percent := defaultLogoSizePercent
+			if !data.LogoSizePercent.IsNull() {
+				percent = int(data.LogoSizePercent.ValueInt64())
+			}
+			encode := func(level qrgen.Level) (*qrgen.Symbol, error) {
+				higher, err := qrgen.Encode(qrText, qrgen.Options{Level: level})
+				if err != nil {
+					return nil, err
+				}
+				if !data.QuietZone.IsNull() {
+					higher = higher.WithQuietZone(int(data.QuietZone.ValueInt64()))
+				}
+				return higher, nil
+			}
+			resp.Diagnostics.Append(logoDiagnostics(symbol, func(*qrgen.Symbol) int { return size }, qrgen.ScalingFill, percent, encode)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			logo, err := os.ReadFile(hostPath(data.LogoFile.ValueString()))
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("logo_file"), "QR Code Generation Failed", fmt.Sprintf("Could not read logo: %s", err))
+				return
+			}
+			image, err = drawLogo(image, logo, percent, colors)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("logo_file"), "QR Code Generation Failed", err.Error())
+				return
+			}
+		}
+	}
+
+	tflog.Debug(ctx, "Rendered QR code image", map[string]interface{}{
+		"version":        symbol.Version(),
+		"format":         format,
+		"image_length":   len(image),
+		"render_time_ms": time.Since(start).Milliseconds(),
+	})
+
+	content := base64.StdEncoding.EncodeToString(image)
+	data.ContentBase64 = types.StringValue(content)
+	data.DataURI = types.StringValue("data:" + imageMediaTypes[format] + ";base64," + content)
+	data.SHA256 = types.StringValue(computeSHA256(string(image)))
+	data.QRVersion = types.Int64Value(int64(symbol.Version()))
+	data.ModuleCount = types.Int64Value(int64(symbol.SymbolModules()))
+	data.ErrorCorrectionUsed = types.StringValue(errorCorrectionNames[level])
+
+	diags = resp.State.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// TestQRCodeImageDataSource verifies that qrcode_image renders PNG and SVG images with their
+// checksum and data URI.
+func TestQRCodeImageDataSource(t *testing.T) {
+	ctx := context.Background()
+	d := &qrcodeImageDataSource{}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config    map[string]tftypes.Value
+		prefix    []byte
+		mediaType string
+		level     string
+	}{
+		"png": {
+			config: map[string]tftypes.Value{
+				"text": tftypes.NewValue(tftypes.String, "https://example.com"),
+			},
+			prefix:    []byte("\x89PNG"),
+			mediaType: "image/png",
+			level:     "M",
+		},
+		"svg": {
+			config: map[string]tftypes.Value{
+				"sensitive_text":   tftypes.NewValue(tftypes.String, "https://example.com"),
+				"format":           tftypes.NewValue(tftypes.String, imageFormatSVG),
+				"error_correction": tftypes.NewValue(tftypes.String, errorCorrectionAutoMax),
+				"foreground_color": tftypes.NewValue(tftypes.String, "#112233"),
+			},
+			prefix:    []byte("<svg"),
+			mediaType: "image/svg+xml",
+			level:     "Q",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw:    testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config),
+			}
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+			}
+
+			d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var model qrcodeImageDataSourceModel
+			resp.State.Get(ctx, &model)
+
+			image, err := base64.StdEncoding.DecodeString(model.ContentBase64.ValueString())
+			if err != nil {
+				t.Fatalf("content_base64 is not base64: %v", err)
+			}
+			if !bytes.Contains(image[:min(len(image), 64)], testCase.prefix) {
+				t.Errorf("expected the image to start with %q, got %q", testCase.prefix, image[:min(len(image), 16)])
+			}
+			if model.SHA256.ValueString() != computeSHA256(string(image)) {
+				t.Errorf("expected sha256 of the image, got %s", model.SHA256)
+			}
+			if !strings.HasPrefix(model.DataURI.ValueString(), "data:"+testCase.mediaType+";base64,") {
+				t.Errorf("expected a %s data URI, got %.40s", testCase.mediaType, model.DataURI.ValueString())
+			}
+			if model.QRVersion.ValueInt64() != 2 || model.ErrorCorrectionUsed.ValueString() != testCase.level {
+				t.Errorf("expected version 2 at level %s, got %s at %s", testCase.level, model.QRVersion, model.ErrorCorrectionUsed)
+			}
+		})
+	}
+}
+
+// TestQRCodeImageDataSourceValidateConfig verifies that size and logos are rejected for SVG images,
+// which scale to the size they are shown at.
+func TestQRCodeImageDataSourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	d := &qrcodeImageDataSource{}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config   map[string]tftypes.Value
+		expected path.Path
+	}{
+		"png size": {
+			config: map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 300)},
+		},
+		"svg size": {
+			config: map[string]tftypes.Value{
+				"size":   tftypes.NewValue(tftypes.Number, 300),
+				"format": tftypes.NewValue(tftypes.String, imageFormatSVG),
+			},
+			expected: path.Root("size"),
+		},
+		"png logo": {
+			config: map[string]tftypes.Value{"logo_file": tftypes.NewValue(tftypes.String, "logo.png")},
+		},
+		"svg logo": {
+			config: map[string]tftypes.Value{
+				"logo_file": tftypes.NewValue(tftypes.String, "logo.png"),
+				"format":    tftypes.NewValue(tftypes.String, imageFormatSVG),
+			},
+			expected: path.Root("logo_file"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testCase.config["text"] = tftypes.NewValue(tftypes.String, "https://example.com")
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+
+			resp := &datasource.ValidateConfigResponse{}
+			d.ValidateConfig(ctx, datasource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+			}, resp)
+
+			expectError := len(testCase.expected.Steps()) > 0
+			if resp.Diagnostics.HasError() != expectError {
+				t.Fatalf("expected error %t, got %v", expectError, resp.Diagnostics)
+			}
+			if expectError {
+				withPath, ok := resp.Diagnostics.Errors()[0].(diag.DiagnosticWithPath)
+				if !ok || !withPath.Path().Equal(testCase.expected) {
+					t.Errorf("expected the error on %s, got %v", testCase.expected, resp.Diagnostics.Errors()[0])
+				}
+			}
+		})
+	}
+}
+
+// TestQRCodeImageDataSourceLogo verifies that logo_file is drawn over PNG images, which still
+// decode, and that logos hiding more than the error correction level restores are rejected.
+func TestQRCodeImageDataSourceLogo(t *testing.T) {
+	ctx := context.Background()
+	d := &qrcodeImageDataSource{}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	logoPath := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(logoPath, testLogoPNG(t), 0o644); err != nil {
+		t.Fatalf("failed to write the logo: %s", err)
+	}
+
+	for level, expectError := range map[string]bool{"M": false, "L": true} {
+		t.Run(level, func(t *testing.T) {
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+					"text":             tftypes.NewValue(tftypes.String, "https://example.com"),
+					"error_correction": tftypes.NewValue(tftypes.String, level),
+					"logo_file":        tftypes.NewValue(tftypes.String, logoPath),
+				}),
+			}
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+			}
+
+			d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+			if expectError {
+				if len(resp.Diagnostics.Errors()) != 1 || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), "Set error_correction to M") {
+					t.Fatalf("expected level M to be suggested, got %v", resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var model qrcodeImageDataSourceModel
+			resp.State.Get(ctx, &model)
+
+			data, err := base64.StdEncoding.DecodeString(model.ContentBase64.ValueString())
+			if err != nil {
+				t.Fatalf("content_base64 is not base64: %v", err)
+			}
+			if bytes.Equal(data, testRenderPNG(t, "https://example.com")) {
+				t.Errorf("expected the logo to be drawn")
+			}
+			if text, err := decodeQRCodeImage(data); err != nil || text != "https://example.com" {
+				t.Errorf("expected the QR code with a logo to decode, got %q: %v", text, err)
+			}
+		})
+	}
+}
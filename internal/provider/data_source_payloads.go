@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource                     = &qrcodePayloadsDataSource{}
+	_ datasource.DataSourceWithConfigure        = &qrcodePayloadsDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &qrcodePayloadsDataSource{}
+)
+
+// Formats of the files that the qrcode_payloads data source parses.
+const (
+	payloadsFormatCSV  = "csv"
+	payloadsFormatJSON = "json"
+)
+
+// Default columns of the qrcode_payloads data source.
+const (
+	defaultPayloadsKeyColumn     = "key"
+	defaultPayloadsContentColumn = "content"
+)
+
+// payloadsTemplatePattern matches the {column} placeholders of a content template.
+var payloadsTemplatePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// qrcodePayloadsDataSource is the data source implementation.
+type qrcodePayloadsDataSource struct {
+	fs afero.Fs
+}
+
+// qrcodePayloadsDataSourceModel maps the qrcode_payloads data source schema data.
+type qrcodePayloadsDataSourceModel struct {
+	File            types.String `tfsdk:"file"`
+	Format          types.String `tfsdk:"format"`
+	Delimiter       types.String `tfsdk:"delimiter"`
+	KeyColumn       types.String `tfsdk:"key_column"`
+	ContentColumn   types.String `tfsdk:"content_column"`
+	ContentTemplate types.String `tfsdk:"content_template"`
+	Payloads        types.Map    `tfsdk:"payloads"`
+}
+
+// NewQRCodePayloadsDataSource creates a new QR code payloads data source instance.
+func NewQRCodePayloadsDataSource() datasource.DataSource {
+	return &qrcodePayloadsDataSource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the data source type name.
+func (d *qrcodePayloadsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_payloads"
+}
+
+// Configure receives the provider-level filesystem.
+func (d *qrcodePayloadsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.fs = data.Filesystem
+}
+
+// Schema defines the data source schema.
+func (d *qrcodePayloadsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_payloads` data source parses a CSV or JSON file into a map of key to QR code content, designed to feed the `contents` of a `qrcode_directory` resource, so that spreadsheet-driven label runs work end to end in Terraform. A CSV file has a header row naming its columns, and a JSON file holds either an array of row objects or an object of key to content.",
+		Attributes: map[string]schema.Attribute{
+			"file": schema.StringAttribute{
+				Required:    true,
+				Description: "Path of the CSV or JSON file to parse.",
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Description: "Format of the file: `csv` or `json`. Defaults to `json` for files with a `.json` extension and to `csv` otherwise.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(payloadsFormatCSV, payloadsFormatJSON),
+				},
+			},
+			"delimiter": schema.StringAttribute{
+				Optional:    true,
+				Description: "Field delimiter of a CSV file, such as `;` or a tab. Defaults to `,`.",
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 1),
+					stringvalidator.NoneOf("\"", "\r", "\n"),
+				},
+			},
+			"key_column": schema.StringAttribute{
+				Optional:    true,
+				Description: "Column holding the key of each row. Defaults to `key`.",
+			},
+			"content_column": schema.StringAttribute{
+				Optional:    true,
+				Description: "Column holding the content of each row. Defaults to `content`. Conflicts with `content_template`.",
+			},
+			"content_template": schema.StringAttribute{
+				Optional:    true,
+				Description: "Template of the content of each row, in which `{column}` placeholders are replaced by the values of the row's columns, such as `https://example.com/assets/{serial}`. Conflicts with `content_column`.",
+			},
+			"payloads": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Map of the key to the content of every row.",
+			},
+		},
+	}
+}
+
+// ConfigValidators returns the cross-attribute validations for the data source configuration.
+func (d *qrcodePayloadsDataSource) ConfigValidators(_ context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.Conflicting(
+			path.MatchRoot("content_column"),
+			path.MatchRoot("content_template"),
+		),
+	}
+}
+
+// Read parses the file into payloads.
+func (d *qrcodePayloadsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config qrcodePayloadsDataSourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	fileName := config.File.ValueString()
+	data, err := afero.ReadFile(d.fs, hostPath(fileName))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("file"), "Failed to Read Payloads", err.Error())
+		return
+	}
+
+	format := config.Format.ValueString()
+	if format == "" {
+		format = payloadsFormatCSV
+		if strings.EqualFold(filepath.Ext(fileName), ".json") {
+			format = payloadsFormatJSON
+		}
+	}
+
+	payloads, err := config.parse(data, format)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("file"), "Invalid Payloads", fmt.Sprintf("Could not parse %s as %s: %s", fileName, strings.ToUpper(format), err))
+		return
+	}
+
+	tflog.Debug(ctx, "Parsed QR code payloads", map[string]interface{}{
+		"file":     fileName,
+		"format":   format,
+		"payloads": len(payloads),
+	})
+
+	config.Payloads, diags = types.MapValueFrom(ctx, types.StringType, payloads)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// parse returns the payloads of a CSV or JSON file.
+func (m qrcodePayloadsDataSourceModel) parse(data []byte, format string) (map[string]string, error) {
+	// Spreadsheet applications often write a byte order mark in front of UTF-8 files
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var rows []map[string]string
+	if format == payloadsFormatJSON {
+		// An object of key to content needs no column mapping
+		var payloads map[string]string
+		if err := json.Unmarshal(data, &payloads); err == nil {
+			return payloads, nil
+		}
+
+		var err error
+		if rows, err = parseJSONRows(data); err != nil {
+			return nil, err
+		}
+	} else {
+		delimiter := ','
+		if !m.Delimiter.IsNull() {
+			delimiter = []rune(m.Delimiter.ValueString())[0]
+		}
+
+		var err error
+		if rows, err = parseCSVRows(data, delimiter); err != nil {
+			return nil, err
+		}
+	}
+
+	keyColumn := defaultPayloadsKeyColumn
+	if !m.KeyColumn.IsNull() {
+		keyColumn = m.KeyColumn.ValueString()
+	}
+	contentColumn := defaultPayloadsContentColumn
+	if !m.ContentColumn.IsNull() {
+		contentColumn = m.ContentColumn.ValueString()
+	}
+
+	payloads := make(map[string]string, len(rows))
+	for i, row := range rows {
+		key, ok := row[keyColumn]
+		if !ok {
+			return nil, fmt.Errorf("row %d has no %q column", i+1, keyColumn)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("row %d has an empty key", i+1)
+		}
+		if _, ok := payloads[key]; ok {
+			return nil, fmt.Errorf("row %d repeats the key %q", i+1, key)
+		}
+
+		if m.ContentTemplate.IsNull() {
+			content, ok := row[contentColumn]
+			if !ok {
+				return nil, fmt.Errorf("row %d has no %q column", i+1, contentColumn)
+			}
+			payloads[key] = content
+			continue
+		}
+
+		var missing string
+		payloads[key] = payloadsTemplatePattern.ReplaceAllStringFunc(m.ContentTemplate.ValueString(), func(placeholder string) string {
+			value, ok := row[placeholder[1:len(placeholder)-1]]
+			if !ok && missing == "" {
+				missing = placeholder
+			}
+			return value
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("row %d has no column for the %s placeholder", i+1, missing)
+		}
+	}
+
+	return payloads, nil
+}
+
+// parseCSVRows returns the rows of a CSV file keyed by the column names of its header row.
+func parseCSVRows(data []byte, delimiter rune) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file has no header row")
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseJSONRows returns the rows of a JSON array of objects. Numbers and booleans are kept as
+// written, and null values are empty.
+func parseJSONRows(data []byte) ([]map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("expected an array of objects or an object of strings: %w", err)
+	}
+
+	rows := make([]map[string]string, 0, len(objects))
+	for i, object := range objects {
+		row := make(map[string]string, len(object))
+		for column, value := range object {
+			switch value := value.(type) {
+			case nil:
+				row[column] = ""
+			case string:
+				row[column] = value
+			case json.Number:
+				row[column] = value.String()
+			case bool:
+				row[column] = fmt.Sprint(value)
+			default:
+				return nil, fmt.Errorf("row %d: %q is not a string, number or boolean", i+1, column)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestQRCodePayloadsDataSource verifies that qrcode_payloads maps CSV and JSON rows to payloads.
+func TestQRCodePayloadsDataSource(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/labels.csv":    "\xef\xbb\xbfkey,content\nwifi,\"WIFI:S:office;;\"\nsite,https://example.com\n",
+		"/assets.csv":    "serial;room\nA-1;101\nA-2;102\n",
+		"/assets.json":   `[{"serial": "A-1", "room": 101, "spare": null}, {"serial": "A-2", "room": 102, "spare": true}]`,
+		"/contents.json": `{"wifi": "WIFI:S:office;;", "site": "https://example.com"}`,
+		"/duplicate.csv": "key,content\na,1\na,2\n",
+		"/nested.json":   `[{"key": "a", "content": {"b": 1}}]`,
+	}
+	for name, data := range files {
+		if err := afero.WriteFile(fs, name, []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	d := &qrcodePayloadsDataSource{fs: fs}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config   map[string]tftypes.Value
+		expected map[string]string
+	}{
+		"csv": {
+			config:   map[string]tftypes.Value{"file": tftypes.NewValue(tftypes.String, "/labels.csv")},
+			expected: map[string]string{"wifi": "WIFI:S:office;;", "site": "https://example.com"},
+		},
+		"csv template": {
+			config: map[string]tftypes.Value{
+				"file":             tftypes.NewValue(tftypes.String, "/assets.csv"),
+				"delimiter":        tftypes.NewValue(tftypes.String, ";"),
+				"key_column":       tftypes.NewValue(tftypes.String, "serial"),
+				"content_template": tftypes.NewValue(tftypes.String, "https://example.com/assets/{serial}?room={room}"),
+			},
+			expected: map[string]string{"A-1": "https://example.com/assets/A-1?room=101", "A-2": "https://example.com/assets/A-2?room=102"},
+		},
+		"json rows": {
+			config: map[string]tftypes.Value{
+				"file":           tftypes.NewValue(tftypes.String, "/assets.json"),
+				"key_column":     tftypes.NewValue(tftypes.String, "serial"),
+				"content_column": tftypes.NewValue(tftypes.String, "room"),
+			},
+			expected: map[string]string{"A-1": "101", "A-2": "102"},
+		},
+		"json object": {
+			config:   map[string]tftypes.Value{"file": tftypes.NewValue(tftypes.String, "/contents.json")},
+			expected: map[string]string{"wifi": "WIFI:S:office;;", "site": "https://example.com"},
+		},
+		"duplicate key": {
+			config: map[string]tftypes.Value{"file": tftypes.NewValue(tftypes.String, "/duplicate.csv")},
+		},
+		"nested value": {
+			config: map[string]tftypes.Value{"file": tftypes.NewValue(tftypes.String, "/nested.json")},
+		},
+		"missing column": {
+			config: map[string]tftypes.Value{
+				"file":       tftypes.NewValue(tftypes.String, "/labels.csv"),
+				"key_column": tftypes.NewValue(tftypes.String, "serial"),
+			},
+		},
+		"missing placeholder": {
+			config: map[string]tftypes.Value{
+				"file":             tftypes.NewValue(tftypes.String, "/assets.csv"),
+				"delimiter":        tftypes.NewValue(tftypes.String, ";"),
+				"key_column":       tftypes.NewValue(tftypes.String, "serial"),
+				"content_template": tftypes.NewValue(tftypes.String, "{building}"),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw:    testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config),
+			}
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+			}
+
+			d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+
+			if testCase.expected == nil {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var model qrcodePayloadsDataSourceModel
+			resp.State.Get(ctx, &model)
+
+			payloads := map[string]string{}
+			model.Payloads.ElementsAs(ctx, &payloads, false)
+
+			if !reflect.DeepEqual(payloads, testCase.expected) {
+				t.Errorf("expected payloads %v, got %v", testCase.expected, payloads)
+			}
+		})
+	}
+}
@@ -4,56 +4,101 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/skip2/go-qrcode"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-qrcode/pkg/qrgen"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
-var _ datasource.DataSource = &QRCodeDataSource{}
+var (
+	_ datasource.DataSource                     = &QRCodeDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &QRCodeDataSource{}
+)
+
+// errorCorrectionAutoMax is the error_correction that picks the highest level fitting the version
+// of the default level.
+const errorCorrectionAutoMax = "auto_max"
+
+// errorCorrectionNames maps error correction levels to their error_correction letters.
+var errorCorrectionNames = map[qrgen.Level]string{
+	qrgen.Low:     "L",
+	qrgen.Medium:  "M",
+	qrgen.High:    "Q",
+	qrgen.Highest: "H",
+}
+
+// asciiDataSourceDeprecation is the deprecation message of the qrcode_generate data source, which
+// is the qrcode_ascii data source under the name of the qrcode_generate resource.
+const asciiDataSourceDeprecation = "Use the qrcode_ascii data source instead, which has the same attributes, or qrcode_image for images. The qrcode_generate data source shares its name with the qrcode_generate resource while taking different attributes, and will be removed in the next major version."
 
-// QRCodeDataSource defines the QR code data source implementation.
-type QRCodeDataSource struct{}
+// QRCodeDataSource defines the QR code data source implementation, which renders QR codes as text.
+type QRCodeDataSource struct {
+	// typeName is the data source type name after the provider type name, such as _ascii.
+	typeName string
 
-// NewQRCodeDataSource returns a new instance of QRCodeDataSource.
+	// deprecated marks the former qrcode_generate name of the data source.
+	deprecated bool
+}
+
+// NewQRCodeASCIIDataSource returns a new instance of the qrcode_ascii data source.
+func NewQRCodeASCIIDataSource() datasource.DataSource {
+	return &QRCodeDataSource{typeName: "_ascii"}
+}
+
+// NewQRCodeDataSource returns a new instance of the deprecated qrcode_generate data source.
 func NewQRCodeDataSource() datasource.DataSource {
-	return &QRCodeDataSource{}
+	return &QRCodeDataSource{typeName: "_generate", deprecated: true}
 }
 
 // Metadata returns the data source type name.
 func (d *QRCodeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_generate"
+	resp.TypeName = req.ProviderTypeName + d.typeName
 }
 
 // Schema defines the input and output attributes for the QR code data source.
 func (d *QRCodeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	description := fmt.Sprintf("The `qrcode%s` data source allows you to generate QR codes from text input without creating a file. This is useful for dynamically generating QR codes in ASCII format for display in logs, terminal outputs, or other text-based interfaces. It also supports various customization options, such as error correction levels, color inversion, and border removal.", d.typeName)
+	deprecationMessage := ""
+	if d.deprecated {
+		description = "**Deprecated:** " + asciiDataSourceDeprecation + "\n\n" + description
+		deprecationMessage = asciiDataSourceDeprecation
+	}
+
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "The `qrcode_generate` data source allows you to generate QR codes from text input without creating a file. This is useful for dynamically generating QR codes in ASCII format for display in logs, terminal outputs, or other text-based interfaces. It also supports various customization options, such as error correction levels, color inversion, and border removal.",
+		MarkdownDescription: description,
+		DeprecationMessage:  deprecationMessage,
 
 		Attributes: map[string]schema.Attribute{
 			"text": schema.StringAttribute{
-				Description: "The text to encode as a QR code.",
+				Description: "The text to encode as a QR code. Exactly one of `text`, `sensitive_text` or `content_file` must be set.",
 				Optional:    true,
-				Validators: []validator.String{
-					stringvalidator.ExactlyOneOf(
-						path.MatchRoot("text"),
-						path.MatchRoot("sensitive_text"),
-					),
-				},
 			},
 			"sensitive_text": schema.StringAttribute{
-				Description: "Sensitive text to encode as a QR code.",
+				Description: "Sensitive text to encode as a QR code. Error and warning messages that would quote it give its length and SHA-256 checksum instead.",
 				Sensitive:   true,
 				Optional:    true,
 			},
+			"content_file": schema.StringAttribute{
+				Description: "Path of a file whose content is encoded as a QR code, such as a vCard, read on the machine running Terraform.",
+				Optional:    true,
+			},
+			"allow_empty": schema.BoolAttribute{
+				Description: "Set to true to generate a QR code of empty content. By default, empty content, such as the result of a lookup that found nothing, fails the plan, so that a blank QR code is never printed. Content of only whitespace is encoded as is.",
+				Optional:    true,
+			},
 			"error_correction": schema.StringAttribute{
-				Description: "Error correction level: L (low), M (medium, default), Q (high), H (highest).",
+				Description: "Error correction level: L (low), M (medium, default), Q (high), H (highest), or `auto_max` for the highest level that still fits the text in the version that M needs, so that printed codes tolerate the most damage without growing. The level used is exported in `error_correction_used`.",
 				Optional:    true,
 			},
 			"disable_border": schema.BoolAttribute{
@@ -64,6 +109,41 @@ func (d *QRCodeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 				Description: "Set to true to invert black and white colors.",
 				Optional:    true,
 			},
+			"ascii_dark_char": schema.StringAttribute{
+				Description: fmt.Sprintf("Character that dark modules are drawn with in `ascii`, such as `#`. When any of `ascii_dark_char`, `ascii_light_char` and `ascii_quiet_zone_char` is set, every module is drawn as two characters on a line per module row, instead of half blocks packing two module rows per line, for monospaced email templates and chat code blocks where block characters render poorly. Defaults to `%s`.", defaultASCIIDarkChar),
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.UTF8LengthBetween(1, 1),
+				},
+			},
+			"ascii_light_char": schema.StringAttribute{
+				Description: "Character that light modules are drawn with in `ascii`, such as `.`. See `ascii_dark_char`. Defaults to a space.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.UTF8LengthBetween(1, 1),
+				},
+			},
+			"ascii_quiet_zone_char": schema.StringAttribute{
+				Description: "Character that the quiet zone around the symbol is drawn with in `ascii`, so that the border stays visible where spaces are trimmed or blend into the background. See `ascii_dark_char`. Defaults to `ascii_light_char`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.UTF8LengthBetween(1, 1),
+				},
+			},
+			"quiet_zone_chars": schema.Int64Attribute{
+				Description: "Width of the quiet zone around `ascii`, in modules, so that the text rendering keeps a visible border when pasted onto dark or colored backgrounds, such as dark terminals. A module is one character wide in the default half blocks, and two characters wide when `ascii_dark_char`, `ascii_light_char` or `ascii_quiet_zone_char` is set. Takes precedence over `disable_border`. Defaults to `4`, or `0` when `disable_border` is set.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"capacity_warning_percent": schema.Float64Attribute{
+				Description: "Share of the data capacity of the largest QR code, in percent, above which a warning reports that the content is approaching the limit where generation fails. Compare with `capacity_used_percent`. Defaults to `90`.",
+				Optional:    true,
+				Validators: []validator.Float64{
+					float64validator.Between(0, 100),
+				},
+			},
 			"ascii": schema.StringAttribute{
 				Description: "ASCII text representation of the QR code.",
 				Computed:    true,
@@ -72,6 +152,35 @@ func (d *QRCodeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 				Description: "SHA-256 checksum of the ASCII QR code.",
 				Computed:    true,
 			},
+			"qr_version": schema.Int64Attribute{
+				Description: "QR code version of the symbol, from 1 to 40. Each version adds 4 modules to the width of the symbol.",
+				Computed:    true,
+			},
+			"module_count": schema.Int64Attribute{
+				Description: "Width of the symbol in modules, without the border.",
+				Computed:    true,
+			},
+			"encoding_mode_used": schema.StringAttribute{
+				Description: "Data modes of the encoded segments in order, such as `byte` or `alphanumeric+numeric`.",
+				Computed:    true,
+			},
+			"error_correction_used": schema.StringAttribute{
+				Description: "Error correction level of the symbol: L, M, Q or H. Differs from `error_correction` when it is `auto_max`.",
+				Computed:    true,
+			},
+			"capacity_used_percent": schema.Float64Attribute{
+				Description: "Share of the data capacity of the largest QR code, version 40 at the same error correction level, that the text takes, in percent. Generation fails once it exceeds 100, and codes become hard to scan well before that, so it can be used to alert on payloads that keep growing.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// ConfigValidators returns the cross-attribute validations for the data source configuration.
+func (d *QRCodeDataSource) ConfigValidators(_ context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		contentSourcesValidator{
+			attributes: []string{"text", "sensitive_text", "content_file"},
 		},
 	}
 }
@@ -82,17 +191,59 @@ func computeSHA256(input string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// parseErrorCorrection returns the level of the error_correction attribute of the data sources,
+// and whether it is auto_max, which starts from M. ok is false for unsupported values.
+func parseErrorCorrection(value string) (level qrgen.Level, autoMax bool, ok bool) {
+	switch strings.ToUpper(value) {
+	case "L":
+		return qrgen.Low, false, true
+	case "M", "": // Default to Medium
+		return qrgen.Medium, false, true
+	case "Q":
+		return qrgen.High, false, true
+	case "H":
+		return qrgen.Highest, false, true
+	case strings.ToUpper(errorCorrectionAutoMax):
+		return qrgen.Medium, true, true
+	default:
+		return 0, false, false
+	}
+}
+
+// encodeAtErrorCorrection encodes text at the level, or at the highest level fitting the version
+// of the level when autoMax is set, and returns the symbol with the level it was encoded at.
+func encodeAtErrorCorrection(text string, level qrgen.Level, autoMax bool) (*qrgen.Symbol, qrgen.Level, error) {
+	if autoMax {
+		return qrgen.EncodeMaxLevel(text, qrgen.Options{Level: level})
+	}
+
+	symbol, err := qrgen.Encode(text, qrgen.Options{Level: level})
+	return symbol, level, err
+}
+
 // Read generates the QR code in both Base64 PNG and ASCII formats.
 func (d *QRCodeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	// Define the input struct matching the schema
 	var data struct {
-		Text            types.String `tfsdk:"text"`
-		SensitiveText   types.String `tfsdk:"sensitive_text"`
-		ErrorCorrection types.String `tfsdk:"error_correction"`
-		DisableBorder   types.Bool   `tfsdk:"disable_border"`
-		Invert          types.Bool   `tfsdk:"invert"`
-		ASCII           types.String `tfsdk:"ascii"`
-		ASCIISHA256     types.String `tfsdk:"ascii_sha256"`
+		Text                   types.String  `tfsdk:"text"`
+		SensitiveText          types.String  `tfsdk:"sensitive_text"`
+		ContentFile            types.String  `tfsdk:"content_file"`
+		AllowEmpty             types.Bool    `tfsdk:"allow_empty"`
+		ErrorCorrection        types.String  `tfsdk:"error_correction"`
+		DisableBorder          types.Bool    `tfsdk:"disable_border"`
+		Invert                 types.Bool    `tfsdk:"invert"`
+		ASCIIDarkChar          types.String  `tfsdk:"ascii_dark_char"`
+		ASCIILightChar         types.String  `tfsdk:"ascii_light_char"`
+		ASCIIQuietZoneChar     types.String  `tfsdk:"ascii_quiet_zone_char"`
+		QuietZoneChars         types.Int64   `tfsdk:"quiet_zone_chars"`
+		CapacityWarningPercent types.Float64 `tfsdk:"capacity_warning_percent"`
+		ASCII                  types.String  `tfsdk:"ascii"`
+		ASCIISHA256            types.String  `tfsdk:"ascii_sha256"`
+		QRVersion              types.Int64   `tfsdk:"qr_version"`
+		ModuleCount            types.Int64   `tfsdk:"module_count"`
+		EncodingModeUsed       types.String  `tfsdk:"encoding_mode_used"`
+		ErrorCorrectionUsed    types.String  `tfsdk:"error_correction_used"`
+		CapacityUsedPercent    types.Float64 `tfsdk:"capacity_used_percent"`
 	}
 
 	// Read input data from Terraform
@@ -102,35 +253,70 @@ func (d *QRCodeDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
+	// Never encode unknown content as an empty string
+	if data.Text.IsUnknown() || data.SensitiveText.IsUnknown() || data.ContentFile.IsUnknown() {
+		if req.ClientCapabilities.DeferralAllowed {
+			tflog.Debug(ctx, "Deferring QR code generation until its content is known")
+			resp.Deferred = &datasource.Deferred{
+				Reason: datasource.DeferredReasonDataSourceConfigUnknown,
+			}
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"QR Code Content Unknown",
+			"The QR code content is not known yet and deferred actions are not enabled, so the QR code cannot be generated.",
+		)
+		return
+	}
+
 	// Determine error correction level
-	var level qrcode.RecoveryLevel
-	switch strings.ToUpper(data.ErrorCorrection.ValueString()) {
-	case "L":
-		level = qrcode.Low
-	case "M", "": // Default to Medium
-		level = qrcode.Medium
-	case "Q":
-		level = qrcode.High
-	case "H":
-		level = qrcode.Highest
-	default:
+	level, autoMax, ok := parseErrorCorrection(data.ErrorCorrection.ValueString())
+	if !ok {
 		resp.Diagnostics.AddError(
 			"Invalid Error Correction Level",
-			"Supported values: L (low), M (medium), Q (high), H (highest).",
+			"Supported values: L (low), M (medium), Q (high), H (highest), auto_max.",
 		)
 		return
 	}
 
 	// Determine which text to use for QR generation
-	qrText := ""
-	if !data.Text.IsNull() {
+	var qrText string
+	var err error
+	switch {
+	case !data.Text.IsNull():
 		qrText = data.Text.ValueString()
-	} else {
+	case !data.ContentFile.IsNull():
+		qrText, err = readContentFile(data.ContentFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content_file"), "QR Code Generation Failed", err.Error())
+			return
+		}
+	default:
 		qrText = data.SensitiveText.ValueString()
+
+		// Errors that echo sensitive text are reported with its length and checksum instead
+		redactor := newRedactor(qrText)
+		defer func() {
+			resp.Diagnostics = redactor.diagnostics(resp.Diagnostics)
+		}()
 	}
 
+	if !data.AllowEmpty.IsUnknown() {
+		resp.Diagnostics.Append(emptyContentDiagnostics(qrText, data.AllowEmpty)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Generating QR code", map[string]interface{}{
+		"content_length":   len(qrText),
+		"error_correction": data.ErrorCorrection.ValueString(),
+	})
+
 	// Generate QR code
-	qr, err := qrcode.New(qrText, level)
+	start := time.Now()
+	symbol, level, err := encodeAtErrorCorrection(qrText, level, autoMax)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"QR Code Generation Failed",
@@ -139,13 +325,23 @@ func (d *QRCodeDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
+	resp.Diagnostics.Append(capacityDiagnostics(symbol, data.CapacityWarningPercent)...)
+
 	// Apply optional flags
-	if data.DisableBorder.ValueBool() {
-		qr.DisableBorder = true
+	if !data.QuietZoneChars.IsNull() {
+		symbol = symbol.WithQuietZone(int(data.QuietZoneChars.ValueInt64()))
+	} else if data.DisableBorder.ValueBool() {
+		symbol = symbol.WithQuietZone(0)
 	}
 
-	// Convert to ASCII (invert mode supported by the library)
-	asciiQR := qr.ToSmallString(data.Invert.ValueBool()) // true = inverted mode
+	// Convert to ASCII
+	asciiQR := renderASCII(symbol, data.ASCIIDarkChar, data.ASCIILightChar, data.ASCIIQuietZoneChar, data.Invert.ValueBool())
+
+	tflog.Debug(ctx, "Rendered QR code ASCII", map[string]interface{}{
+		"version":        symbol.Version(),
+		"ascii_length":   len(asciiQR),
+		"render_time_ms": time.Since(start).Milliseconds(),
+	})
 
 	// Compute SHA-256 checksum
 	asciiChecksum := computeSHA256(asciiQR)
@@ -153,6 +349,11 @@ func (d *QRCodeDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	// Set Terraform state
 	data.ASCII = types.StringValue(asciiQR)
 	data.ASCIISHA256 = types.StringValue(asciiChecksum)
+	data.QRVersion = types.Int64Value(int64(symbol.Version()))
+	data.ModuleCount = types.Int64Value(int64(symbol.SymbolModules()))
+	data.EncodingModeUsed = types.StringValue(symbol.Mode())
+	data.ErrorCorrectionUsed = types.StringValue(errorCorrectionNames[level])
+	data.CapacityUsedPercent = types.Float64Value(roundPercent(symbol.CapacityUsedPercent()))
 
 	diags = resp.State.Set(ctx, &data)
 	resp.Diagnostics.Append(diags...)
@@ -1,12 +1,22 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
-// TestAccQRCodeDataSource verifies the qrcode_generate data source.
+// TestAccQRCodeDataSource verifies the deprecated qrcode_generate data source.
 func TestAccQRCodeDataSource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
@@ -20,6 +30,7 @@ func TestAccQRCodeDataSource(t *testing.T) {
 					}
 				`,
 				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckDataSourceDeprecated("qrcode_generate"),
 					// Verify that the 'ascii' attribute is set
 					resource.TestCheckResourceAttrSet(
 						"data.qrcode_generate.test", "ascii",
@@ -29,8 +40,218 @@ func TestAccQRCodeDataSource(t *testing.T) {
 						"data.qrcode_generate.test", "ascii_sha256",
 						"1008c2f94d40f67e0f9f212284e9535aff2919fb256d512ad5edfa02929b55a5",
 					),
+					resource.TestCheckResourceAttr("data.qrcode_generate.test", "qr_version", "1"),
+					resource.TestCheckResourceAttr("data.qrcode_generate.test", "module_count", "21"),
+					resource.TestCheckResourceAttr("data.qrcode_generate.test", "encoding_mode_used", "byte"),
+					resource.TestCheckResourceAttr("data.qrcode_generate.test", "error_correction_used", "M"),
 				),
 			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_generate" "test" {
+						text             = "https://example.com"
+						error_correction = "auto_max"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Q is the highest level that fits the version 2 that M needs
+					resource.TestCheckResourceAttr("data.qrcode_generate.test", "qr_version", "2"),
+					resource.TestCheckResourceAttr("data.qrcode_generate.test", "error_correction_used", "Q"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccQRCodeDataSourceASCIIGlyphs verifies that ascii is drawn in the configured characters,
+// with the configured quiet zone.
+func TestAccQRCodeDataSourceASCIIGlyphs(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_generate" "test" {
+						text                  = "qrcode"
+						ascii_dark_char       = "#"
+						ascii_light_char      = "."
+						ascii_quiet_zone_char = "~"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// 29 modules, each two characters wide, starting with the quiet zone and the finder pattern
+					resource.TestMatchResourceAttr(
+						"data.qrcode_generate.test", "ascii",
+						regexp.MustCompile(`^(~{58}\n){4}~{8}#{14}\.\.`),
+					),
+				),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_generate" "test" {
+						text                  = "qrcode"
+						disable_border        = true
+						quiet_zone_chars      = 1
+						ascii_dark_char       = "#"
+						ascii_quiet_zone_char = "~"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// quiet_zone_chars takes precedence over disable_border
+					resource.TestMatchResourceAttr(
+						"data.qrcode_generate.test", "ascii",
+						regexp.MustCompile(`^~{46}\n~~#{14}  `),
+					),
+				),
+			},
+		},
+	})
+}
+
+// TestAccQRCodeDataSourceConflictingText verifies that text and sensitive_text are mutually exclusive.
+func TestAccQRCodeDataSourceConflictingText(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_generate" "test" {
+						text           = "qrcode"
+						sensitive_text = "qrcode"
+					}
+				`,
+				ExpectError: regexp.MustCompile("Conflicting QR Code Content"),
+			},
 		},
 	})
 }
+
+// testCheckDataSourceDeprecated checks that the provider schema marks the data source as
+// deprecated, which Terraform reports as a warning on every configuration that reads it.
+func testCheckDataSourceDeprecated(typeName string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		server, err := testAccProtoV6ProviderFactories["qrcode"]()
+		if err != nil {
+			return err
+		}
+		resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+		if err != nil {
+			return err
+		}
+
+		schema, ok := resp.DataSourceSchemas[typeName]
+		if !ok {
+			return fmt.Errorf("data source %s not found", typeName)
+		}
+		if !schema.Block.Deprecated {
+			return fmt.Errorf("expected data source %s to be deprecated", typeName)
+		}
+
+		return nil
+	}
+}
+
+// TestAccQRCodeASCIIDataSource verifies the qrcode_ascii data source, which renders as the
+// qrcode_generate data source does without being deprecated.
+func TestAccQRCodeASCIIDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_ascii" "test" {
+						text = "qrcode"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.qrcode_ascii.test", "ascii_sha256",
+						"1008c2f94d40f67e0f9f212284e9535aff2919fb256d512ad5edfa02929b55a5",
+					),
+					resource.TestCheckResourceAttr("data.qrcode_ascii.test", "qr_version", "1"),
+					resource.TestCheckResourceAttr("data.qrcode_ascii.test", "error_correction_used", "M"),
+				),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_ascii" "test" {
+						text                  = "qrcode"
+						ascii_dark_char       = "#"
+						ascii_light_char      = "."
+						ascii_quiet_zone_char = "~"
+					}
+				`,
+				Check: resource.TestMatchResourceAttr(
+					"data.qrcode_ascii.test", "ascii",
+					regexp.MustCompile(`^(~{58}\n){4}~{8}#{14}\.\.`),
+				),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					data "qrcode_ascii" "test" {
+						text           = "qrcode"
+						sensitive_text = "qrcode"
+					}
+				`,
+				ExpectError: regexp.MustCompile("Conflicting QR Code Content"),
+			},
+		},
+	})
+}
+
+// TestQRCodeDataSourceDeprecatedName verifies that the qrcode_generate data source is deprecated in
+// favor of qrcode_ascii, and renders the same.
+func TestQRCodeDataSourceDeprecatedName(t *testing.T) {
+	ctx := context.Background()
+
+	read := func(d datasource.DataSource) (datasource.SchemaResponse, types.String) {
+		t.Helper()
+
+		schemaResp := &datasource.SchemaResponse{}
+		d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+		config := tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"text": tftypes.NewValue(tftypes.String, "qrcode"),
+			}),
+		}
+		resp := &datasource.ReadResponse{
+			State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+		}
+		d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		var ascii types.String
+		resp.State.GetAttribute(ctx, path.Root("ascii"), &ascii)
+		return *schemaResp, ascii
+	}
+
+	deprecatedSchema, deprecatedASCII := read(NewQRCodeDataSource())
+	asciiSchema, ascii := read(NewQRCodeASCIIDataSource())
+
+	if deprecatedSchema.Schema.DeprecationMessage == "" {
+		t.Error("expected qrcode_generate to be deprecated")
+	}
+	if asciiSchema.Schema.DeprecationMessage != "" {
+		t.Errorf("expected qrcode_ascii not to be deprecated, got %q", asciiSchema.Schema.DeprecationMessage)
+	}
+	if ascii.IsNull() || !ascii.Equal(deprecatedASCII) {
+		t.Errorf("expected both names to render the same ascii, got %q and %q", ascii.ValueString(), deprecatedASCII.ValueString())
+	}
+}
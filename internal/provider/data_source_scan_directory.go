@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &qrcodeScanDirectoryDataSource{}
+	_ datasource.DataSourceWithConfigure = &qrcodeScanDirectoryDataSource{}
+)
+
+// qrcodeScanDirectoryCodeType is the element type of the codes attribute.
+var qrcodeScanDirectoryCodeType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"content": types.StringType,
+		"sha256":  types.StringType,
+	},
+}
+
+// qrcodeScanDirectoryDataSource is the data source implementation.
+type qrcodeScanDirectoryDataSource struct {
+	fs afero.Fs
+}
+
+// qrcodeScanDirectoryDataSourceModel maps the qrcode_scan_directory data source schema data.
+type qrcodeScanDirectoryDataSourceModel struct {
+	Directory types.String `tfsdk:"directory"`
+	Recursive types.Bool   `tfsdk:"recursive"`
+	Codes     types.Map    `tfsdk:"codes"`
+}
+
+// qrcodeScanDirectoryCodeModel maps an element of the codes attribute.
+type qrcodeScanDirectoryCodeModel struct {
+	Content types.String `tfsdk:"content"`
+	SHA256  types.String `tfsdk:"sha256"`
+}
+
+// NewQRCodeScanDirectoryDataSource creates a new QR code scan directory data source instance.
+func NewQRCodeScanDirectoryDataSource() datasource.DataSource {
+	return &qrcodeScanDirectoryDataSource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the data source type name.
+func (d *qrcodeScanDirectoryDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_scan_directory"
+}
+
+// Configure receives the provider-level filesystem.
+func (d *qrcodeScanDirectoryDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.fs = data.Filesystem
+}
+
+// Schema defines the data source schema.
+func (d *qrcodeScanDirectoryDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_scan_directory` data source walks a directory, decodes every PNG QR code image it finds, and returns their decoded content and SHA-256 checksums. This is useful to reconcile printed label inventories against Terraform data.",
+		Attributes: map[string]schema.Attribute{
+			"directory": schema.StringAttribute{
+				Required:    true,
+				Description: "Directory to scan for QR code images.",
+			},
+			"recursive": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to also scan subdirectories.",
+			},
+			"codes": schema.MapAttribute{
+				Computed:    true,
+				ElementType: qrcodeScanDirectoryCodeType,
+				Description: "Scanned images keyed by their path relative to `directory`, using forward slashes. Each element has the decoded `content`, which is null when the image does not contain a readable QR code, and the `sha256` checksum of the image file.",
+			},
+		},
+	}
+}
+
+// Read scans the directory and decodes every QR code image.
+func (d *qrcodeScanDirectoryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config qrcodeScanDirectoryDataSourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dir := config.Directory.ValueString()
+	recursive := config.Recursive.ValueBool()
+	codes := map[string]qrcodeScanDirectoryCodeModel{}
+
+	err := afero.Walk(d.fs, dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if filePath != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.EqualFold(filepath.Ext(filePath), ".png") {
+			return nil
+		}
+
+		data, err := afero.ReadFile(d.fs, hostPath(filePath))
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		hash := sha256.Sum256(data)
+		code := qrcodeScanDirectoryCodeModel{
+			Content: types.StringNull(),
+			SHA256:  types.StringValue(hex.EncodeToString(hash[:])),
+		}
+
+		// Images without a readable QR code are still listed, so they show up in reconciliation
+		if text, err := decodeQRCodeImage(data); err == nil {
+			code.Content = types.StringValue(text)
+		} else {
+			tflog.Debug(ctx, "No QR code found in image", map[string]interface{}{
+				"file":  filePath,
+				"error": err.Error(),
+			})
+		}
+
+		codes[filepath.ToSlash(name)] = code
+		return nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to Scan Directory", err.Error())
+		return
+	}
+
+	tflog.Debug(ctx, "Scanned QR code directory", map[string]interface{}{
+		"directory": dir,
+		"images":    len(codes),
+	})
+
+	codesValue, diags := types.MapValueFrom(ctx, qrcodeScanDirectoryCodeType, codes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.Codes = codesValue
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
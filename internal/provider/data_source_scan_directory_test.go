@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestQRCodeScanDirectoryDataSource verifies that qrcode_scan_directory decodes every PNG image.
+func TestQRCodeScanDirectoryDataSource(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	dir := filepath.Join(t.TempDir(), "labels")
+
+	pngData := testRenderPNG(t, "qrcode")
+	hash := sha256.Sum256(pngData)
+
+	files := map[string][]byte{
+		"a.png":                          pngData,
+		"blank.png":                      []byte("not an image"),
+		"notes.txt":                      []byte("qrcode"),
+		filepath.Join("nested", "b.png"): pngData,
+	}
+	for name, data := range files {
+		if err := afero.WriteFile(fs, filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	d := &qrcodeScanDirectoryDataSource{fs: fs}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		recursive bool
+		expected  map[string]string
+	}{
+		"top-level": {recursive: false, expected: map[string]string{"a.png": "qrcode", "blank.png": ""}},
+		"recursive": {recursive: true, expected: map[string]string{"a.png": "qrcode", "blank.png": "", "nested/b.png": "qrcode"}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+					"directory": tftypes.NewValue(tftypes.String, dir),
+					"recursive": tftypes.NewValue(tftypes.Bool, testCase.recursive),
+				}),
+			}
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+			}
+
+			d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var model qrcodeScanDirectoryDataSourceModel
+			resp.State.Get(ctx, &model)
+
+			codes := map[string]qrcodeScanDirectoryCodeModel{}
+			model.Codes.ElementsAs(ctx, &codes, false)
+
+			if len(codes) != len(testCase.expected) {
+				t.Fatalf("expected %d codes, got %d", len(testCase.expected), len(codes))
+			}
+			for name, expected := range testCase.expected {
+				code, ok := codes[name]
+				if !ok {
+					t.Errorf("expected code for %s", name)
+					continue
+				}
+				if expected == "" && !code.Content.IsNull() {
+					t.Errorf("%s: expected null content, got %s", name, code.Content)
+				}
+				if expected != "" && code.Content.ValueString() != expected {
+					t.Errorf("%s: expected content %q, got %s", name, expected, code.Content)
+				}
+			}
+			if codes["a.png"].SHA256.ValueString() != hex.EncodeToString(hash[:]) {
+				t.Errorf("expected sha256 %s, got %s", hex.EncodeToString(hash[:]), codes["a.png"].SHA256)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource                     = &qrcodeVerifyDataSource{}
+	_ datasource.DataSourceWithConfigure        = &qrcodeVerifyDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &qrcodeVerifyDataSource{}
+)
+
+// qrcodeVerifyDataSource is the data source implementation.
+type qrcodeVerifyDataSource struct {
+	fs afero.Fs
+}
+
+// qrcodeVerifyDataSourceModel maps the qrcode_verify data source schema data.
+type qrcodeVerifyDataSourceModel struct {
+	File            types.String `tfsdk:"file"`
+	ContentBase64   types.String `tfsdk:"content_base64"`
+	ExpectedContent types.String `tfsdk:"expected_content"`
+	ContentEncoding types.String `tfsdk:"content_encoding"`
+	Compress        types.Bool   `tfsdk:"compress"`
+	Matches         types.Bool   `tfsdk:"matches"`
+	Content         types.String `tfsdk:"content"`
+	DecodedBase64   types.String `tfsdk:"decoded_base64"`
+}
+
+// NewQRCodeVerifyDataSource creates a new QR code verify data source instance.
+func NewQRCodeVerifyDataSource() datasource.DataSource {
+	return &qrcodeVerifyDataSource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the data source type name.
+func (d *qrcodeVerifyDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_verify"
+}
+
+// Configure receives the provider-level filesystem.
+func (d *qrcodeVerifyDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.fs = data.Filesystem
+}
+
+// Schema defines the data source schema.
+func (d *qrcodeVerifyDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_verify` data source decodes a PNG QR code image and reports whether it encodes the expected content. It is designed for `check` blocks and `terraform test` assertions: an image without a readable QR code does not fail the read, but sets `matches` to false.",
+		Attributes: map[string]schema.Attribute{
+			"file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of the PNG image to verify. Exactly one of `file` and `content_base64` must be set.",
+			},
+			"content_base64": schema.StringAttribute{
+				Optional:    true,
+				Description: "Base64-encoded PNG image to verify, such as the `content_base64` of a `qrcode_generate` resource or the output of `filebase64()`.",
+			},
+			"expected_content": schema.StringAttribute{
+				Required:    true,
+				Description: "Text that the QR code is expected to encode.",
+			},
+			"content_encoding": schema.StringAttribute{
+				Optional:    true,
+				Description: "Encoding to decode the text of the QR code from before it is compared, as the `content_encoding` of a `qrcode_generate` resource: `base45`, the Base45 encoding of RFC 9285, or `shc`, which decodes a SMART Health Card to its JWS. A QR code whose text is not valid in the encoding sets `matches` to false.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(contentEncodingBase45, contentEncodingSHC),
+				},
+			},
+			"compress": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to decompress the content with zlib after decoding it with `content_encoding`, as the `compress` of a `qrcode_generate` resource. A QR code whose content does not decompress sets `matches` to false.",
+			},
+			"matches": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the image contains a QR code that encodes exactly `expected_content`.",
+			},
+			"content": schema.StringAttribute{
+				Computed:    true,
+				Description: "Decoded text of the QR code, or null when the image does not contain a readable QR code. With `content_encoding` or `compress`, the decoded content, which is null when it is binary data rather than UTF-8 text.",
+			},
+			"decoded_base64": schema.StringAttribute{
+				Computed:    true,
+				Description: "Base64-encoded content decoded with `content_encoding` and `compress`, for binary content. Null unless one of them is set and the QR code decodes.",
+			},
+		},
+	}
+}
+
+// ConfigValidators returns the cross-attribute validations for the data source configuration.
+func (d *qrcodeVerifyDataSource) ConfigValidators(_ context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("file"),
+			path.MatchRoot("content_base64"),
+		),
+	}
+}
+
+// Read decodes the image and compares it with the expected content.
+func (d *qrcodeVerifyDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config qrcodeVerifyDataSourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var data []byte
+	if !config.File.IsNull() {
+		var err error
+		data, err = afero.ReadFile(d.fs, hostPath(config.File.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("file"), "Failed to Read QR Code", err.Error())
+			return
+		}
+	} else {
+		var err error
+		data, err = base64.StdEncoding.DecodeString(config.ContentBase64.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid Base64 Content", err.Error())
+			return
+		}
+	}
+
+	config.Content = types.StringNull()
+	config.DecodedBase64 = types.StringNull()
+	if text, err := decodeQRCodeImage(data); err != nil {
+		tflog.Debug(ctx, "No QR code found in image", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if !config.ContentEncoding.IsNull() || config.Compress.ValueBool() {
+		if decoded, err := decodeContent(text, config.ContentEncoding.ValueString(), config.Compress.ValueBool()); err != nil {
+			tflog.Debug(ctx, "QR code content could not be decoded", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			config.DecodedBase64 = types.StringValue(base64.StdEncoding.EncodeToString(decoded))
+			if utf8.Valid(decoded) {
+				config.Content = types.StringValue(string(decoded))
+			}
+		}
+	} else {
+		config.Content = types.StringValue(text)
+	}
+	config.Matches = types.BoolValue(config.Content.Equal(config.ExpectedContent))
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
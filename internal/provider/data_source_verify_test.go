@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// TestQRCodeVerifyDataSource verifies that qrcode_verify reports matching, different and unreadable images.
+func TestQRCodeVerifyDataSource(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+
+	pngData := testRenderPNG(t, "qrcode")
+	if err := afero.WriteFile(fs, "/labels/a.png", pngData, 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+	base45Data := testRenderPNG(t, qrgen.EncodeBase45([]byte("qrcode")))
+	compressedData := testRenderPNG(t, qrgen.EncodeBase45(compressContent([]byte("qrcode"))))
+
+	d := &qrcodeVerifyDataSource{fs: fs}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		values          map[string]tftypes.Value
+		expectedMatches bool
+		expectedContent string
+	}{
+		"file matches": {
+			values:          map[string]tftypes.Value{"file": tftypes.NewValue(tftypes.String, "/labels/a.png")},
+			expectedMatches: true,
+			expectedContent: "qrcode",
+		},
+		"base64 matches": {
+			values:          map[string]tftypes.Value{"content_base64": tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString(pngData))},
+			expectedMatches: true,
+			expectedContent: "qrcode",
+		},
+		"base45 matches": {
+			values: map[string]tftypes.Value{
+				"content_base64":   tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString(base45Data)),
+				"content_encoding": tftypes.NewValue(tftypes.String, contentEncodingBase45),
+			},
+			expectedMatches: true,
+			expectedContent: "qrcode",
+		},
+		"compressed matches": {
+			values: map[string]tftypes.Value{
+				"content_base64":   tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString(compressedData)),
+				"content_encoding": tftypes.NewValue(tftypes.String, contentEncodingBase45),
+				"compress":         tftypes.NewValue(tftypes.Bool, true),
+			},
+			expectedMatches: true,
+			expectedContent: "qrcode",
+		},
+		"not base45": {
+			values: map[string]tftypes.Value{
+				"file":             tftypes.NewValue(tftypes.String, "/labels/a.png"),
+				"content_encoding": tftypes.NewValue(tftypes.String, contentEncodingBase45),
+			},
+		},
+		"unreadable": {
+			values: map[string]tftypes.Value{"content_base64": tftypes.NewValue(tftypes.String, base64.StdEncoding.EncodeToString([]byte("not an image")))},
+		},
+	}
+
+	for name, testCase := range testCases {
+		for _, expected := range []string{"qrcode", "other"} {
+			values := map[string]tftypes.Value{"expected_content": tftypes.NewValue(tftypes.String, expected)}
+			for key, value := range testCase.values {
+				values[key] = value
+			}
+			config := tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+			}
+
+			d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+			}
+
+			var model qrcodeVerifyDataSourceModel
+			resp.State.Get(ctx, &model)
+
+			if matches := testCase.expectedMatches && expected == "qrcode"; model.Matches.ValueBool() != matches {
+				t.Errorf("%s, expecting %q: expected matches %t, got %s", name, expected, matches, model.Matches)
+			}
+			if testCase.expectedContent == "" && !model.Content.IsNull() {
+				t.Errorf("%s: expected null content, got %s", name, model.Content)
+			}
+			if testCase.expectedContent != "" && model.Content.ValueString() != testCase.expectedContent {
+				t.Errorf("%s: expected content %q, got %s", name, testCase.expectedContent, model.Content)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource = &qrcodeWalletBarcodeDataSource{}
+)
+
+// Barcode formats of wallet passes.
+const (
+	walletFormatQR      = "qr"
+	walletFormatPDF417  = "pdf417"
+	walletFormatAztec   = "aztec"
+	walletFormatCode128 = "code128"
+)
+
+// Message encodings of wallet pass barcodes, as IANA character set names.
+const (
+	walletEncodingLatin1 = "iso-8859-1"
+	walletEncodingUTF8   = "utf-8"
+)
+
+// walletFormats maps the barcode formats to their names in Apple Wallet and Google Wallet passes.
+var walletFormats = map[string]struct{ apple, google string }{
+	walletFormatQR:      {apple: "PKBarcodeFormatQR", google: "QR_CODE"},
+	walletFormatPDF417:  {apple: "PKBarcodeFormatPDF417", google: "PDF_417"},
+	walletFormatAztec:   {apple: "PKBarcodeFormatAztec", google: "AZTEC"},
+	walletFormatCode128: {apple: "PKBarcodeFormatCode128", google: "CODE_128"},
+}
+
+// walletByteCharsets maps the message encodings to the byte_charset of qrcode_generate that
+// encodes the message the same way.
+var walletByteCharsets = map[string]string{
+	walletEncodingLatin1: qrgen.ByteCharsetLatin1,
+	walletEncodingUTF8:   qrgen.ByteCharsetUTF8,
+}
+
+// appleWalletBarcode is the barcode dictionary of an Apple Wallet pass.json.
+type appleWalletBarcode struct {
+	AltText         string `json:"altText,omitempty"`
+	Format          string `json:"format"`
+	Message         string `json:"message"`
+	MessageEncoding string `json:"messageEncoding"`
+}
+
+// googleWalletBarcode is the Barcode object of a Google Wallet pass.
+type googleWalletBarcode struct {
+	AlternateText string `json:"alternateText,omitempty"`
+	Type          string `json:"type"`
+	Value         string `json:"value"`
+}
+
+// qrcodeWalletBarcodeDataSource is the data source implementation.
+type qrcodeWalletBarcodeDataSource struct{}
+
+// qrcodeWalletBarcodeDataSourceModel maps the qrcode_wallet_barcode data source schema data.
+type qrcodeWalletBarcodeDataSourceModel struct {
+	Message           types.String `tfsdk:"message"`
+	Format            types.String `tfsdk:"format"`
+	MessageEncoding   types.String `tfsdk:"message_encoding"`
+	AltText           types.String `tfsdk:"alt_text"`
+	BarcodeJSON       types.String `tfsdk:"barcode_json"`
+	BarcodesJSON      types.String `tfsdk:"barcodes_json"`
+	GoogleBarcodeJSON types.String `tfsdk:"google_barcode_json"`
+	ByteCharset       types.String `tfsdk:"byte_charset"`
+}
+
+// NewQRCodeWalletBarcodeDataSource creates a new wallet pass barcode data source instance.
+func NewQRCodeWalletBarcodeDataSource() datasource.DataSource {
+	return &qrcodeWalletBarcodeDataSource{}
+}
+
+// Metadata returns the data source type name.
+func (d *qrcodeWalletBarcodeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_wallet_barcode"
+}
+
+// Schema defines the data source schema.
+func (d *qrcodeWalletBarcodeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_wallet_barcode` data source builds the barcode JSON of Apple Wallet and Google Wallet passes, so that pass generation pipelines use the same message as the QR codes rendered by Terraform. Set the `byte_charset` of a `qrcode_generate` resource to the computed `byte_charset` to render the message with the bytes the wallet apps encode.",
+		Attributes: map[string]schema.Attribute{
+			"message": schema.StringAttribute{
+				Required:    true,
+				Description: "Message encoded in the barcode, such as a ticket number or a URL.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Barcode format: `%s`, `%s`, `%s` or `%s`. Code 128 barcodes only encode ASCII text. Defaults to `%s`.", walletFormatQR, walletFormatPDF417, walletFormatAztec, walletFormatCode128, walletFormatQR),
+				Validators: []validator.String{
+					stringvalidator.OneOf(walletFormatQR, walletFormatPDF417, walletFormatAztec, walletFormatCode128),
+				},
+			},
+			"message_encoding": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Character set the wallet apps encode the message in, as the `messageEncoding` of Apple Wallet passes: `%s` or `%s`. The read fails when the message contains characters the character set cannot represent. Defaults to `%s`, which Apple Wallet recommends.", walletEncodingLatin1, walletEncodingUTF8, walletEncodingLatin1),
+				Validators: []validator.String{
+					stringvalidator.OneOf(walletEncodingLatin1, walletEncodingUTF8),
+				},
+			},
+			"alt_text": schema.StringAttribute{
+				Optional:    true,
+				Description: "Text shown below the barcode, such as the ticket number, as the `altText` of Apple Wallet and the `alternateText` of Google Wallet passes.",
+			},
+			"barcode_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON object of the barcode, as the `barcode` key of an Apple Wallet `pass.json` or an element of its `barcodes` array, with the `altText`, `format`, `message` and `messageEncoding` keys.",
+			},
+			"barcodes_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON array holding the barcode, as the `barcodes` key of an Apple Wallet `pass.json`.",
+			},
+			"google_barcode_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON object of the barcode, as the `barcode` field of a Google Wallet pass object, with the `alternateText`, `type` and `value` fields.",
+			},
+			"byte_charset": schema.StringAttribute{
+				Computed:    true,
+				Description: "Character set of the message encoding as the `byte_charset` of a `qrcode_generate` resource, such as `ISO-8859-1`.",
+			},
+		},
+	}
+}
+
+// Read builds the barcode JSON of the message.
+func (d *qrcodeWalletBarcodeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config qrcodeWalletBarcodeDataSourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	format := config.Format.ValueString()
+	if format == "" {
+		format = walletFormatQR
+	}
+	encoding := config.MessageEncoding.ValueString()
+	if encoding == "" {
+		encoding = walletEncodingLatin1
+	}
+
+	message := config.Message.ValueString()
+	if err := walletMessageEncodable(message, format, encoding); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("message"), "Invalid Wallet Barcode Message", err.Error())
+		return
+	}
+
+	barcode := appleWalletBarcode{
+		AltText:         config.AltText.ValueString(),
+		Format:          walletFormats[format].apple,
+		Message:         message,
+		MessageEncoding: encoding,
+	}
+	barcodeJSON, err := walletJSON(barcode)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to Build Wallet Barcode", err.Error())
+		return
+	}
+	barcodesJSON, err := walletJSON([]appleWalletBarcode{barcode})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to Build Wallet Barcode", err.Error())
+		return
+	}
+	googleBarcodeJSON, err := walletJSON(googleWalletBarcode{
+		AlternateText: config.AltText.ValueString(),
+		Type:          walletFormats[format].google,
+		Value:         message,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to Build Wallet Barcode", err.Error())
+		return
+	}
+
+	config.BarcodeJSON = types.StringValue(barcodeJSON)
+	config.BarcodesJSON = types.StringValue(barcodesJSON)
+	config.GoogleBarcodeJSON = types.StringValue(googleBarcodeJSON)
+	config.ByteCharset = types.StringValue(walletByteCharsets[encoding])
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// walletMessageEncodable returns an error when the message contains characters that the message
+// encoding or the barcode format cannot represent.
+func walletMessageEncodable(message, format, encoding string) error {
+	for _, r := range message {
+		switch {
+		case format == walletFormatCode128 && r > 0x7e:
+			return fmt.Errorf("code 128 barcodes only encode ASCII text, got %q", r)
+		case encoding == walletEncodingLatin1 && r > 0xff:
+			return fmt.Errorf("%q cannot be represented in %s, set message_encoding to %s", r, walletEncodingLatin1, walletEncodingUTF8)
+		}
+	}
+
+	return nil
+}
+
+// walletJSON serializes v as compact JSON without escaping HTML characters, which are common in
+// URLs.
+func walletJSON(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
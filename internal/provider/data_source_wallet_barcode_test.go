@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// TestQRCodeWalletBarcodeDataSource verifies that qrcode_wallet_barcode builds the Apple Wallet and
+// Google Wallet barcode JSON, and rejects messages the encoding or format cannot represent.
+func TestQRCodeWalletBarcodeDataSource(t *testing.T) {
+	ctx := context.Background()
+	d := &qrcodeWalletBarcodeDataSource{}
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config   map[string]tftypes.Value
+		expected *qrcodeWalletBarcodeDataSourceModel
+	}{
+		"defaults": {
+			config: map[string]tftypes.Value{"message": tftypes.NewValue(tftypes.String, "https://example.com/t?id=1&seat=12")},
+			expected: &qrcodeWalletBarcodeDataSourceModel{
+				BarcodeJSON:       types.StringValue(`{"format":"PKBarcodeFormatQR","message":"https://example.com/t?id=1&seat=12","messageEncoding":"iso-8859-1"}`),
+				BarcodesJSON:      types.StringValue(`[{"format":"PKBarcodeFormatQR","message":"https://example.com/t?id=1&seat=12","messageEncoding":"iso-8859-1"}]`),
+				GoogleBarcodeJSON: types.StringValue(`{"type":"QR_CODE","value":"https://example.com/t?id=1&seat=12"}`),
+				ByteCharset:       types.StringValue("ISO-8859-1"),
+			},
+		},
+		"aztec utf-8": {
+			config: map[string]tftypes.Value{
+				"message":          tftypes.NewValue(tftypes.String, "Zürich → Genève"),
+				"format":           tftypes.NewValue(tftypes.String, walletFormatAztec),
+				"message_encoding": tftypes.NewValue(tftypes.String, walletEncodingUTF8),
+				"alt_text":         tftypes.NewValue(tftypes.String, "ZRH-GVA"),
+			},
+			expected: &qrcodeWalletBarcodeDataSourceModel{
+				BarcodeJSON:       types.StringValue(`{"altText":"ZRH-GVA","format":"PKBarcodeFormatAztec","message":"Zürich → Genève","messageEncoding":"utf-8"}`),
+				BarcodesJSON:      types.StringValue(`[{"altText":"ZRH-GVA","format":"PKBarcodeFormatAztec","message":"Zürich → Genève","messageEncoding":"utf-8"}]`),
+				GoogleBarcodeJSON: types.StringValue(`{"alternateText":"ZRH-GVA","type":"AZTEC","value":"Zürich → Genève"}`),
+				ByteCharset:       types.StringValue("UTF-8"),
+			},
+		},
+		"not latin-1": {
+			config: map[string]tftypes.Value{"message": tftypes.NewValue(tftypes.String, "Zürich → Genève")},
+		},
+		"code 128 not ascii": {
+			config: map[string]tftypes.Value{
+				"message": tftypes.NewValue(tftypes.String, "Café"),
+				"format":  tftypes.NewValue(tftypes.String, walletFormatCode128),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw:    testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config),
+			}
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw},
+			}
+
+			d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+
+			if testCase.expected == nil {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var model qrcodeWalletBarcodeDataSourceModel
+			resp.State.Get(ctx, &model)
+
+			for attribute, values := range map[string][2]string{
+				"barcode_json":        {testCase.expected.BarcodeJSON.ValueString(), model.BarcodeJSON.ValueString()},
+				"barcodes_json":       {testCase.expected.BarcodesJSON.ValueString(), model.BarcodesJSON.ValueString()},
+				"google_barcode_json": {testCase.expected.GoogleBarcodeJSON.ValueString(), model.GoogleBarcodeJSON.ValueString()},
+				"byte_charset":        {testCase.expected.ByteCharset.ValueString(), model.ByteCharset.ValueString()},
+			} {
+				if values[0] != values[1] {
+					t.Errorf("expected %s %s, got %s", attribute, values[0], values[1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/png" // Register the PNG decoder for image.Decode.
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqrcode "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// decodeQRCodeImage decodes the text of the QR code in an encoded image.
+func decodeQRCodeImage(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+
+	result, err := zxingqrcode.NewQRCodeReader().Decode(bitmap, map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("no QR code found: %w", err)
+	}
+
+	return result.GetText(), nil
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// Extensions appended to the names of encrypted files.
+const (
+	encryptedExtensionAge = ".age"
+	encryptedExtensionPGP = ".gpg"
+)
+
+// encryptData encrypts data to age recipients, or to OpenPGP public keys when no age recipients
+// are given, and returns the binary ciphertext along with the extension for its file name.
+func encryptData(data []byte, ageRecipients []string, pgpPublicKeys []string) ([]byte, string, error) {
+	if len(ageRecipients) > 0 {
+		ciphertext, err := encryptAge(data, ageRecipients)
+		return ciphertext, encryptedExtensionAge, err
+	}
+
+	ciphertext, err := encryptPGP(data, pgpPublicKeys)
+	return ciphertext, encryptedExtensionPGP, err
+}
+
+// encryptAge encrypts data to age X25519 recipients.
+func encryptAge(data []byte, recipients []string) ([]byte, error) {
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		r, err := age.ParseX25519Recipient(strings.TrimSpace(recipient))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", recipient, err)
+		}
+		parsed = append(parsed, r)
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, parsed...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encryptPGP encrypts data to ASCII-armored OpenPGP public keys.
+func encryptPGP(data []byte, publicKeys []string) ([]byte, error) {
+	var entities openpgp.EntityList
+	for i, publicKey := range publicKeys {
+		keyRing, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OpenPGP public key at index %d: %w", i, err)
+		}
+		entities = append(entities, keyRing...)
+	}
+
+	var buf bytes.Buffer
+	w, err := openpgp.Encrypt(&buf, entities, nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encryptAESGCM encrypts data with AES-GCM and returns the random 12-byte nonce followed by the
+// ciphertext and its 16-byte authentication tag.
+func encryptAESGCM(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// readAESKey reads a base64-encoded AES key of 128, 192 or 256 bits from the environment variable
+// env, or from the file at keyPath when env is empty, on the machine running Terraform.
+func readAESKey(env, keyPath string) ([]byte, error) {
+	var encoded string
+	if env != "" {
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", env)
+		}
+		encoded = value
+	} else {
+		data, err := os.ReadFile(hostPath(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 AES key: %w", err)
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("AES keys must be 128, 192 or 256 bits, got %d bits", len(key)*8)
+	}
+
+	return key, nil
+}
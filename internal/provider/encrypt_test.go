@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestEncryptDataAge verifies that age ciphertext decrypts with the recipient's identity.
+func TestEncryptDataAge(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %s", err)
+	}
+
+	plaintext := []byte("qrcode image")
+	ciphertext, extension, err := encryptData(plaintext, []string{identity.Recipient().String()}, nil)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+	if extension != encryptedExtensionAge {
+		t.Errorf("expected extension %s, got %s", encryptedExtensionAge, extension)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	if decrypted, _ := io.ReadAll(r); !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+
+	if _, _, err := encryptData(plaintext, []string{"age1invalid"}, nil); err == nil {
+		t.Errorf("expected an error for an invalid recipient")
+	}
+}
+
+// TestEncryptDataPGP verifies that OpenPGP ciphertext decrypts with the private key.
+func TestEncryptDataPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("qrcode", "", "qrcode@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	var publicKey strings.Builder
+	w, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("failed to armor key: %s", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("failed to serialize key: %s", err)
+	}
+	_ = w.Close()
+
+	plaintext := []byte("qrcode image")
+	ciphertext, extension, err := encryptData(plaintext, nil, []string{publicKey.String()})
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+	if extension != encryptedExtensionPGP {
+		t.Errorf("expected extension %s, got %s", encryptedExtensionPGP, extension)
+	}
+
+	message, err := openpgp.ReadMessage(bytes.NewReader(ciphertext), openpgp.EntityList{entity}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	if decrypted, _ := io.ReadAll(message.UnverifiedBody); !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+
+	if _, _, err := encryptData(plaintext, nil, []string{"not a key"}); err == nil {
+		t.Errorf("expected an error for an invalid public key")
+	}
+}
+
+// TestQRCodeResourceValidateConfigEncrypt verifies that the encrypt block needs exactly one kind of recipient and
+// cannot be combined with show_in_diagnostics.
+func TestQRCodeResourceValidateConfigEncrypt(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	listType := tftypes.List{ElementType: tftypes.String}
+	encryptType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object).AttributeTypes["encrypt"].(tftypes.Object)
+	if !ok {
+		t.Fatalf("encrypt is not an object")
+	}
+	encrypt := func(ageRecipients, pgpPublicKeys interface{}) tftypes.Value {
+		return tftypes.NewValue(encryptType, map[string]tftypes.Value{
+			"age_recipients":  tftypes.NewValue(listType, ageRecipients),
+			"pgp_public_keys": tftypes.NewValue(listType, pgpPublicKeys),
+		})
+	}
+	recipients := []tftypes.Value{tftypes.NewValue(tftypes.String, "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p")}
+
+	testCases := map[string]struct {
+		encrypt           tftypes.Value
+		showInDiagnostics bool
+		expectError       bool
+	}{
+		"no block":            {encrypt: tftypes.NewValue(encryptType, nil)},
+		"age recipients":      {encrypt: encrypt(recipients, nil)},
+		"empty block":         {encrypt: encrypt(nil, nil), expectError: true},
+		"both":                {encrypt: encrypt(recipients, recipients), expectError: true},
+		"show in diagnostics": {encrypt: encrypt(recipients, nil), showInDiagnostics: true, expectError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"text":                tftypes.NewValue(tftypes.String, "qrcode"),
+				"encrypt":             testCase.encrypt,
+				"show_in_diagnostics": tftypes.NewValue(tftypes.Bool, testCase.showInDiagnostics),
+			})
+
+			resp := &fwresource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+			}, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Errorf("expected error %t, got %v", testCase.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceContentEncryption verifies that content encrypted with an AES key decrypts to
+// the text from the QR code, and that the key is read from the environment.
+func TestQRCodeResourceContentEncryption(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+	t.Setenv("QRCODE_TEST_AES_KEY", base64.StdEncoding.EncodeToString(key))
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	encryptionType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object).AttributeTypes["content_encryption"]
+	encryption := tftypes.NewValue(encryptionType, map[string]tftypes.Value{
+		"age_recipients": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil),
+		"aes_key_env":    tftypes.NewValue(tftypes.String, "QRCODE_TEST_AES_KEY"),
+		"aes_key_path":   tftypes.NewValue(tftypes.String, nil),
+	})
+
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":               tftypes.NewValue(tftypes.String, "WIFI:T:WPA;S:office;P:secret;;"),
+		"content_encoding":   tftypes.NewValue(tftypes.String, contentEncodingBase45),
+		"content_encryption": encryption,
+	})}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode content_base64: %s", err)
+	}
+	text, err := decodeQRCodeImage(data)
+	if err != nil {
+		t.Fatalf("failed to decode QR code: %s", err)
+	}
+	if strings.Contains(text, "secret") {
+		t.Errorf("expected the QR code not to reveal the text, got %q", text)
+	}
+
+	ciphertext, err := decodeContent(text, contentEncodingBase45, false)
+	if err != nil {
+		t.Fatalf("failed to decode Base45: %s", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("failed to create cipher: %s", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("failed to create GCM: %s", err)
+	}
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	if string(plaintext) != "WIFI:T:WPA;S:office;P:secret;;" {
+		t.Errorf("expected the text, got %q", plaintext)
+	}
+
+	if _, err := readAESKey("QRCODE_TEST_MISSING_KEY", ""); err == nil {
+		t.Errorf("expected an error for an unset environment variable")
+	}
+	t.Setenv("QRCODE_TEST_SHORT_KEY", base64.StdEncoding.EncodeToString([]byte("short")))
+	if _, err := readAESKey("QRCODE_TEST_SHORT_KEY", ""); err == nil {
+		t.Errorf("expected an error for a key of invalid length")
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// Filesystems selectable with the provider filesystem attribute.
+const (
+	filesystemOS     = "os"
+	filesystemMemory = "memory"
+)
+
+// memoryFilesystem is the filesystem selected with filesystem = "memory". Unlike a bare
+// afero.MemMapFs used in tests, its files do not outlive the provider process, so resources do
+// not check for them when refreshing.
+type memoryFilesystem struct {
+	afero.Fs
+}
+
+// newFilesystem returns the filesystem that resources read and write QR code files through.
+func newFilesystem(kind string) afero.Fs {
+	if kind == filesystemMemory {
+		return &memoryFilesystem{Fs: afero.NewMemMapFs()}
+	}
+
+	return afero.NewOsFs()
+}
+
+// isMemoryFilesystem reports whether fs is the provider in-memory filesystem.
+func isMemoryFilesystem(fs afero.Fs) bool {
+	_, ok := fs.(*memoryFilesystem)
+	return ok
+}
+
+// lstat returns the file info of filePath without following a final symbolic link, when fs
+// supports it.
+func lstat(fs afero.Fs, filePath string) (os.FileInfo, error) {
+	if lstater, ok := fs.(afero.Lstater); ok {
+		info, _, err := lstater.LstatIfPossible(hostPath(filePath))
+		return info, err
+	}
+
+	return fs.Stat(hostPath(filePath))
+}
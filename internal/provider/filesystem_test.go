@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/spf13/afero"
+)
+
+// TestNewFilesystem verifies that only the memory filesystem skips refresh checks.
+func TestNewFilesystem(t *testing.T) {
+	if isMemoryFilesystem(newFilesystem(filesystemOS)) {
+		t.Errorf("expected the os filesystem not to be in memory")
+	}
+	if isMemoryFilesystem(newFilesystem("")) {
+		t.Errorf("expected the default filesystem not to be in memory")
+	}
+	if !isMemoryFilesystem(newFilesystem(filesystemMemory)) {
+		t.Errorf("expected the memory filesystem to be in memory")
+	}
+	if isMemoryFilesystem(afero.NewMemMapFs()) {
+		t.Errorf("expected a bare in-memory filesystem to be checked on refresh")
+	}
+}
+
+// TestAccProviderMemoryFilesystem verifies that nothing is written locally with the memory filesystem.
+func TestAccProviderMemoryFilesystem(t *testing.T) {
+	filePath := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {
+						filesystem = "memory"
+					}
+
+					resource "qrcode_generate" "test" {
+						text = "qrcode"
+						file = "` + filePath + `"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("qrcode_generate.test", "filename", filePath),
+					resource.TestCheckResourceAttrSet("qrcode_generate.test", "content_base64"),
+					func(s *terraform.State) error {
+						if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+							return fmt.Errorf("file %s should not exist", filePath)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
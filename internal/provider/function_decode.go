@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &decodeFunction{}
+
+// decodeFunction is the decode function implementation.
+type decodeFunction struct{}
+
+// NewDecodeFunction creates a new decode function instance.
+func NewDecodeFunction() function.Function {
+	return &decodeFunction{}
+}
+
+// Metadata returns the function name.
+func (f *decodeFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "decode"
+}
+
+// Definition defines the function parameters and return type.
+func (f *decodeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Decode the text of a QR code image",
+		MarkdownDescription: "Decodes a base64-encoded PNG image, such as the `content_base64` of a `qrcode_generate` resource or the output of `filebase64()`, and returns the text of the QR code it contains. This is useful in `check` blocks to assert that images produced elsewhere encode the expected value. Provider-defined functions require Terraform 1.8 or later.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "content_base64",
+				MarkdownDescription: "Base64-encoded PNG image containing a QR code.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run decodes the QR code image.
+func (f *decodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var contentBase64 string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &contentBase64))
+	if resp.Error != nil {
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(contentBase64)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid base64 content: %s", err))
+		return
+	}
+
+	text, err := decodeQRCodeImage(data)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Failed to decode QR code: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, text))
+}
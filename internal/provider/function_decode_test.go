@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// TestDecodeFunction verifies that decode returns the text of a rendered QR code.
+func TestDecodeFunction(t *testing.T) {
+	ctx := context.Background()
+
+	pngData := testRenderPNG(t, "qrcode")
+
+	testCases := map[string]struct {
+		contentBase64 string
+		expected      string
+		expectError   bool
+	}{
+		"qr code":        {contentBase64: base64.StdEncoding.EncodeToString(pngData), expected: "qrcode"},
+		"invalid base64": {contentBase64: "not base64", expectError: true},
+		"not an image":   {contentBase64: base64.StdEncoding.EncodeToString([]byte("qrcode")), expectError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(testCase.contentBase64)}),
+			}
+			resp := &function.RunResponse{
+				Result: function.NewResultData(types.StringUnknown()),
+			}
+
+			NewDecodeFunction().Run(ctx, req, resp)
+
+			if testCase.expectError {
+				if resp.Error == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+			if actual := resp.Result.Value(); !actual.Equal(types.StringValue(testCase.expected)) {
+				t.Errorf("expected %q, got %s", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+// TestAccDecodeFunction verifies that decode round-trips the content of a qrcode_generate resource.
+func TestAccDecodeFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text = "qrcode"
+					}
+
+					output "decoded" {
+						value = provider::qrcode::decode(qrcode_generate.test.content_base64)
+					}
+				`,
+				Check: resource.TestCheckOutput("decoded", "qrcode"),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					output "decoded" {
+						value = provider::qrcode::decode("not base64")
+					}
+				`,
+				ExpectError: regexp.MustCompile("Invalid base64 content"),
+			},
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &epcStringFunction{}
+
+// epcStringFunction is the epc_string function implementation.
+type epcStringFunction struct{}
+
+// NewEPCStringFunction creates a new epc_string function instance.
+func NewEPCStringFunction() function.Function {
+	return &epcStringFunction{}
+}
+
+// Metadata returns the function name.
+func (f *epcStringFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "epc_string"
+}
+
+// Definition defines the function parameters and return type.
+func (f *epcStringFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build the text of an EPC QR code for a SEPA credit transfer",
+		MarkdownDescription: "Returns the text of an EPC QR code, also known as GiroCode, that banking apps scan to prefill a SEPA credit transfer, as specified by the European Payments Council in EPC069-12. The text is version `002` in UTF-8, and can be encoded with the `text` of a `qrcode_generate` resource or checked in tests without rendering an image. Provider-defined functions require Terraform 1.8 or later.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "name",
+				MarkdownDescription: "Name of the beneficiary, up to 70 characters.",
+			},
+			function.StringParameter{
+				Name:                "iban",
+				MarkdownDescription: "IBAN of the beneficiary, with or without spaces. Its check digits are verified.",
+			},
+			function.NumberParameter{
+				Name:                "amount",
+				AllowNullValue:      true,
+				MarkdownDescription: "Amount in euros, from 0.01 to 999999999.99 with at most two decimals, or null to let the payer enter it.",
+			},
+			function.StringParameter{
+				Name:                "remittance",
+				AllowNullValue:      true,
+				MarkdownDescription: "ISO 11649 creditor reference, such as `RF18539007547034`, which is encoded as the structured reference, or remittance text of up to 140 characters, or null.",
+			},
+			function.StringParameter{
+				Name:                "bic",
+				AllowNullValue:      true,
+				MarkdownDescription: "BIC of the bank of the beneficiary, or null, which banks within the EEA accept.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the text of the EPC QR code.
+func (f *epcStringFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name, iban string
+	var amount types.Number
+	var remittance, bic types.String
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &name, &iban, &amount, &remittance, &bic))
+	if resp.Error != nil {
+		return
+	}
+
+	payment := epcPayment{
+		Name:       name,
+		IBAN:       iban,
+		Remittance: remittance.ValueString(),
+		BIC:        bic.ValueString(),
+	}
+	if !amount.IsNull() {
+		formatted, err := formatPaymentAmount(amount.ValueBigFloat(), maxEPCAmount)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(2, err.Error())
+			return
+		}
+		payment.Amount = formatted
+	}
+
+	text, err := payment.epcString()
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, text))
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TestEPCStringFunction verifies that epc_string builds EPC QR code text, and refuses invalid
+// accounts and amounts.
+func TestEPCStringFunction(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		name        string
+		iban        string
+		amount      types.Number
+		remittance  types.String
+		bic         types.String
+		expected    string
+		expectError bool
+	}{
+		"amount and text": {
+			name:       "Red Cross",
+			iban:       "DE89 3704 0044 0532 0130 00",
+			amount:     types.NumberValue(big.NewFloat(12.5)),
+			remittance: types.StringValue("Donation"),
+			bic:        types.StringNull(),
+			expected:   "BCD\n002\n1\nSCT\n\nRed Cross\nDE89370400440532013000\nEUR12.5\n\n\nDonation",
+		},
+		"creditor reference and bic": {
+			name:       "Red Cross",
+			iban:       "DE89370400440532013000",
+			amount:     types.NumberNull(),
+			remittance: types.StringValue("RF18 5390 0754 7034"),
+			bic:        types.StringValue("cobadeffxxx"),
+			expected:   "BCD\n002\n1\nSCT\nCOBADEFFXXX\nRed Cross\nDE89370400440532013000\n\n\nRF18539007547034",
+		},
+		"account only": {
+			name:       "Red Cross",
+			iban:       "DE89370400440532013000",
+			amount:     types.NumberNull(),
+			remittance: types.StringNull(),
+			bic:        types.StringNull(),
+			expected:   "BCD\n002\n1\nSCT\n\nRed Cross\nDE89370400440532013000",
+		},
+		"invalid iban": {
+			name: "Red Cross", iban: "DE89370400440532013001",
+			amount: types.NumberNull(), remittance: types.StringNull(), bic: types.StringNull(),
+			expectError: true,
+		},
+		"too many decimals": {
+			name: "Red Cross", iban: "DE89370400440532013000",
+			amount: types.NumberValue(big.NewFloat(0.125)), remittance: types.StringNull(), bic: types.StringNull(),
+			expectError: true,
+		},
+		"negative amount": {
+			name: "Red Cross", iban: "DE89370400440532013000",
+			amount: types.NumberValue(big.NewFloat(-1)), remittance: types.StringNull(), bic: types.StringNull(),
+			expectError: true,
+		},
+		"empty name": {
+			name: "", iban: "DE89370400440532013000",
+			amount: types.NumberNull(), remittance: types.StringNull(), bic: types.StringNull(),
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{
+					types.StringValue(testCase.name),
+					types.StringValue(testCase.iban),
+					testCase.amount,
+					testCase.remittance,
+					testCase.bic,
+				}),
+			}
+			resp := &function.RunResponse{
+				Result: function.NewResultData(types.StringUnknown()),
+			}
+
+			NewEPCStringFunction().Run(ctx, req, resp)
+
+			if testCase.expectError {
+				if resp.Error == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+			if actual := resp.Result.Value(); !actual.Equal(types.StringValue(testCase.expected)) {
+				t.Errorf("expected %q, got %s", testCase.expected, actual)
+			}
+		})
+	}
+}
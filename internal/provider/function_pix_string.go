@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &pixStringFunction{}
+
+// pixStringFunction is the pix_string function implementation.
+type pixStringFunction struct{}
+
+// NewPIXStringFunction creates a new pix_string function instance.
+func NewPIXStringFunction() function.Function {
+	return &pixStringFunction{}
+}
+
+// Metadata returns the function name.
+func (f *pixStringFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "pix_string"
+}
+
+// Definition defines the function parameters and return type.
+func (f *pixStringFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build the text of a Pix BR Code",
+		MarkdownDescription: "Returns the text of a static Pix BR Code, the EMV merchant-presented QR code that Brazilian banking apps scan to pay a Pix key, ending in its CRC16 checksum. The text can be encoded with the `text` of a `qrcode_generate` resource or checked in tests without rendering an image. Provider-defined functions require Terraform 1.8 or later.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "key",
+				MarkdownDescription: "Pix key of the receiver: a CPF or CNPJ, a phone number such as `+5511999999999`, an email address or a random key.",
+			},
+			function.StringParameter{
+				Name:                "merchant_name",
+				MarkdownDescription: "Name of the receiver, up to 25 ASCII characters.",
+			},
+			function.StringParameter{
+				Name:                "merchant_city",
+				MarkdownDescription: "City of the receiver, up to 15 ASCII characters.",
+			},
+			function.NumberParameter{
+				Name:                "amount",
+				AllowNullValue:      true,
+				MarkdownDescription: "Amount in reais, from 0.01 to 9999999999.99 with at most two decimals, or null to let the payer enter it.",
+			},
+			function.StringParameter{
+				Name:                "txid",
+				AllowNullValue:      true,
+				MarkdownDescription: "Identifier of the payment, up to 25 ASCII characters, or null for `***`, which marks payments without an identifier.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the text of the BR Code.
+func (f *pixStringFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var key, merchantName, merchantCity string
+	var amount types.Number
+	var txid types.String
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &key, &merchantName, &merchantCity, &amount, &txid))
+	if resp.Error != nil {
+		return
+	}
+
+	payment := pixPayment{
+		Key:          key,
+		MerchantName: merchantName,
+		MerchantCity: merchantCity,
+		TxID:         txid.ValueString(),
+	}
+	if !amount.IsNull() {
+		if _, err := formatPaymentAmount(amount.ValueBigFloat(), maxPIXAmount); err != nil {
+			resp.Error = function.NewArgumentFuncError(3, err.Error())
+			return
+		}
+		payment.Amount = amount.ValueBigFloat().Text('f', 2)
+	}
+
+	text, err := payment.pixString()
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, text))
+}
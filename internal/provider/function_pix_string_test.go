@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TestPIXStringFunction verifies that pix_string builds BR Code text matching the example of the
+// Central Bank of Brazil, and refuses invalid fields.
+func TestPIXStringFunction(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		key          string
+		merchantName string
+		amount       types.Number
+		txid         types.String
+		expected     string
+		expectError  bool
+	}{
+		"specification example": {
+			key:          "123e4567-e12b-12d1-a456-426655440000",
+			merchantName: "Fulano de Tal",
+			amount:       types.NumberNull(),
+			txid:         types.StringNull(),
+			expected:     "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D",
+		},
+		"amount and txid": {
+			key:          "123e4567-e12b-12d1-a456-426655440000",
+			merchantName: "Fulano de Tal",
+			amount:       types.NumberValue(big.NewFloat(10.5)),
+			txid:         types.StringValue("PEDIDO42"),
+			expected:     "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-426655440000520400005303986540510.505802BR5913Fulano de Tal6008BRASILIA62120508PEDIDO426304CCEC",
+		},
+		"accented name": {
+			key: "123e4567-e12b-12d1-a456-426655440000", merchantName: "João",
+			amount: types.NumberNull(), txid: types.StringNull(),
+			expectError: true,
+		},
+		"long txid": {
+			key: "123e4567-e12b-12d1-a456-426655440000", merchantName: "Fulano de Tal",
+			amount: types.NumberNull(), txid: types.StringValue("12345678901234567890123456"),
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{
+					types.StringValue(testCase.key),
+					types.StringValue(testCase.merchantName),
+					types.StringValue("BRASILIA"),
+					testCase.amount,
+					testCase.txid,
+				}),
+			}
+			resp := &function.RunResponse{
+				Result: function.NewResultData(types.StringUnknown()),
+			}
+
+			NewPIXStringFunction().Run(ctx, req, resp)
+
+			if testCase.expectError {
+				if resp.Error == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+			if actual := resp.Result.Value(); !actual.Equal(types.StringValue(testCase.expected)) {
+				t.Errorf("expected %q, got %s", testCase.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package provider
+
+import "strings"
+
+// windowsMaxPath is the length from which Windows APIs reject paths without the extended-length
+// prefix. Directories are limited to MAX_PATH minus the 12 characters of an 8.3 file name.
+const windowsMaxPath = 248
+
+// windowsLongPath adds the extended-length prefix to an absolute, cleaned Windows path when it is
+// too long for the legacy APIs, so paths over MAX_PATH can be created and removed. Drive letter
+// paths get the \\?\ prefix and UNC paths the \\?\UNC\ prefix. Short, relative and already
+// prefixed paths are returned unchanged.
+func windowsLongPath(p string) string {
+	if len(p) < windowsMaxPath {
+		return p
+	}
+
+	switch {
+	case strings.HasPrefix(p, `\\?\`), strings.HasPrefix(p, `\\.\`):
+		return p
+	case strings.HasPrefix(p, `\\`):
+		return `\\?\UNC\` + p[2:]
+	case len(p) >= 3 && p[1] == ':' && p[2] == '\\':
+		return `\\?\` + p
+	}
+
+	return p
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package provider
+
+// hostPath returns the configured file path unchanged, as only Windows needs path conversion.
+func hostPath(p string) string {
+	return p
+}
@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestWindowsLongPath verifies the extended-length prefix is only added to long absolute paths.
+func TestWindowsLongPath(t *testing.T) {
+	long := strings.Repeat(`a\`, windowsMaxPath/2) + "qrcode.png"
+
+	tests := map[string]string{
+		`C:\codes\qrcode.png`:          `C:\codes\qrcode.png`,
+		`C:\` + long:                   `\\?\C:\` + long,
+		`\\server\share\` + long:       `\\?\UNC\server\share\` + long,
+		`\\?\C:\` + long:               `\\?\C:\` + long,
+		`\\?\UNC\server\share\` + long: `\\?\UNC\server\share\` + long,
+		long:                           long,
+	}
+
+	for input, expected := range tests {
+		if actual := windowsLongPath(input); actual != expected {
+			t.Errorf("windowsLongPath(%q): expected %q, got %q", input, expected, actual)
+		}
+	}
+}
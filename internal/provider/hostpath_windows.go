@@ -0,0 +1,17 @@
+//go:build windows
+
+package provider
+
+import "path/filepath"
+
+// hostPath converts a configured file path into one the Windows file APIs accept: forward slashes
+// are normalized to backslashes, relative and drive-relative paths are made absolute, and long
+// paths get the extended-length prefix.
+func hostPath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+
+	return windowsLongPath(abs)
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Forms that idn_mode converts the host names of URLs to.
+const (
+	idnModePunycode = "punycode"
+	idnModeUnicode  = "unicode"
+)
+
+// urlSchemePattern matches the scheme of a URL.
+var urlSchemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+// convertIDN converts the host name of a URL with an authority, such as https://bücher.example/,
+// to its ASCII punycode or its Unicode form, validating it with the IDNA lookup rules that
+// browsers apply. Host names that are already in the form are left as they are, as are IP
+// addresses and text that is not such a URL. Only the host name changes, so that the rest of the
+// URL is encoded exactly as written.
+func convertIDN(text, mode string) (string, error) {
+	scheme, rest, ok := strings.Cut(text, "://")
+	if !ok || !urlSchemePattern.MatchString(scheme) {
+		return text, nil
+	}
+
+	authorityEnd := strings.IndexAny(rest, "/?#")
+	if authorityEnd < 0 {
+		authorityEnd = len(rest)
+	}
+	authority := rest[:authorityEnd]
+
+	hostStart := strings.LastIndex(authority, "@") + 1
+	host := authority[hostStart:]
+	if strings.HasPrefix(host, "[") {
+		return text, nil
+	}
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+
+	var converted string
+	var err error
+	switch mode {
+	case idnModePunycode:
+		if isASCII(host) {
+			return text, nil
+		}
+		converted, err = idna.Lookup.ToASCII(host)
+	case idnModeUnicode:
+		if !hasPunycodeLabel(host) {
+			return text, nil
+		}
+		converted, err = idna.Lookup.ToUnicode(host)
+	default:
+		return text, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("host name %q is not a valid internationalized domain name: %w", host, err)
+	}
+
+	prefix := len(scheme) + len("://") + hostStart
+	return text[:prefix] + converted + text[prefix+len(host):], nil
+}
+
+// isASCII reports whether text holds only ASCII characters.
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasPunycodeLabel reports whether a host name has a label in punycode.
+func hasPunycodeLabel(host string) bool {
+	for _, label := range strings.Split(host, ".") {
+		if strings.HasPrefix(strings.ToLower(label), "xn--") {
+			return true
+		}
+	}
+
+	return false
+}
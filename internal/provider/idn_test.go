@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestConvertIDN verifies that only the host name of URLs is converted, and that invalid host
+// names are rejected.
+func TestConvertIDN(t *testing.T) {
+	testCases := map[string]struct {
+		text     string
+		mode     string
+		expected string
+		error    bool
+	}{
+		"punycode": {
+			text:     "https://user@bücher.example:8443/Bücher?q=ä#top",
+			mode:     idnModePunycode,
+			expected: "https://user@xn--bcher-kva.example:8443/Bücher?q=ä#top",
+		},
+		"unicode": {
+			text:     "https://xn--bcher-kva.example/path",
+			mode:     idnModeUnicode,
+			expected: "https://bücher.example/path",
+		},
+		"ascii host": {
+			text:     "https://Example.COM/",
+			mode:     idnModePunycode,
+			expected: "https://Example.COM/",
+		},
+		"unicode ascii host": {
+			text:     "https://Example.COM/",
+			mode:     idnModeUnicode,
+			expected: "https://Example.COM/",
+		},
+		"ip address": {
+			text:     "http://[2001:db8::1]:8080/",
+			mode:     idnModePunycode,
+			expected: "http://[2001:db8::1]:8080/",
+		},
+		"not a url": {
+			text:     `{"url":"https://bücher.example"}`,
+			mode:     idnModePunycode,
+			expected: `{"url":"https://bücher.example"}`,
+		},
+		"invalid host": {
+			text:  "https://bü_cher.example/",
+			mode:  idnModePunycode,
+			error: true,
+		},
+		"invalid punycode": {
+			text:  "https://xn--a.example/",
+			mode:  idnModeUnicode,
+			error: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			converted, err := convertIDN(testCase.text, testCase.mode)
+			if testCase.error {
+				if err == nil {
+					t.Errorf("expected an error, got %q", converted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if converted != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, converted)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceIDNMode verifies that the converted URL is encoded, and that an invalid host
+// name fails validation.
+func TestQRCodeResourceIDNMode(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	config := func(text string) tfsdk.Config {
+		return tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+			"text":     tftypes.NewValue(tftypes.String, text),
+			"idn_mode": tftypes.NewValue(tftypes.String, idnModePunycode),
+		})}
+	}
+
+	var model qrcodeResourceModel
+	if diags := config("https://bücher.example/").Get(ctx, &model); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if content := model.content(); content != "https://xn--bcher-kva.example/" {
+		t.Errorf("expected the punycode URL to be encoded, got %q", content)
+	}
+
+	validateResp := &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{Config: config("https://bü_cher.example/")}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected an invalid host name to fail validation")
+	}
+}
@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ippRequestTimeout bounds every request to a printer.
+const ippRequestTimeout = 60 * time.Second
+
+// ippDefaultPort is the port of ipp and ipps URIs without one.
+const ippDefaultPort = "631"
+
+// IPP operations, attribute group delimiters and value tags of RFC 8010 and RFC 8011.
+const (
+	ippOperationPrintJob = 0x0002
+
+	ippTagOperationAttributes = 0x01
+	ippTagJobAttributes       = 0x02
+	ippTagEndOfAttributes     = 0x03
+
+	ippTagInteger         = 0x21
+	ippTagTextWithoutLang = 0x41
+	ippTagNameWithoutLang = 0x42
+	ippTagKeyword         = 0x44
+	ippTagURI             = 0x45
+	ippTagCharset         = 0x47
+	ippTagNaturalLanguage = 0x48
+	ippTagMimeMediaType   = 0x49
+)
+
+// ippStatusSuccessfulLimit is the lowest status code that is not successful.
+const ippStatusSuccessfulLimit = 0x0100
+
+// ippDocumentFormats maps image formats to the MIME types printers are told the document is in.
+// Printer command languages are sent as raw data that the printer reads itself.
+var ippDocumentFormats = map[string]string{
+	imageFormatPNG: "image/png",
+	imageFormatSVG: "image/svg+xml",
+	imageFormatPDF: "application/pdf",
+}
+
+// qrcodePrintModel maps the print block of the qrcode_generate resource schema data.
+type qrcodePrintModel struct {
+	URI    types.String `tfsdk:"uri"`
+	Media  types.String `tfsdk:"media"`
+	Copies types.Int64  `tfsdk:"copies"`
+}
+
+// ippJob is a print job submitted to a printer.
+type ippJob struct {
+	printerURI string
+	media      string
+	copies     int
+	format     string
+	name       string
+}
+
+// printerURL returns the HTTP URL that IPP requests to the printer URI are posted to.
+func printerURL(printerURI string) (string, error) {
+	u, err := url.Parse(printerURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid printer URI %q: %w", printerURI, err)
+	}
+
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("printer URI %q has no host", printerURI)
+	}
+
+	switch u.Scheme {
+	case "ipp":
+		u.Scheme = "http"
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), ippDefaultPort)
+		}
+	case "ipps":
+		u.Scheme = "https"
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), ippDefaultPort)
+		}
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("printer URI %q must use the ipp, ipps, http or https scheme", printerURI)
+	}
+
+	return u.String(), nil
+}
+
+// submitPrintJob sends the document to the printer with a Print-Job request and returns the ID
+// of the job the printer created. Requests that fail with a transient error, such as a server
+// error response, are retried as retry sets.
+func submitPrintJob(ctx context.Context, retry retryPolicy, job ippJob, document []byte) (int64, error) {
+	endpoint, err := printerURL(job.printerURI)
+	if err != nil {
+		return 0, err
+	}
+
+	var jobID int64
+	_, err = retry.do(ctx, func() error {
+		var err error
+		jobID, err = sendPrintJob(ctx, endpoint, job, document)
+		return err
+	})
+
+	return jobID, err
+}
+
+// sendPrintJob posts a Print-Job request to endpoint once, as submitPrintJob does.
+func sendPrintJob(ctx context.Context, endpoint string, job ippJob, document []byte) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, io.MultiReader(bytes.NewReader(job.request()), bytes.NewReader(document)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/ipp")
+
+	client := &http.Client{Timeout: ippRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, &httpStatusError{status: resp.StatusCode, message: fmt.Sprintf("printer responded %s", resp.Status)}
+	}
+
+	return parsePrintJobResponse(body)
+}
+
+// request encodes the Print-Job request that precedes the document.
+func (j ippJob) request() []byte {
+	documentFormat, ok := ippDocumentFormats[j.format]
+	if !ok {
+		documentFormat = "application/octet-stream"
+	}
+
+	request := []byte{2, 0}
+	request = binary.BigEndian.AppendUint16(request, ippOperationPrintJob)
+	request = binary.BigEndian.AppendUint32(request, 1)
+
+	request = append(request, ippTagOperationAttributes)
+	request = appendIPPAttribute(request, ippTagCharset, "attributes-charset", []byte("utf-8"))
+	request = appendIPPAttribute(request, ippTagNaturalLanguage, "attributes-natural-language", []byte("en"))
+	request = appendIPPAttribute(request, ippTagURI, "printer-uri", []byte(j.printerURI))
+	request = appendIPPAttribute(request, ippTagNameWithoutLang, "requesting-user-name", []byte("terraform"))
+	request = appendIPPAttribute(request, ippTagNameWithoutLang, "job-name", []byte(j.name))
+	request = appendIPPAttribute(request, ippTagMimeMediaType, "document-format", []byte(documentFormat))
+
+	request = append(request, ippTagJobAttributes)
+	request = appendIPPAttribute(request, ippTagInteger, "copies", binary.BigEndian.AppendUint32(nil, uint32(j.copies)))
+	if j.media != "" {
+		request = appendIPPAttribute(request, ippTagKeyword, "media", []byte(j.media))
+	}
+
+	return append(request, ippTagEndOfAttributes)
+}
+
+// appendIPPAttribute appends an attribute with a single value.
+func appendIPPAttribute(request []byte, tag byte, name string, value []byte) []byte {
+	request = append(request, tag)
+	request = binary.BigEndian.AppendUint16(request, uint16(len(name)))
+	request = append(request, name...)
+	request = binary.BigEndian.AppendUint16(request, uint16(len(value)))
+
+	return append(request, value...)
+}
+
+// parsePrintJobResponse returns the job-id of a successful Print-Job response, and otherwise an
+// error with the status-message of the printer.
+func parsePrintJobResponse(body []byte) (int64, error) {
+	if len(body) < 8 {
+		return 0, errors.New("printer responded with a truncated IPP response")
+	}
+	status := binary.BigEndian.Uint16(body[2:4])
+
+	var jobID int64
+	var message string
+	hasJobID := false
+	for rest := body[8:]; len(rest) > 0; {
+		tag := rest[0]
+		rest = rest[1:]
+		if tag == ippTagEndOfAttributes {
+			break
+		}
+		if tag < 0x10 {
+			continue
+		}
+
+		if len(rest) < 2 {
+			return 0, errors.New("printer responded with a truncated IPP attribute")
+		}
+		nameLength := int(binary.BigEndian.Uint16(rest))
+		if len(rest) < 4+nameLength {
+			return 0, errors.New("printer responded with a truncated IPP attribute")
+		}
+		name := string(rest[2 : 2+nameLength])
+		valueLength := int(binary.BigEndian.Uint16(rest[2+nameLength:]))
+		rest = rest[4+nameLength:]
+		if len(rest) < valueLength {
+			return 0, errors.New("printer responded with a truncated IPP attribute")
+		}
+		value := rest[:valueLength]
+		rest = rest[valueLength:]
+
+		switch {
+		case name == "job-id" && tag == ippTagInteger && valueLength == 4:
+			jobID = int64(int32(binary.BigEndian.Uint32(value)))
+			hasJobID = true
+		case name == "status-message" && tag == ippTagTextWithoutLang:
+			message = string(value)
+		}
+	}
+
+	if status >= ippStatusSuccessfulLimit {
+		if message == "" {
+			message = "no status message"
+		}
+		return 0, fmt.Errorf("printer refused the job with status 0x%04x: %s", status, message)
+	}
+	if !hasJobID {
+		return 0, errors.New("printer accepted the job without a job-id")
+	}
+
+	return jobID, nil
+}
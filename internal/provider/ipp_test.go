@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// ippAttributes decodes the attributes of an IPP request into a map of name to value, and
+// returns the data that follows them.
+func ippAttributes(t *testing.T, request []byte) (map[string][]byte, []byte) {
+	t.Helper()
+
+	attributes := map[string][]byte{}
+	rest := request[8:]
+	for len(rest) > 0 {
+		tag := rest[0]
+		rest = rest[1:]
+		if tag == ippTagEndOfAttributes {
+			return attributes, rest
+		}
+		if tag < 0x10 {
+			continue
+		}
+		nameLength := int(binary.BigEndian.Uint16(rest))
+		name := string(rest[2 : 2+nameLength])
+		valueLength := int(binary.BigEndian.Uint16(rest[2+nameLength:]))
+		attributes[name] = rest[4+nameLength : 4+nameLength+valueLength]
+		rest = rest[4+nameLength+valueLength:]
+	}
+	t.Fatalf("request has no end-of-attributes tag")
+	return nil, nil
+}
+
+// ippResponse encodes a response with the status code and attributes.
+func ippResponse(status uint16, attributes ...[]byte) []byte {
+	response := []byte{2, 0}
+	response = binary.BigEndian.AppendUint16(response, status)
+	response = binary.BigEndian.AppendUint32(response, 1)
+	response = append(response, ippTagOperationAttributes)
+	for _, attribute := range attributes {
+		response = append(response, attribute...)
+	}
+	return append(response, ippTagEndOfAttributes)
+}
+
+// newFakePrinter returns a printer that records the last request and answers with response.
+func newFakePrinter(t *testing.T, response []byte, request *[]byte) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/ipp" {
+			http.Error(w, "not an IPP request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		*request = body
+		w.Header().Set("Content-Type", "application/ipp")
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestSubmitPrintJob verifies the Print-Job request and that the job ID or the status message of
+// the printer is returned.
+func TestSubmitPrintJob(t *testing.T) {
+	ctx := context.Background()
+
+	var request []byte
+	printer := newFakePrinter(t, ippResponse(0x0000,
+		appendIPPAttribute(nil, ippTagInteger, "job-id", binary.BigEndian.AppendUint32(nil, 42)),
+	), &request)
+
+	jobID, err := submitPrintJob(ctx, retryPolicy{}, ippJob{
+		printerURI: printer.URL + "/ipp/print",
+		media:      "oe_4x6-label_4x6in",
+		copies:     2,
+		format:     imageFormatPDF,
+		name:       "qr.pdf",
+	}, []byte("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("failed to print: %s", err)
+	}
+	if jobID != 42 {
+		t.Errorf("expected job ID 42, got %d", jobID)
+	}
+
+	if operation := binary.BigEndian.Uint16(request[2:4]); operation != ippOperationPrintJob {
+		t.Errorf("expected a Print-Job request, got operation 0x%04x", operation)
+	}
+	attributes, document := ippAttributes(t, request)
+	for name, expected := range map[string][]byte{
+		"printer-uri":     []byte(printer.URL + "/ipp/print"),
+		"job-name":        []byte("qr.pdf"),
+		"document-format": []byte("application/pdf"),
+		"media":           []byte("oe_4x6-label_4x6in"),
+		"copies":          {0, 0, 0, 2},
+	} {
+		if !bytes.Equal(attributes[name], expected) {
+			t.Errorf("expected %s %q, got %q", name, expected, attributes[name])
+		}
+	}
+	if string(document) != "%PDF-1.4" {
+		t.Errorf("expected the document after the attributes, got %q", document)
+	}
+
+	refusing := newFakePrinter(t, ippResponse(0x040a,
+		appendIPPAttribute(nil, ippTagTextWithoutLang, "status-message", []byte("document-format not supported")),
+	), &request)
+	if _, err := submitPrintJob(ctx, retryPolicy{}, ippJob{printerURI: refusing.URL, copies: 1, format: imageFormatSVG}, nil); err == nil || !bytes.Contains([]byte(err.Error()), []byte("document-format not supported")) {
+		t.Errorf("expected the status message of the printer, got %v", err)
+	}
+}
+
+// TestSubmitPrintJobRetry verifies that Print-Job requests the printer answers with a server error
+// are sent again.
+func TestSubmitPrintJobRetry(t *testing.T) {
+	ctx := context.Background()
+
+	response := ippResponse(0x0000, appendIPPAttribute(nil, ippTagInteger, "job-id", binary.BigEndian.AppendUint32(nil, 7)))
+	printer := &flakyHandler{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(response)
+		}),
+		failures: 1,
+		status:   http.StatusServiceUnavailable,
+	}
+	server := httptest.NewServer(printer)
+	defer server.Close()
+
+	jobID, err := submitPrintJob(ctx, retryPolicy{maxAttempts: 3}, ippJob{printerURI: server.URL, copies: 1, format: imageFormatPNG}, []byte("png"))
+	if err != nil || jobID != 7 {
+		t.Fatalf("expected job 7, got %d: %v", jobID, err)
+	}
+	if printer.requests != 2 {
+		t.Errorf("expected 2 requests, got %d", printer.requests)
+	}
+}
+
+// TestPrinterURL verifies that IPP URIs are posted to over HTTP on the IPP port.
+func TestPrinterURL(t *testing.T) {
+	testCases := map[string]struct {
+		uri      string
+		expected string
+		error    bool
+	}{
+		"ipp":       {uri: "ipp://printer.local/ipp/print", expected: "http://printer.local:631/ipp/print"},
+		"ipps port": {uri: "ipps://cups.example.com:443/printers/labels", expected: "https://cups.example.com:443/printers/labels"},
+		"http":      {uri: "http://localhost:8631/printers/labels", expected: "http://localhost:8631/printers/labels"},
+		"scheme":    {uri: "lpd://printer.local/queue", error: true},
+		"host":      {uri: "ipp:///ipp/print", error: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			endpoint, err := printerURL(testCase.uri)
+			if testCase.error {
+				if err == nil {
+					t.Errorf("expected an error, got %s", endpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if endpoint != testCase.expected {
+				t.Errorf("expected %s, got %s", testCase.expected, endpoint)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourcePrint verifies that the image is printed as it is generated and that the job
+// ID is kept in state.
+func TestQRCodeResourcePrint(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	var request []byte
+	printer := newFakePrinter(t, ippResponse(0x0000,
+		appendIPPAttribute(nil, ippTagInteger, "job-id", binary.BigEndian.AppendUint32(nil, 7)),
+	), &request)
+
+	printType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object).AttributeTypes["print"].(tftypes.Object)
+	if !ok {
+		t.Fatalf("print is not an object")
+	}
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":   tftypes.NewValue(tftypes.String, "https://example.com"),
+		"format": tftypes.NewValue(tftypes.String, imageFormatZPL),
+		"print": tftypes.NewValue(printType, map[string]tftypes.Value{
+			"uri":    tftypes.NewValue(tftypes.String, printer.URL+"/printers/labels"),
+			"media":  tftypes.NewValue(tftypes.String, nil),
+			"copies": tftypes.NewValue(tftypes.Number, nil),
+		}),
+	})}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if state.PrintJobID.ValueInt64() != 7 {
+		t.Errorf("expected print_job_id 7, got %s", state.PrintJobID)
+	}
+
+	attributes, document := ippAttributes(t, request)
+	if format := string(attributes["document-format"]); format != "application/octet-stream" {
+		t.Errorf("expected ZPL to be sent as raw data, got %s", format)
+	}
+	if !bytes.HasPrefix(document, []byte("~DGR:QRCODE.GRF,")) {
+		t.Errorf("expected the ZPL label to be printed, got %q", document)
+	}
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// jwsHeader is the protected header of the JWS signatures of QR code payloads.
+type jwsHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// jwsSigner signs QR code payloads as compact JWS, with the algorithm that the type of its key
+// calls for.
+type jwsSigner struct {
+	key       crypto.Signer
+	algorithm string
+	keyID     string
+}
+
+// parseJWSSigningKey parses a PEM private key as a JWS signer: RSA keys sign with RS256, P-256 and
+// P-384 keys with ES256 and ES384, and Ed25519 keys with EdDSA. Keys may be PKCS #8, PKCS #1 or SEC 1
+// encoded. The key ID is set as the kid header of signatures, unless it is empty.
+func parseJWSSigningKey(key, keyID string) (*jwsSigner, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("no PEM block found in JWS signing key")
+	}
+
+	var privateKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in JWS signing key, expected a private key", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS signing key: %w", err)
+	}
+
+	signer := &jwsSigner{keyID: keyID}
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA JWS signing keys must be at least 2048 bits, got %d", k.N.BitLen())
+		}
+		signer.key, signer.algorithm = k, "RS256"
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			signer.key, signer.algorithm = k, "ES256"
+		case elliptic.P384():
+			signer.key, signer.algorithm = k, "ES384"
+		default:
+			return nil, fmt.Errorf("unsupported curve %s of JWS signing key, expected P-256 or P-384", k.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		signer.key, signer.algorithm = k, "EdDSA"
+	default:
+		return nil, fmt.Errorf("unsupported JWS signing key type %T, expected an RSA, ECDSA or Ed25519 key", privateKey)
+	}
+
+	return signer, nil
+}
+
+// sign returns the compact JWS of payload: the protected header, the payload and the signature,
+// each base64url encoded without padding and joined with dots. ECDSA signatures are randomized,
+// so signing the same payload twice gives different results.
+func (s *jwsSigner) sign(payload []byte) (string, error) {
+	header, err := json.Marshal(jwsHeader{Algorithm: s.algorithm, KeyID: s.keyID})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signingInput))
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		signature, err = signECDSA(key, []byte(signingInput))
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not sign JWS: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// signECDSA signs data with key as JWS requires: the hash of the curve size, and the R and S
+// values concatenated as fixed-size big-endian integers rather than ASN.1 encoded.
+func signECDSA(key *ecdsa.PrivateKey, data []byte) ([]byte, error) {
+	var h hash.Hash = sha256.New()
+	if key.Curve == elliptic.P384() {
+		h = sha512.New384()
+	}
+	h.Write(data)
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	size := (key.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+
+	return signature, nil
+}
+
+// jwsPayload returns the decoded payload of a compact JWS, without verifying its signature.
+func jwsPayload(jws string) ([]byte, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("compact JWS has %d parts, expected 3", len(parts))
+	}
+
+	return base64.RawURLEncoding.DecodeString(parts[1])
+}
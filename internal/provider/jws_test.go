@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// testJWSKeyPEM returns key as a PKCS #8 PEM block.
+func testJWSKeyPEM(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %s", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// verifyTestJWS checks the signature of a compact JWS with the public key, and returns its header.
+func verifyTestJWS(t *testing.T, jws string, publicKey interface{}) jwsHeader {
+	t.Helper()
+
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a compact JWS, got %q", jws)
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("failed to decode signature: %s", err)
+	}
+
+	var valid bool
+	digest := sha256.Sum256(signingInput)
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, signingInput, signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		valid = len(signature) == 64 && ecdsa.Verify(key, digest[:], r, s)
+	}
+	if !valid {
+		t.Errorf("expected a valid signature of %q", jws)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		t.Fatalf("failed to decode header: %s", err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		t.Fatalf("failed to parse header: %s", err)
+	}
+
+	return header
+}
+
+func TestJWSSigner(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	tests := map[string]struct {
+		pem       string
+		publicKey interface{}
+		algorithm string
+	}{
+		"ed25519": {testJWSKeyPEM(t, edKey), edKey.Public(), "EdDSA"},
+		"p256":    {testJWSKeyPEM(t, ecKey), ecKey.Public(), "ES256"},
+		"p256 sec1": {func() string {
+			der, err := x509.MarshalECPrivateKey(ecKey)
+			if err != nil {
+				t.Fatalf("failed to marshal key: %s", err)
+			}
+			return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+		}(), ecKey.Public(), "ES256"},
+		"rsa pkcs1": {string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})), rsaKey.Public(), "RS256"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			signer, err := parseJWSSigningKey(test.pem, "provisioning-2026")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			jws, err := signer.sign([]byte("https://example.com/enroll?token=abc"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			header := verifyTestJWS(t, jws, test.publicKey)
+			if header.Algorithm != test.algorithm || header.KeyID != "provisioning-2026" {
+				t.Errorf("expected alg %s and kid provisioning-2026, got %+v", test.algorithm, header)
+			}
+
+			payload, err := jwsPayload(jws)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(payload) != "https://example.com/enroll?token=abc" {
+				t.Errorf("expected the payload, got %q", payload)
+			}
+		})
+	}
+
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	for name, key := range map[string]string{
+		"not pem":   "not a key",
+		"small rsa": testJWSKeyPEM(t, smallKey),
+		"public key": func() string {
+			der, _ := x509.MarshalPKIXPublicKey(edKey.Public())
+			return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		}(),
+	} {
+		if _, err := parseJWSSigningKey(key, ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestQRCodeResourceSignJWS(t *testing.T) {
+	ctx := context.Background()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	signer, err := parseJWSSigningKey(testJWSKeyPEM(t, privateKey), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r := &qrcodeResource{fs: afero.NewMemMapFs(), jwsSigner: signer}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":     tftypes.NewValue(tftypes.String, "https://example.com/enroll?token=abc"),
+		"file":     tftypes.NewValue(tftypes.String, "/qr.png"),
+		"sign_jws": tftypes.NewValue(tftypes.Bool, true),
+	})}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode content_base64: %s", err)
+	}
+	text, err := decodeQRCodeImage(data)
+	if err != nil {
+		t.Fatalf("failed to decode QR code: %s", err)
+	}
+	if text != state.JWS.ValueString() {
+		t.Errorf("expected the QR code to encode the jws attribute %q, got %q", state.JWS.ValueString(), text)
+	}
+	verifyTestJWS(t, text, publicKey)
+
+	matches, err := imageEncodesText(r.fs, state, "/qr.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !matches {
+		t.Errorf("expected the image to encode the signed text")
+	}
+
+	// Signing without a key fails
+	r.jwsSigner = nil
+	resp = &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if !resp.Diagnostics.HasError() {
+		t.Errorf("expected an error without a JWS signing key")
+	}
+}
@@ -0,0 +1,598 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of Kubernetes objects that the kubernetes block of the qrcode_generate resource writes to.
+const (
+	kubernetesKindConfigMap = "ConfigMap"
+	kubernetesKindSecret    = "Secret"
+)
+
+// Location of the service account credentials mounted into pods, used for in-cluster access.
+const (
+	kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	kubernetesDefaultNamespace  = "default"
+)
+
+// kubernetesKeyPattern matches valid keys of ConfigMaps and Secrets.
+var kubernetesKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// kubernetesRequestTimeout bounds every request to the Kubernetes API server.
+const kubernetesRequestTimeout = 30 * time.Second
+
+// qrcodeProviderKubernetesModel maps the kubernetes block of the provider schema data.
+type qrcodeProviderKubernetesModel struct {
+	ConfigPath    types.String `tfsdk:"config_path"`
+	ConfigContext types.String `tfsdk:"config_context"`
+	InCluster     types.Bool   `tfsdk:"in_cluster"`
+}
+
+// qrcodeKubernetesModel maps the kubernetes block of the qrcode_generate resource schema data.
+type qrcodeKubernetesModel struct {
+	Kind      types.String `tfsdk:"kind"`
+	Namespace types.String `tfsdk:"namespace"`
+	Name      types.String `tfsdk:"name"`
+	Key       types.String `tfsdk:"key"`
+}
+
+// equal reports whether two destinations name the same key of the same object.
+func (m *qrcodeKubernetesModel) equal(other *qrcodeKubernetesModel) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+
+	return m.Kind.Equal(other.Kind) && m.Namespace.Equal(other.Namespace) && m.Name.Equal(other.Name) && m.Key.Equal(other.Key)
+}
+
+// kubernetesClient writes keys of ConfigMaps and Secrets through the Kubernetes API.
+type kubernetesClient struct {
+	server     string
+	httpClient *http.Client
+
+	// namespace is the namespace of the kubeconfig context or service account, used when a
+	// destination does not set one.
+	namespace string
+
+	// Credentials, of which at most one kind is set.
+	token    string
+	username string
+	password string
+	exec     *kubeconfigExec
+
+	// retry controls how apply requests that fail with a transient error are retried.
+	retry retryPolicy
+
+	// execToken caches the token returned by the exec credential plugin.
+	execMutex  sync.Mutex
+	execToken  string
+	execExpiry time.Time
+}
+
+// kubeconfig maps the parts of a kubeconfig file that the provider uses.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string            `yaml:"name"`
+		Cluster kubeconfigCluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string         `yaml:"name"`
+		User kubeconfigUser `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// kubeconfigCluster maps a cluster of a kubeconfig file.
+type kubeconfigCluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	TLSServerName            string `yaml:"tls-server-name"`
+}
+
+// kubeconfigUser maps a user of a kubeconfig file.
+type kubeconfigUser struct {
+	Token                 string          `yaml:"token"`
+	TokenFile             string          `yaml:"tokenFile"`
+	ClientCertificate     string          `yaml:"client-certificate"`
+	ClientCertificateData string          `yaml:"client-certificate-data"`
+	ClientKey             string          `yaml:"client-key"`
+	ClientKeyData         string          `yaml:"client-key-data"`
+	Username              string          `yaml:"username"`
+	Password              string          `yaml:"password"`
+	Exec                  *kubeconfigExec `yaml:"exec"`
+}
+
+// kubeconfigExec maps the exec credential plugin of a kubeconfig user, such as
+// aws eks get-token or gke-gcloud-auth-plugin.
+type kubeconfigExec struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	Env        []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+}
+
+// newKubernetesClient returns a client for the cluster configured in the provider kubernetes
+// block: the service account of the pod running Terraform, or a kubeconfig context. Writes are
+// retried as retry sets.
+func newKubernetesClient(config qrcodeProviderKubernetesModel, retry retryPolicy) (*kubernetesClient, error) {
+	client, err := newConfiguredKubernetesClient(config)
+	if err != nil {
+		return nil, err
+	}
+	client.retry = retry
+
+	return client, nil
+}
+
+// newConfiguredKubernetesClient returns a client for the cluster configured in the provider
+// kubernetes block, as newKubernetesClient does.
+func newConfiguredKubernetesClient(config qrcodeProviderKubernetesModel) (*kubernetesClient, error) {
+	if config.InCluster.ValueBool() {
+		return newInClusterKubernetesClient()
+	}
+
+	configPath := config.ConfigPath.ValueString()
+	if configPath == "" {
+		configPath = defaultKubeconfigPath()
+	}
+
+	data, err := os.ReadFile(hostPath(configPath))
+	if err != nil {
+		return nil, fmt.Errorf("could not read kubeconfig: %w", err)
+	}
+
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("could not parse kubeconfig %s: %w", configPath, err)
+	}
+
+	return kc.client(config.ConfigContext.ValueString(), filepath.Dir(configPath))
+}
+
+// defaultKubeconfigPath returns the first path in the KUBECONFIG environment variable, or
+// ~/.kube/config, the same as kubectl.
+func defaultKubeconfigPath() string {
+	for _, configPath := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if configPath != "" {
+			return configPath
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".kube", "config")
+}
+
+// client returns a client for the named context, or the current context when name is empty.
+// Relative file paths are resolved against dir, the directory of the kubeconfig file.
+func (kc kubeconfig) client(name, dir string) (*kubernetesClient, error) {
+	if name == "" {
+		name = kc.CurrentContext
+	}
+	if name == "" {
+		return nil, fmt.Errorf("kubeconfig has no current context, set config_context")
+	}
+
+	var clusterName, userName, namespace string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == name {
+			clusterName, userName, namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig has no context %q", name)
+	}
+
+	var cluster *kubeconfigCluster
+	for i := range kc.Clusters {
+		if kc.Clusters[i].Name == clusterName {
+			cluster = &kc.Clusters[i].Cluster
+			break
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("kubeconfig has no cluster %q for context %q", clusterName, name)
+	}
+
+	var user kubeconfigUser
+	for _, u := range kc.Users {
+		if u.Name == userName {
+			user = u.User
+			break
+		}
+	}
+
+	resolve := func(filePath string) string {
+		if filePath == "" || filepath.IsAbs(filePath) {
+			return filePath
+		}
+		return filepath.Join(dir, filePath)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cluster.TLSServerName,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+
+	caData, err := fileOrData(resolve(cluster.CertificateAuthority), cluster.CertificateAuthorityData)
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificate authority: %w", err)
+	}
+	if caData != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("certificate authority of cluster %q holds no PEM certificates", clusterName)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certData, err := fileOrData(resolve(user.ClientCertificate), user.ClientCertificateData)
+	if err != nil {
+		return nil, fmt.Errorf("could not read client certificate: %w", err)
+	}
+	keyData, err := fileOrData(resolve(user.ClientKey), user.ClientKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("could not read client key: %w", err)
+	}
+	if certData != nil || keyData != nil {
+		certificate, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate of user %q: %w", userName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	token := user.Token
+	if token == "" && user.TokenFile != "" {
+		data, err := os.ReadFile(hostPath(resolve(user.TokenFile)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	if namespace == "" {
+		namespace = kubernetesDefaultNamespace
+	}
+
+	return &kubernetesClient{
+		server:     strings.TrimSuffix(cluster.Server, "/"),
+		httpClient: newKubernetesHTTPClient(tlsConfig),
+		namespace:  namespace,
+		token:      token,
+		username:   user.Username,
+		password:   user.Password,
+		exec:       user.Exec,
+	}, nil
+}
+
+// newInClusterKubernetesClient returns a client that authenticates as the service account of the
+// pod running Terraform.
+func newInClusterKubernetesClient() (*kubernetesClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set, Terraform is not running in a pod")
+	}
+
+	token, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read service account token: %w", err)
+	}
+	caData, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read service account certificate authority: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caData) {
+		return nil, fmt.Errorf("service account certificate authority holds no PEM certificates")
+	}
+
+	namespace := kubernetesDefaultNamespace
+	if data, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "namespace")); err == nil {
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	return &kubernetesClient{
+		server:     "https://" + net.JoinHostPort(host, port),
+		httpClient: newKubernetesHTTPClient(&tls.Config{RootCAs: pool}),
+		namespace:  namespace,
+		token:      strings.TrimSpace(string(token)),
+	}, nil
+}
+
+// newKubernetesHTTPClient returns an HTTP client that connects with the given TLS configuration.
+func newKubernetesHTTPClient(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: kubernetesRequestTimeout,
+	}
+}
+
+// fileOrData returns the base64-decoded data of a kubeconfig field, or the content of the file it
+// names, or nil when neither is set.
+func fileOrData(filePath, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if filePath != "" {
+		return os.ReadFile(hostPath(filePath))
+	}
+
+	return nil, nil
+}
+
+// namespaceOf returns the namespace of a destination, or the default namespace of the client.
+func (c *kubernetesClient) namespaceOf(destination *qrcodeKubernetesModel) string {
+	if !destination.Namespace.IsNull() && destination.Namespace.ValueString() != "" {
+		return destination.Namespace.ValueString()
+	}
+
+	return c.namespace
+}
+
+// objectPath returns the API path of the ConfigMap or Secret of a destination.
+func (c *kubernetesClient) objectPath(destination *qrcodeKubernetesModel) string {
+	resource := "configmaps"
+	if destination.Kind.ValueString() == kubernetesKindSecret {
+		resource = "secrets"
+	}
+
+	return fmt.Sprintf("/api/v1/namespaces/%s/%s/%s", url.PathEscape(c.namespaceOf(destination)), resource, url.PathEscape(destination.Name.ValueString()))
+}
+
+// fieldManager returns the server-side apply field manager of a destination. Every key has its
+// own manager, so that resources writing keys of the same object do not remove each other's keys.
+func fieldManager(destination *qrcodeKubernetesModel) string {
+	hash := sha256.Sum256([]byte(destination.Key.ValueString()))
+	return "terraform-provider-qrcode-" + hex.EncodeToString(hash[:8])
+}
+
+// Write sets the key of a destination to data with server-side apply, creating the ConfigMap or
+// Secret when it does not exist and leaving its other keys untouched. ConfigMaps hold the data in
+// binaryData.
+func (c *kubernetesClient) Write(ctx context.Context, destination *qrcodeKubernetesModel, data []byte) error {
+	field := "binaryData"
+	if destination.Kind.ValueString() == kubernetesKindSecret {
+		field = "data"
+	}
+
+	object := c.applyObject(destination)
+	object[field] = map[string]string{
+		destination.Key.ValueString(): base64.StdEncoding.EncodeToString(data),
+	}
+
+	return c.apply(ctx, destination, object)
+}
+
+// Read returns the data of the key of a destination, and whether the key exists.
+func (c *kubernetesClient) Read(ctx context.Context, destination *qrcodeKubernetesModel) ([]byte, bool, error) {
+	body, status, err := c.do(ctx, http.MethodGet, c.objectPath(destination), "", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if status == http.StatusNotFound {
+		return nil, false, nil
+	}
+
+	var object struct {
+		Data       map[string]string `json:"data"`
+		BinaryData map[string]string `json:"binaryData"`
+	}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, false, fmt.Errorf("could not parse %s %s: %w", destination.Kind.ValueString(), destination.Name.ValueString(), err)
+	}
+
+	key := destination.Key.ValueString()
+	value, ok := object.BinaryData[key]
+	if destination.Kind.ValueString() == kubernetesKindSecret {
+		value, ok = object.Data[key]
+	} else if !ok {
+		// Text written by other tools to data of a ConfigMap is not base64-encoded
+		if text, ok := object.Data[key]; ok {
+			return []byte(text), true, nil
+		}
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("key %s of %s %s is not base64-encoded: %w", key, destination.Kind.ValueString(), destination.Name.ValueString(), err)
+	}
+
+	return data, true, nil
+}
+
+// Remove removes the key of a destination by applying the object without it. The ConfigMap or
+// Secret itself is left in place, as other keys may have been added to it.
+func (c *kubernetesClient) Remove(ctx context.Context, destination *qrcodeKubernetesModel) error {
+	_, status, err := c.do(ctx, http.MethodGet, c.objectPath(destination), "", nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return nil
+	}
+
+	return c.apply(ctx, destination, c.applyObject(destination))
+}
+
+// applyObject returns the server-side apply configuration of the object of a destination, without
+// data.
+func (c *kubernetesClient) applyObject(destination *qrcodeKubernetesModel) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       destination.Kind.ValueString(),
+		"metadata": map[string]string{
+			"name":      destination.Name.ValueString(),
+			"namespace": c.namespaceOf(destination),
+		},
+	}
+}
+
+// apply sends a server-side apply request, taking over fields owned by other managers. Requests
+// that fail with a transient error, such as a server error response, are retried.
+func (c *kubernetesClient) apply(ctx context.Context, destination *qrcodeKubernetesModel, object map[string]interface{}) error {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{
+		"fieldManager": []string{fieldManager(destination)},
+		"force":        []string{"true"},
+	}
+	var status int
+	_, err = c.retry.do(ctx, func() error {
+		var err error
+		_, status, err = c.do(ctx, http.MethodPatch, c.objectPath(destination)+"?"+query.Encode(), "application/apply-patch+yaml", body)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return fmt.Errorf("namespace %s not found", c.namespaceOf(destination))
+	}
+
+	return nil
+}
+
+// do sends an authenticated request to the API server, and returns the response body and status.
+// Errors other than not found are returned as errors with the message of the API server.
+func (c *kubernetesClient) do(ctx context.Context, method, apiPath, contentType string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.server+apiPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	token, err := c.bearerToken(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		var status struct {
+			Message string `json:"message"`
+		}
+		message := resp.Status
+		if json.Unmarshal(respBody, &status) == nil && status.Message != "" {
+			message = status.Message
+		}
+		return nil, resp.StatusCode, &httpStatusError{status: resp.StatusCode, message: fmt.Sprintf("%s %s: %s", method, apiPath, message)}
+	}
+
+	return respBody, resp.StatusCode, nil
+}
+
+// bearerToken returns the static token of the client, or runs the exec credential plugin for a
+// token, which is cached until it expires.
+func (c *kubernetesClient) bearerToken(ctx context.Context) (string, error) {
+	if c.exec == nil {
+		return c.token, nil
+	}
+
+	c.execMutex.Lock()
+	defer c.execMutex.Unlock()
+
+	if c.execToken != "" && (c.execExpiry.IsZero() || time.Now().Before(c.execExpiry)) {
+		return c.execToken, nil
+	}
+
+	cmd := exec.CommandContext(ctx, c.exec.Command, c.exec.Args...)
+	cmd.Env = os.Environ()
+	for _, env := range c.exec.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf(`KUBERNETES_EXEC_INFO={"apiVersion":%q,"kind":"ExecCredential","spec":{"interactive":false}}`, c.exec.APIVersion))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("exec credential plugin %s failed: %w: %s", c.exec.Command, err, message)
+		}
+		return "", fmt.Errorf("exec credential plugin %s failed: %w", c.exec.Command, err)
+	}
+
+	var credential struct {
+		Status struct {
+			Token               string    `json:"token"`
+			ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(output, &credential); err != nil {
+		return "", fmt.Errorf("exec credential plugin %s returned an invalid credential: %w", c.exec.Command, err)
+	}
+	if credential.Status.Token == "" {
+		return "", fmt.Errorf("exec credential plugin %s returned no token, client certificates from plugins are not supported", c.exec.Command)
+	}
+
+	c.execToken = credential.Status.Token
+	c.execExpiry = credential.Status.ExpirationTimestamp
+
+	return c.execToken, nil
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeKubernetesAPI serves ConfigMaps and Secrets with just enough server-side apply for the
+// provider: every field manager owns the keys it last applied.
+type fakeKubernetesAPI struct {
+	t     *testing.T
+	token string
+
+	mutex   sync.Mutex
+	objects map[string]map[string]map[string]string
+	owners  map[string]map[string][]string
+}
+
+func (f *fakeKubernetesAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"kind":"Status","message":"Unauthorized"}`))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/apps/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	object, ok := f.objects[r.URL.Path]
+	switch r.Method {
+	case http.MethodGet:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	case http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/apply-patch+yaml" || r.URL.Query().Get("force") != "true" {
+			f.t.Errorf("unexpected apply request %s", r.URL)
+		}
+		manager := r.URL.Query().Get("fieldManager")
+
+		body, _ := io.ReadAll(r.Body)
+		var applied map[string]json.RawMessage
+		if err := json.Unmarshal(body, &applied); err != nil {
+			f.t.Errorf("invalid apply body %s", body)
+		}
+
+		if !ok {
+			object = map[string]map[string]string{"data": {}, "binaryData": {}}
+			f.objects[r.URL.Path] = object
+			f.owners[r.URL.Path] = map[string][]string{}
+		}
+		for _, key := range f.owners[r.URL.Path][manager] {
+			delete(object["data"], key)
+			delete(object["binaryData"], key)
+		}
+		f.owners[r.URL.Path][manager] = nil
+		for _, field := range []string{"data", "binaryData"} {
+			var values map[string]string
+			_ = json.Unmarshal(applied[field], &values)
+			for key, value := range values {
+				object[field][key] = value
+				f.owners[r.URL.Path][manager] = append(f.owners[r.URL.Path][manager], key)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// TestKubernetesClient verifies that images are written to, read from and removed from keys of
+// ConfigMaps and Secrets without touching other keys.
+func TestKubernetesClient(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeKubernetesAPI{
+		t:       t,
+		token:   "s3cr3t",
+		objects: map[string]map[string]map[string]string{},
+		owners:  map[string]map[string][]string{},
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte(api.token+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kubeconfigPath := filepath.Join(dir, "config")
+	kubeconfigData := `
+apiVersion: v1
+kind: Config
+current-context: other
+clusters:
+  - name: test
+    cluster:
+      server: ` + server.URL + `
+contexts:
+  - name: other
+    context:
+      cluster: missing
+  - name: test
+    context:
+      cluster: test
+      user: test
+      namespace: apps
+users:
+  - name: test
+    user:
+      tokenFile: token
+`
+	if err := os.WriteFile(kubeconfigPath, []byte(kubeconfigData), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newKubernetesClient(qrcodeProviderKubernetesModel{ConfigPath: types.StringValue(kubeconfigPath)}, retryPolicy{}); err == nil {
+		t.Errorf("expected a context with a missing cluster to fail")
+	}
+
+	client, err := newKubernetesClient(qrcodeProviderKubernetesModel{
+		ConfigPath:    types.StringValue(kubeconfigPath),
+		ConfigContext: types.StringValue("test"),
+	}, retryPolicy{})
+	if err != nil {
+		t.Fatalf("failed to configure client: %s", err)
+	}
+
+	wifi := &qrcodeKubernetesModel{Kind: types.StringValue(kubernetesKindConfigMap), Namespace: types.StringNull(), Name: types.StringValue("codes"), Key: types.StringValue("wifi.png")}
+	site := &qrcodeKubernetesModel{Kind: types.StringValue(kubernetesKindConfigMap), Namespace: types.StringValue("apps"), Name: types.StringValue("codes"), Key: types.StringValue("site.png")}
+	secret := &qrcodeKubernetesModel{Kind: types.StringValue(kubernetesKindSecret), Namespace: types.StringNull(), Name: types.StringValue("codes"), Key: types.StringValue("wifi.png")}
+
+	for destination, data := range map[*qrcodeKubernetesModel][]byte{wifi: []byte("wifi"), site: []byte("site"), secret: []byte("secret")} {
+		if err := client.Write(ctx, destination, data); err != nil {
+			t.Fatalf("failed to write %s: %s", destination.Key.ValueString(), err)
+		}
+	}
+	if configMap := api.objects["/api/v1/namespaces/apps/configmaps/codes"]; len(configMap["binaryData"]) != 2 {
+		t.Fatalf("expected both keys in binaryData, got %v", configMap)
+	}
+
+	data, found, err := client.Read(ctx, secret)
+	if err != nil || !found || !bytes.Equal(data, []byte("secret")) {
+		t.Errorf("expected to read the secret key, got %q, %v: %v", data, found, err)
+	}
+
+	if err := client.Remove(ctx, wifi); err != nil {
+		t.Fatalf("failed to remove key: %s", err)
+	}
+	if _, found, err := client.Read(ctx, wifi); err != nil || found {
+		t.Errorf("expected the removed key to be missing: %v", err)
+	}
+	if data, found, err := client.Read(ctx, site); err != nil || !found || !bytes.Equal(data, []byte("site")) {
+		t.Errorf("expected the other key to be kept, got %q, %v: %v", data, found, err)
+	}
+
+	missing := &qrcodeKubernetesModel{Kind: types.StringValue(kubernetesKindSecret), Namespace: types.StringNull(), Name: types.StringValue("missing"), Key: types.StringValue("wifi.png")}
+	if err := client.Remove(ctx, missing); err != nil {
+		t.Errorf("expected removing from a missing object to succeed: %s", err)
+	}
+	if _, ok := api.objects["/api/v1/namespaces/apps/secrets/missing"]; ok {
+		t.Errorf("expected removing from a missing object not to create it")
+	}
+
+	client.token = "wrong"
+	if _, _, err := client.Read(ctx, site); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("expected the API server message, got %v", err)
+	}
+}
+
+// TestKubernetesClientRetry verifies that apply requests the API server answers with a server
+// error are sent again.
+func TestKubernetesClientRetry(t *testing.T) {
+	ctx := context.Background()
+	api := &flakyHandler{
+		Handler: &fakeKubernetesAPI{
+			t:       t,
+			token:   "s3cr3t",
+			objects: map[string]map[string]map[string]string{},
+			owners:  map[string]map[string][]string{},
+		},
+		failures: 2,
+		status:   http.StatusServiceUnavailable,
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := &kubernetesClient{
+		server:     server.URL,
+		httpClient: server.Client(),
+		namespace:  "apps",
+		token:      "s3cr3t",
+		retry:      retryPolicy{maxAttempts: 3},
+	}
+	destination := &qrcodeKubernetesModel{Kind: types.StringValue(kubernetesKindSecret), Namespace: types.StringNull(), Name: types.StringValue("codes"), Key: types.StringValue("wifi.png")}
+	if err := client.Write(ctx, destination, []byte("wifi")); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	if api.requests != 3 {
+		t.Errorf("expected 3 requests, got %d", api.requests)
+	}
+}
+
+// TestKubernetesClientExecCredential verifies that tokens come from the exec credential plugin,
+// and that a failing plugin reports what it wrote to stderr.
+func TestKubernetesClientExecCredential(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		script        string
+		expectedToken string
+		expectedError string
+	}{
+		"token": {
+			script:        `echo '{"kind":"ExecCredential","status":{"token":"exec-token"}}'`,
+			expectedToken: "exec-token",
+		},
+		"failure": {
+			script:        `echo 'error: the SSO session has expired' >&2; exit 1`,
+			expectedError: "exit status 1: error: the SSO session has expired",
+		},
+		"no token": {
+			script:        `echo '{"kind":"ExecCredential","status":{}}'`,
+			expectedError: "returned no token",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &kubernetesClient{exec: &kubeconfigExec{
+				APIVersion: "client.authentication.k8s.io/v1",
+				Command:    "sh",
+				Args:       []string{"-c", testCase.script},
+			}}
+
+			token, err := client.bearerToken(ctx)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected an error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if token != testCase.expectedToken {
+				t.Errorf("expected token %q, got %q", testCase.expectedToken, token)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Defaults of the provider consul and vault blocks, as used by the consul and vault CLIs.
+const (
+	defaultConsulAddress = "http://127.0.0.1:8500"
+	defaultVaultKVMount  = "secret"
+)
+
+// kvRequestTimeout bounds every request to Consul and Vault.
+const kvRequestTimeout = 30 * time.Second
+
+// qrcodeProviderConsulModel maps the consul block of the provider schema data.
+type qrcodeProviderConsulModel struct {
+	Address    types.String `tfsdk:"address"`
+	Token      types.String `tfsdk:"token"`
+	Datacenter types.String `tfsdk:"datacenter"`
+	CAFile     types.String `tfsdk:"ca_file"`
+}
+
+// qrcodeProviderVaultModel maps the vault block of the provider schema data.
+type qrcodeProviderVaultModel struct {
+	Address   types.String `tfsdk:"address"`
+	Token     types.String `tfsdk:"token"`
+	Namespace types.String `tfsdk:"namespace"`
+	CAFile    types.String `tfsdk:"ca_file"`
+}
+
+// qrcodeConsulKVModel maps the consul_kv block of the qrcode_generate resource schema data.
+type qrcodeConsulKVModel struct {
+	Path types.String `tfsdk:"path"`
+}
+
+// qrcodeVaultKVModel maps the vault_kv block of the qrcode_generate resource schema data.
+type qrcodeVaultKVModel struct {
+	Mount types.String `tfsdk:"mount"`
+	Path  types.String `tfsdk:"path"`
+}
+
+// kvPayload is the value written to Consul KV keys and the data of Vault KV secrets: the
+// base64-encoded image and its SHA-256 checksum, so that readers can verify what they read.
+type kvPayload struct {
+	ContentBase64 string `json:"content_base64"`
+	SHA256        string `json:"sha256"`
+}
+
+// newKVPayload returns the payload of an image.
+func newKVPayload(data []byte) kvPayload {
+	return kvPayload{
+		ContentBase64: base64.StdEncoding.EncodeToString(data),
+		SHA256:        computeSHA256(string(data)),
+	}
+}
+
+// matches reports whether the payload holds an image with the given checksum, checking the
+// content rather than trusting the stored checksum.
+func (p kvPayload) matches(checksum string) bool {
+	data, err := base64.StdEncoding.DecodeString(p.ContentBase64)
+	return err == nil && computeSHA256(string(data)) == checksum
+}
+
+// kvHTTPClient sends authenticated requests to the HTTP API of Consul or Vault.
+type kvHTTPClient struct {
+	address    string
+	headers    http.Header
+	httpClient *http.Client
+
+	// retry controls how requests that fail with a transient error are retried.
+	retry retryPolicy
+}
+
+// newKVHTTPClient returns a client for the API at address, trusting the certificates in caFile
+// in addition to the system ones when it is set.
+func newKVHTTPClient(address, caFile string, headers http.Header, retry retryPolicy) (*kvHTTPClient, error) {
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+
+	tlsConfig := &tls.Config{}
+	if caFile != "" {
+		caData, err := os.ReadFile(hostPath(caFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read certificate authority: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("certificate authority %s holds no PEM certificates", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &kvHTTPClient{
+		address: strings.TrimSuffix(address, "/"),
+		headers: headers,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+			Timeout: kvRequestTimeout,
+		},
+		retry: retry,
+	}, nil
+}
+
+// do sends a request and returns the response body, and false when the API responded not found.
+// Other error responses are returned as errors with the message of the API. Requests that fail
+// with a transient error, such as a server error response, are retried.
+func (c *kvHTTPClient) do(ctx context.Context, method, apiPath string, body []byte) ([]byte, bool, error) {
+	var respBody []byte
+	var found bool
+	_, err := c.retry.do(ctx, func() error {
+		var err error
+		respBody, found, err = c.send(ctx, method, apiPath, body)
+		return err
+	})
+
+	return respBody, found, err
+}
+
+// send sends a request once, as do does.
+func (c *kvHTTPClient) send(ctx context.Context, method, apiPath string, body []byte) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.address+apiPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return respBody, false, nil
+	}
+	if resp.StatusCode >= 300 {
+		// Vault reports errors as a JSON list, Consul as plain text
+		var vaultErrors struct {
+			Errors []string `json:"errors"`
+		}
+		message := strings.TrimSpace(string(respBody))
+		if json.Unmarshal(respBody, &vaultErrors) == nil && len(vaultErrors.Errors) > 0 {
+			message = strings.Join(vaultErrors.Errors, "; ")
+		}
+		if message == "" {
+			message = resp.Status
+		}
+		return nil, false, &httpStatusError{status: resp.StatusCode, message: fmt.Sprintf("%s %s: %s", method, apiPath, message)}
+	}
+
+	return respBody, true, nil
+}
+
+// escapeKVPath escapes every segment of a slash-separated key or secret path.
+func escapeKVPath(kvPath string) string {
+	segments := strings.Split(strings.Trim(kvPath, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// consulClient writes images to Consul KV.
+type consulClient struct {
+	api        *kvHTTPClient
+	datacenter string
+}
+
+// newConsulClient returns a client for the Consul agent configured in the provider consul block,
+// falling back to the CONSUL_HTTP_ADDR, CONSUL_HTTP_TOKEN and CONSUL_CACERT environment variables.
+// Requests are retried as retry sets.
+func newConsulClient(config qrcodeProviderConsulModel, retry retryPolicy) (*consulClient, error) {
+	address := valueOrEnv(config.Address, "CONSUL_HTTP_ADDR", defaultConsulAddress)
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	headers := http.Header{}
+	if token := valueOrEnv(config.Token, "CONSUL_HTTP_TOKEN", ""); token != "" {
+		headers.Set("X-Consul-Token", token)
+	}
+
+	api, err := newKVHTTPClient(address, valueOrEnv(config.CAFile, "CONSUL_CACERT", ""), headers, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulClient{api: api, datacenter: config.Datacenter.ValueString()}, nil
+}
+
+// keyPath returns the API path of a key, in the configured datacenter.
+func (c *consulClient) keyPath(key string, query url.Values) string {
+	if c.datacenter != "" {
+		query.Set("dc", c.datacenter)
+	}
+
+	keyPath := "/v1/kv/" + escapeKVPath(key)
+	if len(query) > 0 {
+		keyPath += "?" + query.Encode()
+	}
+
+	return keyPath
+}
+
+// Write sets the key to the payload of an image.
+func (c *consulClient) Write(ctx context.Context, key string, data []byte) error {
+	body, err := json.Marshal(newKVPayload(data))
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.api.do(ctx, http.MethodPut, c.keyPath(key, url.Values{}), body)
+	return err
+}
+
+// Read returns the payload of the key, and whether the key exists.
+func (c *consulClient) Read(ctx context.Context, key string) (kvPayload, bool, error) {
+	var payload kvPayload
+
+	body, found, err := c.api.do(ctx, http.MethodGet, c.keyPath(key, url.Values{"raw": []string{"true"}}), nil)
+	if err != nil || !found {
+		return payload, false, err
+	}
+
+	// A value that is not a payload was overwritten outside Terraform
+	if json.Unmarshal(body, &payload) != nil {
+		return kvPayload{}, true, nil
+	}
+
+	return payload, true, nil
+}
+
+// Remove deletes the key.
+func (c *consulClient) Remove(ctx context.Context, key string) error {
+	_, _, err := c.api.do(ctx, http.MethodDelete, c.keyPath(key, url.Values{}), nil)
+	return err
+}
+
+// vaultClient writes images to Vault KV version 2 secrets engines.
+type vaultClient struct {
+	api *kvHTTPClient
+}
+
+// newVaultClient returns a client for the Vault server configured in the provider vault block,
+// falling back to the VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE and VAULT_CACERT environment
+// variables. Requests are retried as retry sets.
+func newVaultClient(config qrcodeProviderVaultModel, retry retryPolicy) (*vaultClient, error) {
+	address := valueOrEnv(config.Address, "VAULT_ADDR", "")
+	if address == "" {
+		return nil, fmt.Errorf("address is not set and VAULT_ADDR is empty")
+	}
+
+	headers := http.Header{}
+	if token := valueOrEnv(config.Token, "VAULT_TOKEN", ""); token != "" {
+		headers.Set("X-Vault-Token", token)
+	}
+	if namespace := valueOrEnv(config.Namespace, "VAULT_NAMESPACE", ""); namespace != "" {
+		headers.Set("X-Vault-Namespace", namespace)
+	}
+
+	api, err := newKVHTTPClient(address, valueOrEnv(config.CAFile, "VAULT_CACERT", ""), headers, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vaultClient{api: api}, nil
+}
+
+// dataPath returns the API path of the data of a secret.
+func (c *vaultClient) dataPath(secret *qrcodeVaultKVModel) string {
+	mount := defaultVaultKVMount
+	if !secret.Mount.IsNull() {
+		mount = secret.Mount.ValueString()
+	}
+
+	return "/v1/" + escapeKVPath(mount) + "/data/" + escapeKVPath(secret.Path.ValueString())
+}
+
+// Write writes the payload of an image as a new version of the secret.
+func (c *vaultClient) Write(ctx context.Context, secret *qrcodeVaultKVModel, data []byte) error {
+	body, err := json.Marshal(map[string]kvPayload{"data": newKVPayload(data)})
+	if err != nil {
+		return err
+	}
+
+	_, found, err := c.api.do(ctx, http.MethodPut, c.dataPath(secret), body)
+	if err == nil && !found {
+		err = fmt.Errorf("no KV version 2 secrets engine at %s", c.dataPath(secret))
+	}
+
+	return err
+}
+
+// Read returns the payload of the latest version of the secret, and whether it exists and is not
+// deleted.
+func (c *vaultClient) Read(ctx context.Context, secret *qrcodeVaultKVModel) (kvPayload, bool, error) {
+	body, found, err := c.api.do(ctx, http.MethodGet, c.dataPath(secret), nil)
+	if err != nil || !found {
+		return kvPayload{}, false, err
+	}
+
+	var response struct {
+		Data struct {
+			Data kvPayload `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return kvPayload{}, false, fmt.Errorf("could not parse secret %s: %w", secret.Path.ValueString(), err)
+	}
+
+	return response.Data.Data, true, nil
+}
+
+// Remove deletes the latest version of the secret, which can still be undeleted in Vault.
+func (c *vaultClient) Remove(ctx context.Context, secret *qrcodeVaultKVModel) error {
+	_, _, err := c.api.do(ctx, http.MethodDelete, c.dataPath(secret), nil)
+	return err
+}
+
+// valueOrEnv returns the configured value, or the value of the environment variable, or
+// fallback.
+func valueOrEnv(value types.String, env, fallback string) string {
+	if !value.IsNull() && value.ValueString() != "" {
+		return value.ValueString()
+	}
+	if envValue := os.Getenv(env); envValue != "" {
+		return envValue
+	}
+
+	return fallback
+}
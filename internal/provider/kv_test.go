@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeKVAPI serves keys by request path, answering with the body written to the path last.
+type fakeKVAPI struct {
+	headers map[string]string
+
+	mutex  sync.Mutex
+	values map[string][]byte
+}
+
+func (f *fakeKVAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for name, value := range f.headers {
+		if r.Header.Get(name) != value {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := f.values[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(value)
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.values[r.URL.Path] = body
+	case http.MethodDelete:
+		delete(f.values, r.URL.Path)
+	}
+}
+
+// TestConsulClient verifies that images are written to, read from and deleted from Consul KV.
+func TestConsulClient(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeKVAPI{headers: map[string]string{"X-Consul-Token": "s3cr3t"}, values: map[string][]byte{}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := newConsulClient(qrcodeProviderConsulModel{
+		Address:    types.StringValue(strings.TrimPrefix(server.URL, "http://")),
+		Token:      types.StringValue("s3cr3t"),
+		Datacenter: types.StringNull(),
+		CAFile:     types.StringNull(),
+	}, retryPolicy{})
+	if err != nil {
+		t.Fatalf("failed to configure client: %s", err)
+	}
+
+	if err := client.Write(ctx, "provisioning/wifi code", []byte("image")); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	if _, ok := api.values["/v1/kv/provisioning/wifi code"]; !ok {
+		t.Fatalf("expected the key to be written, got %v", api.values)
+	}
+
+	payload, found, err := client.Read(ctx, "provisioning/wifi code")
+	if err != nil || !found || !payload.matches(computeSHA256("image")) || payload.SHA256 != computeSHA256("image") {
+		t.Errorf("expected to read the image, got %v, %v: %v", payload, found, err)
+	}
+
+	api.values["/v1/kv/provisioning/wifi code"] = []byte("overwritten")
+	if payload, found, err := client.Read(ctx, "provisioning/wifi code"); err != nil || !found || payload.matches(computeSHA256("image")) {
+		t.Errorf("expected an overwritten key not to match, got %v, %v: %v", payload, found, err)
+	}
+
+	if err := client.Remove(ctx, "provisioning/wifi code"); err != nil {
+		t.Fatalf("failed to remove: %s", err)
+	}
+	if _, found, err := client.Read(ctx, "provisioning/wifi code"); err != nil || found {
+		t.Errorf("expected the key to be deleted: %v", err)
+	}
+}
+
+// TestKVHTTPClientRetry verifies that requests answered with a server error or too many requests
+// are sent again, and those refused by the API are not.
+func TestKVHTTPClientRetry(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		status           int
+		expectedRequests int
+		expectError      bool
+	}{
+		"server error":      {status: http.StatusBadGateway, expectedRequests: 2},
+		"too many requests": {status: http.StatusTooManyRequests, expectedRequests: 2},
+		"forbidden":         {status: http.StatusForbidden, expectedRequests: 1, expectError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api := &flakyHandler{
+				Handler:  &fakeKVAPI{values: map[string][]byte{}},
+				failures: 1,
+				status:   testCase.status,
+			}
+			server := httptest.NewServer(api)
+			defer server.Close()
+
+			client, err := newConsulClient(qrcodeProviderConsulModel{
+				Address:    types.StringValue(server.URL),
+				Token:      types.StringNull(),
+				Datacenter: types.StringNull(),
+				CAFile:     types.StringNull(),
+			}, retryPolicy{maxAttempts: 3})
+			if err != nil {
+				t.Fatalf("failed to configure client: %s", err)
+			}
+
+			err = client.Write(ctx, "wifi", []byte("image"))
+			if (err != nil) != testCase.expectError {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if api.requests != testCase.expectedRequests {
+				t.Errorf("expected %d requests, got %d", testCase.expectedRequests, api.requests)
+			}
+		})
+	}
+}
+
+// TestVaultClient verifies that images are written to, read from and deleted from Vault KV
+// version 2 secrets.
+func TestVaultClient(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeKVAPI{headers: map[string]string{"X-Vault-Token": "s3cr3t", "X-Vault-Namespace": "ops"}, values: map[string][]byte{}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	config := qrcodeProviderVaultModel{
+		Address:   types.StringValue(server.URL),
+		Token:     types.StringValue("s3cr3t"),
+		Namespace: types.StringValue("ops"),
+		CAFile:    types.StringNull(),
+	}
+	client, err := newVaultClient(config, retryPolicy{})
+	if err != nil {
+		t.Fatalf("failed to configure client: %s", err)
+	}
+
+	secret := &qrcodeVaultKVModel{Mount: types.StringNull(), Path: types.StringValue("provisioning/wifi")}
+	if err := client.Write(ctx, secret, []byte("image")); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+
+	// Vault wraps the written data in the data of the response
+	var written struct {
+		Data kvPayload `json:"data"`
+	}
+	if err := json.Unmarshal(api.values["/v1/secret/data/provisioning/wifi"], &written); err != nil || !written.Data.matches(computeSHA256("image")) {
+		t.Fatalf("expected the secret to be written, got %v: %v", api.values, err)
+	}
+	api.values["/v1/secret/data/provisioning/wifi"] = []byte(`{"data":` + string(api.values["/v1/secret/data/provisioning/wifi"]) + `}`)
+
+	payload, found, err := client.Read(ctx, secret)
+	if err != nil || !found || !payload.matches(computeSHA256("image")) {
+		t.Errorf("expected to read the image, got %v, %v: %v", payload, found, err)
+	}
+
+	if err := client.Remove(ctx, secret); err != nil {
+		t.Fatalf("failed to remove: %s", err)
+	}
+	if _, found, err := client.Read(ctx, secret); err != nil || found {
+		t.Errorf("expected the secret to be deleted: %v", err)
+	}
+
+	config.Token = types.StringValue("wrong")
+	client, err = newVaultClient(config, retryPolicy{})
+	if err != nil {
+		t.Fatalf("failed to configure client: %s", err)
+	}
+	if err := client.Write(ctx, secret, []byte("image")); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected the Vault error message, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ list.ListResource              = &qrcodeListResource{}
+	_ list.ListResourceWithConfigure = &qrcodeListResource{}
+)
+
+// qrcodeListResource is the list resource implementation.
+type qrcodeListResource struct {
+	outputDirectory string
+	fs              afero.Fs
+}
+
+// NewQRCodeListResource creates a new QR code list resource instance.
+func NewQRCodeListResource() list.ListResource {
+	return &qrcodeListResource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the list resource type name, which matches the managed resource.
+func (r *qrcodeListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_generate"
+}
+
+// Configure receives the provider-level output directory and filesystem.
+func (r *qrcodeListResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected List Resource Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.outputDirectory = data.OutputDirectory
+	r.fs = data.Filesystem
+}
+
+// ListResourceConfigSchema defines the list resource configuration schema.
+func (r *qrcodeListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_generate` list resource enumerates PNG QR code files under a directory together with their SHA-256 checksums, so pre-existing codes can be bulk imported as `qrcode_generate` resources.",
+		Attributes: map[string]schema.Attribute{
+			"directory": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory to search for QR code files. Defaults to the provider `output_directory`.",
+			},
+			"recursive": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to also search subdirectories.",
+			},
+		},
+	}
+}
+
+// List streams one result per PNG file found under the directory.
+func (r *qrcodeListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config struct {
+		Directory types.String `tfsdk:"directory"`
+		Recursive types.Bool   `tfsdk:"recursive"`
+	}
+
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	dir := r.outputDirectory
+	if !config.Directory.IsNull() {
+		dir = config.Directory.ValueString()
+	}
+
+	if dir == "" {
+		diags.AddError(
+			"Missing Directory",
+			"Set the list resource `directory` argument or the provider `output_directory` argument.",
+		)
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	recursive := config.Recursive.ValueBool()
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		var count int64
+
+		err := afero.Walk(r.fs, dir, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				if filePath != dir && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !strings.EqualFold(filepath.Ext(filePath), ".png") {
+				return nil
+			}
+
+			if req.Limit > 0 && count >= req.Limit {
+				return filepath.SkipAll
+			}
+			count++
+
+			if !push(r.listResult(ctx, req, filePath)) {
+				return filepath.SkipAll
+			}
+
+			return nil
+		})
+
+		// Unlike filepath.WalkDir, afero.Walk returns filepath.SkipAll to the caller
+		if err != nil && !errors.Is(err, filepath.SkipAll) {
+			var diags diag.Diagnostics
+			diags.AddError("Failed to List QR Codes", err.Error())
+			push(list.ListResult{Diagnostics: diags})
+		}
+	}
+}
+
+// listResult builds the list result for a single QR code file.
+func (r *qrcodeListResource) listResult(ctx context.Context, req list.ListRequest, filePath string) list.ListResult {
+	result := req.NewListResult(ctx)
+	result.DisplayName = filePath
+
+	tflog.Debug(ctx, "Listing QR code file", map[string]interface{}{
+		"file": filePath,
+	})
+
+	result.Diagnostics.Append(result.Identity.Set(ctx, &qrcodeResourceIdentityModel{
+		File: types.StringValue(filePath),
+	})...)
+
+	if !req.IncludeResource {
+		return result
+	}
+
+	pngData, err := afero.ReadFile(r.fs, hostPath(filePath))
+	if err != nil {
+		result.Diagnostics.AddError("Failed to Read QR Code", err.Error())
+		return result
+	}
+
+	hash := sha256.Sum256(pngData)
+
+	// The resource starts as a null object of the resource schema, so only the attributes known
+	// from the file are set and the others stay null
+	for name, value := range map[string]types.String{
+		"file":           types.StringValue(filePath),
+		"filename":       types.StringValue(filePath),
+		"sha256":         types.StringValue(hex.EncodeToString(hash[:])),
+		"content_base64": types.StringValue(base64.StdEncoding.EncodeToString(pngData)),
+	} {
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root(name), value)...)
+	}
+
+	return result
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestQRCodeListResource verifies that the qrcode_generate list resource enumerates PNG files.
+func TestQRCodeListResource(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	dir := filepath.Join(t.TempDir(), "codes")
+
+	for _, name := range []string{"a.png", "b.PNG", "notes.txt", filepath.Join("nested", "c.png")} {
+		filePath := filepath.Join(dir, name)
+		if err := fs.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("failed to create directory: %s", err)
+		}
+		if err := afero.WriteFile(fs, filePath, []byte(name), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	r := &qrcodeResource{fs: fs}
+	resourceSchemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	l := &qrcodeListResource{outputDirectory: dir, fs: fs}
+	listSchemaResp := &list.ListResourceSchemaResponse{}
+	l.ListResourceConfigSchema(ctx, list.ListResourceSchemaRequest{}, listSchemaResp)
+
+	testCases := map[string]struct {
+		recursive bool
+		expected  int
+	}{
+		"top-level": {recursive: false, expected: 2},
+		"recursive": {recursive: true, expected: 3},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := list.ListRequest{
+				Config: tfsdk.Config{
+					Schema: listSchemaResp.Schema,
+					Raw: tftypes.NewValue(listSchemaResp.Schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+						"directory": tftypes.NewValue(tftypes.String, nil),
+						"recursive": tftypes.NewValue(tftypes.Bool, testCase.recursive),
+					}),
+				},
+				IncludeResource:        true,
+				ResourceSchema:         resourceSchemaResp.Schema,
+				ResourceIdentitySchema: newIdentity().Schema,
+			}
+
+			stream := &list.ListResultsStream{}
+			l.List(ctx, req, stream)
+
+			var count int
+			for result := range stream.Results {
+				if result.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", result.Diagnostics)
+				}
+
+				var model qrcodeResourceModel
+				if diags := result.Resource.Get(ctx, &model); diags.HasError() {
+					t.Fatalf("failed to read resource: %v", diags)
+				}
+
+				expectedChecksum, err := fileSHA256(fs, model.File.ValueString())
+				if err != nil {
+					t.Fatalf("failed to calculate SHA-256 checksum: %s", err)
+				}
+				if model.SHA256 != types.StringValue(expectedChecksum) {
+					t.Errorf("expected sha256 %s, got %s", expectedChecksum, model.SHA256)
+				}
+				if model.Filename != model.File || model.ContentBase64.IsNull() || !model.Text.IsNull() {
+					t.Errorf("expected filename, content_base64 and no text, got %s, %.20s and %s", model.Filename, model.ContentBase64, model.Text)
+				}
+
+				count++
+			}
+
+			if count != testCase.expected {
+				t.Errorf("expected %d results, got %d", testCase.expected, count)
+			}
+		})
+	}
+}
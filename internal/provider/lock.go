@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/spf13/afero"
+)
+
+// lockFileName is the file in each output directory that writers lock, so that resources and
+// Terraform processes writing to the same directory at the same time do not corrupt its files.
+const lockFileName = ".qrcode.lock"
+
+// Default of the lock_timeout provider attribute.
+const defaultLockTimeout = "30s"
+
+// lockRetryDelay is how often a held lock is tried again while waiting for it.
+const lockRetryDelay = 50 * time.Millisecond
+
+// errLockTimeout is returned when a directory lock is not released within the lock timeout. It
+// is not retried, as the timeout already covers waiting for the holder.
+var errLockTimeout = errors.New("timed out waiting for lock")
+
+// lockDirectory takes the advisory lock on dir, waiting up to timeout for other writers to release
+// it, and returns the function that releases it. Only the local filesystem is shared with other
+// processes, so other filesystems are not locked.
+func lockDirectory(ctx context.Context, fs afero.Fs, dir string, timeout time.Duration) (func(), error) {
+	if _, ok := fs.(*afero.OsFs); !ok {
+		return func() {}, nil
+	}
+
+	lock := flock.New(hostPath(filepath.Join(dir, lockFileName)))
+
+	// A zero timeout tries the lock once without waiting
+	locked, err := lock.TryLock()
+	if !locked && err == nil && timeout > 0 {
+		lockCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		locked, err = lock.TryLockContext(lockCtx, lockRetryDelay)
+	}
+	if !locked {
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			err = errLockTimeout
+		}
+		return nil, fmt.Errorf("could not lock %s after %s: %w", lock.Path(), timeout, err)
+	}
+
+	return func() {
+		_ = lock.Unlock()
+	}, nil
+}
+
+// removeEmptyDirectory removes dir when nothing but its lock file is left in it. The lock is taken
+// first, without waiting, so that the lock file is never removed while another writer holds it: a
+// writer that locked the path next would lock a new file while the holder is still writing. A
+// directory that is locked, or holds files of other resources, is left in place.
+func removeEmptyDirectory(ctx context.Context, fs afero.Fs, dir string) {
+	if isSymlink(fs, dir) {
+		return
+	}
+	if exists, err := afero.DirExists(fs, hostPath(dir)); err != nil || !exists {
+		return
+	}
+
+	unlock, err := lockDirectory(ctx, fs, dir, 0)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	entries, err := afero.ReadDir(fs, hostPath(dir))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.Name() != lockFileName {
+			return
+		}
+	}
+
+	_ = fs.Remove(hostPath(filepath.Join(dir, lockFileName)))
+	_ = fs.Remove(hostPath(dir))
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// TestLockDirectory verifies that a directory lock held by one writer makes another wait until
+// the lock timeout, and is available again once released.
+func TestLockDirectory(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewOsFs()
+	dir := t.TempDir()
+
+	unlock, err := lockDirectory(ctx, fs, dir, time.Second)
+	if err != nil {
+		t.Fatalf("failed to lock: %s", err)
+	}
+
+	if _, err := lockDirectory(ctx, fs, dir, 100*time.Millisecond); !errors.Is(err, errLockTimeout) {
+		t.Errorf("expected a lock timeout, got %v", err)
+	}
+	if _, err := lockDirectory(ctx, fs, dir, 0); !errors.Is(err, errLockTimeout) {
+		t.Errorf("expected a lock timeout without waiting, got %v", err)
+	}
+
+	unlock()
+
+	unlock, err = lockDirectory(ctx, fs, dir, 0)
+	if err != nil {
+		t.Fatalf("failed to lock after release: %s", err)
+	}
+	unlock()
+
+	// The timeout is permanent, so saving does not retry it
+	if isTransientError(errLockTimeout) {
+		t.Errorf("expected lock timeouts not to be retried")
+	}
+}
+
+// TestLockDirectoryMemoryFilesystem verifies that in-memory filesystems are not locked.
+func TestLockDirectoryMemoryFilesystem(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	unlock, err := lockDirectory(context.Background(), fs, "/out", time.Second)
+	if err != nil {
+		t.Fatalf("failed to lock: %s", err)
+	}
+	unlock()
+
+	if exists, _ := afero.Exists(fs, filepath.Join("/out", lockFileName)); exists {
+		t.Errorf("expected no lock file in memory")
+	}
+}
+
+// TestRemoveEmptyDirectory verifies that a directory is only removed with its lock file when
+// nothing else is left in it and no other writer holds the lock.
+func TestRemoveEmptyDirectory(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewOsFs()
+
+	testCases := map[string]struct {
+		otherFile    bool
+		locked       bool
+		expectRemove bool
+	}{
+		"empty":      {expectRemove: true},
+		"other file": {otherFile: true},
+		"locked":     {locked: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "out")
+			if err := fs.MkdirAll(dir, 0o755); err != nil {
+				t.Fatalf("failed to create the directory: %s", err)
+			}
+			if testCase.otherFile {
+				if err := afero.WriteFile(fs, filepath.Join(dir, "qr.png"), []byte("other resource"), 0o644); err != nil {
+					t.Fatalf("failed to write the file: %s", err)
+				}
+			}
+			unlock, err := lockDirectory(ctx, fs, dir, 0)
+			if err != nil {
+				t.Fatalf("failed to lock: %s", err)
+			}
+			if !testCase.locked {
+				unlock()
+			}
+
+			removeEmptyDirectory(ctx, fs, dir)
+			if testCase.locked {
+				unlock()
+			}
+
+			if exists, _ := afero.DirExists(fs, dir); exists == testCase.expectRemove {
+				t.Errorf("expected the directory to be removed %t, got exists %t", testCase.expectRemove, exists)
+			}
+			if exists, _ := afero.Exists(fs, filepath.Join(dir, lockFileName)); !testCase.expectRemove && !exists {
+				t.Errorf("expected the lock file to be kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"aead.dev/minisign"
+)
+
+// Names of the manifest files that the qrcode_directory resource writes next to its images.
+const (
+	manifestFileName          = "manifest.json"
+	manifestSignatureFileName = manifestFileName + ".minisig"
+)
+
+// manifestFile is a file listed in a manifest.
+type manifestFile struct {
+	Path    string `json:"path"`
+	SHA256  string `json:"sha256"`
+	Size    int    `json:"size"`
+	Version int    `json:"version"`
+}
+
+// qrcodeManifest lists generated files and their checksums, so that consumers can verify that
+// they were not modified after apply.
+type qrcodeManifest struct {
+	Files []manifestFile `json:"files"`
+}
+
+// buildManifest returns the JSON manifest of the given files, with paths relative to the
+// manifest. Files are sorted by path, so that the manifest only changes with its content.
+func buildManifest(files []manifestFile) ([]byte, error) {
+	manifest := qrcodeManifest{
+		Files: append([]manifestFile{}, files...),
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// parseSigningKey parses a minisign secret key, as written by minisign -G, decrypting it with
+// password when it is encrypted.
+func parseSigningKey(key, password string) (minisign.PrivateKey, error) {
+	if minisign.IsEncrypted([]byte(key)) {
+		privateKey, err := minisign.DecryptKey(password, []byte(key))
+		if err != nil {
+			return privateKey, fmt.Errorf("could not decrypt minisign secret key: %w", err)
+		}
+		return privateKey, nil
+	}
+
+	var privateKey minisign.PrivateKey
+	if err := privateKey.UnmarshalText([]byte(key)); err != nil {
+		return privateKey, fmt.Errorf("invalid minisign secret key: %w", err)
+	}
+
+	return privateKey, nil
+}
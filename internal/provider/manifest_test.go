@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"aead.dev/minisign"
+)
+
+// TestBuildManifest verifies that manifests list files sorted by path.
+func TestBuildManifest(t *testing.T) {
+	data, err := buildManifest([]manifestFile{
+		{Path: "b.png", SHA256: "bb", Size: 2, Version: 3},
+		{Path: "a.png", SHA256: "aa", Size: 1, Version: 2},
+	})
+	if err != nil {
+		t.Fatalf("failed to build manifest: %s", err)
+	}
+
+	var manifest qrcodeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("invalid manifest: %s", err)
+	}
+
+	expected := []manifestFile{{Path: "a.png", SHA256: "aa", Size: 1, Version: 2}, {Path: "b.png", SHA256: "bb", Size: 2, Version: 3}}
+	if len(manifest.Files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(manifest.Files))
+	}
+	for i, file := range expected {
+		if manifest.Files[i] != file {
+			t.Errorf("file %d: expected %v, got %v", i, file, manifest.Files[i])
+		}
+	}
+}
+
+// TestParseSigningKey verifies that a parsed minisign secret key signs verifiable manifests.
+func TestParseSigningKey(t *testing.T) {
+	publicKey, privateKey, err := minisign.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	keyText, err := privateKey.MarshalText()
+	if err != nil {
+		t.Fatalf("failed to marshal key: %s", err)
+	}
+
+	signingKey, err := parseSigningKey(string(keyText)+"\n", "")
+	if err != nil {
+		t.Fatalf("failed to parse key: %s", err)
+	}
+
+	message := []byte(`{"files":[]}`)
+	if !minisign.Verify(publicKey, message, minisign.Sign(signingKey, message)) {
+		t.Errorf("signature does not verify with the public key")
+	}
+
+	if _, err := parseSigningKey("not a key", ""); err == nil {
+		t.Errorf("expected an error for an invalid key")
+	}
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// generationMetrics collects what the resources of a provider process generated. Terraform starts
+// the provider once per command, so the totals cover a single apply.
+type generationMetrics struct {
+	// file is the path the totals are written to after every generation, or empty.
+	file string
+
+	// diagnostics reports the totals as a warning after every generation.
+	diagnostics bool
+
+	mutex          sync.Mutex
+	startedAt      time.Time
+	codes          int
+	files          int
+	bytesWritten   int64
+	generationTime time.Duration
+}
+
+// generationMetricsReport is the JSON document written to the metrics file.
+type generationMetricsReport struct {
+	StartedAt        string `json:"started_at"`
+	CodesGenerated   int    `json:"codes_generated"`
+	FilesWritten     int    `json:"files_written"`
+	BytesWritten     int64  `json:"bytes_written"`
+	GenerationTimeMS int64  `json:"generation_time_ms"`
+}
+
+// newGenerationMetrics returns metrics that are written to file, when set, and reported as
+// warnings when diagnostics is true.
+func newGenerationMetrics(file string, diagnostics bool) *generationMetrics {
+	return &generationMetrics{
+		file:        file,
+		diagnostics: diagnostics,
+		startedAt:   time.Now(),
+	}
+}
+
+// addWrite counts a file written with size bytes. Nil metrics count nothing.
+func (m *generationMetrics) addWrite(size int) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.files++
+	m.bytesWritten += int64(size)
+}
+
+// record counts codes generated for target in elapsed, including writing them, and reports the
+// totals so far. Nil metrics count nothing.
+func (m *generationMetrics) record(ctx context.Context, target string, codes int, elapsed time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m == nil {
+		return diags
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.codes += codes
+	m.generationTime += elapsed
+	report := generationMetricsReport{
+		StartedAt:        m.startedAt.UTC().Format(time.RFC3339),
+		CodesGenerated:   m.codes,
+		FilesWritten:     m.files,
+		BytesWritten:     m.bytesWritten,
+		GenerationTimeMS: m.generationTime.Milliseconds(),
+	}
+
+	tflog.Debug(ctx, "Recorded generation metrics", map[string]interface{}{
+		"target":             target,
+		"codes":              codes,
+		"elapsed_ms":         elapsed.Milliseconds(),
+		"codes_generated":    report.CodesGenerated,
+		"bytes_written":      report.BytesWritten,
+		"generation_time_ms": report.GenerationTimeMS,
+	})
+
+	if m.diagnostics {
+		diags.AddWarning(
+			"QR Code Generation Metrics",
+			fmt.Sprintf(
+				"%s: %d QR codes generated in %s.\nThis apply so far: %d QR codes generated, %d files of %d bytes written, %s spent.",
+				target, codes, elapsed.Round(time.Millisecond), report.CodesGenerated, report.FilesWritten, report.BytesWritten, m.generationTime.Round(time.Millisecond),
+			),
+		)
+	}
+
+	if m.file != "" {
+		if err := writeGenerationMetrics(m.file, report); err != nil {
+			// Metrics are informational, so failing to write them does not fail the apply
+			diags.AddWarning("Failed to Write Generation Metrics", err.Error())
+		}
+	}
+
+	return diags
+}
+
+// writeGenerationMetrics writes report to file on the local filesystem, creating any missing
+// parent directories.
+func writeGenerationMetrics(file string, report generationMetricsReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(hostPath(filepath.Dir(file)), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(hostPath(file), append(data, '\n'), 0644)
+}
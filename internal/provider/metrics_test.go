@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerationMetrics verifies that generation metrics are totaled across resources, reported as
+// warnings and written to the metrics file.
+func TestGenerationMetrics(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "reports", "metrics.json")
+	metrics := newGenerationMetrics(file, true)
+
+	metrics.addWrite(100)
+	diags := metrics.record(ctx, "/out/a.png", 1, 20*time.Millisecond)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+
+	metrics.addWrite(200)
+	metrics.addWrite(300)
+	diags = metrics.record(ctx, "/out/dir", 2, 30*time.Millisecond)
+	if details := diagnosticDetails(diags); !strings.Contains(details, "/out/dir: 2 QR codes generated in 30ms") || !strings.Contains(details, "3 QR codes generated, 3 files of 600 bytes written, 50ms spent") {
+		t.Errorf("expected the resource and the totals to be reported, got %q", details)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read metrics file: %s", err)
+	}
+	var report generationMetricsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse metrics file: %s", err)
+	}
+	if report.CodesGenerated != 3 || report.FilesWritten != 3 || report.BytesWritten != 600 || report.GenerationTimeMS != 50 || report.StartedAt == "" {
+		t.Errorf("unexpected metrics %+v", report)
+	}
+
+	quiet := newGenerationMetrics("", false)
+	if diags := quiet.record(ctx, "/out/a.png", 1, time.Millisecond); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	var disabled *generationMetrics
+	disabled.addWrite(100)
+	if diags := disabled.record(ctx, "/out/a.png", 1, time.Millisecond); len(diags) != 0 {
+		t.Errorf("expected nil metrics to report nothing, got %v", diags)
+	}
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Defaults for the qrcode_directory montage block.
+const (
+	defaultMontageColumns = 4
+	defaultMontagePadding = 16
+)
+
+// montageCell is an image in a montage and its caption.
+type montageCell struct {
+	Image   image.Image
+	Caption string
+}
+
+// renderMontage lays out the cells in rows of the given number of columns on white, with padding
+// pixels around and between them. Cells are size pixels square, and with captions each image has
+// its caption centered below it, clipped to the cell width.
+func renderMontage(cells []montageCell, size, columns, padding int, captions bool) *image.RGBA {
+	columns = max(1, min(columns, len(cells)))
+	rows := (len(cells) + columns - 1) / columns
+
+	scale := max(1, size/defaultSize)
+	cellHeight := size
+	if captions {
+		cellHeight += padding/2 + renderLabel("", scale).Bounds().Dy()
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, padding+columns*(size+padding), padding+rows*(cellHeight+padding)))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for i, cell := range cells {
+		origin := image.Pt(padding+(i%columns)*(size+padding), padding+(i/columns)*(cellHeight+padding))
+		bounds := cell.Image.Bounds()
+		draw.Draw(img, image.Rect(0, 0, size, size).Add(origin), cell.Image, bounds.Min, draw.Src)
+
+		if !captions {
+			continue
+		}
+
+		label := renderLabel(cell.Caption, scale)
+		labelOrigin := origin.Add(image.Pt(max(0, (size-label.Bounds().Dx())/2), size+padding/2))
+		clip := image.Rect(origin.X, labelOrigin.Y, origin.X+size, origin.Y+cellHeight)
+		draw.Draw(img, label.Bounds().Add(labelOrigin).Intersect(clip), label, image.Point{}, draw.Src)
+	}
+
+	return img
+}
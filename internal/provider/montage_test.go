@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+// TestRenderMontage verifies the grid layout of montages, that captions add a row below each image
+// and that the images can still be read.
+func TestRenderMontage(t *testing.T) {
+	var cells []montageCell
+	for _, text := range []string{"one", "two", "three"} {
+		data := testRenderPNG(t, text)
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+		cells = append(cells, montageCell{Image: img, Caption: text})
+	}
+
+	montage := renderMontage(cells, defaultSize, 2, 10, false)
+	if expected := image.Rect(0, 0, 10+2*(defaultSize+10), 10+2*(defaultSize+10)); montage.Bounds() != expected {
+		t.Fatalf("expected bounds %v, got %v", expected, montage.Bounds())
+	}
+	if actual := montage.RGBAAt(5, 5); actual != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("expected white padding, got %v", actual)
+	}
+
+	// The third image starts the second row
+	third := image.NewRGBA(image.Rect(0, 0, defaultSize, defaultSize))
+	draw.Draw(third, third.Bounds(), montage, image.Pt(10, 20+defaultSize), draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, third); err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if text, err := decodeQRCodeImage(buf.Bytes()); err != nil || text != "three" {
+		t.Errorf("expected the third QR code to decode, got %q: %v", text, err)
+	}
+
+	captioned := renderMontage(cells, defaultSize, 3, 10, true)
+	if captioned.Bounds().Dx() != 10+3*(defaultSize+10) || captioned.Bounds().Dy() <= 20+defaultSize {
+		t.Errorf("expected a single row with room for captions, got %v", captioned.Bounds())
+	}
+
+	dark := false
+	for y := 10 + defaultSize; y < captioned.Bounds().Dy()-10; y++ {
+		for x := 10; x < 10+defaultSize; x++ {
+			if isDark(captioned.At(x, y)) {
+				dark = true
+			}
+		}
+	}
+	if !dark {
+		t.Errorf("expected a caption below the first QR code")
+	}
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// outputAttributes are the attributes of the qrcode_generate resource computed from the text and
+// image, as planned unknown by markOutputsUnknown.
+var outputAttributes = map[string]bool{
+	"sha256":                true,
+	"sizes_sha256":          true,
+	"filename":              true,
+	"content_base64":        true,
+	"ascii":                 true,
+	"ascii_sha256":          true,
+	"encrypted_sha256":      true,
+	"qr_version":            true,
+	"error_correction_used": true,
+	"content_sha256":        true,
+	"module_count":          true,
+	"encoding_mode_used":    true,
+	"capacity_used_percent": true,
+	"jws":                   true,
+	"print_job_id":          true,
+}
+
+// fileOnlyChange reports whether plan differs from state in the file attribute alone, apart from
+// the outputs.
+func fileOnlyChange(state, plan tftypes.Value) bool {
+	if state.IsNull() || !state.IsKnown() || plan.IsNull() || !plan.IsKnown() {
+		return false
+	}
+
+	var stateAttributes, planAttributes map[string]tftypes.Value
+	if state.As(&stateAttributes) != nil || plan.As(&planAttributes) != nil {
+		return false
+	}
+	if planAttributes["file"].Equal(stateAttributes["file"]) {
+		return false
+	}
+
+	for name, value := range planAttributes {
+		if name == "file" || outputAttributes[name] {
+			continue
+		}
+		if !value.Equal(stateAttributes[name]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// keepOutputs plans the attributes computed from the text and image as they are in state, for a
+// plan that keeps the image.
+func (m *qrcodeResourceModel) keepOutputs(state qrcodeResourceModel) {
+	m.SHA256 = state.SHA256
+	m.SizesSHA256 = state.SizesSHA256
+	m.Filename = state.Filename
+	m.ContentBase64 = state.ContentBase64
+	m.ASCII = state.ASCII
+	m.ASCIISHA256 = state.ASCIISHA256
+	m.EncryptedSHA256 = state.EncryptedSHA256
+	m.QRVersion = state.QRVersion
+	m.ErrorCorrectionUsed = state.ErrorCorrectionUsed
+	m.ContentSHA256 = state.ContentSHA256
+	m.ModuleCount = state.ModuleCount
+	m.EncodingModeUsed = state.EncodingModeUsed
+	m.CapacityUsedPercent = state.CapacityUsedPercent
+	m.JWS = state.JWS
+	m.PrintJobID = state.PrintJobID
+}
+
+// planMove plans a change of file alone as a move of the written files, keeping the outputs of
+// state. It reports false when the files cannot be moved as they are, because they are missing,
+// changed since they were written or were written before their checksums were recorded, or when
+// a named file moves into a directory, where it would be named after its content. The image is
+// then rendered again.
+func (r *qrcodeResource) planMove(state, plan qrcodeResourceModel, written writtenFiles) (qrcodeResourceModel, bool) {
+	if written.Path == "" || plan.File.IsNull() || plan.File.IsUnknown() {
+		return plan, false
+	}
+
+	filePath := plan.File.ValueString()
+	if isDirectoryPath(r.fs, filePath) {
+		if written.Path == state.File.ValueString() {
+			return plan, false
+		}
+		// Content-addressed files keep their name in the new directory
+		filePath = filepath.Join(filePath, filepath.Base(written.Path))
+	}
+	if filePath == written.Path || !r.unchangedFiles(written) {
+		return plan, false
+	}
+
+	plan.keepOutputs(state)
+	plan.Filename = types.StringValue(filePath)
+	return plan, true
+}
+
+// unchangedFiles reports whether the written files all still have the checksums they were written
+// with.
+func (r *qrcodeResource) unchangedFiles(written writtenFiles) bool {
+	files := map[string]string{written.Path: written.SHA256}
+	for size, variantPath := range written.Variants {
+		files[variantPath] = written.VariantsSHA256[size]
+	}
+
+	for filePath, checksum := range files {
+		if checksum == "" {
+			return false
+		}
+		if actual, err := fileSHA256(r.fs, filePath); err != nil || actual != checksum {
+			return false
+		}
+	}
+
+	return true
+}
+
+// move applies a plan from planMove, moving the written files to the planned filename and the
+// size variants along with it. The files are read and checked against their checksums again, as
+// they may have changed since the plan, and are only removed once all of them are written.
+func (r *qrcodeResource) move(ctx context.Context, plan qrcodeResourceModel, previous writtenFiles, resp *resource.UpdateResponse) {
+	filePath := plan.Filename.ValueString()
+	moves := map[string]string{previous.Path: filePath}
+	checksums := map[string]string{previous.Path: previous.SHA256}
+	for size, variantPath := range previous.Variants {
+		variantSize, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			continue
+		}
+		moves[variantPath] = sizeVariantPath(filePath, variantSize)
+		checksums[variantPath] = previous.VariantsSHA256[size]
+	}
+
+	contents := map[string][]byte{}
+	for from := range moves {
+		data, err := afero.ReadFile(r.fs, hostPath(from))
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to Move QR Code", err.Error())
+			return
+		}
+		if actual := computeSHA256(string(data)); actual != checksums[from] {
+			resp.Diagnostics.AddError(
+				"QR Code File Changed After Plan",
+				fmt.Sprintf("The file %s has SHA-256 checksum %s, but was planned to be moved with %s. Run the plan again.", from, actual, checksums[from]),
+			)
+			return
+		}
+		contents[from] = data
+	}
+
+	opts := r.writeOptions.forNewFile(plan.Overwrite)
+	for from, to := range moves {
+		resp.Diagnostics.Append(r.replaceSymlink(ctx, plan, opts, to)...)
+		resp.Diagnostics.Append(saveQRCodeFile(ctx, r.fs, opts, to, contents[from])...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	for from := range moves {
+		if err := r.fs.Remove(hostPath(from)); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError("Failed to Delete Previous QR Code", err.Error())
+			return
+		}
+
+		tflog.Debug(ctx, "Moved QR code file", map[string]interface{}{
+			"from": from,
+			"to":   moves[from],
+		})
+	}
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+
+	diags = resp.Identity.Set(ctx, &qrcodeResourceIdentityModel{
+		File: plan.Filename,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Private != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, writtenFilesKey, newWrittenFiles(plan).marshal())...)
+	}
+}
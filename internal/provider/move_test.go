@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestQRCodeResourceMove verifies that a change of file alone moves the written files with their
+// bytes, and that files changed since they were written are rendered again instead.
+func TestQRCodeResourceMove(t *testing.T) {
+	ctx := context.Background()
+
+	for name, changed := range map[string]bool{"moved": false, "changed on disk": true} {
+		t.Run(name, func(t *testing.T) {
+			r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+			schemaResp, newIdentity := testResourceSchema(ctx, r)
+			objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+			config := map[string]tftypes.Value{
+				"text": tftypes.NewValue(tftypes.String, "https://example.com"),
+				"file": tftypes.NewValue(tftypes.String, "/old/qr.png"),
+				"sizes": tftypes.NewValue(tftypes.List{ElementType: tftypes.Number}, []tftypes.Value{
+					tftypes.NewValue(tftypes.Number, 128),
+				}),
+			}
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), config)}
+			createResp := &fwresource.CreateResponse{
+				State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+				Identity: newIdentity(),
+			}
+			r.create(ctx, fwresource.CreateRequest{Plan: plan}, createResp, "")
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", createResp.Diagnostics)
+			}
+
+			written := map[string][]byte{}
+			for _, file := range []string{"/old/qr.png", "/old/qr-128.png"} {
+				data, err := afero.ReadFile(r.fs, file)
+				if err != nil {
+					t.Fatalf("failed to read %s: %s", file, err)
+				}
+				written[file] = data
+			}
+			if changed {
+				if err := afero.WriteFile(r.fs, "/old/qr-128.png", []byte("edited"), 0o644); err != nil {
+					t.Fatalf("failed to change the file: %s", err)
+				}
+			}
+
+			// Plan file alone to change, with the outputs unknown as Terraform proposes them
+			config["file"] = tftypes.NewValue(tftypes.String, "/new/qr.png")
+			var state map[string]tftypes.Value
+			if err := createResp.State.Raw.As(&state); err != nil {
+				t.Fatalf("failed to read state: %s", err)
+			}
+			proposed := maps.Clone(state)
+			proposed["file"] = config["file"]
+			for name := range outputAttributes {
+				proposed[name] = tftypes.NewValue(objectType.AttributeTypes[name], tftypes.UnknownValue)
+			}
+			proposedRaw := tftypes.NewValue(objectType, proposed)
+
+			planReq := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), config)},
+				State:  createResp.State,
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: proposedRaw},
+			}
+			planResp := &fwresource.ModifyPlanResponse{Plan: planReq.Plan}
+			r.ModifyPlan(ctx, planReq, planResp)
+			if planResp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", planResp.Diagnostics)
+			}
+
+			var planned qrcodeResourceModel
+			planResp.Diagnostics.Append(planResp.Plan.Get(ctx, &planned)...)
+			if planResp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", planResp.Diagnostics)
+			}
+			if changed {
+				if !planned.SHA256.IsUnknown() {
+					t.Errorf("expected the image to be rendered again, got sha256 %s", planned.SHA256)
+				}
+				return
+			}
+			if planned.SHA256.IsUnknown() || planned.Filename.ValueString() != "/new/qr.png" {
+				t.Fatalf("expected a move to /new/qr.png, got filename %s and sha256 %s", planned.Filename, planned.SHA256)
+			}
+
+			updateResp := &fwresource.UpdateResponse{
+				State:    tfsdk.State{Schema: schemaResp.Schema, Raw: planResp.Plan.Raw},
+				Identity: newIdentity(),
+			}
+			r.Update(ctx, fwresource.UpdateRequest{Plan: planResp.Plan, State: createResp.State}, updateResp)
+			if updateResp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", updateResp.Diagnostics)
+			}
+			if !updateResp.State.Raw.Equal(planResp.Plan.Raw) {
+				t.Errorf("expected the planned state, got %s", updateResp.State.Raw)
+			}
+
+			for from, to := range map[string]string{"/old/qr.png": "/new/qr.png", "/old/qr-128.png": "/new/qr-128.png"} {
+				data, err := afero.ReadFile(r.fs, to)
+				if err != nil {
+					t.Fatalf("expected %s to be moved to %s: %s", from, to, err)
+				}
+				if !bytes.Equal(data, written[from]) {
+					t.Errorf("expected %s to keep the bytes of %s", to, from)
+				}
+				if _, err := r.fs.Stat(from); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be removed, got %v", from, err)
+				}
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceMoveAfterRead verifies that a file that a refresh found tampered with or
+// missing, which clears sha256 in state, is rendered and written again rather than moved, whether
+// file stays the same or changes too.
+func TestQRCodeResourceMoveAfterRead(t *testing.T) {
+	ctx := context.Background()
+	text := "https://example.com"
+
+	testCases := map[string]struct {
+		config map[string]tftypes.Value
+		change func(t *testing.T, fs afero.Fs) error
+	}{
+		"tampered": {
+			config: map[string]tftypes.Value{"verify_on_read": tftypes.NewValue(tftypes.Bool, true)},
+			change: func(t *testing.T, fs afero.Fs) error {
+				return afero.WriteFile(fs, "/out/qr.png", testRenderPNG(t, "https://example.net"), 0o644)
+			},
+		},
+		"missing": {
+			config: map[string]tftypes.Value{"on_missing_file": tftypes.NewValue(tftypes.String, onMissingFileRecreate)},
+			change: func(t *testing.T, fs afero.Fs) error {
+				return fs.Remove("/out/qr.png")
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		for _, target := range []string{"/out/qr.png", "/new/qr.png"} {
+			t.Run(name+" to "+target, func(t *testing.T) {
+				r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+				schemaResp, newIdentity := testResourceSchema(ctx, r)
+				objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+				config := maps.Clone(testCase.config)
+				config["text"] = tftypes.NewValue(tftypes.String, text)
+				config["file"] = tftypes.NewValue(tftypes.String, "/out/qr.png")
+				plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), config)}
+				createResp := &fwresource.CreateResponse{
+					State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+					Identity: newIdentity(),
+				}
+				r.create(ctx, fwresource.CreateRequest{Plan: plan}, createResp, "")
+				if createResp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", createResp.Diagnostics)
+				}
+				written, err := afero.ReadFile(r.fs, "/out/qr.png")
+				if err != nil {
+					t.Fatalf("failed to read the image: %s", err)
+				}
+
+				if err := testCase.change(t, r.fs); err != nil {
+					t.Fatalf("failed to change the file: %s", err)
+				}
+
+				readResp := &fwresource.ReadResponse{State: createResp.State, Identity: newIdentity()}
+				r.Read(ctx, fwresource.ReadRequest{State: createResp.State}, readResp)
+				if readResp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", readResp.Diagnostics)
+				}
+
+				// Plan file to the target, with the outputs unknown as Terraform proposes them when
+				// the file changes, and as they are in state otherwise
+				var state map[string]tftypes.Value
+				if err := readResp.State.Raw.As(&state); err != nil {
+					t.Fatalf("failed to read state: %s", err)
+				}
+				if !state["sha256"].IsNull() || !state["file"].Equal(config["file"]) {
+					t.Fatalf("expected the refresh to clear sha256 alone, got %s and %s", state["sha256"], state["file"])
+				}
+				proposed := maps.Clone(state)
+				config["file"] = tftypes.NewValue(tftypes.String, target)
+				if !proposed["file"].Equal(config["file"]) {
+					proposed["file"] = config["file"]
+					for name := range outputAttributes {
+						proposed[name] = tftypes.NewValue(objectType.AttributeTypes[name], tftypes.UnknownValue)
+					}
+				}
+				proposedRaw := tftypes.NewValue(objectType, proposed)
+
+				planReq := fwresource.ModifyPlanRequest{
+					Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), config)},
+					State:  readResp.State,
+					Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: proposedRaw},
+				}
+				planResp := &fwresource.ModifyPlanResponse{Plan: planReq.Plan}
+				r.ModifyPlan(ctx, planReq, planResp)
+				if planResp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", planResp.Diagnostics)
+				}
+
+				var planned qrcodeResourceModel
+				planResp.Diagnostics.Append(planResp.Plan.Get(ctx, &planned)...)
+				if planResp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", planResp.Diagnostics)
+				}
+				if !planned.SHA256.IsUnknown() {
+					t.Fatalf("expected the image to be rendered again, got sha256 %s", planned.SHA256)
+				}
+
+				updateResp := &fwresource.UpdateResponse{
+					State:    tfsdk.State{Schema: schemaResp.Schema, Raw: planResp.Plan.Raw},
+					Identity: newIdentity(),
+				}
+				r.Update(ctx, fwresource.UpdateRequest{Plan: planResp.Plan, State: readResp.State}, updateResp)
+				if updateResp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", updateResp.Diagnostics)
+				}
+
+				data, err := afero.ReadFile(r.fs, target)
+				if err != nil {
+					t.Fatalf("expected the image to be written to %s: %s", target, err)
+				}
+				if !bytes.Equal(data, written) {
+					t.Errorf("expected the image to be written again as it was created")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Line endings selectable with the normalize block line_endings attribute.
+const (
+	lineEndingsLF   = "lf"
+	lineEndingsCRLF = "crlf"
+)
+
+// qrcodeNormalizeModel maps the normalize block of the qrcode_generate resource schema data.
+type qrcodeNormalizeModel struct {
+	TrimSpace   types.Bool   `tfsdk:"trim_space"`
+	LineEndings types.String `tfsdk:"line_endings"`
+	UnicodeNFC  types.Bool   `tfsdk:"unicode_nfc"`
+}
+
+// known reports whether every normalization option is known.
+func (m *qrcodeNormalizeModel) known() bool {
+	return m == nil || (!m.TrimSpace.IsUnknown() && !m.LineEndings.IsUnknown() && !m.UnicodeNFC.IsUnknown())
+}
+
+// apply normalizes text: composes it to Unicode NFC, converts its line endings and trims leading
+// and trailing whitespace, as configured. Composing first keeps the trimming from splitting a
+// combining sequence.
+func (m *qrcodeNormalizeModel) apply(text string) string {
+	if m == nil {
+		return text
+	}
+
+	if m.UnicodeNFC.ValueBool() {
+		text = norm.NFC.String(text)
+	}
+
+	switch m.LineEndings.ValueString() {
+	case lineEndingsLF:
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	case lineEndingsCRLF:
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	}
+
+	if m.TrimSpace.ValueBool() {
+		text = strings.TrimSpace(text)
+	}
+
+	return text
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// TestQRCodeNormalizeApply verifies each normalization on its own and combined.
+func TestQRCodeNormalizeApply(t *testing.T) {
+	testCases := map[string]struct {
+		normalize *qrcodeNormalizeModel
+		text      string
+		expected  string
+	}{
+		"none": {
+			text:     " a\r\nb\n",
+			expected: " a\r\nb\n",
+		},
+		"trim space": {
+			normalize: &qrcodeNormalizeModel{TrimSpace: types.BoolValue(true)},
+			text:      " \ta\r\nb\n",
+			expected:  "a\r\nb",
+		},
+		"lf": {
+			normalize: &qrcodeNormalizeModel{LineEndings: types.StringValue(lineEndingsLF)},
+			text:      "a\r\nb\nc",
+			expected:  "a\nb\nc",
+		},
+		"crlf": {
+			normalize: &qrcodeNormalizeModel{LineEndings: types.StringValue(lineEndingsCRLF)},
+			text:      "a\r\nb\nc",
+			expected:  "a\r\nb\r\nc",
+		},
+		"nfc": {
+			normalize: &qrcodeNormalizeModel{UnicodeNFC: types.BoolValue(true)},
+			text:      "Cafe\u0301",
+			expected:  "Caf\u00e9",
+		},
+		"combined": {
+			normalize: &qrcodeNormalizeModel{
+				TrimSpace:   types.BoolValue(true),
+				LineEndings: types.StringValue(lineEndingsLF),
+				UnicodeNFC:  types.BoolValue(true),
+			},
+			text:     "\r\nCafe\u0301\r\nau lait\r\n",
+			expected: "Caf\u00e9\nau lait",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := testCase.normalize.apply(testCase.text); actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+// TestAccQRCodeResourceNormalize verifies that normalized text encodes the same as the plain text.
+func TestAccQRCodeResourceNormalize(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "plain" {
+						text = "line one\nline two"
+					}
+
+					resource "qrcode_generate" "normalized" {
+						text = "  line one\r\nline two\r\n"
+
+						normalize {
+							trim_space   = true
+							line_endings = "lf"
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("qrcode_generate.normalized", "text", "  line one\r\nline two\r\n"),
+					resource.TestCheckResourceAttrPair("qrcode_generate.normalized", "sha256", "qrcode_generate.plain", "sha256"),
+				),
+			},
+		},
+	})
+}
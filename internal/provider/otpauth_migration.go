@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// otpauthMigrationPrefix starts the URIs that Google Authenticator imports accounts from.
+const otpauthMigrationPrefix = "otpauth-migration://offline?data="
+
+// Values of the account block algorithm and type attributes.
+const (
+	otpAlgorithmSHA1   = "SHA1"
+	otpAlgorithmSHA256 = "SHA256"
+	otpAlgorithmSHA512 = "SHA512"
+	otpAlgorithmMD5    = "MD5"
+
+	otpTypeTOTP = "totp"
+	otpTypeHOTP = "hotp"
+)
+
+// Enum values of the MigrationPayload protobuf message.
+var (
+	otpAlgorithmValues = map[string]uint64{
+		otpAlgorithmSHA1:   1,
+		otpAlgorithmSHA256: 2,
+		otpAlgorithmSHA512: 3,
+		otpAlgorithmMD5:    4,
+	}
+	otpDigitsValues = map[int64]uint64{6: 1, 8: 2}
+	otpTypeValues   = map[string]uint64{otpTypeHOTP: 1, otpTypeTOTP: 2}
+)
+
+// qrcodeOTPAuthMigrationModel maps the otpauth_migration block of the qrcode_generate resource
+// schema data.
+type qrcodeOTPAuthMigrationModel struct {
+	Accounts []qrcodeOTPAuthAccountModel `tfsdk:"account"`
+}
+
+// qrcodeOTPAuthAccountModel maps an account block of the otpauth_migration block.
+type qrcodeOTPAuthAccountModel struct {
+	Name      types.String `tfsdk:"name"`
+	Issuer    types.String `tfsdk:"issuer"`
+	Secret    types.String `tfsdk:"secret"`
+	Algorithm types.String `tfsdk:"algorithm"`
+	Digits    types.Int64  `tfsdk:"digits"`
+	Type      types.String `tfsdk:"type"`
+	Counter   types.Int64  `tfsdk:"counter"`
+}
+
+// known reports whether every account attribute is known.
+func (m *qrcodeOTPAuthMigrationModel) known() bool {
+	if m == nil {
+		return true
+	}
+
+	for _, account := range m.Accounts {
+		if account.Name.IsUnknown() || account.Issuer.IsUnknown() || account.Secret.IsUnknown() || account.Algorithm.IsUnknown() || account.Digits.IsUnknown() || account.Type.IsUnknown() || account.Counter.IsUnknown() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// uri returns the otpauth-migration URI that transfers every account in a single QR code: a
+// base64-encoded MigrationPayload protobuf message, as exported by Google Authenticator. The
+// batch ID is derived from the accounts, so that the same accounts always encode the same.
+func (m *qrcodeOTPAuthMigrationModel) uri() (string, error) {
+	var accounts []byte
+	for i, account := range m.Accounts {
+		parameters, err := account.parameters()
+		if err != nil {
+			return "", fmt.Errorf("account %d: %w", i, err)
+		}
+		accounts = appendProtobufBytes(accounts, 1, parameters)
+	}
+
+	checksum := sha256.Sum256(accounts)
+	batchID := binary.BigEndian.Uint32(checksum[:4]) & 0x7fffffff
+
+	payload := accounts
+	payload = appendProtobufVarint(payload, 2, 1) // version
+	payload = appendProtobufVarint(payload, 3, 1) // batch_size
+	payload = appendProtobufVarint(payload, 4, 0) // batch_index
+	payload = appendProtobufVarint(payload, 5, uint64(batchID))
+
+	return otpauthMigrationPrefix + url.QueryEscape(base64.StdEncoding.EncodeToString(payload)), nil
+}
+
+// parameters encodes the account as an OtpParameters protobuf message.
+func (m qrcodeOTPAuthAccountModel) parameters() ([]byte, error) {
+	secret, err := decodeOTPSecret(m.Secret.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := otpAlgorithmSHA1
+	if !m.Algorithm.IsNull() {
+		algorithm = m.Algorithm.ValueString()
+	}
+	digits := int64(6)
+	if !m.Digits.IsNull() {
+		digits = m.Digits.ValueInt64()
+	}
+	otpType := otpTypeTOTP
+	if !m.Type.IsNull() {
+		otpType = m.Type.ValueString()
+	}
+
+	var parameters []byte
+	parameters = appendProtobufBytes(parameters, 1, secret)
+	parameters = appendProtobufBytes(parameters, 2, []byte(m.Name.ValueString()))
+	if issuer := m.Issuer.ValueString(); issuer != "" {
+		parameters = appendProtobufBytes(parameters, 3, []byte(issuer))
+	}
+	parameters = appendProtobufVarint(parameters, 4, otpAlgorithmValues[algorithm])
+	parameters = appendProtobufVarint(parameters, 5, otpDigitsValues[digits])
+	parameters = appendProtobufVarint(parameters, 6, otpTypeValues[otpType])
+	if otpType == otpTypeHOTP {
+		parameters = appendProtobufVarint(parameters, 7, uint64(m.Counter.ValueInt64()))
+	}
+
+	return parameters, nil
+}
+
+// decodeOTPSecret decodes a base32 secret as shown by authenticator apps, ignoring case, spaces
+// and padding.
+func decodeOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("secret is not valid base32: %w", err)
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("secret is empty")
+	}
+
+	return decoded, nil
+}
+
+// appendProtobufVarint appends a varint field to a protobuf message.
+func appendProtobufVarint(message []byte, field int, value uint64) []byte {
+	message = binary.AppendUvarint(message, uint64(field)<<3)
+	return binary.AppendUvarint(message, value)
+}
+
+// appendProtobufBytes appends a length-delimited field, such as a string or an embedded message,
+// to a protobuf message.
+func appendProtobufBytes(message []byte, field int, value []byte) []byte {
+	message = binary.AppendUvarint(message, uint64(field)<<3|2)
+	message = binary.AppendUvarint(message, uint64(len(value)))
+	return append(message, value...)
+}
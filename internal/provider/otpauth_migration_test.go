@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TestOTPAuthMigrationURI verifies that accounts encode as the MigrationPayload message that
+// Google Authenticator imports.
+func TestOTPAuthMigrationURI(t *testing.T) {
+	migration := &qrcodeOTPAuthMigrationModel{
+		Accounts: []qrcodeOTPAuthAccountModel{
+			{
+				Name:   types.StringValue("alice"),
+				Issuer: types.StringValue("Example"),
+				Secret: types.StringValue("jbsw y3dp ehpk 3pxp"),
+			},
+			{
+				Name:      types.StringValue("bob"),
+				Secret:    types.StringValue("JBSWY3DPEHPK3PXP"),
+				Algorithm: types.StringValue(otpAlgorithmSHA256),
+				Digits:    types.Int64Value(8),
+				Type:      types.StringValue(otpTypeHOTP),
+				Counter:   types.Int64Value(300),
+			},
+		},
+	}
+
+	uri, err := migration.uri()
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if !strings.HasPrefix(uri, otpauthMigrationPrefix) {
+		t.Fatalf("expected an otpauth-migration URI, got %s", uri)
+	}
+
+	data, err := url.QueryUnescape(strings.TrimPrefix(uri, otpauthMigrationPrefix))
+	if err != nil {
+		t.Fatalf("failed to unescape: %s", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	secret := []byte("Hello!\xde\xad\xbe\xef")
+	var expected []byte
+	expected = append(expected, 0x0a, 0x22, 0x0a, 0x0a)
+	expected = append(expected, secret...)
+	expected = append(expected, 0x12, 0x05)
+	expected = append(expected, "alice"...)
+	expected = append(expected, 0x1a, 0x07)
+	expected = append(expected, "Example"...)
+	expected = append(expected, 0x20, 0x01, 0x28, 0x01, 0x30, 0x02)
+	expected = append(expected, 0x0a, 0x1a, 0x0a, 0x0a)
+	expected = append(expected, secret...)
+	expected = append(expected, 0x12, 0x03)
+	expected = append(expected, "bob"...)
+	expected = append(expected, 0x20, 0x02, 0x28, 0x02, 0x30, 0x01, 0x38, 0xac, 0x02)
+	expected = append(expected, 0x10, 0x01, 0x18, 0x01, 0x20, 0x00, 0x28)
+	if !bytes.HasPrefix(payload, expected) {
+		t.Errorf("expected payload to start with %x, got %x", expected, payload)
+	}
+
+	again, err := migration.uri()
+	if err != nil || again != uri {
+		t.Errorf("expected the same accounts to encode the same, got %s: %v", again, err)
+	}
+}
+
+// TestDecodeOTPSecret verifies that invalid secrets are rejected.
+func TestDecodeOTPSecret(t *testing.T) {
+	for _, secret := range []string{"", "JBSWY3DP1", "JBS"} {
+		if _, err := decodeOTPSecret(secret); err == nil {
+			t.Errorf("%q: expected an error", secret)
+		}
+	}
+}
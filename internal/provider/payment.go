@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of EPC QR codes, as specified by the European Payments Council in EPC069-12.
+const (
+	maxEPCNameLength       = 70
+	maxEPCRemittanceLength = 140
+	maxEPCAmount           = "999999999.99"
+)
+
+// Limits of Pix BR Codes, as specified by the Central Bank of Brazil.
+const (
+	maxPIXKeyLength          = 77
+	maxPIXMerchantNameLength = 25
+	maxPIXMerchantCityLength = 15
+	maxPIXTxIDLength         = 25
+	maxPIXAmount             = "9999999999.99"
+)
+
+// epcPayment is a SEPA credit transfer encoded as an EPC QR code.
+type epcPayment struct {
+	// Name is the name of the beneficiary.
+	Name string
+
+	// IBAN is the account of the beneficiary, with or without spaces.
+	IBAN string
+
+	// Amount is the amount in euros with at most two decimals, or empty to let the payer enter it.
+	Amount string
+
+	// Remittance is an ISO 11649 creditor reference, such as RF18539007547034, or unstructured
+	// remittance text, or empty.
+	Remittance string
+
+	// BIC is the BIC of the bank of the beneficiary, or empty, which version 002 allows within the
+	// EEA.
+	BIC string
+}
+
+// epcString returns the text of the EPC QR code of the payment: version 002 in UTF-8, with a
+// creditor reference as the structured reference and any other remittance as text.
+func (p epcPayment) epcString() (string, error) {
+	if p.Name == "" || utf8.RuneCountInString(p.Name) > maxEPCNameLength {
+		return "", fmt.Errorf("name must be 1 to %d characters long", maxEPCNameLength)
+	}
+
+	iban := strings.ToUpper(strings.ReplaceAll(p.IBAN, " ", ""))
+	if err := validateIBAN(iban); err != nil {
+		return "", err
+	}
+
+	bic := strings.ToUpper(p.BIC)
+	if bic != "" && len(bic) != 8 && len(bic) != 11 {
+		return "", fmt.Errorf("BIC must be 8 or 11 characters long, got %q", p.BIC)
+	}
+
+	amount := ""
+	if p.Amount != "" {
+		amount = "EUR" + p.Amount
+	}
+
+	reference, text := "", p.Remittance
+	if validateCreditorReference(strings.ReplaceAll(p.Remittance, " ", "")) == nil {
+		reference, text = strings.ReplaceAll(p.Remittance, " ", ""), ""
+	}
+	if utf8.RuneCountInString(text) > maxEPCRemittanceLength {
+		return "", fmt.Errorf("remittance text must be at most %d characters long", maxEPCRemittanceLength)
+	}
+
+	lines := []string{"BCD", "002", "1", "SCT", bic, p.Name, iban, amount, "", reference, text}
+
+	// Trailing empty fields are left out
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// pixPayment is a static Pix payment encoded as a BR Code.
+type pixPayment struct {
+	// Key is the Pix key of the receiver: a CPF or CNPJ, a phone number, an email address or a
+	// random key.
+	Key string
+
+	// MerchantName and MerchantCity identify the receiver, without accents.
+	MerchantName string
+	MerchantCity string
+
+	// Amount is the amount in reais with two decimals, or empty to let the payer enter it.
+	Amount string
+
+	// TxID identifies the payment, or is empty for payments without an identifier.
+	TxID string
+}
+
+// pixString returns the text of the BR Code of the payment, in the EMV merchant-presented format
+// ending in its CRC16 checksum.
+func (p pixPayment) pixString() (string, error) {
+	if p.Key == "" || len(p.Key) > maxPIXKeyLength {
+		return "", fmt.Errorf("key must be 1 to %d characters long", maxPIXKeyLength)
+	}
+	for name, field := range map[string]struct {
+		value     string
+		maxLength int
+	}{
+		"merchant name": {p.MerchantName, maxPIXMerchantNameLength},
+		"merchant city": {p.MerchantCity, maxPIXMerchantCityLength},
+		"txid":          {p.TxID, maxPIXTxIDLength},
+	} {
+		if len(field.value) > field.maxLength {
+			return "", fmt.Errorf("%s must be at most %d characters long", name, field.maxLength)
+		}
+		for _, r := range field.value {
+			if r < 0x20 || r > 0x7e {
+				return "", fmt.Errorf("%s must only contain ASCII characters, got %q", name, r)
+			}
+		}
+	}
+	if p.MerchantName == "" || p.MerchantCity == "" {
+		return "", fmt.Errorf("merchant name and merchant city must not be empty")
+	}
+
+	txid := p.TxID
+	if txid == "" {
+		// Payments without an identifier use *** as required by the BR Code specification
+		txid = "***"
+	}
+
+	var b strings.Builder
+	b.WriteString(emvField("00", "01"))
+	b.WriteString(emvField("26", emvField("00", "br.gov.bcb.pix")+emvField("01", p.Key)))
+	b.WriteString(emvField("52", "0000"))
+	b.WriteString(emvField("53", "986"))
+	if p.Amount != "" {
+		b.WriteString(emvField("54", p.Amount))
+	}
+	b.WriteString(emvField("58", "BR"))
+	b.WriteString(emvField("59", p.MerchantName))
+	b.WriteString(emvField("60", p.MerchantCity))
+	b.WriteString(emvField("62", emvField("05", txid)))
+	b.WriteString("6304")
+
+	return b.String() + fmt.Sprintf("%04X", crc16CCITT([]byte(b.String()))), nil
+}
+
+// emvField encodes a field of an EMV merchant-presented QR code: its ID, the length of its value
+// in two digits and the value.
+func emvField(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// crc16CCITT returns the CRC-16/CCITT-FALSE checksum of data, with polynomial 0x1021 and initial
+// value 0xFFFF, which ends EMV merchant-presented QR codes.
+func crc16CCITT(data []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// formatPaymentAmount formats a positive amount with at most two decimals, up to maxAmount, as
+// payment QR codes require, such as 12.5 for 12.50. Amounts with more decimals are refused rather
+// than rounded.
+func formatPaymentAmount(amount *big.Float, maxAmount string) (string, error) {
+	text := amount.Text('f', -1)
+	if integer, decimals, ok := strings.Cut(text, "."); ok && len(decimals) > 2 {
+		return "", fmt.Errorf("amount must have at most two decimals, got %s.%s", integer, decimals)
+	}
+
+	limit, _, _ := big.ParseFloat(maxAmount, 10, amount.Prec(), big.ToNearestEven)
+	if amount.Sign() <= 0 || amount.Cmp(limit) > 0 {
+		return "", fmt.Errorf("amount must be greater than 0 and at most %s, got %s", maxAmount, text)
+	}
+
+	return text, nil
+}
+
+// validateIBAN checks the length and the ISO 7064 MOD 97-10 check digits of an IBAN without
+// spaces.
+func validateIBAN(iban string) error {
+	if len(iban) < 15 || len(iban) > 34 {
+		return fmt.Errorf("IBAN must be 15 to 34 characters long, got %d", len(iban))
+	}
+	if !mod97Valid(iban) {
+		return fmt.Errorf("invalid IBAN check digits in %s", iban)
+	}
+	return nil
+}
+
+// validateCreditorReference checks an ISO 11649 creditor reference without spaces, such as
+// RF18539007547034.
+func validateCreditorReference(reference string) error {
+	if len(reference) < 5 || len(reference) > 25 || !strings.HasPrefix(reference, "RF") {
+		return fmt.Errorf("creditor reference must start with RF and be 5 to 25 characters long")
+	}
+	if !mod97Valid(reference) {
+		return fmt.Errorf("invalid creditor reference check digits in %s", reference)
+	}
+	return nil
+}
+
+// mod97Valid reports whether the ISO 7064 MOD 97-10 check of IBANs and creditor references holds:
+// the first four characters moved to the end, letters replaced by 10 to 35, leave a remainder of
+// 1 when divided by 97.
+func mod97Valid(value string) bool {
+	remainder := 0
+	for _, c := range value[4:] + value[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
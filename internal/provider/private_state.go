@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// writtenFilesKey is the private state key of the files that a qrcode_generate resource wrote.
+const writtenFilesKey = "written_files"
+
+// privateState reads the private state of a resource, as kept by Terraform next to its state.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// writtenFiles records the files that a resource last wrote and the options they were rendered
+// with. It is kept in private state rather than derived from the attributes, so that Update and
+// Delete find the files written before attributes were renamed or changed shape in a later version
+// of the provider.
+type writtenFiles struct {
+	// Path is the image file, or empty when the image was not written to a file.
+	Path string `json:"path,omitempty"`
+
+	// Variants maps sizes in pixels to the paths of the size variants written next to the image.
+	Variants map[string]string `json:"variants,omitempty"`
+
+	// Format and Size are the image format and size in pixels the files were rendered with.
+	Format string `json:"format,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+
+	// SHA256 is the checksum of the image file, and VariantsSHA256 maps sizes in pixels to the
+	// checksums of the size variants, so that Delete can tell files it wrote from files that
+	// another process wrote to the same paths since.
+	SHA256         string            `json:"sha256,omitempty"`
+	VariantsSHA256 map[string]string `json:"variants_sha256,omitempty"`
+}
+
+// newWrittenFiles returns the files written by a resource, from its state after create.
+func newWrittenFiles(m qrcodeResourceModel) writtenFiles {
+	files := writtenFiles{
+		Path:   m.Filename.ValueString(),
+		Format: m.Format.ValueString(),
+		Size:   m.Size.ValueInt64(),
+	}
+	if files.Path != "" {
+		files.SHA256 = m.outputSHA256()
+	}
+	if variants := m.sizeVariantPaths(); len(variants) > 0 {
+		files.Variants = variants
+		files.VariantsSHA256 = m.sizeVariantSHA256s()
+	}
+
+	return files
+}
+
+// previousWrittenFiles returns the files recorded in the private state of a resource. Resources
+// created before the files were recorded fall back to the files named by their state.
+func previousWrittenFiles(ctx context.Context, private privateState, state qrcodeResourceModel) (writtenFiles, diag.Diagnostics) {
+	fallback := writtenFiles{
+		Path:           state.outputPath(),
+		Variants:       state.sizeVariantPaths(),
+		Format:         state.Format.ValueString(),
+		Size:           state.Size.ValueInt64(),
+		SHA256:         state.outputSHA256(),
+		VariantsSHA256: state.sizeVariantSHA256s(),
+	}
+	if private == nil {
+		return fallback, nil
+	}
+
+	data, diags := private.GetKey(ctx, writtenFilesKey)
+	if diags.HasError() || len(data) == 0 {
+		return fallback, diags
+	}
+
+	var files writtenFiles
+	if err := json.Unmarshal(data, &files); err != nil {
+		diags.AddWarning(
+			"Invalid Private State",
+			"The files written by the QR code could not be read from private state, so the files named by its state are cleaned up instead: "+err.Error(),
+		)
+		return fallback, diags
+	}
+
+	return files, diags
+}
+
+// marshal encodes the files as the JSON value of writtenFilesKey.
+func (f writtenFiles) marshal() []byte {
+	// Marshaling strings, maps of strings and integers does not fail
+	data, _ := json.Marshal(f)
+	return data
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// testPrivateState is private state with the given keys.
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func TestPreviousWrittenFiles(t *testing.T) {
+	ctx := context.Background()
+	state := qrcodeResourceModel{
+		File:     types.StringValue("/out/"),
+		Filename: types.StringValue("/out/current.png"),
+		Format:   types.StringValue(imageFormatPNG),
+		Size:     types.Int64Value(256),
+		SizesSHA256: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"512": types.StringValue("checksum"),
+		}),
+	}
+	recorded := writtenFiles{
+		Path:     "/old/renamed.png",
+		Variants: map[string]string{"64": "/old/renamed-64.png"},
+		Format:   imageFormatPNG,
+		Size:     128,
+	}
+
+	testCases := map[string]struct {
+		private  privateState
+		expected writtenFiles
+		warning  bool
+	}{
+		"recorded": {
+			private:  testPrivateState{writtenFilesKey: recorded.marshal()},
+			expected: recorded,
+		},
+		"not recorded": {
+			private: testPrivateState{},
+			expected: writtenFiles{
+				Path:           "/out/current.png",
+				Variants:       map[string]string{"512": "/out/current-512.png"},
+				Format:         imageFormatPNG,
+				Size:           256,
+				VariantsSHA256: map[string]string{"512": "checksum"},
+			},
+		},
+		"invalid": {
+			private: testPrivateState{writtenFilesKey: []byte(`{"path": 1}`)},
+			expected: writtenFiles{
+				Path:           "/out/current.png",
+				Variants:       map[string]string{"512": "/out/current-512.png"},
+				Format:         imageFormatPNG,
+				Size:           256,
+				VariantsSHA256: map[string]string{"512": "checksum"},
+			},
+			warning: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			files, diags := previousWrittenFiles(ctx, testCase.private, state)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if (diags.WarningsCount() > 0) != testCase.warning {
+				t.Errorf("expected warning %t, got %v", testCase.warning, diags)
+			}
+			if !reflect.DeepEqual(files, testCase.expected) {
+				t.Errorf("expected %+v, got %+v", testCase.expected, files)
+			}
+		})
+	}
+}
+
+func TestNewWrittenFiles(t *testing.T) {
+	files := newWrittenFiles(qrcodeResourceModel{
+		Filename:    types.StringValue("/out/code.svg"),
+		Format:      types.StringValue(imageFormatSVG),
+		Size:        types.Int64Value(300),
+		SizesSHA256: types.MapNull(types.StringType),
+	})
+
+	expected := `{"path":"/out/code.svg","format":"svg","size":300}`
+	if string(files.marshal()) != expected {
+		t.Errorf("expected %s, got %s", expected, files.marshal())
+	}
+}
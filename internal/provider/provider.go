@@ -2,16 +2,32 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"aead.dev/minisign"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/spf13/afero"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &qrcodeProvider{}
+	_ provider.Provider                  = &qrcodeProvider{}
+	_ provider.ProviderWithActions       = &qrcodeProvider{}
+	_ provider.ProviderWithListResources = &qrcodeProvider{}
+	_ provider.ProviderWithFunctions     = &qrcodeProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -31,6 +47,69 @@ type qrcodeProvider struct {
 	version string
 }
 
+// qrcodeProviderModel maps the provider schema data.
+type qrcodeProviderModel struct {
+	OutputDirectory            types.String `tfsdk:"output_directory"`
+	Filesystem                 types.String `tfsdk:"filesystem"`
+	ManifestSigningKey         types.String `tfsdk:"manifest_signing_key"`
+	ManifestSigningKeyPassword types.String `tfsdk:"manifest_signing_key_password"`
+	JWSSigningKey              types.String `tfsdk:"jws_signing_key"`
+	JWSKeyID                   types.String `tfsdk:"jws_key_id"`
+	WriteMaxAttempts           types.Int64  `tfsdk:"write_max_attempts"`
+	WriteRetryBackoff          types.String `tfsdk:"write_retry_backoff"`
+	LockTimeout                types.String `tfsdk:"lock_timeout"`
+	FailOnOverwrite            types.Bool   `tfsdk:"fail_on_overwrite"`
+	MetricsFile                types.String `tfsdk:"metrics_file"`
+	MetricsDiagnostics         types.Bool   `tfsdk:"metrics_diagnostics"`
+	MinSize                    types.Int64  `tfsdk:"min_size"`
+	MaxSize                    types.Int64  `tfsdk:"max_size"`
+
+	Kubernetes *qrcodeProviderKubernetesModel `tfsdk:"kubernetes"`
+	Consul     *qrcodeProviderConsulModel     `tfsdk:"consul"`
+	Vault      *qrcodeProviderVaultModel      `tfsdk:"vault"`
+	Styles     []qrcodeStyleModel             `tfsdk:"style"`
+}
+
+// qrcodeProviderData is the provider-level configuration shared with resources.
+type qrcodeProviderData struct {
+	// OutputDirectory is the directory managed by the provider, or empty
+	// when not configured.
+	OutputDirectory string
+
+	// Filesystem is the filesystem that QR code files are read from and
+	// written to.
+	Filesystem afero.Fs
+
+	// ManifestSigningKey signs the manifests written by resources, or is
+	// nil when manifests are not signed.
+	ManifestSigningKey *minisign.PrivateKey
+
+	// JWSSigner signs the payloads of resources with sign_jws set, or is
+	// nil when jws_signing_key is not configured.
+	JWSSigner *jwsSigner
+
+	// WriteOptions control how resources retry file writes that fail
+	// with a transient error and wait for directory locks.
+	WriteOptions writeOptions
+
+	// Kubernetes writes images to ConfigMaps and Secrets, or is nil when
+	// the kubernetes block is not configured.
+	Kubernetes *kubernetesClient
+
+	// Consul and Vault write images to KV stores, or are nil when their
+	// blocks are not configured.
+	Consul *consulClient
+	Vault  *vaultClient
+
+	// Styles are the named styles that resources reference with their
+	// style attribute.
+	Styles map[string]qrcodeStyleModel
+
+	// SizeLimits are the smallest and largest image sizes that plans
+	// accept.
+	SizeLimits sizeLimits
+}
+
 // Metadata returns the provider type name.
 func (p *qrcodeProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "qrcode"
@@ -41,17 +120,291 @@ func (p *qrcodeProvider) Metadata(_ context.Context, _ provider.MetadataRequest,
 func (p *qrcodeProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "The `qrcode` provider allows you to generate QR codes from input strings. This can be useful for encoding configuration details, authentication keys, or any other data in a scannable format. QR codes can be generated in PNG or ASCII formats, making it easy to integrate into various workflows.",
+		Attributes: map[string]schema.Attribute{
+			"output_directory": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory where generated QR code files are kept. The `qrcode_generate` list resource enumerates files under this directory by default.",
+			},
+			"filesystem": schema.StringAttribute{
+				Optional:    true,
+				Description: "Filesystem that QR code files are written to: `os` for the local filesystem, or `memory` to keep files in memory only, so nothing is written locally when images are only consumed through `content_base64`. Files in memory do not outlive a single Terraform command and are not checked for drift. Defaults to `os`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(filesystemOS, filesystemMemory),
+				},
+			},
+			"manifest_signing_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "minisign secret key, as written by `minisign -G`, that signs the manifests written by `qrcode_directory` resources with `write_manifest` set. The signature is written next to the manifest as `manifest.json.minisig` and can be checked with `minisign -Vm manifest.json -p <public-key-file>`.",
+			},
+			"manifest_signing_key_password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password that `manifest_signing_key` is encrypted with. Not needed for keys generated with `minisign -G -W`.",
+			},
+			"jws_signing_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "PEM private key that signs the payloads of `qrcode_generate` resources with `sign_jws` set, so that scanning apps can check that a QR code was issued by you. RSA keys of at least 2048 bits sign with `RS256`, P-256 and P-384 keys with `ES256` and `ES384`, and Ed25519 keys with `EdDSA`. PKCS #8, PKCS #1 and SEC 1 keys are accepted, such as those written by `openssl genpkey`.",
+			},
+			"jws_key_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "Key ID set as the `kid` header of JWS signatures, so that apps trusting several keys know which one to verify with.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("jws_signing_key")),
+				},
+			},
+			"lock_timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("How long a file write waits for other resources or Terraform processes writing to the same directory, as a duration such as `10s` or `2m`. Writers coordinate through an advisory lock on a `%s` file in the directory, so concurrent writes do not corrupt output. Only the `os` filesystem is locked. Defaults to `%s`.", lockFileName, defaultLockTimeout),
+			},
+			"fail_on_overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to make resources refuse to replace files they did not write, such as the files of another workspace sharing the output directory, unless the resource sets `overwrite = true`. The apply then fails instead of writing to an existing path. Files that a resource wrote before are still updated.",
+			},
+			"metrics_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a JSON file that the totals of the current apply are written to after every resource that generates QR codes: `codes_generated`, `files_written`, `bytes_written` and `generation_time_ms`, the time spent rendering and writing, with the `started_at` time of the provider. The file is always written to the local filesystem.",
+			},
+			"metrics_diagnostics": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to report the number of QR codes generated by every resource and the time it took as a warning, together with the totals of the current apply, so that slow generation stands out in large applies.",
+			},
+			"write_max_attempts": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Number of times a file write is tried before the apply fails, so that transient errors such as an unresponsive network filesystem do not fail the whole apply. Writes that succeed after a retry are reported as warnings with the number of attempts. Only input/output errors, timeouts, interrupted calls and stale file handles are retried. Requests to Kubernetes, Consul, Vault and printers are retried the same way when the server answers with a server error or too many requests. Set to `1` to disable retries. Defaults to `%d`.", defaultWriteMaxAttempts),
+				Validators: []validator.Int64{
+					int64validator.Between(1, 10),
+				},
+			},
+			"write_retry_backoff": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Wait before the first retry of a failed file write, as a duration such as `500ms` or `2s`. The wait doubles before each further retry. Defaults to `%s`.", defaultWriteRetryBackoff),
+			},
+			"min_size": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Smallest `size` in pixels that `qrcode_generate` resources and `qrcode_image` data sources accept, including sizes computed from a physical width. Images sized by `pixels_per_module` or `size_from_module_px` can be smaller. Sizes outside `min_size` and `max_size` fail the plan. Defaults to `%d`.", minSize),
+				Validators: []validator.Int64{
+					int64validator.Between(1, sizeCeiling),
+				},
+			},
+			"max_size": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Largest `size` in pixels that `qrcode_generate` resources and `qrcode_image` data sources accept, up to `%d`, such as `6000` for trade show banners. PNG images larger than 2000 pixels are encoded a row at a time rather than drawn in memory first, unless `interlaced`, `reproducible`, `background_image` or `annotation` is set. Defaults to `%d`.", sizeCeiling, maxSize),
+				Validators: []validator.Int64{
+					int64validator.Between(1, sizeCeiling),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"style": schema.ListNestedBlock{
+				Description: "A named style that `qrcode_generate` resources reference with their `style` attribute, such as `brand_dark`, so that many resources share colors and a quiet zone and a rebrand changes them in one place. The style sets defaults for the resource attributes of the same name, which a resource can still set itself. Resources are regenerated when their style changes.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: styleAttributes(),
+				},
+			},
+			"consul": schema.SingleNestedBlock{
+				Description: "Consul agent that `qrcode_generate` resources with a `consul_kv` block write images to.",
+				Attributes: map[string]schema.Attribute{
+					"address": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Address of the Consul HTTP API, such as `https://consul.example.com:8501`. Defaults to the `CONSUL_HTTP_ADDR` environment variable, or `%s`.", defaultConsulAddress),
+					},
+					"token": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "ACL token with write access to the keys. Defaults to the `CONSUL_HTTP_TOKEN` environment variable.",
+					},
+					"datacenter": schema.StringAttribute{
+						Optional:    true,
+						Description: "Datacenter to write keys to. Defaults to the datacenter of the agent.",
+					},
+					"ca_file": schema.StringAttribute{
+						Optional:    true,
+						Description: "Path of a PEM file of certificate authorities to trust in addition to the system ones. Defaults to the `CONSUL_CACERT` environment variable.",
+					},
+				},
+			},
+			"kubernetes": schema.SingleNestedBlock{
+				Description: "Credentials for the Kubernetes cluster that `qrcode_generate` resources with a `kubernetes` block write images to. Clusters are reached with a kubeconfig context, authenticating with a token, client certificate, basic auth or exec credential plugin, or with the service account of the pod running Terraform.",
+				Attributes: map[string]schema.Attribute{
+					"config_path": schema.StringAttribute{
+						Optional:    true,
+						Description: "Path of the kubeconfig file. Defaults to the first path in the `KUBECONFIG` environment variable, or `~/.kube/config`.",
+					},
+					"config_context": schema.StringAttribute{
+						Optional:    true,
+						Description: "kubeconfig context to use. Defaults to the current context.",
+					},
+					"in_cluster": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Set to true to authenticate as the service account of the pod running Terraform instead of with a kubeconfig file.",
+						Validators: []validator.Bool{
+							boolvalidator.ConflictsWith(
+								path.MatchRoot("kubernetes").AtName("config_path"),
+								path.MatchRoot("kubernetes").AtName("config_context"),
+							),
+						},
+					},
+				},
+			},
+			"vault": schema.SingleNestedBlock{
+				Description: "Vault server that `qrcode_generate` resources with a `vault_kv` block write images to.",
+				Attributes: map[string]schema.Attribute{
+					"address": schema.StringAttribute{
+						Optional:    true,
+						Description: "Address of the Vault server, such as `https://vault.example.com:8200`. Defaults to the `VAULT_ADDR` environment variable.",
+					},
+					"token": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "Token with write access to the secrets. Defaults to the `VAULT_TOKEN` environment variable.",
+					},
+					"namespace": schema.StringAttribute{
+						Optional:    true,
+						Description: "Vault Enterprise namespace of the secrets engines. Defaults to the `VAULT_NAMESPACE` environment variable.",
+					},
+					"ca_file": schema.StringAttribute{
+						Optional:    true,
+						Description: "Path of a PEM file of certificate authorities to trust in addition to the system ones. Defaults to the `VAULT_CACERT` environment variable.",
+					},
+				},
+			},
+		},
 	}
 }
 
 // Configure prepares any necessary provider-level setup.
 func (p *qrcodeProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config qrcodeProviderModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data := &qrcodeProviderData{
+		OutputDirectory: config.OutputDirectory.ValueString(),
+		Filesystem:      newFilesystem(config.Filesystem.ValueString()),
+		Styles:          map[string]qrcodeStyleModel{},
+		WriteOptions: writeOptions{
+			retry: retryPolicy{
+				maxAttempts: defaultWriteMaxAttempts,
+			},
+		},
+	}
+
+	for i, style := range config.Styles {
+		name := style.Name.ValueString()
+		if _, ok := data.Styles[name]; ok {
+			resp.Diagnostics.AddAttributeError(path.Root("style").AtListIndex(i).AtName("name"), "Duplicate Style", fmt.Sprintf("A style named %q is already defined.", name))
+			return
+		}
+		data.Styles[name] = style
+	}
+
+	data.SizeLimits = sizeLimits{min: minSize, max: maxSize}
+	if !config.MinSize.IsNull() {
+		data.SizeLimits.min = config.MinSize.ValueInt64()
+	}
+	if !config.MaxSize.IsNull() {
+		data.SizeLimits.max = config.MaxSize.ValueInt64()
+	}
+	if data.SizeLimits.min > data.SizeLimits.max {
+		resp.Diagnostics.AddAttributeError(path.Root("max_size"), "Invalid Size Limits", fmt.Sprintf("max_size must be at least min_size, %d pixels, got %d.", data.SizeLimits.min, data.SizeLimits.max))
+		return
+	}
+
+	if !config.WriteMaxAttempts.IsNull() {
+		data.WriteOptions.retry.maxAttempts = int(config.WriteMaxAttempts.ValueInt64())
+	}
+
+	backoff := defaultWriteRetryBackoff
+	if !config.WriteRetryBackoff.IsNull() {
+		backoff = config.WriteRetryBackoff.ValueString()
+	}
+	retryBackoff, err := time.ParseDuration(backoff)
+	if err != nil || retryBackoff < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("write_retry_backoff"), "Invalid Write Retry Backoff", fmt.Sprintf("Expected a non-negative duration such as 500ms, got %q.", backoff))
+		return
+	}
+	data.WriteOptions.retry.backoff = retryBackoff
+
+	timeout := defaultLockTimeout
+	if !config.LockTimeout.IsNull() {
+		timeout = config.LockTimeout.ValueString()
+	}
+	lockTimeout, err := time.ParseDuration(timeout)
+	if err != nil || lockTimeout < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("lock_timeout"), "Invalid Lock Timeout", fmt.Sprintf("Expected a non-negative duration such as 30s, got %q.", timeout))
+		return
+	}
+	data.WriteOptions.lockTimeout = lockTimeout
+	data.WriteOptions.failOnOverwrite = config.FailOnOverwrite.ValueBool()
+	data.WriteOptions.metrics = newGenerationMetrics(config.MetricsFile.ValueString(), config.MetricsDiagnostics.ValueBool())
+
+	if !config.ManifestSigningKey.IsNull() {
+		signingKey, err := parseSigningKey(config.ManifestSigningKey.ValueString(), config.ManifestSigningKeyPassword.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("manifest_signing_key"), "Invalid Manifest Signing Key", err.Error())
+			return
+		}
+		data.ManifestSigningKey = &signingKey
+	}
+
+	if !config.JWSSigningKey.IsNull() {
+		signer, err := parseJWSSigningKey(config.JWSSigningKey.ValueString(), config.JWSKeyID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jws_signing_key"), "Invalid JWS Signing Key", err.Error())
+			return
+		}
+		data.JWSSigner = signer
+	}
+
+	if config.Kubernetes != nil {
+		client, err := newKubernetesClient(*config.Kubernetes, data.WriteOptions.retry)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Invalid Kubernetes Configuration", err.Error())
+			return
+		}
+		data.Kubernetes = client
+	}
+
+	if config.Consul != nil {
+		client, err := newConsulClient(*config.Consul, data.WriteOptions.retry)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul"), "Invalid Consul Configuration", err.Error())
+			return
+		}
+		data.Consul = client
+	}
+
+	if config.Vault != nil {
+		client, err := newVaultClient(*config.Vault, data.WriteOptions.retry)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault"), "Invalid Vault Configuration", err.Error())
+			return
+		}
+		data.Vault = client
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.ListResourceData = data
+	resp.ActionData = data
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *qrcodeProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewQRCodeDataSource,
+		NewQRCodeASCIIDataSource,
+		NewQRCodeImageDataSource,
+		NewQRCodeScanDirectoryDataSource,
+		NewQRCodeVerifyDataSource,
+		NewQRCodePayloadsDataSource,
+		NewQRCodeWalletBarcodeDataSource,
 	}
 }
 
@@ -59,5 +412,32 @@ func (p *qrcodeProvider) DataSources(_ context.Context) []func() datasource.Data
 func (p *qrcodeProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewQRCodeResource,
+		NewBarcodeResource,
+		NewQRCodeDirectoryResource,
+		NewQRCodeStructuredAppendResource,
+		NewBadgeResource,
+	}
+}
+
+// Actions defines the actions implemented in the provider.
+func (p *qrcodeProvider) Actions(_ context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewQRCodeRestoreAction,
+	}
+}
+
+// ListResources defines the list resources implemented in the provider.
+func (p *qrcodeProvider) ListResources(_ context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewQRCodeListResource,
+	}
+}
+
+// Functions defines the provider-defined functions implemented in the provider.
+func (p *qrcodeProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewDecodeFunction,
+		NewEPCStringFunction,
+		NewPIXStringFunction,
 	}
 }
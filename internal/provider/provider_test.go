@@ -1,10 +1,17 @@
 package provider
 
 import (
+	"context"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf6to5server"
 )
 
 // testAccProtoV6ProviderFactories is a map that Terraform uses to load the provider during acceptance tests.
@@ -12,7 +19,68 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	"qrcode": providerserver.NewProtocol6WithError(New("test")()),
 }
 
+// testAccProtoV5ProviderFactories loads the provider as it is served to Terraform CLI releases without protocol v6.
+var testAccProtoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+	"qrcode": func() (tfprotov5.ProviderServer, error) {
+		return tf6to5server.DowngradeServer(context.Background(), providerserver.NewProtocol6(New("test")()))
+	},
+}
+
 func TestProvider(t *testing.T) {
 	// This is a placeholder test to ensure the provider compiles and can be loaded.
 	// Actual acceptance tests should be defined in separate test functions.
 }
+
+// TestProviderProtocol5Schema verifies that every schema in the provider can be downgraded to protocol v5.
+func TestProviderProtocol5Schema(t *testing.T) {
+	server, err := testAccProtoV5ProviderFactories["qrcode"]()
+	if err != nil {
+		t.Fatalf("failed to create protocol v5 server: %s", err)
+	}
+
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("failed to get provider schema: %s", err)
+	}
+
+	for _, diag := range resp.Diagnostics {
+		if diag.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Errorf("unexpected error diagnostic: %s: %s", diag.Summary, diag.Detail)
+		}
+	}
+}
+
+// testObjectValue builds a value of the given schema type with every attribute null, except those in values.
+func testObjectValue(ctx context.Context, schemaType attr.Type, values map[string]tftypes.Value) tftypes.Value {
+	objectType, ok := schemaType.TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		panic("schema type is not an object")
+	}
+
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+			continue
+		}
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+
+	return tftypes.NewValue(objectType, attributes)
+}
+
+// testResourceSchema returns the schema of r, and a function that returns a null identity of r, as
+// Terraform passes to Create and Read before the resource sets it.
+func testResourceSchema(ctx context.Context, r fwresource.ResourceWithIdentity) (*fwresource.SchemaResponse, func() *tfsdk.ResourceIdentity) {
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+	identityResp := &fwresource.IdentitySchemaResponse{}
+	r.IdentitySchema(ctx, fwresource.IdentitySchemaRequest{}, identityResp)
+
+	return schemaResp, func() *tfsdk.ResourceIdentity {
+		return &tfsdk.ResourceIdentity{
+			Schema: identityResp.IdentitySchema,
+			Raw:    tftypes.NewValue(identityResp.IdentitySchema.Type().TerraformType(ctx), nil),
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// minRedactedLineLength is the length of the shortest line of sensitive text that is redacted on
+// its own, so that short words that happen to appear in a message are left alone.
+const minRedactedLineLength = 4
+
+// redactor replaces sensitive text in diagnostics with its length and SHA-256 checksum, so that
+// errors that echo the content, such as those of the encoding libraries, do not reveal it. A nil
+// redactor leaves diagnostics unchanged.
+type redactor struct {
+	// secrets are the forms of the sensitive text to replace, longest first.
+	secrets []string
+
+	// replacement describes the sensitive text without revealing it.
+	replacement string
+}
+
+// newRedactor returns a redactor for text, or nil when text is empty. Variants are other forms of
+// the same text that messages may quote, such as the text before normalization. Besides the text
+// and its variants, their escaped forms as formatted by %q and each of their lines are redacted.
+func newRedactor(text string, variants ...string) *redactor {
+	if text == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var secrets []string
+	add := func(secret string) {
+		if secret != "" && !seen[secret] {
+			seen[secret] = true
+			secrets = append(secrets, secret)
+		}
+	}
+
+	for _, secret := range append([]string{text}, variants...) {
+		add(secret)
+		add(strings.Trim(strconv.Quote(secret), `"`))
+		for _, line := range strings.Split(secret, "\n") {
+			if line = strings.TrimSpace(line); len(line) >= minRedactedLineLength {
+				add(line)
+			}
+		}
+	}
+
+	// Longer secrets go first, so that a line is not replaced inside the text it belongs to
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	return &redactor{
+		secrets:     secrets,
+		replacement: fmt.Sprintf("(sensitive text of %d bytes with SHA-256 %s)", len(text), computeSHA256(text)),
+	}
+}
+
+// redact returns message with every form of the sensitive text replaced.
+func (r *redactor) redact(message string) string {
+	if r == nil {
+		return message
+	}
+
+	for _, secret := range r.secrets {
+		message = strings.ReplaceAll(message, secret, r.replacement)
+	}
+
+	return message
+}
+
+// diagnostics returns diags with the sensitive text redacted from every summary and detail,
+// keeping their severity and attribute path.
+func (r *redactor) diagnostics(diags diag.Diagnostics) diag.Diagnostics {
+	if r == nil || len(diags) == 0 {
+		return diags
+	}
+
+	redacted := make(diag.Diagnostics, 0, len(diags))
+	for _, d := range diags {
+		summary, detail := r.redact(d.Summary()), r.redact(d.Detail())
+
+		var diagnostic diag.Diagnostic
+		if d.Severity() == diag.SeverityError {
+			diagnostic = diag.NewErrorDiagnostic(summary, detail)
+		} else {
+			diagnostic = diag.NewWarningDiagnostic(summary, detail)
+		}
+		if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+			diagnostic = diag.WithPath(withPath.Path(), diagnostic)
+		}
+
+		redacted = append(redacted, diagnostic)
+	}
+
+	return redacted
+}
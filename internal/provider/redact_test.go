@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// TestRedactorDiagnostics verifies that sensitive text, its quoted form and its lines are replaced
+// by its length and checksum, keeping the severity and path of diagnostics.
+func TestRedactorDiagnostics(t *testing.T) {
+	text := "WIFI:S:office;T:WPA;P:hunter2\nsecond line"
+	r := newRedactor(text)
+
+	var diags diag.Diagnostics
+	diags.AddAttributeError(path.Root("sensitive_text"), "QR Code Generation Failed", "could not encode "+text)
+	diags.AddWarning("Quoted", "got "+strings.ReplaceAll(text, "\n", `\n`))
+	diags.AddError("Line", "the line second line is invalid")
+	diags.AddError("Unrelated", "permission denied")
+
+	redacted := r.diagnostics(diags)
+	if len(redacted) != len(diags) {
+		t.Fatalf("expected %d diagnostics, got %d", len(diags), len(redacted))
+	}
+
+	for i, d := range redacted {
+		if strings.Contains(d.Detail(), "hunter2") || strings.Contains(d.Detail(), "second line") {
+			t.Errorf("diagnostic %d still contains sensitive text: %s", i, d.Detail())
+		}
+		if d.Severity() != diags[i].Severity() || d.Summary() != diags[i].Summary() {
+			t.Errorf("diagnostic %d changed severity or summary", i)
+		}
+	}
+
+	if expected := "could not encode " + r.replacement; redacted[0].Detail() != expected {
+		t.Errorf("expected %q, got %q", expected, redacted[0].Detail())
+	}
+	if !strings.Contains(r.replacement, computeSHA256(text)) || !strings.Contains(r.replacement, "41 bytes") {
+		t.Errorf("expected the replacement to give the length and checksum, got %q", r.replacement)
+	}
+	if withPath, ok := redacted[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("sensitive_text")) {
+		t.Errorf("expected the attribute path to be kept")
+	}
+	if redacted[3].Detail() != "permission denied" {
+		t.Errorf("expected unrelated diagnostics to be unchanged, got %q", redacted[3].Detail())
+	}
+
+	var nilRedactor *redactor
+	if actual := nilRedactor.diagnostics(diags); len(actual) != len(diags) || actual[0].Detail() != diags[0].Detail() {
+		t.Errorf("expected a nil redactor to leave diagnostics unchanged")
+	}
+}
@@ -0,0 +1,551 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Register the JPEG decoder for logos.
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+	xdraw "golang.org/x/image/draw"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// sha256Pattern matches a lowercase hex-encoded SHA-256 checksum.
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Image formats that QR codes can be rendered in.
+const (
+	imageFormatPNG    = "png"
+	imageFormatSVG    = "svg"
+	imageFormatPDF    = "pdf"
+	imageFormatZPL    = "zpl"
+	imageFormatEPL    = "epl"
+	imageFormatTSPL   = "tspl"
+	imageFormatESCPOS = "escpos"
+)
+
+// isPrinterFormat reports whether format is the command language of label or receipt printers.
+func isPrinterFormat(format string) bool {
+	return format == imageFormatZPL || format == imageFormatEPL || format == imageFormatTSPL || format == imageFormatESCPOS
+}
+
+// How escpos output prints the QR code.
+const (
+	escposModeQR     = "qr"
+	escposModeRaster = "raster"
+)
+
+// escposModuleDots returns the module size in dots of QR codes encoded by ESC/POS printers: the
+// whole number of dots per module of the symbol that fits size, from 1 to 16.
+func escposModuleDots(size int, symbol *qrgen.Symbol) int {
+	return min(max(size/symbol.Modules(), 1), qrgen.ESCPOSMaxModuleDots)
+}
+
+// Scalings of modules to the size of PNG images.
+const (
+	scalingFill  = "fill"
+	scalingExact = "exact"
+	scalingFit   = "fit"
+)
+
+// Content encodings applied to the bytes of the content before they are encoded in the QR code.
+const (
+	// contentEncodingBase45 encodes the content in Base45.
+	contentEncodingBase45 = "base45"
+
+	// contentEncodingSHC encodes a compact JWS as a SMART Health Card.
+	contentEncodingSHC = "shc"
+)
+
+// maxDecompressedContentLength is the length in bytes of the largest content that compressed QR
+// codes are decompressed to, which guards against compression bombs.
+const maxDecompressedContentLength = 1 << 20
+
+// pngScalings maps the scaling attribute to the scaling of the renderer.
+var pngScalings = map[string]qrgen.Scaling{
+	scalingFill:  qrgen.ScalingFill,
+	scalingExact: qrgen.ScalingExact,
+	scalingFit:   qrgen.ScalingFit,
+}
+
+// qrcodeBackgroundImageModel maps the background_image block of the qrcode_generate resource
+// schema data.
+type qrcodeBackgroundImageModel struct {
+	Path    types.String `tfsdk:"path"`
+	X       types.Int64  `tfsdk:"x"`
+	Y       types.Int64  `tfsdk:"y"`
+	Padding types.Int64  `tfsdk:"padding"`
+}
+
+// placement returns where the QR code is drawn on the background image.
+func (m *qrcodeBackgroundImageModel) placement() qrgen.Placement {
+	return qrgen.Placement{
+		X:       int(m.X.ValueInt64()),
+		Y:       int(m.Y.ValueInt64()),
+		Padding: int(m.Padding.ValueInt64()),
+	}
+}
+
+// Corners that annotations are stamped in.
+const (
+	annotationCornerTopLeft     = "top_left"
+	annotationCornerTopRight    = "top_right"
+	annotationCornerBottomLeft  = "bottom_left"
+	annotationCornerBottomRight = "bottom_right"
+)
+
+// pngKeywordPattern matches PNG text chunk keywords, which are printable ASCII without leading,
+// trailing or consecutive spaces.
+var pngKeywordPattern = regexp.MustCompile(`^[!-~]+( [!-~]+)*$`)
+
+// labelTextPattern matches text that renderLabel can draw with the built-in bitmap font.
+var labelTextPattern = regexp.MustCompile(`^[\x20-\x7e]+$`)
+
+// qrcodeAnnotationModel maps the annotation block of the qrcode_generate resource schema data.
+type qrcodeAnnotationModel struct {
+	Text   types.String `tfsdk:"text"`
+	Corner types.String `tfsdk:"corner"`
+}
+
+// Share of the image width that logos are drawn in, in percent.
+const (
+	defaultLogoSizePercent = 15
+	minLogoSizePercent     = 5
+	maxLogoSizePercent     = 30
+)
+
+// Size limits for rendered QR code images, in pixels. The provider min_size and max_size
+// attributes move minSize and maxSize, but never past sizeCeiling.
+const (
+	defaultSize = 256
+	minSize     = 100
+	maxSize     = 2000
+	sizeCeiling = 10000
+)
+
+// sizeLimits are the smallest and largest sizes in pixels that the provider renders images at, as
+// set by its min_size and max_size attributes. The zero value holds the default limits, so that
+// resources the provider did not configure still check sizes.
+type sizeLimits struct {
+	min int64
+	max int64
+}
+
+// bounds returns the smallest and largest sizes in pixels.
+func (l sizeLimits) bounds() (int64, int64) {
+	if l == (sizeLimits{}) {
+		return minSize, maxSize
+	}
+	return l.min, l.max
+}
+
+// contains reports whether size is within the limits.
+func (l sizeLimits) contains(size int64) bool {
+	low, high := l.bounds()
+	return size >= low && size <= high
+}
+
+// Defaults of the ascii_dark_char and ascii_light_char attributes.
+const (
+	defaultASCIIDarkChar  = "█"
+	defaultASCIILightChar = " "
+)
+
+// renderASCII renders the symbol as text. Without glyphs, two module rows are packed per line in
+// half blocks the same as go-qrcode renders them. When any glyph is set, every module is drawn in
+// its glyph on a line per module row. inverse swaps dark and light.
+func renderASCII(symbol *qrgen.Symbol, dark, light, quietZone types.String, inverse bool) string {
+	if dark.IsNull() && light.IsNull() && quietZone.IsNull() {
+		return symbol.SmallString(inverse)
+	}
+
+	glyphs := qrgen.TextGlyphs{
+		Dark:      defaultASCIIDarkChar,
+		Light:     defaultASCIILightChar,
+		QuietZone: quietZone.ValueString(),
+	}
+	if !dark.IsNull() {
+		glyphs.Dark = dark.ValueString()
+	}
+	if !light.IsNull() {
+		glyphs.Light = light.ValueString()
+	}
+	if inverse {
+		glyphs.Dark, glyphs.Light = glyphs.Light, glyphs.Dark
+	}
+
+	return symbol.Text(glyphs)
+}
+
+// saveQRCodeFile writes a rendered QR code to filePath, creating any missing parent directories.
+// The write holds the lock on the directory, and transient failures are retried. A write that only
+// succeeded after retries is reported as a warning with the number of attempts. Exclusive writes
+// fail when filePath already exists.
+func saveQRCodeFile(ctx context.Context, fs afero.Fs, opts writeOptions, filePath string, data []byte) diag.Diagnostics {
+	_, diags := streamQRCodeFile(ctx, fs, opts, filePath, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+
+	return diags
+}
+
+// streamQRCodeFile writes the output of render to filePath as saveQRCodeFile does, streaming it to
+// the file instead of holding it in memory, and returns its SHA-256 checksum, computed as it is
+// written. render is called again for every retry.
+func streamQRCodeFile(ctx context.Context, fs afero.Fs, opts writeOptions, filePath string, render func(w io.Writer) error) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var checksum string
+	var written int
+	attempts, err := opts.retry.do(ctx, func() error {
+		dir := filepath.Dir(filePath)
+		if err := fs.MkdirAll(hostPath(dir), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+
+		unlock, err := lockDirectory(ctx, fs, dir, opts.lockTimeout)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		// Checked under the lock, so that a file written by another workspace in the meantime is
+		// not replaced either
+		if opts.exclusive {
+			if _, err := lstat(fs, filePath); err == nil {
+				return fmt.Errorf("%s already exists and the provider sets fail_on_overwrite; set overwrite = true on the resource to replace it: %w", filePath, os.ErrExist)
+			}
+		}
+
+		file, err := fs.OpenFile(hostPath(filePath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+
+		hash := sha256.New()
+		out := &countingWriter{w: io.MultiWriter(file, hash)}
+		buffered := bufio.NewWriter(out)
+		if err := render(buffered); err != nil {
+			file.Close()
+			if out.err == nil {
+				return fmt.Errorf("%w: %w", errRenderFailed, err)
+			}
+			return err
+		}
+		if err := buffered.Flush(); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+
+		checksum = hex.EncodeToString(hash.Sum(nil))
+		written = out.n
+		return nil
+	})
+	if err != nil {
+		if attempts > 1 {
+			diags.AddError("Failed to Save QR Code", fmt.Sprintf("Failed after %d attempts: %s", attempts, err))
+		} else {
+			diags.AddError("Failed to Save QR Code", err.Error())
+		}
+		return "", diags
+	}
+
+	if attempts > 1 {
+		diags.AddWarning(
+			"QR Code Saved After Retries",
+			fmt.Sprintf("Writing %s failed with a transient error and succeeded on attempt %d.", filePath, attempts),
+		)
+	}
+
+	opts.metrics.addWrite(written)
+
+	tflog.Debug(ctx, "Saved QR code", map[string]interface{}{
+		"file":     filePath,
+		"bytes":    written,
+		"attempts": attempts,
+	})
+
+	return checksum, diags
+}
+
+// countingWriter counts the bytes written to w and keeps the first error, so that errors of the
+// file are told apart from errors of rendering.
+type countingWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+// Write writes p to the underlying writer.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+
+	return n, err
+}
+
+// fileSHA256 computes the hex-encoded SHA-256 checksum of the file at filePath.
+func fileSHA256(fs afero.Fs, filePath string) (string, error) {
+	file, err := fs.Open(hostPath(filePath))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// isSymlink reports whether filePath itself is a symbolic link, without following it.
+func isSymlink(fs afero.Fs, filePath string) bool {
+	info, err := lstat(fs, filePath)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// contentAddressedPrefixLength is the number of checksum characters used to name content-addressed files.
+const contentAddressedPrefixLength = 16
+
+// isDirectoryPath reports whether filePath denotes a directory, either by ending with a path
+// separator or by naming an existing directory.
+func isDirectoryPath(fs afero.Fs, filePath string) bool {
+	if strings.HasSuffix(filePath, "/") || strings.HasSuffix(filePath, string(filepath.Separator)) {
+		return true
+	}
+
+	info, err := fs.Stat(hostPath(filePath))
+	return err == nil && info.IsDir()
+}
+
+// contentAddressedFilePath returns the path of an image file in dir named after its checksum, with
+// the extension of format.
+func contentAddressedFilePath(dir, sha256Checksum, format string) string {
+	return filepath.Join(dir, sha256Checksum[:contentAddressedPrefixLength]+"."+format)
+}
+
+// compressContent compresses data with zlib at the best compression, as configured by the
+// compress attribute.
+func compressContent(data []byte) []byte {
+	var buf bytes.Buffer
+	// Writes to a buffer do not fail
+	w, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	_, _ = w.Write(data)
+	_ = w.Close()
+
+	return buf.Bytes()
+}
+
+// encodeContent applies the content encoding to data, as configured by the content_encoding
+// attribute.
+func encodeContent(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case contentEncodingBase45:
+		return qrgen.EncodeBase45(data), nil
+	case contentEncodingSHC:
+		return qrgen.EncodeSHC(string(data))
+	}
+	return string(data), nil
+}
+
+// decodeContent reverses encodeContent and, when compressed is set, compressContent, returning the
+// content of the text of a QR code.
+func decodeContent(text, encoding string, compressed bool) ([]byte, error) {
+	data := []byte(text)
+	switch encoding {
+	case contentEncodingBase45:
+		var err error
+		if data, err = qrgen.DecodeBase45(text); err != nil {
+			return nil, err
+		}
+	case contentEncodingSHC:
+		jws, err := qrgen.DecodeSHC(text)
+		if err != nil {
+			return nil, err
+		}
+		data = []byte(jws)
+	}
+
+	if compressed {
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		if data, err = io.ReadAll(io.LimitReader(r, maxDecompressedContentLength+1)); err != nil {
+			return nil, err
+		}
+		if len(data) > maxDecompressedContentLength {
+			return nil, fmt.Errorf("decompressed content is larger than %d bytes", maxDecompressedContentLength)
+		}
+	}
+
+	return data, nil
+}
+
+// sizeVariantPath returns the path of the variant of an image file at another size, with the size
+// in pixels appended to its name before the extension, such as qr-512.png for qr.png.
+func sizeVariantPath(filePath string, size int64) string {
+	extension := filepath.Ext(filePath)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filePath, extension), size, extension)
+}
+
+// annotatePNG stamps text in a corner of a PNG image, in a strip added above or below the image so
+// that the quiet zone stays clear. The text is drawn in the dark color on the quiet zone color,
+// scaled with the image so that it stays legible in print.
+func annotatePNG(data []byte, text, corner string, colors qrgen.Colors) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+
+	scale := max(1, width/defaultSize)
+	label := renderLabel(text, scale)
+	margin := 2 * scale
+	if label.Bounds().Dx()+2*margin > width {
+		return nil, fmt.Errorf("annotation %q is %d pixels wide, which does not fit the %d pixel wide image", text, label.Bounds().Dx()+2*margin, width)
+	}
+
+	stripColor := colors.Light
+	if colors.QuietZone != (color.RGBA{}) {
+		stripColor = colors.QuietZone
+	}
+
+	// Keep the palette of two color images, adding the colors of the annotation
+	bounds := image.Rect(0, 0, width, height+label.Bounds().Dy()+2*margin)
+	var annotated draw.Image = image.NewRGBA(bounds)
+	if paletted, ok := img.(*image.Paletted); ok {
+		palette := append(color.Palette{}, paletted.Palette...)
+		for _, c := range []color.RGBA{stripColor, colors.Dark} {
+			if color.RGBAModel.Convert(palette.Convert(c)) != c {
+				palette = append(palette, c)
+			}
+		}
+		annotated = image.NewPaletted(bounds, palette)
+	}
+	draw.Draw(annotated, bounds, image.NewUniform(stripColor), image.Point{}, draw.Src)
+
+	imageTop, labelTop := 0, height+margin
+	if corner == annotationCornerTopLeft || corner == annotationCornerTopRight {
+		imageTop, labelTop = label.Bounds().Dy()+2*margin, margin
+	}
+	labelLeft := margin
+	if corner == annotationCornerTopRight || corner == annotationCornerBottomRight {
+		labelLeft = width - margin - label.Bounds().Dx()
+	}
+
+	draw.Draw(annotated, image.Rect(0, imageTop, width, imageTop+height), img, img.Bounds().Min, draw.Src)
+
+	// The label is dark on white, so its darkness masks the dark color
+	mask := image.NewAlpha(label.Bounds())
+	for i, y := range label.Pix {
+		mask.Pix[i] = 0xff - y
+	}
+	draw.DrawMask(annotated, label.Bounds().Add(image.Pt(labelLeft, labelTop)), image.NewUniform(colors.Dark), image.Point{}, mask, image.Point{}, draw.Over)
+
+	return qrgen.EncodePNG(annotated)
+}
+
+// drawLogo draws a PNG or JPEG logo over the center of a PNG image of a QR code, on a square box
+// of the light color that is percent of the image width, scaled to fit the box with a margin and
+// keeping its aspect ratio. The modules under the box are restored by the error correction of the
+// symbol, which logoDiagnostics checks.
+func drawLogo(data, logo []byte, percent int, colors qrgen.Colors) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	logoImage, _, err := image.Decode(bytes.NewReader(logo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo: %w", err)
+	}
+
+	// The box is placed as qrgen.Symbol.ObscuredCenter expects it
+	bounds := img.Bounds()
+	box := bounds.Dx() * percent / 100
+	margin := box / 10
+	if box-2*margin < 1 {
+		return nil, fmt.Errorf("a logo of %d%% does not fit the %d pixel wide image", percent, bounds.Dx())
+	}
+
+	drawn := image.NewRGBA(bounds)
+	draw.Draw(drawn, bounds, img, bounds.Min, draw.Src)
+
+	boxRect := image.Rect(0, 0, box, box).Add(bounds.Min).Add(image.Pt((bounds.Dx()-box)/2, (bounds.Dy()-box)/2))
+	draw.Draw(drawn, boxRect, image.NewUniform(colors.Light), image.Point{}, draw.Src)
+
+	inner := box - 2*margin
+	width, height := inner, inner
+	if logoWidth, logoHeight := logoImage.Bounds().Dx(), logoImage.Bounds().Dy(); logoWidth > logoHeight {
+		height = max(1, inner*logoHeight/logoWidth)
+	} else {
+		width = max(1, inner*logoWidth/logoHeight)
+	}
+	logoRect := image.Rect(0, 0, width, height).Add(boxRect.Min).Add(image.Pt((box-width)/2, (box-height)/2))
+	xdraw.CatmullRom.Scale(drawn, logoRect, logoImage, logoImage.Bounds(), xdraw.Over, nil)
+
+	return qrgen.EncodePNG(drawn)
+}
+
+// logoDiagnostics reports a logo box of percent of the image width that hides more codewords of
+// the symbol than its error correction level restores. size returns the width of the image of a
+// symbol, and encode encodes the text at another level with the other settings unchanged, so that
+// the lowest level that restores the codewords can be suggested.
+func logoDiagnostics(symbol *qrgen.Symbol, size func(*qrgen.Symbol) int, scaling qrgen.Scaling, percent int, encode func(qrgen.Level) (*qrgen.Symbol, error)) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	obscured, err := symbol.ObscuredCenter(size(symbol), scaling, percent)
+	if err != nil || obscured.Recoverable() {
+		return diags
+	}
+
+	// Higher levels add error correction codewords, but may need a larger version to fit the text
+	suggestion := "No higher error correction level restores them, so reduce logo_size_percent."
+	for level := symbol.Level() + 1; level <= qrgen.Highest; level++ {
+		higher, err := encode(level)
+		if err != nil {
+			break
+		}
+		if higherObscured, err := higher.ObscuredCenter(size(higher), scaling, percent); err == nil && higherObscured.Recoverable() {
+			suggestion = fmt.Sprintf("Set error_correction to %s, or reduce logo_size_percent.", errorCorrectionNames[level])
+			break
+		}
+	}
+
+	diags.AddAttributeError(
+		path.Root("logo_file"),
+		"Logo Hides Too Much of the QR Code",
+		fmt.Sprintf("The logo box covers %.1f%% of the data modules and hides %d codewords of an error correction block, while error correction level %s restores at most %d, so the QR code would not scan. %s", obscured.DataModulesPercent, obscured.Codewords, errorCorrectionNames[symbol.Level()], obscured.Correctable, suggestion),
+	)
+
+	return diags
+}
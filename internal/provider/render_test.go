@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"testing"
+
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// testRenderPNG renders text as a PNG image of the default size, as qrcode_generate renders it by default.
+func testRenderPNG(t *testing.T, text string) []byte {
+	t.Helper()
+
+	symbol, err := qrgen.Encode(text, qrgen.Options{Level: qrgen.Medium})
+	if err != nil {
+		t.Fatalf("failed to encode %q: %s", text, err)
+	}
+	data, err := symbol.PNG(defaultSize, qrgen.DefaultColors)
+	if err != nil {
+		t.Fatalf("failed to render %q: %s", text, err)
+	}
+
+	return data
+}
+
+// TestAnnotatePNG verifies that annotations are stamped in a strip on the side of their corner,
+// leaving the QR code readable, and that text wider than the image is rejected.
+func TestAnnotatePNG(t *testing.T) {
+	symbol, err := qrgen.Encode("https://example.com", qrgen.Options{Level: qrgen.Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	colors := qrgen.Colors{Dark: color.RGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff}, Light: qrgen.DefaultColors.Light}
+	data, err := symbol.PNG(defaultSize, colors)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	for _, corner := range []string{annotationCornerTopLeft, annotationCornerBottomRight} {
+		t.Run(corner, func(t *testing.T) {
+			annotated, err := annotatePNG(data, "asset-0042", corner, colors)
+			if err != nil {
+				t.Fatalf("failed to annotate: %s", err)
+			}
+			img, err := png.Decode(bytes.NewReader(annotated))
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			if img.Bounds().Dx() != defaultSize || img.Bounds().Dy() <= defaultSize {
+				t.Fatalf("expected a strip to be added below or above the image, got %v", img.Bounds())
+			}
+
+			// Find the text in the strip, on the side of the corner
+			strip := img.Bounds().Dy() - defaultSize
+			top := defaultSize
+			if corner == annotationCornerTopLeft {
+				top = 0
+			}
+			minX, maxX := img.Bounds().Dx(), 0
+			for y := top; y < top+strip; y++ {
+				for x := 0; x < img.Bounds().Dx(); x++ {
+					if color.RGBAModel.Convert(img.At(x, y)) == colors.Dark {
+						minX, maxX = min(minX, x), max(maxX, x)
+					}
+				}
+			}
+			if maxX == 0 || (corner == annotationCornerTopLeft) != (minX < defaultSize/2) || (corner == annotationCornerBottomRight) != (maxX > defaultSize/2) {
+				t.Errorf("expected the text in the %s corner, found it between x %d and %d", corner, minX, maxX)
+			}
+
+			if text, err := decodeQRCodeImage(annotated); err != nil || text != "https://example.com" {
+				t.Errorf("expected the annotated QR code to decode, got %q: %v", text, err)
+			}
+		})
+	}
+
+	if _, err := annotatePNG(data, strings.Repeat("x", 64), annotationCornerBottomRight, colors); err == nil {
+		t.Errorf("expected text wider than the image to be rejected")
+	}
+}
+
+// testLogoPNG encodes a 40 by 20 pixel red logo, which drawLogo scales to the width of its box.
+func testLogoPNG(t *testing.T) []byte {
+	t.Helper()
+
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}), image.Point{}, draw.Src)
+	var data bytes.Buffer
+	if err := png.Encode(&data, logo); err != nil {
+		t.Fatalf("failed to encode the logo: %s", err)
+	}
+
+	return data.Bytes()
+}
+
+// TestDrawLogo verifies that a logo is drawn on a light box at the center of the image, leaving
+// the QR code readable, and that data other than images is rejected.
+func TestDrawLogo(t *testing.T) {
+	data := testRenderPNG(t, "https://example.com")
+
+	drawn, err := drawLogo(data, testLogoPNG(t), defaultLogoSizePercent, qrgen.DefaultColors)
+	if err != nil {
+		t.Fatalf("failed to draw the logo: %s", err)
+	}
+	img, err := png.Decode(bytes.NewReader(drawn))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if img.Bounds().Dx() != defaultSize || img.Bounds().Dy() != defaultSize {
+		t.Fatalf("expected the size of the image to be kept, got %v", img.Bounds())
+	}
+	if r, g, b, _ := img.At(defaultSize/2, defaultSize/2).RGBA(); r>>8 != 0xe5 || g>>8 != 0x39 || b>>8 != 0x35 {
+		t.Errorf("expected the logo at the center, got %v", img.At(defaultSize/2, defaultSize/2))
+	}
+
+	if text, err := decodeQRCodeImage(drawn); err != nil || text != "https://example.com" {
+		t.Errorf("expected the QR code with a logo to decode, got %q: %v", text, err)
+	}
+
+	if _, err := drawLogo(data, []byte("not an image"), defaultLogoSizePercent, qrgen.DefaultColors); err == nil {
+		t.Errorf("expected a logo that is not an image to be rejected")
+	}
+}
+
+// TestLogoDiagnostics verifies that logos are only accepted when the QR code still decodes with
+// them drawn, and that rejected logos suggest the lowest error correction level that restores the
+// codewords they hide.
+func TestLogoDiagnostics(t *testing.T) {
+	const text = "https://example.com/account/settings"
+	logo := testLogoPNG(t)
+	encode := func(level qrgen.Level) (*qrgen.Symbol, error) {
+		return qrgen.Encode(text, qrgen.Options{Level: level})
+	}
+	size := func(*qrgen.Symbol) int { return defaultSize }
+
+	rejected := 0
+	for _, level := range []qrgen.Level{qrgen.Low, qrgen.Medium, qrgen.High, qrgen.Highest} {
+		symbol, err := encode(level)
+		if err != nil {
+			t.Fatalf("failed to encode: %s", err)
+		}
+		data, err := symbol.PNG(defaultSize, qrgen.DefaultColors)
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+
+		for percent := minLogoSizePercent; percent <= maxLogoSizePercent; percent++ {
+			t.Run(fmt.Sprintf("%s at %d%%", errorCorrectionNames[level], percent), func(t *testing.T) {
+				diags := logoDiagnostics(symbol, size, qrgen.ScalingFill, percent, encode)
+				if diags.HasError() {
+					rejected++
+					if detail := diags.Errors()[0].Detail(); level < qrgen.Highest && !strings.Contains(detail, "Set error_correction to") && !strings.Contains(detail, "No higher error correction level") {
+						t.Errorf("expected a suggestion, got %q", detail)
+					}
+					return
+				}
+
+				drawn, err := drawLogo(data, logo, percent, qrgen.DefaultColors)
+				if err != nil {
+					t.Fatalf("failed to draw the logo: %s", err)
+				}
+				if decoded, err := decodeQRCodeImage(drawn); err != nil || decoded != text {
+					t.Errorf("accepted a logo that the QR code does not decode with: %q, %v", decoded, err)
+				}
+			})
+		}
+	}
+	if rejected == 0 {
+		t.Errorf("expected large logos at low levels to be rejected")
+	}
+
+	// Level L restores too little for the default logo, which level M restores
+	symbol, err := encode(qrgen.Low)
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	diags := logoDiagnostics(symbol, size, qrgen.ScalingFill, defaultLogoSizePercent, encode)
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "Set error_correction to M") {
+		t.Errorf("expected level M to be suggested, got %v", diags)
+	}
+}
+
+// TestEncodeContent verifies that compressed and Base45-encoded content decodes to the original,
+// that compression shrinks repetitive text, and that compression bombs are refused.
+func TestEncodeContent(t *testing.T) {
+	text := strings.Repeat(`{"device": "sensor", "interval": 60}`, 40)
+
+	for _, encoding := range []string{"", contentEncodingBase45} {
+		for _, compress := range []bool{false, true} {
+			data := []byte(text)
+			if compress {
+				data = compressContent(data)
+			}
+
+			encoded, err := encodeContent(data, encoding)
+			if err != nil {
+				t.Fatalf("encoding %q, compress %t: failed to encode: %s", encoding, compress, err)
+			}
+			if compress && len(encoded) >= len(text)/4 {
+				t.Errorf("encoding %q: expected compression to shrink %d bytes, got %d", encoding, len(text), len(encoded))
+			}
+			if encoding == contentEncodingBase45 && strings.Trim(encoded, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:") != "" {
+				t.Errorf("expected Base45 text in the alphanumeric character set, got %q", encoded)
+			}
+
+			decoded, err := decodeContent(encoded, encoding, compress)
+			if err != nil {
+				t.Fatalf("encoding %q, compress %t: failed to decode: %s", encoding, compress, err)
+			}
+			if string(decoded) != text {
+				t.Errorf("encoding %q, compress %t: expected the original text, got %q", encoding, compress, decoded)
+			}
+		}
+	}
+
+	jws := "eyJ6aXAiOiJERUYiLCJhbGciOiJFUzI1NiJ9.3ZJNb9swDIb_SsBd_.qz4-K_3Vg"
+	shc, err := encodeContent([]byte(jws), contentEncodingSHC)
+	if err != nil {
+		t.Fatalf("failed to encode SMART Health Card: %s", err)
+	}
+	if !strings.HasPrefix(shc, qrgen.SHCPrefix) || strings.Trim(strings.TrimPrefix(shc, qrgen.SHCPrefix), "0123456789") != "" {
+		t.Errorf("expected the shc:/ prefix followed by digits, got %q", shc)
+	}
+	if decoded, err := decodeContent(shc, contentEncodingSHC, false); err != nil || string(decoded) != jws {
+		t.Errorf("expected the JWS, got %q, %v", decoded, err)
+	}
+	if _, err := encodeContent([]byte("not a JWS"), contentEncodingSHC); err == nil {
+		t.Errorf("expected an error for text that is not a JWS")
+	}
+
+	bomb := qrgen.EncodeBase45(compressContent([]byte(strings.Repeat("0", maxDecompressedContentLength+1))))
+	if _, err := decodeContent(bomb, contentEncodingBase45, true); err == nil {
+		t.Errorf("expected content larger than %d bytes to be refused", maxDecompressedContentLength)
+	}
+	if _, err := decodeContent("not compressed", "", true); err == nil {
+		t.Errorf("expected uncompressed content to be refused")
+	}
+}
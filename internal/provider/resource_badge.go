@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Ensure implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &badgeResource{}
+	_ resource.ResourceWithConfigure = &badgeResource{}
+)
+
+// defaultBadgeDPI is the resolution that PDF badges are sized for by default, which prints a
+// badge of the default width 4 inches wide.
+const defaultBadgeDPI = 150
+
+// badgeResource is the resource implementation.
+type badgeResource struct {
+	fs afero.Fs
+
+	// writeOptions control how files are written.
+	writeOptions writeOptions
+}
+
+// badgeResourceModel maps the qrcode_badge resource schema data.
+type badgeResourceModel struct {
+	Text      types.String `tfsdk:"text"`
+	Name      types.String `tfsdk:"name"`
+	Title     types.String `tfsdk:"title"`
+	Photo     types.String `tfsdk:"photo"`
+	Width     types.Int64  `tfsdk:"width"`
+	Format    types.String `tfsdk:"format"`
+	DPI       types.Int64  `tfsdk:"dpi"`
+	File      types.String `tfsdk:"file"`
+	Overwrite types.Bool   `tfsdk:"overwrite"`
+	SHA256    types.String `tfsdk:"sha256"`
+}
+
+// NewBadgeResource creates a new badge resource instance.
+func NewBadgeResource() resource.Resource {
+	return &badgeResource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the resource type name.
+func (r *badgeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_badge"
+}
+
+// Configure receives the provider-level filesystem.
+func (r *badgeResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.fs = data.Filesystem
+	r.writeOptions = data.WriteOptions
+}
+
+// Schema defines the resource schema.
+func (r *badgeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_badge` resource composes a badge, such as a conference badge, from an optional photo, a name, an optional title and a QR code, centered on white from top to bottom, and saves it as a single PNG or PDF file. Use `for_each` over attendee data to generate a badge per attendee.",
+		Attributes: map[string]schema.Attribute{
+			"text": schema.StringAttribute{
+				Required:    true,
+				Description: "Text content to encode in the QR code, such as a vCard or a registration URL.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name printed in large text below the photo, in printable ASCII characters.",
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 64),
+					stringvalidator.RegexMatches(labelTextPattern, "must consist of printable ASCII characters"),
+				},
+			},
+			"title": schema.StringAttribute{
+				Optional:    true,
+				Description: "Title, such as a role or company, printed in smaller text below the name, in printable ASCII characters.",
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 128),
+					stringvalidator.RegexMatches(labelTextPattern, "must consist of printable ASCII characters"),
+				},
+			},
+			"photo": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a PNG or JPEG photo printed at the top of the badge, scaled to fit half its width. The file is read on the machine running Terraform when the badge is generated, and changes to its content are not detected, so change `photo` or replace the resource to apply them.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"width": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultBadgeWidth),
+				Description: fmt.Sprintf("Width of the badge in pixels, from %d to %d. The QR code and the photo take half of it, and the height follows from the content. Defaults to %d.", minBadgeWidth, maxBadgeWidth, defaultBadgeWidth),
+				Validators: []validator.Int64{
+					int64validator.Between(minBadgeWidth, maxBadgeWidth),
+				},
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(badgeFormatPNG),
+				Description: "Format of the badge file: `png`, or `pdf` for a single page PDF sized by `dpi` that print shops accept. Defaults to `png`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(badgeFormatPNG, badgeFormatPDF),
+				},
+			},
+			"dpi": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultBadgeDPI),
+				Description: fmt.Sprintf("Resolution the badge is printed at, in dots per inch, which sizes the PDF page. Defaults to %d. Only used when `format` is `pdf`.", defaultBadgeDPI),
+				Validators: []validator.Int64{
+					int64validator.Between(72, 2400),
+				},
+			},
+			"file": schema.StringAttribute{
+				Required:    true,
+				Description: "Path to save the generated badge.",
+			},
+			"overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to allow replacing an existing file at `file` when the provider sets `fail_on_overwrite`.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the generated badge file.",
+			},
+		},
+	}
+}
+
+// Create generates a badge and saves it to a file.
+func (r *badgeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	r.create(ctx, req, resp, "")
+}
+
+// create generates a badge and saves it to a file. previousPath is the file written by the
+// resource before, which is replaced even when the provider sets fail_on_overwrite.
+func (r *badgeResource) create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse, previousPath string) {
+	started := time.Now()
+
+	var plan badgeResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Generating badge", map[string]interface{}{
+		"content_length": len(plan.Text.ValueString()),
+		"format":         plan.Format.ValueString(),
+		"file":           plan.File.ValueString(),
+	})
+
+	symbol, err := qrgen.Encode(plan.Text.ValueString(), qrgen.Options{Level: qrgen.Medium})
+	if err != nil {
+		resp.Diagnostics.AddError("Badge Generation Failed", err.Error())
+		return
+	}
+
+	content := badgeContent{
+		Name:   plan.Name.ValueString(),
+		Title:  plan.Title.ValueString(),
+		Symbol: symbol,
+	}
+	if !plan.Photo.IsNull() {
+		photoData, err := afero.ReadFile(r.fs, hostPath(plan.Photo.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("photo"), "Badge Generation Failed", fmt.Sprintf("Could not read photo: %s", err))
+			return
+		}
+		if content.Photo, _, err = image.Decode(bytes.NewReader(photoData)); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("photo"), "Badge Generation Failed", fmt.Sprintf("Could not decode photo: %s", err))
+			return
+		}
+	}
+
+	img, err := renderBadge(content, int(plan.Width.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Badge Generation Failed", err.Error())
+		return
+	}
+
+	data, err := encodeBadge(img, plan.Format.ValueString(), int(plan.DPI.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Badge Generation Failed", err.Error())
+		return
+	}
+
+	// Compute SHA-256 checksum
+	hash := sha256.Sum256(data)
+
+	// Save to file
+	opts := r.writeOptions
+	if plan.File.ValueString() != previousPath {
+		opts = opts.forNewFile(plan.Overwrite)
+	}
+	resp.Diagnostics.Append(saveQRCodeFile(ctx, r.fs, opts, plan.File.ValueString(), data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, plan.File.ValueString(), 1, time.Since(started))...)
+
+	// Set state
+	plan.SHA256 = types.StringValue(hex.EncodeToString(hash[:]))
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read removes the resource from state when the badge file no longer exists.
+func (r *badgeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state badgeResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Files written to memory do not outlive the provider process
+	if isMemoryFilesystem(r.fs) {
+		return
+	}
+
+	if _, err := r.fs.Stat(hostPath(state.File.ValueString())); os.IsNotExist(err) {
+		tflog.Debug(ctx, "Badge file is missing, removing from state", map[string]interface{}{
+			"file": state.File.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+// Update regenerates the badge, replacing the file written before.
+func (r *badgeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state badgeResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.create(ctx, resource.CreateRequest{
+		Plan: req.Plan,
+	}, (*resource.CreateResponse)(resp), state.File.ValueString())
+}
+
+// Delete removes the badge file.
+func (r *badgeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state badgeResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.fs.Remove(hostPath(state.File.ValueString())); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError("Failed to Delete Badge", err.Error())
+		return
+	}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+// TestBadgeResourceCreate verifies that badges with a JPEG photo are saved as PNG and PDF files.
+func TestBadgeResourceCreate(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	r := &badgeResource{fs: fs}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	var photo bytes.Buffer
+	if err := jpeg.Encode(&photo, image.NewGray(image.Rect(0, 0, 120, 160)), nil); err != nil {
+		t.Fatalf("failed to encode photo: %s", err)
+	}
+	if err := afero.WriteFile(fs, "/attendees/ada.jpg", photo.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	testCases := map[string]struct {
+		format   string
+		expected string
+	}{
+		"png": {format: badgeFormatPNG, expected: "\x89PNG"},
+		"pdf": {format: badgeFormatPDF, expected: "%PDF-"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			file := "/badges/ada." + testCase.format
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"text":   tftypes.NewValue(tftypes.String, "https://example.com/attendees/42"),
+				"name":   tftypes.NewValue(tftypes.String, "Ada Lovelace"),
+				"title":  tftypes.NewValue(tftypes.String, "Analytical Engines"),
+				"photo":  tftypes.NewValue(tftypes.String, "/attendees/ada.jpg"),
+				"width":  tftypes.NewValue(tftypes.Number, defaultBadgeWidth),
+				"format": tftypes.NewValue(tftypes.String, testCase.format),
+				"dpi":    tftypes.NewValue(tftypes.Number, defaultBadgeDPI),
+				"file":   tftypes.NewValue(tftypes.String, file),
+			})
+
+			resp := &fwresource.CreateResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: raw}}
+			r.Create(ctx, fwresource.CreateRequest{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw}}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			data, err := afero.ReadFile(fs, file)
+			if err != nil || !bytes.HasPrefix(data, []byte(testCase.expected)) {
+				t.Errorf("expected a %s file, got %.8q: %v", testCase.format, data, err)
+			}
+
+			var state badgeResourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+			if state.SHA256.ValueString() != computeSHA256(string(data)) {
+				t.Errorf("expected the checksum of the file, got %s", state.SHA256.ValueString())
+			}
+		})
+	}
+}
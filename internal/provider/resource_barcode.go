@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+)
+
+// Ensure implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &barcodeResource{}
+	_ resource.ResourceWithValidateConfig = &barcodeResource{}
+	_ resource.ResourceWithConfigure      = &barcodeResource{}
+)
+
+// barcodeResource is the resource implementation.
+type barcodeResource struct {
+	fs afero.Fs
+
+	// writeOptions control how files are written.
+	writeOptions writeOptions
+}
+
+// barcodeResourceModel maps the qrcode_barcode resource schema data.
+type barcodeResourceModel struct {
+	Symbology     types.String `tfsdk:"symbology"`
+	Value         types.String `tfsdk:"value"`
+	ModuleWidth   types.Int64  `tfsdk:"module_width"`
+	BarHeight     types.Int64  `tfsdk:"bar_height"`
+	HumanReadable types.Bool   `tfsdk:"human_readable"`
+	BearerBars    types.Bool   `tfsdk:"bearer_bars"`
+	File          types.String `tfsdk:"file"`
+	Overwrite     types.Bool   `tfsdk:"overwrite"`
+	EncodedValue  types.String `tfsdk:"encoded_value"`
+	SHA256        types.String `tfsdk:"sha256"`
+}
+
+// NewBarcodeResource creates a new barcode resource instance.
+func NewBarcodeResource() resource.Resource {
+	return &barcodeResource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the resource type name.
+func (r *barcodeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_barcode"
+}
+
+// Configure receives the provider-level filesystem.
+func (r *barcodeResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.fs = data.Filesystem
+	r.writeOptions = data.WriteOptions
+}
+
+// Schema defines the resource schema.
+func (r *barcodeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_barcode` resource generates 1D barcodes as PNG images: EAN-13 and UPC-A for retail, Code 39 and ITF-14 for warehouse and logistics systems. GS1 check digits (EAN-13, UPC-A, ITF-14) are computed automatically when omitted and validated when supplied, and the human-readable text can be printed below the bars.",
+		Attributes: map[string]schema.Attribute{
+			"symbology": schema.StringAttribute{
+				Required:    true,
+				Description: "Barcode symbology: ean13, upca, code39 or itf14.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(symbologyEAN13, symbologyUPCA, symbologyCode39, symbologyITF14),
+				},
+			},
+			"value": schema.StringAttribute{
+				Required:    true,
+				Description: "Value to encode. For EAN-13, UPC-A and ITF-14 the check digit may be omitted (12, 11 and 13 digits respectively) and is then computed; when present it is validated. Code 39 accepts upper-case letters, digits, space and `-.$/+%`.",
+			},
+			"module_width": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(3),
+				Description: "Width of the narrowest bar in pixels. Defaults to 3.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 20),
+				},
+			},
+			"bar_height": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(100),
+				Description: "Height of the bars in pixels. Defaults to 100.",
+				Validators: []validator.Int64{
+					int64validator.Between(10, 2000),
+				},
+			},
+			"human_readable": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Set to false to omit the human-readable digits below the bars. Defaults to true.",
+			},
+			"bearer_bars": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Set to true to frame an ITF-14 barcode with bearer bars. Only valid with the itf14 symbology.",
+			},
+			"file": schema.StringAttribute{
+				Required:    true,
+				Description: "Path to save the generated barcode image.",
+			},
+			"overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to allow replacing an existing file at `file` when the provider sets `fail_on_overwrite`.",
+			},
+			"encoded_value": schema.StringAttribute{
+				Computed:    true,
+				Description: "The encoded value, including the check digit where the symbology has one.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the generated barcode image.",
+			},
+		},
+	}
+}
+
+// ValidateConfig rejects bearer bars on symbologies that do not use them.
+func (r *barcodeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config barcodeResourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Symbology.IsUnknown() || config.BearerBars.IsUnknown() {
+		return
+	}
+
+	if config.BearerBars.ValueBool() && config.Symbology.ValueString() != symbologyITF14 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("bearer_bars"),
+			"Invalid Attribute Combination",
+			"Bearer bars are only supported with the itf14 symbology.",
+		)
+	}
+}
+
+// Create generates a barcode and saves it to a file.
+func (r *barcodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	r.create(ctx, req, resp, "")
+}
+
+// create generates a barcode and saves it to a file. previousPath is the file written by the
+// resource before, which is replaced even when the provider sets fail_on_overwrite.
+func (r *barcodeResource) create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse, previousPath string) {
+	started := time.Now()
+
+	var plan barcodeResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Generating barcode", map[string]interface{}{
+		"symbology": plan.Symbology.ValueString(),
+		"file":      plan.File.ValueString(),
+	})
+
+	bc, encodedValue, err := encodeBarcode(plan.Symbology.ValueString(), plan.Value.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Barcode Value", err.Error())
+		return
+	}
+
+	pngData, err := renderBarcodePNG(bc, encodedValue, barcodeRenderOptions{
+		ModuleWidth:   int(plan.ModuleWidth.ValueInt64()),
+		BarHeight:     int(plan.BarHeight.ValueInt64()),
+		HumanReadable: plan.HumanReadable.ValueBool(),
+		BearerBars:    plan.BearerBars.ValueBool(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Barcode Generation Failed", err.Error())
+		return
+	}
+
+	// Compute SHA-256 checksum
+	hash := sha256.Sum256(pngData)
+
+	// Save to file
+	opts := r.writeOptions
+	if plan.File.ValueString() != previousPath {
+		opts = opts.forNewFile(plan.Overwrite)
+	}
+	resp.Diagnostics.Append(saveQRCodeFile(ctx, r.fs, opts, plan.File.ValueString(), pngData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, plan.File.ValueString(), 1, time.Since(started))...)
+
+	// Set state
+	plan.EncodedValue = types.StringValue(encodedValue)
+	plan.SHA256 = types.StringValue(hex.EncodeToString(hash[:]))
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read removes the resource from state when the barcode file no longer exists.
+func (r *barcodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state barcodeResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Files written to memory do not outlive the provider process
+	if isMemoryFilesystem(r.fs) {
+		return
+	}
+
+	if _, err := r.fs.Stat(hostPath(state.File.ValueString())); os.IsNotExist(err) {
+		tflog.Debug(ctx, "Barcode file is missing, removing from state", map[string]interface{}{
+			"file": state.File.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+// Update is identical to Create since barcodes are immutable.
+func (r *barcodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state barcodeResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.create(ctx, resource.CreateRequest{
+		Plan: req.Plan,
+	}, (*resource.CreateResponse)(resp), state.File.ValueString())
+}
+
+// Delete removes the barcode file.
+func (r *barcodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state barcodeResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.fs.Remove(hostPath(state.File.ValueString())); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError("Failed to Delete Barcode", err.Error())
+		return
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// TestAccBarcodeResource verifies the qrcode_barcode resource.
+func TestAccBarcodeResource(t *testing.T) {
+	filePath := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_barcode" "test" {
+						symbology = "ean13"
+						value     = "400638133393"
+						file      = "` + filePath + `"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("qrcode_barcode.test", "encoded_value", "4006381333931"),
+					resource.TestCheckResourceAttrSet("qrcode_barcode.test", "sha256"),
+				),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_barcode" "test" {
+						symbology = "upca"
+						value     = "036000291453"
+						file      = "` + filePath + `"
+					}
+				`,
+				ExpectError: regexp.MustCompile("expected 2"),
+			},
+		},
+	})
+}
@@ -0,0 +1,647 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"aead.dev/minisign"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Ensure implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &qrcodeDirectoryResource{}
+	_ resource.ResourceWithConfigure      = &qrcodeDirectoryResource{}
+	_ resource.ResourceWithValidateConfig = &qrcodeDirectoryResource{}
+)
+
+// qrcodeDirectoryNamePattern matches entry names that are safe to use as file names.
+var qrcodeDirectoryNamePattern = regexp.MustCompile(`^[^/\\]+$`)
+
+// Names of the files that the qrcode_directory resource writes next to its images.
+const (
+	qrcodeDirectoryPDFFileName     = "qrcodes.pdf"
+	qrcodeDirectoryMontageFileName = "montage.png"
+)
+
+// qrcodeDirectoryResource is the resource implementation.
+type qrcodeDirectoryResource struct {
+	fs afero.Fs
+
+	// writeOptions control how files are written.
+	writeOptions writeOptions
+
+	// signingKey signs the manifest, or is nil when manifests are not signed.
+	signingKey *minisign.PrivateKey
+}
+
+// qrcodeDirectoryResourceModel maps the qrcode_directory resource schema data.
+type qrcodeDirectoryResourceModel struct {
+	Directory      types.String                 `tfsdk:"directory"`
+	Contents       types.Map                    `tfsdk:"contents"`
+	Size           types.Int64                  `tfsdk:"size"`
+	Manifest       types.Map                    `tfsdk:"manifest"`
+	WriteManifest  types.Bool                   `tfsdk:"write_manifest"`
+	Overwrite      types.Bool                   `tfsdk:"overwrite"`
+	ManifestSHA256 types.String                 `tfsdk:"manifest_sha256"`
+	ManifestJSON   types.String                 `tfsdk:"manifest_json"`
+	WritePDF       types.Bool                   `tfsdk:"write_pdf"`
+	PDFSHA256      types.String                 `tfsdk:"pdf_sha256"`
+	Montage        *qrcodeDirectoryMontageModel `tfsdk:"montage"`
+	MontageSHA256  types.String                 `tfsdk:"montage_sha256"`
+}
+
+// qrcodeDirectoryMontageModel maps the montage block of the qrcode_directory resource.
+type qrcodeDirectoryMontageModel struct {
+	Columns  types.Int64 `tfsdk:"columns"`
+	Padding  types.Int64 `tfsdk:"padding"`
+	Captions types.Bool  `tfsdk:"captions"`
+}
+
+// NewQRCodeDirectoryResource creates a new QR code directory resource instance.
+func NewQRCodeDirectoryResource() resource.Resource {
+	return &qrcodeDirectoryResource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the resource type name.
+func (r *qrcodeDirectoryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_directory"
+}
+
+// Configure receives the provider-level filesystem.
+func (r *qrcodeDirectoryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.fs = data.Filesystem
+	r.writeOptions = data.WriteOptions
+	r.signingKey = data.ManifestSigningKey
+}
+
+// Schema defines the resource schema.
+func (r *qrcodeDirectoryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_directory` resource owns a directory of QR code images. Each entry of `contents` is written as `<name>.png`, files for entries removed from the map are pruned, and files that are deleted or modified outside Terraform are regenerated on the next apply.",
+		Attributes: map[string]schema.Attribute{
+			"directory": schema.StringAttribute{
+				Required:    true,
+				Description: "Path of the directory to write the QR code images to. Changing this forces a new resource.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"contents": schema.MapAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+				Description: "Map of file name (without the .png extension) to the text content to encode in that QR code.",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(
+						stringvalidator.LengthAtLeast(1),
+						stringvalidator.NoneOf(".", ".."),
+						stringvalidator.RegexMatches(qrcodeDirectoryNamePattern, "must not contain path separators"),
+					),
+				},
+			},
+			"size": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Size of each QR code image in pixels.",
+				Validators: []validator.Int64{
+					int64validator.Between(minSize, maxSize),
+				},
+			},
+			"manifest": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Map of file name to the SHA-256 checksum of the generated QR code image.",
+			},
+			"write_manifest": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to write `manifest.json` to the directory, listing the path, SHA-256 checksum, size and QR code version of every image, so that consumers can verify the images were not modified after apply. When the provider has a `manifest_signing_key`, the manifest is signed and the signature written as `manifest.json.minisig`. A manifest or signature that is deleted or modified outside Terraform is rewritten on the next apply.",
+			},
+			"write_pdf": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to also write `" + qrcodeDirectoryPDFFileName + "` to the directory, with one page per entry in name order, so that a whole batch goes to the print shop as a single file. Each page holds the QR code at `size` points with the entry name printed below it as a caption, in printable ASCII characters. A PDF that is deleted or modified outside Terraform is rewritten on the next apply.",
+			},
+			"pdf_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of `" + qrcodeDirectoryPDFFileName + "`, or null when `write_pdf` is not set.",
+			},
+			"montage_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of `" + qrcodeDirectoryMontageFileName + "`, or null without a `montage` block.",
+			},
+			"overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to allow replacing existing files in `directory` that the resource did not write when the provider sets `fail_on_overwrite`.",
+			},
+			"manifest_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of `manifest.json`, or null when `write_manifest` is not set.",
+			},
+			"manifest_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON manifest listing the `path`, `sha256` checksum, `size` in bytes and QR code `version` of every image, for downstream automation. It is the same document that `write_manifest` writes to `manifest.json`, and is set whether or not the file is written.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"montage": schema.SingleNestedBlock{
+				Description: "Also writes `" + qrcodeDirectoryMontageFileName + "` to the directory, with every QR code in name order laid out in a grid on white, for a quick visual review of a label run before printing. No entry may be named `montage` with this block. A montage that is deleted or modified outside Terraform is rewritten on the next apply.",
+				Attributes: map[string]schema.Attribute{
+					"columns": schema.Int64Attribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Number of QR codes per row. Defaults to `%d`.", defaultMontageColumns),
+						Validators: []validator.Int64{
+							int64validator.Between(1, 100),
+						},
+					},
+					"padding": schema.Int64Attribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Space around and between the QR codes, in pixels. Defaults to `%d`.", defaultMontagePadding),
+						Validators: []validator.Int64{
+							int64validator.Between(0, 1000),
+						},
+					},
+					"captions": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Set to true to print the entry name below each QR code.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig rejects an entry whose image would be overwritten by the montage.
+func (r *qrcodeDirectoryResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config qrcodeDirectoryResourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Montage == nil || config.Contents.IsUnknown() || config.Contents.IsNull() {
+		return
+	}
+
+	name := strings.TrimSuffix(qrcodeDirectoryMontageFileName, ".png")
+	if _, ok := config.Contents.Elements()[name]; ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("contents"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("The image of the entry %q would be overwritten by the montage.", name),
+		)
+	}
+}
+
+// Create writes one QR code image per entry.
+func (r *qrcodeDirectoryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan qrcodeDirectoryResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	started := time.Now()
+	r.write(ctx, &plan, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, plan.Directory.ValueString(), len(plan.Manifest.Elements()), time.Since(started))...)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read drops entries whose files were deleted or modified outside Terraform, so they are regenerated.
+func (r *qrcodeDirectoryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state qrcodeDirectoryResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Files written to memory do not outlive the provider process
+	if isMemoryFilesystem(r.fs) {
+		return
+	}
+
+	contents := map[string]string{}
+	resp.Diagnostics.Append(state.Contents.ElementsAs(ctx, &contents, false)...)
+	manifest := map[string]string{}
+	resp.Diagnostics.Append(state.Manifest.ElementsAs(ctx, &manifest, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for name, expected := range manifest {
+		actual, err := fileSHA256(r.fs, qrcodeDirectoryFilePath(state.Directory.ValueString(), name))
+		if err == nil && actual == expected {
+			continue
+		}
+
+		tflog.Debug(ctx, "QR code file is missing or modified", map[string]interface{}{
+			"directory": state.Directory.ValueString(),
+			"name":      name,
+		})
+		delete(contents, name)
+		delete(manifest, name)
+	}
+
+	// Plan rewriting a manifest that was deleted or modified
+	if state.WriteManifest.ValueBool() {
+		dir := state.Directory.ValueString()
+		actual, err := fileSHA256(r.fs, filepath.Join(dir, manifestFileName))
+		_, sigErr := r.fs.Stat(hostPath(filepath.Join(dir, manifestSignatureFileName)))
+		if err != nil || actual != state.ManifestSHA256.ValueString() || (r.signingKey != nil && sigErr != nil) {
+			tflog.Debug(ctx, "QR code manifest is missing or modified", map[string]interface{}{
+				"directory": dir,
+			})
+			state.WriteManifest = types.BoolNull()
+		}
+	}
+
+	// Plan rewriting a montage that was deleted or modified
+	if state.Montage != nil {
+		actual, err := fileSHA256(r.fs, filepath.Join(state.Directory.ValueString(), qrcodeDirectoryMontageFileName))
+		if err != nil || actual != state.MontageSHA256.ValueString() {
+			tflog.Debug(ctx, "QR code montage is missing or modified", map[string]interface{}{
+				"directory": state.Directory.ValueString(),
+			})
+			state.Montage = nil
+		}
+	}
+
+	// Plan rewriting a PDF that was deleted or modified
+	if state.WritePDF.ValueBool() {
+		actual, err := fileSHA256(r.fs, filepath.Join(state.Directory.ValueString(), qrcodeDirectoryPDFFileName))
+		if err != nil || actual != state.PDFSHA256.ValueString() {
+			tflog.Debug(ctx, "QR code PDF is missing or modified", map[string]interface{}{
+				"directory": state.Directory.ValueString(),
+			})
+			state.WritePDF = types.BoolNull()
+		}
+	}
+
+	state.Contents, diags = types.MapValueFrom(ctx, types.StringType, contents)
+	resp.Diagnostics.Append(diags...)
+	state.Manifest, diags = types.MapValueFrom(ctx, types.StringType, manifest)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update rewrites every entry and prunes files for entries that were removed.
+func (r *qrcodeDirectoryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state qrcodeDirectoryResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := map[string]string{}
+	resp.Diagnostics.Append(state.Manifest.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	started := time.Now()
+	r.write(ctx, &plan, previous, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, plan.Directory.ValueString(), len(plan.Manifest.Elements()), time.Since(started))...)
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes every file in the manifest, and the directory itself when it is left empty.
+func (r *qrcodeDirectoryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state qrcodeDirectoryResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	manifest := map[string]string{}
+	resp.Diagnostics.Append(state.Manifest.ElementsAs(ctx, &manifest, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for name := range manifest {
+		filePath := qrcodeDirectoryFilePath(state.Directory.ValueString(), name)
+		if err := r.fs.Remove(hostPath(filePath)); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError("Failed to Delete QR Code", err.Error())
+		}
+	}
+
+	resp.Diagnostics.Append(r.removeManifest(state.Directory.ValueString())...)
+	for _, name := range []string{qrcodeDirectoryPDFFileName, qrcodeDirectoryMontageFileName} {
+		if err := r.fs.Remove(hostPath(filepath.Join(state.Directory.ValueString(), name))); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError("Failed to Delete QR Code", err.Error())
+		}
+	}
+
+	// Only an empty directory is removed, so files not owned by this resource are kept. A symbolic
+	// link to a directory is left in place, as os.Remove would delete the link regardless.
+	removeEmptyDirectory(ctx, r.fs, state.Directory.ValueString())
+}
+
+// write renders and saves every entry in plan, prunes files listed in previous that are no longer
+// present, and sets the plan manifest.
+func (r *qrcodeDirectoryResource) write(ctx context.Context, plan *qrcodeDirectoryResourceModel, previous map[string]string, diags *diag.Diagnostics) {
+	contents := map[string]string{}
+	diags.Append(plan.Contents.ElementsAs(ctx, &contents, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	size := defaultSize
+	if !plan.Size.IsNull() {
+		size = int(plan.Size.ValueInt64())
+	}
+
+	dir := plan.Directory.ValueString()
+	manifest := make(map[string]string, len(contents))
+	files := make([]manifestFile, 0, len(contents))
+	var pages []qrgen.PDFPage
+	var cells []montageCell
+
+	for name, text := range contents {
+		symbol, err := qrgen.Encode(text, qrgen.Options{Level: qrgen.Medium})
+		if err != nil {
+			diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not generate QR code %q: %s", name, err))
+			return
+		}
+		pngData, err := symbol.PNG(size, qrgen.DefaultColors)
+		if err != nil {
+			diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not generate QR code %q: %s", name, err))
+			return
+		}
+
+		tflog.Debug(ctx, "Rendered QR code PNG", map[string]interface{}{
+			"name":      name,
+			"version":   symbol.Version(),
+			"png_bytes": len(pngData),
+		})
+
+		if plan.WritePDF.ValueBool() {
+			pages = append(pages, qrgen.PDFPage{Symbol: symbol, Caption: name})
+		}
+
+		if plan.Montage != nil {
+			img, err := png.Decode(bytes.NewReader(pngData))
+			if err != nil {
+				diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not generate QR code %q: %s", name, err))
+				return
+			}
+			cells = append(cells, montageCell{Image: img, Caption: name})
+		}
+
+		opts := r.writeOptions
+		if _, ok := previous[name]; !ok {
+			opts = opts.forNewFile(plan.Overwrite)
+		}
+		diags.Append(saveQRCodeFile(ctx, r.fs, opts, qrcodeDirectoryFilePath(dir, name), pngData)...)
+		if diags.HasError() {
+			return
+		}
+
+		hash := sha256.Sum256(pngData)
+		manifest[name] = hex.EncodeToString(hash[:])
+		files = append(files, manifestFile{
+			Path:    name + ".png",
+			SHA256:  manifest[name],
+			Size:    len(pngData),
+			Version: symbol.Version(),
+		})
+	}
+
+	for name := range previous {
+		if _, ok := contents[name]; ok {
+			continue
+		}
+
+		filePath := qrcodeDirectoryFilePath(dir, name)
+		if err := r.fs.Remove(hostPath(filePath)); err != nil && !os.IsNotExist(err) {
+			diags.AddError("Failed to Delete QR Code", err.Error())
+			return
+		}
+
+		tflog.Debug(ctx, "Pruned QR code file", map[string]interface{}{
+			"file": filePath,
+		})
+	}
+
+	diags.Append(r.writePDF(ctx, plan, pages, size, previous == nil)...)
+	if diags.HasError() {
+		return
+	}
+
+	diags.Append(r.writeMontage(ctx, plan, cells, size, previous == nil)...)
+	if diags.HasError() {
+		return
+	}
+
+	var d diag.Diagnostics
+	plan.Manifest, d = types.MapValueFrom(ctx, types.StringType, manifest)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	manifestData, err := buildManifest(files)
+	if err != nil {
+		diags.AddError("Failed to Build Manifest", err.Error())
+		return
+	}
+	plan.ManifestJSON = types.StringValue(string(manifestData))
+
+	plan.ManifestSHA256 = types.StringNull()
+	if !plan.WriteManifest.ValueBool() {
+		diags.Append(r.removeManifest(dir)...)
+		return
+	}
+
+	// The manifest is new when the resource is created
+	manifestOpts := r.writeOptions
+	if previous == nil {
+		manifestOpts = manifestOpts.forNewFile(plan.Overwrite)
+	}
+
+	diags.Append(saveQRCodeFile(ctx, r.fs, manifestOpts, filepath.Join(dir, manifestFileName), manifestData)...)
+	if diags.HasError() {
+		return
+	}
+
+	signaturePath := filepath.Join(dir, manifestSignatureFileName)
+	if r.signingKey != nil {
+		diags.Append(saveQRCodeFile(ctx, r.fs, manifestOpts, signaturePath, minisign.Sign(*r.signingKey, manifestData))...)
+		if diags.HasError() {
+			return
+		}
+	} else if err := r.fs.Remove(hostPath(signaturePath)); err != nil && !os.IsNotExist(err) {
+		// A signature left from an earlier signed manifest would no longer verify
+		diags.AddError("Failed to Delete Manifest Signature", err.Error())
+		return
+	}
+
+	plan.ManifestSHA256 = types.StringValue(computeSHA256(string(manifestData)))
+}
+
+// writePDF saves the pages, one per entry, as a single PDF in the plan directory and sets the plan
+// checksum, or removes the PDF when write_pdf is not set. created is true when the resource is
+// created, so that the PDF is a new file.
+func (r *qrcodeDirectoryResource) writePDF(ctx context.Context, plan *qrcodeDirectoryResourceModel, pages []qrgen.PDFPage, size int, created bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pdfPath := filepath.Join(plan.Directory.ValueString(), qrcodeDirectoryPDFFileName)
+	plan.PDFSHA256 = types.StringNull()
+	if !plan.WritePDF.ValueBool() || len(pages) == 0 {
+		if err := r.fs.Remove(hostPath(pdfPath)); err != nil && !os.IsNotExist(err) {
+			diags.AddError("Failed to Delete PDF", err.Error())
+		}
+		return diags
+	}
+
+	// Pages are in name order, so that the PDF only changes with its content
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Caption < pages[j].Caption
+	})
+
+	opts := r.writeOptions
+	if created {
+		opts = opts.forNewFile(plan.Overwrite)
+	}
+
+	// A PDF of thousands of pages is streamed to the file a page at a time
+	checksum, saveDiags := streamQRCodeFile(ctx, r.fs, opts, pdfPath, func(w io.Writer) error {
+		return qrgen.WritePDFPages(w, pages, size, qrgen.DefaultColors, "")
+	})
+	diags.Append(saveDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	plan.PDFSHA256 = types.StringValue(checksum)
+
+	return diags
+}
+
+// writeMontage saves the cells, one per entry, as a montage in the plan directory and sets the
+// plan checksum, or removes the montage without a montage block. created is true when the resource
+// is created, so that the montage is a new file.
+func (r *qrcodeDirectoryResource) writeMontage(ctx context.Context, plan *qrcodeDirectoryResourceModel, cells []montageCell, size int, created bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	montagePath := filepath.Join(plan.Directory.ValueString(), qrcodeDirectoryMontageFileName)
+	plan.MontageSHA256 = types.StringNull()
+	if plan.Montage == nil || len(cells) == 0 {
+		if err := r.fs.Remove(hostPath(montagePath)); err != nil && !os.IsNotExist(err) {
+			diags.AddError("Failed to Delete Montage", err.Error())
+		}
+		return diags
+	}
+
+	// Cells are in name order, so that the montage only changes with its content
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i].Caption < cells[j].Caption
+	})
+
+	columns := defaultMontageColumns
+	if !plan.Montage.Columns.IsNull() {
+		columns = int(plan.Montage.Columns.ValueInt64())
+	}
+	padding := defaultMontagePadding
+	if !plan.Montage.Padding.IsNull() {
+		padding = int(plan.Montage.Padding.ValueInt64())
+	}
+
+	montage, err := qrgen.EncodePNG(renderMontage(cells, size, columns, padding, plan.Montage.Captions.ValueBool()))
+	if err != nil {
+		diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not generate montage: %s", err))
+		return diags
+	}
+
+	opts := r.writeOptions
+	if created {
+		opts = opts.forNewFile(plan.Overwrite)
+	}
+	diags.Append(saveQRCodeFile(ctx, r.fs, opts, montagePath, montage)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	plan.MontageSHA256 = types.StringValue(computeSHA256(string(montage)))
+
+	return diags
+}
+
+// removeManifest removes the manifest and its signature from dir, if present.
+func (r *qrcodeDirectoryResource) removeManifest(dir string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, name := range []string{manifestFileName, manifestSignatureFileName} {
+		if err := r.fs.Remove(hostPath(filepath.Join(dir, name))); err != nil && !os.IsNotExist(err) {
+			diags.AddError("Failed to Delete Manifest", err.Error())
+		}
+	}
+
+	return diags
+}
+
+// qrcodeDirectoryFilePath returns the path of the image for the named entry.
+func qrcodeDirectoryFilePath(dir, name string) string {
+	return filepath.Join(dir, name+".png")
+}
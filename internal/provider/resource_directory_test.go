@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"aead.dev/minisign"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// TestAccQRCodeDirectoryResource verifies the qrcode_directory resource writes and prunes files.
+func TestAccQRCodeDirectoryResource(t *testing.T) {
+	dir := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				return fmt.Errorf("directory %s still exists", dir)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_directory" "test" {
+						directory = "` + dir + `"
+						contents = {
+							first  = "one"
+							second = "two"
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("qrcode_directory.test", "manifest.%", "2"),
+					resource.TestCheckResourceAttrSet("qrcode_directory.test", "manifest.first"),
+					resource.TestCheckResourceAttrSet("qrcode_directory.test", "manifest.second"),
+				),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_directory" "test" {
+						directory = "` + dir + `"
+						contents = {
+							first = "one"
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("qrcode_directory.test", "manifest.%", "1"),
+					// Verify the removed entry was pruned
+					func(s *terraform.State) error {
+						filePath := filepath.Join(dir, "second.png")
+						if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+							return fmt.Errorf("file %s was not pruned", filePath)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// TestAccQRCodeDirectoryResourceManifest verifies that the manifest lists the images and is signed.
+func TestAccQRCodeDirectoryResourceManifest(t *testing.T) {
+	dir := randomTempFileName()
+
+	publicKey, privateKey, err := minisign.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	keyText, err := privateKey.MarshalText()
+	if err != nil {
+		t.Fatalf("failed to marshal key: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {
+						manifest_signing_key = "` + strings.ReplaceAll(string(keyText), "\n", `\n`) + `"
+					}
+
+					resource "qrcode_directory" "test" {
+						directory      = "` + dir + `"
+						write_manifest = true
+						contents = {
+							first = "one"
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("qrcode_directory.test", "manifest_sha256"),
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["qrcode_directory.test"].Primary.Attributes
+
+						data, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+						if err != nil {
+							return err
+						}
+						var manifest qrcodeManifest
+						if err := json.Unmarshal(data, &manifest); err != nil {
+							return err
+						}
+						if len(manifest.Files) != 1 || manifest.Files[0].Path != "first.png" || manifest.Files[0].SHA256 != attributes["manifest.first"] || manifest.Files[0].Version != 1 {
+							return fmt.Errorf("unexpected manifest %s", data)
+						}
+						if string(data) != attributes["manifest_json"] {
+							return fmt.Errorf("expected manifest_json to match manifest.json, got %s", attributes["manifest_json"])
+						}
+
+						signature, err := os.ReadFile(filepath.Join(dir, manifestSignatureFileName))
+						if err != nil {
+							return err
+						}
+						if !minisign.Verify(publicKey, data, signature) {
+							return fmt.Errorf("manifest signature does not verify")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+
+	// Cleanup the test directory
+	_ = os.RemoveAll(dir)
+}
+
+// TestAccQRCodeDirectoryResourcePDF verifies that the PDF holds a page per entry and is removed
+// when write_pdf is unset.
+func TestAccQRCodeDirectoryResourcePDF(t *testing.T) {
+	dir := randomTempFileName()
+	pdfPath := filepath.Join(dir, qrcodeDirectoryPDFFileName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_directory" "test" {
+						directory = "` + dir + `"
+						write_pdf = true
+						contents = {
+							first  = "one"
+							second = "two"
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("qrcode_directory.test", "pdf_sha256"),
+					func(s *terraform.State) error {
+						data, err := os.ReadFile(pdfPath)
+						if err != nil {
+							return err
+						}
+						for _, expected := range []string{"/Count 2", "(first) Tj", "(second) Tj"} {
+							if !strings.Contains(string(data), expected) {
+								return fmt.Errorf("expected %s to contain %q", pdfPath, expected)
+							}
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_directory" "test" {
+						directory = "` + dir + `"
+						contents = {
+							first  = "one"
+							second = "two"
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("qrcode_directory.test", "pdf_sha256"),
+					func(s *terraform.State) error {
+						if _, err := os.Stat(pdfPath); !os.IsNotExist(err) {
+							return fmt.Errorf("PDF %s was not removed", pdfPath)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// TestAccQRCodeDirectoryResourceMontage verifies that the montage is written and that entries
+// it would overwrite are rejected.
+func TestAccQRCodeDirectoryResourceMontage(t *testing.T) {
+	dir := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_directory" "test" {
+						directory = "` + dir + `"
+						contents = {
+							montage = "one"
+						}
+
+						montage {}
+					}
+				`,
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_directory" "test" {
+						directory = "` + dir + `"
+						contents = {
+							first  = "one"
+							second = "two"
+						}
+
+						montage {
+							columns  = 1
+							captions = true
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("qrcode_directory.test", "montage_sha256"),
+					func(s *terraform.State) error {
+						_, err := os.Stat(filepath.Join(dir, qrcodeDirectoryMontageFileName))
+						return err
+					},
+				),
+			},
+		},
+	})
+}
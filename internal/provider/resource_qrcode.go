@@ -3,201 +3,3032 @@ package provider
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Ensure implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                     = &qrcodeResource{}
+	_ resource.ResourceWithIdentity         = &qrcodeResource{}
+	_ resource.ResourceWithImportState      = &qrcodeResource{}
+	_ resource.ResourceWithModifyPlan       = &qrcodeResource{}
+	_ resource.ResourceWithConfigValidators = &qrcodeResource{}
+	_ resource.ResourceWithConfigure        = &qrcodeResource{}
+	_ resource.ResourceWithValidateConfig   = &qrcodeResource{}
+)
+
+// mmPerInch converts between the width_mm and width_in attributes.
+const mmPerInch = 25.4
+
+// hexColorPattern matches a #RRGGBB hex color.
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// singleLinePattern matches text without line breaks.
+var singleLinePattern = regexp.MustCompile(`^[^\r\n]*$`)
+
+// defaultMinContrastRatio is the smallest contrast ratio between the module colors before a plan
+// warns that the QR code may not scan, the WCAG AA ratio for text.
+const defaultMinContrastRatio = 4.5
+
+// defaultCapacityWarningPercent is the share of the largest QR code's data capacity above which
+// a plan warns that the text is approaching the limit.
+const defaultCapacityWarningPercent = 90.0
+
+// Default smallest module sizes below which a plan warns that the QR code may not scan.
+const (
+	defaultMinModulePixels = 3
+	defaultMinModuleMM     = 0.33
+)
+
+// Behaviors of the on_missing_file attribute when the QR code file disappears outside Terraform.
+const (
+	onMissingFileRecreate = "recreate"
+	onMissingFileRemove   = "remove"
+	onMissingFileError    = "error"
+)
+
+// qrcodeResource is the resource implementation.
+type qrcodeResource struct {
+	fs afero.Fs
+
+	// writeOptions control how files are written.
+	writeOptions writeOptions
+
+	// kubernetes writes images to ConfigMaps and Secrets, or is nil when the provider kubernetes
+	// block is not configured.
+	kubernetes *kubernetesClient
+
+	// consul and vault write images to KV stores, or are nil when the provider consul and vault
+	// blocks are not configured.
+	consul *consulClient
+	vault  *vaultClient
+
+	// jwsSigner signs payloads when sign_jws is set, or is nil when the provider jws_signing_key is
+	// not configured.
+	jwsSigner *jwsSigner
+
+	// styles are the named styles of the provider that style references.
+	styles map[string]qrcodeStyleModel
+
+	// sizeLimits are the sizes that plans accept, as set by the provider min_size and max_size.
+	sizeLimits sizeLimits
+}
+
+// qrcodeResourceModel maps the qrcode_generate resource schema data.
+type qrcodeResourceModel struct {
+	Text                   types.String                  `tfsdk:"text"`
+	SensitiveText          types.String                  `tfsdk:"sensitive_text"`
+	SensitiveTextEnv       types.String                  `tfsdk:"sensitive_text_env"`
+	SensitiveTextPath      types.String                  `tfsdk:"sensitive_text_path"`
+	SensitiveTextSHA256    types.String                  `tfsdk:"sensitive_text_sha256"`
+	ContentJSON            types.Dynamic                 `tfsdk:"content_json"`
+	ContentFile            types.String                  `tfsdk:"content_file"`
+	ContentFileSHA256      types.String                  `tfsdk:"content_file_sha256"`
+	Size                   types.Int64                   `tfsdk:"size"`
+	WidthMM                types.Float64                 `tfsdk:"width_mm"`
+	WidthIn                types.Float64                 `tfsdk:"width_in"`
+	DPI                    types.Int64                   `tfsdk:"dpi"`
+	PixelsPerModule        types.Int64                   `tfsdk:"pixels_per_module"`
+	MinModulePixels        types.Int64                   `tfsdk:"min_module_pixels"`
+	SizeFromModulePx       types.Int64                   `tfsdk:"size_from_module_px"`
+	MinModuleMM            types.Float64                 `tfsdk:"min_module_mm"`
+	QuietZone              types.Int64                   `tfsdk:"quiet_zone"`
+	Strict                 types.Bool                    `tfsdk:"strict"`
+	AllowEmpty             types.Bool                    `tfsdk:"allow_empty"`
+	ForegroundColor        types.String                  `tfsdk:"foreground_color"`
+	BackgroundColor        types.String                  `tfsdk:"background_color"`
+	QuietZoneColor         types.String                  `tfsdk:"quiet_zone_color"`
+	EyeColor               types.String                  `tfsdk:"eye_color"`
+	EyeColorTopLeft        types.String                  `tfsdk:"eye_color_top_left"`
+	EyeColorTopRight       types.String                  `tfsdk:"eye_color_top_right"`
+	EyeColorBottomLeft     types.String                  `tfsdk:"eye_color_bottom_left"`
+	Style                  types.String                  `tfsdk:"style"`
+	StyleSHA256            types.String                  `tfsdk:"style_sha256"`
+	MinContrastRatio       types.Float64                 `tfsdk:"min_contrast_ratio"`
+	CapacityWarningPercent types.Float64                 `tfsdk:"capacity_warning_percent"`
+	Normalize              *qrcodeNormalizeModel         `tfsdk:"normalize"`
+	OTPAuthMigration       *qrcodeOTPAuthMigrationModel  `tfsdk:"otpauth_migration"`
+	SSHKey                 *qrcodeSSHKeyModel            `tfsdk:"ssh_key"`
+	SSHFingerprint         types.String                  `tfsdk:"ssh_fingerprint"`
+	Encrypt                *qrcodeEncryptModel           `tfsdk:"encrypt"`
+	EncryptedSHA256        types.String                  `tfsdk:"encrypted_sha256"`
+	Kubernetes             *qrcodeKubernetesModel        `tfsdk:"kubernetes"`
+	ConsulKV               *qrcodeConsulKVModel          `tfsdk:"consul_kv"`
+	BackgroundImage        *qrcodeBackgroundImageModel   `tfsdk:"background_image"`
+	Annotation             *qrcodeAnnotationModel        `tfsdk:"annotation"`
+	LogoFile               types.String                  `tfsdk:"logo_file"`
+	LogoSizePercent        types.Int64                   `tfsdk:"logo_size_percent"`
+	VaultKV                *qrcodeVaultKVModel           `tfsdk:"vault_kv"`
+	Print                  *qrcodePrintModel             `tfsdk:"print"`
+	File                   types.String                  `tfsdk:"file"`
+	ExpectedSHA256         types.String                  `tfsdk:"expected_sha256"`
+	ShowInDiagnostics      types.Bool                    `tfsdk:"show_in_diagnostics"`
+	ASCIIDarkChar          types.String                  `tfsdk:"ascii_dark_char"`
+	ASCIILightChar         types.String                  `tfsdk:"ascii_light_char"`
+	ASCIIQuietZoneChar     types.String                  `tfsdk:"ascii_quiet_zone_char"`
+	QuietZoneChars         types.Int64                   `tfsdk:"quiet_zone_chars"`
+	OnMissingFile          types.String                  `tfsdk:"on_missing_file"`
+	FollowSymlinks         types.Bool                    `tfsdk:"follow_symlinks"`
+	Overwrite              types.Bool                    `tfsdk:"overwrite"`
+	ForceDelete            types.Bool                    `tfsdk:"force_delete"`
+	VerifyOnRead           types.Bool                    `tfsdk:"verify_on_read"`
+	OptimizeEncoding       types.Bool                    `tfsdk:"optimize_encoding"`
+	ErrorCorrection        types.String                  `tfsdk:"error_correction"`
+	ByteCharset            types.String                  `tfsdk:"byte_charset"`
+	IDNMode                types.String                  `tfsdk:"idn_mode"`
+	ContentEncoding        types.String                  `tfsdk:"content_encoding"`
+	Compress               types.Bool                    `tfsdk:"compress"`
+	ContentEncryption      *qrcodeContentEncryptionModel `tfsdk:"content_encryption"`
+	SignJWS                types.Bool                    `tfsdk:"sign_jws"`
+	Format                 types.String                  `tfsdk:"format"`
+	AltText                types.String                  `tfsdk:"alt_text"`
+	SVGOptimize            types.Bool                    `tfsdk:"svg_optimize"`
+	Captions               types.List                    `tfsdk:"captions"`
+	ESCPOSMode             types.String                  `tfsdk:"escpos_mode"`
+	Interlaced             types.Bool                    `tfsdk:"interlaced"`
+	Rotation               types.Int64                   `tfsdk:"rotation"`
+	Scaling                types.String                  `tfsdk:"scaling"`
+	Metadata               types.Map                     `tfsdk:"metadata"`
+	StripMetadata          types.Bool                    `tfsdk:"strip_metadata"`
+	Reproducible           types.Bool                    `tfsdk:"reproducible"`
+	Sizes                  types.List                    `tfsdk:"sizes"`
+	PrintProfile           types.String                  `tfsdk:"print_profile"`
+	Filename               types.String                  `tfsdk:"filename"`
+	SHA256                 types.String                  `tfsdk:"sha256"`
+	SizesSHA256            types.Map                     `tfsdk:"sizes_sha256"`
+	ContentBase64          types.String                  `tfsdk:"content_base64"`
+	ASCII                  types.String                  `tfsdk:"ascii"`
+	ASCIISHA256            types.String                  `tfsdk:"ascii_sha256"`
+	QRVersion              types.Int64                   `tfsdk:"qr_version"`
+	ErrorCorrectionUsed    types.String                  `tfsdk:"error_correction_used"`
+	ContentSHA256          types.String                  `tfsdk:"content_sha256"`
+	ModuleCount            types.Int64                   `tfsdk:"module_count"`
+	EncodingModeUsed       types.String                  `tfsdk:"encoding_mode_used"`
+	CapacityUsedPercent    types.Float64                 `tfsdk:"capacity_used_percent"`
+	JWS                    types.String                  `tfsdk:"jws"`
+	PrintJobID             types.Int64                   `tfsdk:"print_job_id"`
+
+	// referencedText is the text read from sensitive_text_env or sensitive_text_path, which is
+	// never kept in plan or state.
+	referencedText string
+
+	// fileContent is the content read from content_file.
+	fileContent string
+}
+
+// qrcodeEncryptModel maps the encrypt block of the qrcode_generate resource schema data.
+type qrcodeEncryptModel struct {
+	AgeRecipients types.List `tfsdk:"age_recipients"`
+	PGPPublicKeys types.List `tfsdk:"pgp_public_keys"`
+}
+
+// qrcodeContentEncryptionModel maps the content_encryption block of the qrcode_generate resource
+// schema data.
+type qrcodeContentEncryptionModel struct {
+	AgeRecipients types.List   `tfsdk:"age_recipients"`
+	AESKeyEnv     types.String `tfsdk:"aes_key_env"`
+	AESKeyPath    types.String `tfsdk:"aes_key_path"`
+}
+
+// encrypt encrypts data to the age recipients, or with the AES key read from aes_key_env or
+// aes_key_path.
+func (m *qrcodeContentEncryptionModel) encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	if !m.AgeRecipients.IsNull() {
+		var recipients []string
+		if diags := m.AgeRecipients.ElementsAs(ctx, &recipients, false); diags.HasError() {
+			return nil, fmt.Errorf("invalid age recipients")
+		}
+		return encryptAge(data, recipients)
+	}
+
+	key, err := readAESKey(m.AESKeyEnv.ValueString(), m.AESKeyPath.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	return encryptAESGCM(data, key)
+}
+
+// sizeVariantSHA256s returns the checksums of the size variants written next to the image, by
+// their size in pixels, as kept in sizes_sha256.
+func (m qrcodeResourceModel) sizeVariantSHA256s() map[string]string {
+	checksums := map[string]string{}
+	if m.SizesSHA256.IsNull() || m.SizesSHA256.IsUnknown() {
+		return checksums
+	}
+
+	for key, value := range m.SizesSHA256.Elements() {
+		if checksum, ok := value.(types.String); ok && !checksum.IsNull() && !checksum.IsUnknown() {
+			checksums[key] = checksum.ValueString()
+		}
+	}
+
+	return checksums
+}
+
+// outputPath returns the path of the written QR code image, or an empty string when the image is
+// only kept in state. States written before the filename attribute existed, and freshly imported
+// states, only carry the file path.
+func (m qrcodeResourceModel) outputPath() string {
+	if !m.Filename.IsNull() && !m.Filename.IsUnknown() {
+		return m.Filename.ValueString()
+	}
+	return m.File.ValueString()
+}
+
+// sizeVariantPaths returns the paths of the size variants written next to the image, by their
+// size in pixels as kept in sizes_sha256.
+func (m qrcodeResourceModel) sizeVariantPaths() map[string]string {
+	paths := map[string]string{}
+	filePath := m.outputPath()
+	if filePath == "" || m.SizesSHA256.IsNull() || m.SizesSHA256.IsUnknown() {
+		return paths
+	}
+
+	for key := range m.SizesSHA256.Elements() {
+		size, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		paths[key] = sizeVariantPath(filePath, size)
+	}
+
+	return paths
+}
+
+// content returns the text to encode, normalized and with the host name of URLs converted as
+// configured. Text referenced by sensitive_text_env or sensitive_text_path and the content of
+// content_file must have been read first, and content_json must be known.
+func (m qrcodeResourceModel) content() string {
+	text, _ := m.convertedContent()
+	return text
+}
+
+// convertedContent returns the content with its host name converted to idn_mode, or the content
+// unconverted and the error when the host name cannot be converted.
+func (m qrcodeResourceModel) convertedContent() (string, error) {
+	text := m.SensitiveText.ValueString()
+	switch {
+	case !m.Text.IsNull():
+		text = m.Text.ValueString()
+	case !m.ContentJSON.IsNull():
+		text, _ = canonicalJSON(m.ContentJSON)
+	case !m.ContentFile.IsNull():
+		text = m.fileContent
+	case m.OTPAuthMigration != nil:
+		// Secrets are validated with the configuration
+		text, _ = m.OTPAuthMigration.uri()
+	case m.SSHKey != nil:
+		// Keys are validated with the configuration
+		text, _, _ = m.SSHKey.entry()
+	case m.hasTextReference():
+		text = m.referencedText
+	}
+	text = m.Normalize.apply(text)
+
+	converted, err := convertIDN(text, m.IDNMode.ValueString())
+	if err != nil {
+		return text, err
+	}
+	return converted, nil
+}
+
+// payload returns the text encoded in the QR code: the content, or its JWS when sign_jws is set,
+// compressed, encrypted and encoded as configured. The JWS must have been signed first.
+// Encryption is randomized, so that the payload of encrypted content differs every time.
+func (m qrcodeResourceModel) payload(ctx context.Context) (string, error) {
+	data := []byte(m.content())
+	if m.SignJWS.ValueBool() {
+		data = []byte(m.JWS.ValueString())
+	}
+	if m.Compress.ValueBool() {
+		data = compressContent(data)
+	}
+
+	if m.ContentEncryption != nil {
+		var err error
+		if data, err = m.ContentEncryption.encrypt(ctx, data); err != nil {
+			return "", fmt.Errorf("could not encrypt content: %w", err)
+		}
+	}
+
+	return encodeContent(data, m.ContentEncoding.ValueString())
+}
+
+// hasTextReference reports whether the text is read from sensitive_text_env or
+// sensitive_text_path.
+func (m qrcodeResourceModel) hasTextReference() bool {
+	return !m.SensitiveTextEnv.IsNull() || !m.SensitiveTextPath.IsNull()
+}
+
+// redactor returns the redactor of the text when it is sensitive, set by sensitive_text or read
+// from sensitive_text_env or sensitive_text_path, or nil otherwise. Referenced text is only
+// redacted once it has been read.
+func (m qrcodeResourceModel) redactor() *redactor {
+	switch {
+	case !m.SensitiveText.IsNull() && !m.SensitiveText.IsUnknown():
+		return newRedactor(m.SensitiveText.ValueString(), m.content())
+	case m.hasTextReference():
+		return newRedactor(m.referencedText, m.content())
+	}
+
+	return nil
+}
+
+// resolveTextReference reads the text referenced by sensitive_text_env or sensitive_text_path on
+// the machine running Terraform, and returns its hex-encoded SHA-256 checksum.
+func (m *qrcodeResourceModel) resolveTextReference() (string, error) {
+	if !m.SensitiveTextEnv.IsNull() {
+		name := m.SensitiveTextEnv.ValueString()
+		text, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		m.referencedText = text
+	} else {
+		data, err := os.ReadFile(hostPath(m.SensitiveTextPath.ValueString()))
+		if err != nil {
+			return "", err
+		}
+		m.referencedText = string(data)
+	}
+
+	return computeSHA256(m.referencedText), nil
+}
+
+// readContentFile reads the content of content_file on the machine running Terraform, and returns
+// its hex-encoded SHA-256 checksum.
+func (m *qrcodeResourceModel) readContentFile() (string, error) {
+	content, err := readContentFile(m.ContentFile.ValueString())
+	if err != nil {
+		return "", err
+	}
+	m.fileContent = content
+	return computeSHA256(content), nil
+}
+
+// outputSHA256 returns the checksum of the image as written to file and the configured
+// destinations, which is the ciphertext when encrypt is set.
+func (m qrcodeResourceModel) outputSHA256() string {
+	if m.Encrypt != nil {
+		return m.EncryptedSHA256.ValueString()
+	}
+
+	return m.SHA256.ValueString()
+}
+
+// markOutputsUnknown plans the attributes computed from the text and image as unknown, for a plan that
+// regenerates the image without a change to its configuration.
+func (m *qrcodeResourceModel) markOutputsUnknown() {
+	m.SHA256 = types.StringUnknown()
+	m.SizesSHA256 = types.MapUnknown(types.StringType)
+	m.Filename = types.StringUnknown()
+	m.ContentBase64 = types.StringUnknown()
+	m.ASCII = types.StringUnknown()
+	m.ASCIISHA256 = types.StringUnknown()
+	m.EncryptedSHA256 = types.StringUnknown()
+	m.QRVersion = types.Int64Unknown()
+	m.ErrorCorrectionUsed = types.StringUnknown()
+	m.ContentSHA256 = types.StringUnknown()
+	m.ModuleCount = types.Int64Unknown()
+	m.EncodingModeUsed = types.StringUnknown()
+	m.CapacityUsedPercent = types.Float64Unknown()
+	m.JWS = types.StringUnknown()
+	m.PrintJobID = types.Int64Unknown()
+}
+
+// setSymbolMetadata sets the attributes that describe the encoded symbol.
+func (m *qrcodeResourceModel) setSymbolMetadata(symbol *qrgen.Symbol) {
+	m.QRVersion = types.Int64Value(int64(symbol.Version()))
+	m.ErrorCorrectionUsed = types.StringValue(errorCorrectionNames[symbol.Level()])
+	m.ContentSHA256 = types.StringValue(symbol.ContentSHA256())
+	m.ModuleCount = types.Int64Value(int64(symbol.SymbolModules()))
+	m.EncodingModeUsed = types.StringValue(symbol.Mode())
+	m.CapacityUsedPercent = types.Float64Value(roundPercent(symbol.CapacityUsedPercent()))
+}
+
+// contentKnown reports whether the encoded symbol and its quiet zone are known, which is needed to size the image by
+// pixels_per_module or size_from_module_px.
+func (m qrcodeResourceModel) contentKnown() bool {
+	return m.textKnown() && !m.SensitiveTextEnv.IsUnknown() && !m.SensitiveTextPath.IsUnknown() && !m.OptimizeEncoding.IsUnknown() && !m.ErrorCorrection.IsUnknown() && !m.ByteCharset.IsUnknown() && !m.IDNMode.IsUnknown() && !m.ContentEncoding.IsUnknown() && !m.Compress.IsUnknown() && !m.QuietZone.IsUnknown() && !m.Style.IsUnknown() && m.Normalize.known()
+}
+
+// sizeKnown reports whether the attributes that size the image are known.
+func (m qrcodeResourceModel) sizeKnown() bool {
+	return !m.Size.IsUnknown() && !m.WidthMM.IsUnknown() && !m.WidthIn.IsUnknown() && !m.DPI.IsUnknown() && !m.PixelsPerModule.IsUnknown() && !m.SizeFromModulePx.IsUnknown()
+}
+
+// textKnown reports whether the text to encode is known, including every value in content_json,
+// every account in otpauth_migration and the ssh_key block.
+func (m qrcodeResourceModel) textKnown() bool {
+	if !m.ContentJSON.IsNull() {
+		if _, ok := canonicalJSON(m.ContentJSON); !ok {
+			return false
+		}
+	}
+
+	return !m.Text.IsUnknown() && !m.SensitiveText.IsUnknown() && !m.ContentFile.IsUnknown() && m.OTPAuthMigration.known() && m.SSHKey.known()
+}
+
+// planSSHFingerprint plans the fingerprint of the ssh_key block, which is null without the block
+// and unknown until the key is known.
+func (m *qrcodeResourceModel) planSSHFingerprint(config qrcodeResourceModel) {
+	switch {
+	case config.SSHKey == nil:
+		m.SSHFingerprint = types.StringNull()
+	case config.SSHKey.known():
+		if _, fingerprint, err := config.SSHKey.entry(); err == nil {
+			m.SSHFingerprint = types.StringValue(fingerprint)
+		}
+	default:
+		m.SSHFingerprint = types.StringUnknown()
+	}
+}
+
+// symbolOptions returns the options that the text is encoded with.
+func (m qrcodeResourceModel) symbolOptions() qrgen.Options {
+	// error_correction is validated with the configuration
+	level, _, _ := parseErrorCorrection(m.ErrorCorrection.ValueString())
+	return qrgen.Options{
+		Level:        level,
+		Optimize:     m.OptimizeEncoding.ValueBool(),
+		ByteCharset:  m.ByteCharset.ValueString(),
+		Reproducible: m.Reproducible.ValueBool(),
+	}
+}
+
+// logoSizePercent returns the width of the box that the logo is drawn on, in percent of the image
+// width.
+func (m qrcodeResourceModel) logoSizePercent() int {
+	if m.LogoSizePercent.IsNull() {
+		return defaultLogoSizePercent
+	}
+	return int(m.LogoSizePercent.ValueInt64())
+}
+
+// logoDiagnostics reports a logo that hides more of the symbol than its error correction restores,
+// in an image of the planned size.
+func (m qrcodeResourceModel) logoDiagnostics(ctx context.Context, symbol *qrgen.Symbol, size types.Int64) diag.Diagnostics {
+	if m.LogoFile.IsNull() || m.LogoSizePercent.IsUnknown() || m.Rotation.IsUnknown() || m.Scaling.IsUnknown() || size.IsUnknown() {
+		return nil
+	}
+
+	// Sizes per module follow the symbol, which grows at higher levels
+	imageSize := func(symbol *qrgen.Symbol) int {
+		switch {
+		case !m.PixelsPerModule.IsNull():
+			return symbol.Modules() * int(m.PixelsPerModule.ValueInt64())
+		case !m.SizeFromModulePx.IsNull():
+			return int(sizeFromModulePx(symbol.Modules(), m.SizeFromModulePx.ValueInt64()))
+		}
+		if size.IsNull() {
+			return defaultSize
+		}
+		return int(size.ValueInt64())
+	}
+	rotate := func(symbol *qrgen.Symbol) *qrgen.Symbol {
+		if m.Rotation.IsNull() {
+			return symbol
+		}
+		return symbol.Rotate(int(m.Rotation.ValueInt64()))
+	}
+	encode := func(level qrgen.Level) (*qrgen.Symbol, error) {
+		higher := m
+		higher.ErrorCorrection = types.StringValue(errorCorrectionNames[level])
+		symbol, err := higher.symbol(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return rotate(symbol), nil
+	}
+
+	return logoDiagnostics(rotate(symbol), imageSize, pngScalings[m.Scaling.ValueString()], m.logoSizePercent(), encode)
+}
+
+// sizeFromModulePx returns the size in pixels of an image of the given number of modules,
+// including the quiet zone, sized by size_from_module_px: the default size, grown so that every
+// module is at least modulePx pixels.
+func sizeFromModulePx(modules int, modulePx int64) int64 {
+	return max(int64(defaultSize), int64(modules)*modulePx)
+}
+
+// planPhysicalSize plans the size in pixels and the printed widths from whichever of them is
+// configured, converting with the configured dpi, and checks that the size is within limits.
+// Sizing by pixels_per_module or size_from_module_px needs the number of modules of the encoded symbol,
+// which is zero when it is not known yet.
+func (m *qrcodeResourceModel) planPhysicalSize(config qrcodeResourceModel, modules int, limits sizeLimits) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	smallest, largest := limits.bounds()
+	if !config.Size.IsNull() && !config.Size.IsUnknown() && !limits.contains(config.Size.ValueInt64()) {
+		diags.AddAttributeError(
+			path.Root("size"),
+			"Invalid Size",
+			fmt.Sprintf("Size must be between %d and %d pixels, got %d. The limits are set by the min_size and max_size provider attributes.", smallest, largest, config.Size.ValueInt64()),
+		)
+		return diags
+	}
+
+	m.Size = config.Size
+	m.WidthMM = config.WidthMM
+	m.WidthIn = config.WidthIn
+
+	if !config.PixelsPerModule.IsNull() && !config.PixelsPerModule.IsUnknown() {
+		if modules == 0 {
+			m.Size = types.Int64Unknown()
+		} else {
+			size := int64(modules) * config.PixelsPerModule.ValueInt64()
+			if size > largest {
+				diags.AddAttributeError(
+					path.Root("pixels_per_module"),
+					"Invalid Size",
+					fmt.Sprintf("%d modules of %d pixels is %d pixels; size must be at most %d pixels.", modules, config.PixelsPerModule.ValueInt64(), size, largest),
+				)
+				return diags
+			}
+			m.Size = types.Int64Value(size)
+		}
+	}
+
+	if !config.SizeFromModulePx.IsNull() && !config.SizeFromModulePx.IsUnknown() {
+		if modules == 0 {
+			m.Size = types.Int64Unknown()
+		} else {
+			size := sizeFromModulePx(modules, config.SizeFromModulePx.ValueInt64())
+			if size > largest {
+				diags.AddAttributeError(
+					path.Root("size_from_module_px"),
+					"Invalid Size",
+					fmt.Sprintf("%d modules of at least %d pixels is %d pixels; size must be at most %d pixels.", modules, config.SizeFromModulePx.ValueInt64(), size, largest),
+				)
+				return diags
+			}
+			m.Size = types.Int64Value(size)
+		}
+	}
+
+	if config.Size.IsUnknown() || config.WidthMM.IsUnknown() || config.WidthIn.IsUnknown() || config.DPI.IsUnknown() || config.PixelsPerModule.IsUnknown() || config.SizeFromModulePx.IsUnknown() || m.Size.IsUnknown() {
+		m.Size = types.Int64Unknown()
+		m.WidthMM = types.Float64Unknown()
+		m.WidthIn = types.Float64Unknown()
+		return diags
+	}
+
+	if m.Size.IsNull() && config.WidthMM.IsNull() && config.WidthIn.IsNull() && !limits.contains(defaultSize) {
+		diags.AddAttributeError(
+			path.Root("size"),
+			"Missing Size",
+			fmt.Sprintf("The default size of %d pixels is outside the limits set by the min_size and max_size provider attributes, from %d to %d pixels, so size must be set.", defaultSize, smallest, largest),
+		)
+		return diags
+	}
+
+	if config.DPI.IsNull() {
+		for attribute, width := range map[string]types.Float64{"width_mm": config.WidthMM, "width_in": config.WidthIn} {
+			if !width.IsNull() {
+				diags.AddAttributeError(
+					path.Root(attribute),
+					"Missing DPI",
+					fmt.Sprintf("The dpi attribute must be set to convert %s to pixels.", attribute),
+				)
+			}
+		}
+		return diags
+	}
+
+	dpi := float64(config.DPI.ValueInt64())
+
+	var widthIn float64
+	switch {
+	case !config.WidthMM.IsNull():
+		widthIn = config.WidthMM.ValueFloat64() / mmPerInch
+	case !config.WidthIn.IsNull():
+		widthIn = config.WidthIn.ValueFloat64()
+	default:
+		size := defaultSize
+		if !m.Size.IsNull() {
+			size = int(m.Size.ValueInt64())
+		}
+		widthIn = float64(size) / dpi
+	}
+
+	if m.Size.IsNull() && (!config.WidthMM.IsNull() || !config.WidthIn.IsNull()) {
+		size := int64(math.Round(widthIn * dpi))
+		if !limits.contains(size) {
+			diags.AddAttributeError(
+				path.Root("dpi"),
+				"Invalid Size",
+				fmt.Sprintf("A width of %.2f in at %d dpi is %d pixels; size must be between %d and %d pixels.", widthIn, config.DPI.ValueInt64(), size, smallest, largest),
+			)
+			return diags
+		}
+		m.Size = types.Int64Value(size)
+	}
+	if m.WidthMM.IsNull() {
+		m.WidthMM = types.Float64Value(roundWidth(widthIn * mmPerInch))
+	}
+	if m.WidthIn.IsNull() {
+		m.WidthIn = types.Float64Value(roundWidth(widthIn))
+	}
+
+	return diags
+}
+
+// sizeVariantDiagnostics reports the sizes of the sizes attribute that are outside limits. Sizes
+// that are not known yet are checked when they are.
+func sizeVariantDiagnostics(sizes types.List, limits sizeLimits) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if sizes.IsNull() || sizes.IsUnknown() {
+		return diags
+	}
+
+	smallest, largest := limits.bounds()
+	for i, element := range sizes.Elements() {
+		size, ok := element.(types.Int64)
+		if !ok || size.IsNull() || size.IsUnknown() || limits.contains(size.ValueInt64()) {
+			continue
+		}
+		diags.AddAttributeError(
+			path.Root("sizes").AtListIndex(i),
+			"Invalid Size",
+			fmt.Sprintf("Size must be between %d and %d pixels, got %d. The limits are set by the min_size and max_size provider attributes.", smallest, largest, size.ValueInt64()),
+		)
+	}
+
+	return diags
+}
+
+// symbol encodes the payload and applies the configured quiet zone.
+func (m qrcodeResourceModel) symbol(ctx context.Context) (*qrgen.Symbol, error) {
+	symbol, _, err := m.encode(ctx)
+	return symbol, err
+}
+
+// encode encodes the payload and applies the configured quiet zone, and also returns the payload,
+// for printers that encode it themselves.
+func (m qrcodeResourceModel) encode(ctx context.Context) (*qrgen.Symbol, string, error) {
+	payload, err := m.payload(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var symbol *qrgen.Symbol
+	if _, autoMax, _ := parseErrorCorrection(m.ErrorCorrection.ValueString()); autoMax {
+		symbol, _, err = qrgen.EncodeMaxLevel(payload, m.symbolOptions())
+	} else {
+		symbol, err = qrgen.Encode(payload, m.symbolOptions())
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	tflog.Debug(ctx, "Encoded QR code", map[string]interface{}{
+		"content_length":   len(payload),
+		"version":          symbol.Version(),
+		"mode":             symbol.Mode(),
+		"error_correction": errorCorrectionNames[symbol.Level()],
+	})
+
+	if !m.QuietZone.IsNull() {
+		symbol = symbol.WithQuietZone(int(m.QuietZone.ValueInt64()))
+	}
+
+	return symbol, payload, nil
+}
+
+// colors returns the configured module colors.
+func (m qrcodeResourceModel) colors() (qrgen.Colors, error) {
+	colors := qrgen.DefaultColors
+
+	var err error
+	if !m.ForegroundColor.IsNull() {
+		if colors.Dark, err = qrgen.ParseHexColor(m.ForegroundColor.ValueString()); err != nil {
+			return colors, err
+		}
+	}
+	if !m.BackgroundColor.IsNull() {
+		if colors.Light, err = qrgen.ParseHexColor(m.BackgroundColor.ValueString()); err != nil {
+			return colors, err
+		}
+	}
+	if !m.QuietZoneColor.IsNull() {
+		if colors.QuietZone, err = qrgen.ParseHexColor(m.QuietZoneColor.ValueString()); err != nil {
+			return colors, err
+		}
+	}
+	for corner, eyeColor := range m.eyeColors() {
+		if !eyeColor.IsNull() {
+			if colors.Eyes[corner], err = qrgen.ParseHexColor(eyeColor.ValueString()); err != nil {
+				return colors, err
+			}
+		}
+	}
+
+	return colors, nil
+}
+
+// eyeColors returns the configured colors of the finder patterns, indexed by corner, falling back
+// to eye_color for corners without their own color.
+func (m qrcodeResourceModel) eyeColors() [3]types.String {
+	eyeColors := [3]types.String{
+		qrgen.EyeTopLeft:    m.EyeColorTopLeft,
+		qrgen.EyeTopRight:   m.EyeColorTopRight,
+		qrgen.EyeBottomLeft: m.EyeColorBottomLeft,
+	}
+	for corner, eyeColor := range eyeColors {
+		if eyeColor.IsNull() {
+			eyeColors[corner] = m.EyeColor
+		}
+	}
+
+	return eyeColors
+}
+
+// eyeColorAttribute is an eye color attribute of the qrcode_generate resource.
+type eyeColorAttribute struct {
+	name  string
+	value types.String
+}
+
+// eyeColorAttributes returns the eye color attributes in schema order.
+func (m qrcodeResourceModel) eyeColorAttributes() []eyeColorAttribute {
+	return []eyeColorAttribute{
+		{name: "eye_color", value: m.EyeColor},
+		{name: "eye_color_top_left", value: m.EyeColorTopLeft},
+		{name: "eye_color_top_right", value: m.EyeColorTopRight},
+		{name: "eye_color_bottom_left", value: m.EyeColorBottomLeft},
+	}
+}
+
+// scannabilityDiagnostics reports configurations that produce QR codes which often fail to scan: a
+// quiet zone narrower than the specification requires, colors with too little contrast, and
+// modules smaller than the configured thresholds. They are warnings, or errors in strict mode.
+func (m qrcodeResourceModel) scannabilityDiagnostics(modules int) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Strict.IsUnknown() {
+		return diags
+	}
+
+	report := diags.AddAttributeWarning
+	if m.Strict.ValueBool() {
+		report = diags.AddAttributeError
+	}
+
+	if !m.QuietZone.IsNull() && !m.QuietZone.IsUnknown() && m.QuietZone.ValueInt64() < qrgen.QuietZoneModules {
+		report(
+			path.Root("quiet_zone"),
+			"Quiet Zone Too Narrow",
+			fmt.Sprintf("The quiet zone is %d modules wide, while the QR code specification requires at least %d. Scanners may fail to find the QR code unless it is placed on a wide enough light background.", m.QuietZone.ValueInt64(), qrgen.QuietZoneModules),
+		)
+	}
+
+	if !m.ForegroundColor.IsUnknown() && !m.BackgroundColor.IsUnknown() && !m.QuietZoneColor.IsUnknown() && !m.MinContrastRatio.IsUnknown() {
+		minContrastRatio := defaultMinContrastRatio
+		if !m.MinContrastRatio.IsNull() {
+			minContrastRatio = m.MinContrastRatio.ValueFloat64()
+		}
+
+		// Colors that fail to parse are reported by the attribute validators
+		if colors, err := m.colors(); err == nil {
+			if ratio := qrgen.ContrastRatio(colors.Dark, colors.Light); ratio < minContrastRatio {
+				report(
+					path.Root("foreground_color"),
+					"QR Code May Not Scan",
+					fmt.Sprintf("The contrast ratio between %s and %s is %.2f, which is below the minimum of %.2f. Use a darker foreground or a lighter background color.", qrgen.HexColor(colors.Dark), qrgen.HexColor(colors.Light), ratio, minContrastRatio),
+				)
+			}
+
+			// Scanners find the finder patterns by their edge against the quiet zone
+			if ratio := qrgen.ContrastRatio(colors.Dark, colors.QuietZone); !m.QuietZoneColor.IsNull() && ratio < minContrastRatio {
+				report(
+					path.Root("quiet_zone_color"),
+					"QR Code May Not Scan",
+					fmt.Sprintf("The contrast ratio between %s and the quiet zone color %s is %.2f, which is below the minimum of %.2f. Use a darker foreground or a lighter quiet zone color.", qrgen.HexColor(colors.Dark), qrgen.HexColor(colors.QuietZone), ratio, minContrastRatio),
+				)
+			}
+
+			// Scanners locate the QR code by its finder patterns before reading any data
+			for _, attribute := range m.eyeColorAttributes() {
+				if attribute.value.IsNull() || attribute.value.IsUnknown() {
+					continue
+				}
+				eye, err := qrgen.ParseHexColor(attribute.value.ValueString())
+				if ratio := qrgen.ContrastRatio(eye, colors.Light); err == nil && ratio < minContrastRatio {
+					report(
+						path.Root(attribute.name),
+						"QR Code May Not Scan",
+						fmt.Sprintf("The contrast ratio between the eye color %s and %s is %.2f, which is below the minimum of %.2f. Use a darker eye color or a lighter background color.", qrgen.HexColor(eye), qrgen.HexColor(colors.Light), ratio, minContrastRatio),
+					)
+				}
+			}
+		}
+	}
+
+	if modules == 0 || m.Size.IsUnknown() || m.MinModulePixels.IsUnknown() || m.MinModuleMM.IsUnknown() || m.Format.IsUnknown() {
+		return diags
+	}
+
+	size := defaultSize
+	if !m.Size.IsNull() {
+		size = int(m.Size.ValueInt64())
+	}
+	modulePixels := float64(size) / float64(modules)
+
+	// Vector images scale without losing detail, so only PNG modules have a pixel size
+	minModulePixels := int64(defaultMinModulePixels)
+	if !m.MinModulePixels.IsNull() {
+		minModulePixels = m.MinModulePixels.ValueInt64()
+	}
+	if format := m.Format.ValueString(); (format == "" || format == imageFormatPNG) && modulePixels < float64(minModulePixels) {
+		report(
+			path.Root("size"),
+			"QR Code May Not Scan",
+			fmt.Sprintf("The QR code has %d modules across %d pixels, %.2f pixels per module, which is below the minimum of %d. Increase the size, or shorten the content to reduce the number of modules.", modules, size, modulePixels, minModulePixels),
+		)
+	}
+
+	if m.DPI.IsNull() || m.DPI.IsUnknown() {
+		return diags
+	}
+
+	minModuleMM := defaultMinModuleMM
+	if !m.MinModuleMM.IsNull() {
+		minModuleMM = m.MinModuleMM.ValueFloat64()
+	}
+	if moduleMM := modulePixels / float64(m.DPI.ValueInt64()) * mmPerInch; moduleMM < minModuleMM {
+		report(
+			path.Root("dpi"),
+			"QR Code May Not Scan",
+			fmt.Sprintf("The printed QR code has modules of %.2f mm, which is below the minimum of %.2f mm. Increase the printed width, or shorten the content to reduce the number of modules.", moduleMM, minModuleMM),
+		)
+	}
+
+	return diags
+}
+
+// capacityDiagnostics warns when the symbol uses more than threshold percent of the largest QR
+// code's data capacity, so that content which grows over time is noticed before encoding fails.
+// A null threshold uses the default.
+func capacityDiagnostics(symbol *qrgen.Symbol, threshold types.Float64) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if threshold.IsUnknown() {
+		return diags
+	}
+
+	warningPercent := defaultCapacityWarningPercent
+	if !threshold.IsNull() {
+		warningPercent = threshold.ValueFloat64()
+	}
+
+	if used := symbol.CapacityUsedPercent(); used > warningPercent {
+		diags.AddAttributeWarning(
+			path.Root("capacity_warning_percent"),
+			"QR Code Near Capacity",
+			fmt.Sprintf("The content is encoded as a version %d symbol and takes %.2f%% of the data capacity of the largest QR code, above the warning threshold of %.2f%%. Encoding fails once the content exceeds the capacity. Shorten the content, for example with a URL shortener, before it grows further.", symbol.Version(), used, warningPercent),
+		)
+	}
+
+	return diags
+}
+
+// roundWidth rounds a computed printed width to hundredths, so that it reads naturally in plans.
+func roundWidth(width float64) float64 {
+	return math.Round(width*100) / 100
+}
+
+// roundPercent rounds a computed percentage to hundredths, so that it reads naturally in plans.
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*100) / 100
+}
+
+// qrcodeResourceIdentityModel maps the qrcode_generate resource identity data.
+type qrcodeResourceIdentityModel struct {
+	File types.String `tfsdk:"file"`
+}
+
+// NewQRCodeResource creates a new QR code resource instance.
+func NewQRCodeResource() resource.Resource {
+	return &qrcodeResource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the resource type name.
+func (r *qrcodeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_generate"
+}
+
+// Configure receives the provider-level filesystem.
+func (r *qrcodeResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.fs = data.Filesystem
+	r.writeOptions = data.WriteOptions
+	r.kubernetes = data.Kubernetes
+	r.consul = data.Consul
+	r.vault = data.Vault
+	r.jwsSigner = data.JWSSigner
+	r.styles = data.Styles
+	r.sizeLimits = data.SizeLimits
+}
+
+// Schema defines the resource schema.
+func (r *qrcodeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_generate` resource allows you to create QR codes from text input. This is useful for encoding information such as URLs, authentication keys, or configuration details into a scannable format. The QR codes can be generated in PNG, SVG or PDF format and saved to a specified file path, or displayed in ASCII format for terminal-based use.",
+		Attributes: map[string]schema.Attribute{
+			"text": schema.StringAttribute{
+				Optional:    true,
+				Description: "The text content to encode in the QR code.",
+			},
+			"sensitive_text": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Sensitive text content to encode in the QR code. Error and warning messages that would quote it, or text read from `sensitive_text_env` or `sensitive_text_path`, give its length and SHA-256 checksum instead.",
+			},
+			"content_json": schema.DynamicAttribute{
+				Optional:    true,
+				Description: "Value to encode as canonical JSON, such as an HCL object. Object keys and set elements are sorted, no whitespace is added and numbers are written in their shortest exact form, so that semantically identical values always encode the same and never change the image or its checksums.",
+			},
+			"content_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a file whose content is encoded in the QR code, such as a vCard, read on the machine running Terraform. Its checksum is kept in `content_file_sha256`, so that a plan regenerates the image when the file is edited outside Terraform.",
+			},
+			"content_file_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the content read from `content_file`, as of the last plan. A plan that finds a different checksum regenerates the image. Null unless `content_file` is set.",
+			},
+			"sensitive_text_env": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of an environment variable holding the text to encode, as an alternative to `sensitive_text` that keeps the text out of plan and state. The variable is read on the machine running Terraform, and only its checksum is kept, in `sensitive_text_sha256`, so that changes are detected. `ascii`, which would reveal the text, is null, as is `content_base64` unless `encrypt` is set, and `show_in_diagnostics` cannot be set.",
+			},
+			"sensitive_text_path": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a file holding the text to encode, as an alternative to `sensitive_text` that keeps the text out of plan and state. The file is read on the machine running Terraform, and only its checksum is kept, in `sensitive_text_sha256`, so that changes are detected. `ascii`, which would reveal the text, is null, as is `content_base64` unless `encrypt` is set, and `show_in_diagnostics` cannot be set.",
+			},
+			"sensitive_text_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the text read from `sensitive_text_env` or `sensitive_text_path`. A plan that finds a different checksum regenerates the image. Null when the text is configured directly.",
+			},
+			"size": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: fmt.Sprintf("Size of the QR code image in pixels, from %d to %d unless the provider sets `min_size` or `max_size`. Defaults to `%d`. Computed from `width_mm` or `width_in` and `dpi` when the size is given as a physical width instead, and from `pixels_per_module` and the number of modules when the size is given per module. SVG images scale to the size they are shown at, so `size` cannot be set when `format` is `svg`.", minSize, maxSize, defaultSize),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"width_mm": schema.Float64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Printed width of the QR code image in millimeters, as an alternative to `size`. Requires `dpi`. Computed from `size` and `dpi` when `dpi` is set.",
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"width_in": schema.Float64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Printed width of the QR code image in inches, as an alternative to `size`. Requires `dpi`. Computed from `size` and `dpi` when `dpi` is set.",
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"pixels_per_module": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Size of each module in pixels, as an alternative to `size`. Every module is scaled by the same whole number of pixels, so the image has no resampling artifacts. The resulting image size, which depends on the encoded content, is recorded in `size`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"min_module_pixels": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Smallest module size in pixels before the plan warns that the PNG image may not scan. Defaults to `3`. Only warns and never changes the image; use `size_from_module_px` to size the image so that its modules are at least that large.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"size_from_module_px": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Module size in pixels that the image is sized from, as an alternative to `size`. The image is `%d` pixels, grown to the number of modules times `size_from_module_px` when the symbol needs more, so that codes stay scannable however long the text turns out to be at apply time. The resulting image size is recorded in `size`. Unlike `min_module_pixels`, which only warns when the modules of an image of the given `size` are smaller than a threshold, this sets the size of the image.", defaultSize),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"min_module_mm": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Smallest printed module size in millimeters before the plan warns that the QR code may not scan. Only checked when `dpi` is set. Defaults to `0.33`.",
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"quiet_zone": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Width of the light border around the QR code, in modules. The QR code specification requires at least `4`, so narrower borders are reported at plan time. Defaults to `4`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"quiet_zone_chars": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Width of the quiet zone around `ascii`, in modules, independent of `quiet_zone`, so that the text rendering keeps a visible border when pasted onto dark or colored backgrounds, such as dark terminals, even where the image has a narrow one. A module is one character wide in the default half blocks, and two characters wide when `ascii_dark_char`, `ascii_light_char` or `ascii_quiet_zone_char` is set. Defaults to `quiet_zone`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"allow_empty": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to generate a QR code of empty content. By default, empty content, such as the result of a lookup that found nothing, fails the plan, so that a blank QR code is never printed. Content of only whitespace is encoded as is.",
+			},
+			"strict": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to fail the plan, instead of warning, when the QR code may not scan: when `quiet_zone` is narrower than the specification requires, modules are smaller than `min_module_pixels` or `min_module_mm`, or the colors contrast less than `min_contrast_ratio`.",
+			},
+			"style": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of a provider `style` block to take the colors and quiet zone from, such as `brand_dark`. The attributes the resource sets itself win over the style. A change to the style regenerates the image.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"foreground_color": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the dark modules, as a `#RRGGBB` hex color. Defaults to `#000000`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"background_color": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the light modules, and of the quiet zone unless `quiet_zone_color` is set, as a `#RRGGBB` hex color. Defaults to `#ffffff`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"quiet_zone_color": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the quiet zone, as a `#RRGGBB` hex color, for QR codes on a colored `background_color` that need a white quiet zone to scan reliably. The margin that `scaling` `fit` leaves around the symbol is drawn in it too. Defaults to `background_color`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"eye_color": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the dark modules of the three finder patterns, the \"eyes\" in the corners of the QR code, as a `#RRGGBB` hex color, so that they can carry a brand color while the data modules stay `foreground_color`. Defaults to `foreground_color`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"eye_color_top_left": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the dark modules of the top left finder pattern, as a `#RRGGBB` hex color. Defaults to `eye_color`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"eye_color_top_right": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the dark modules of the top right finder pattern, as a `#RRGGBB` hex color. Defaults to `eye_color`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"eye_color_bottom_left": schema.StringAttribute{
+				Optional:    true,
+				Description: "Color of the dark modules of the bottom left finder pattern, as a `#RRGGBB` hex color. Defaults to `eye_color`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+				},
+			},
+			"min_contrast_ratio": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Smallest WCAG contrast ratio between `foreground_color` and `background_color`, or `quiet_zone_color`, and between the eye colors and `background_color`, before the plan warns that the QR code may not scan, from `1` for equal colors to `21` for black and white. Defaults to `4.5`.",
+				Validators: []validator.Float64{
+					float64validator.Between(1, 21),
+				},
+			},
+			"capacity_warning_percent": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Share of the data capacity of the largest QR code, in percent, above which the plan warns that the content is approaching the limit where generation fails. Compare with `capacity_used_percent`. Defaults to `90`.",
+				Validators: []validator.Float64{
+					float64validator.Between(0, 100),
+				},
+			},
+			"dpi": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Print resolution in dots per inch, used to convert between `size` and the printed width. PDF pages are sized to the printed width when it is set.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to save the generated QR code image. If the path ends with a path separator or is an existing directory, the image is saved in that directory as `<sha256-prefix>.<format>`, named after its content. If omitted, the image is only kept in state as `content_base64`. Changing only `file` moves the written image and its `sizes` copies to the new path with the same bytes, rather than generating them again, as long as they still have the checksums they were written with. An image moved from a named file into a directory is generated again, to be named after its content.",
+			},
+			"expected_sha256": schema.StringAttribute{
+				Optional:    true,
+				Description: "Expected SHA-256 checksum of the generated QR code image. If set, the apply fails when the generated image does not match, pinning a known-good rendering.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(sha256Pattern, "must be a lowercase hex-encoded SHA-256 checksum"),
+				},
+			},
+			"show_in_diagnostics": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to print the ASCII rendering of the QR code as a warning when it is generated, so it can be scanned straight from the terminal. The rendering encodes the content, including `sensitive_text`, so only enable this where the apply output is not shared. Cannot be combined with `encrypt`, since the rendering is not encrypted, or with `sensitive_text_env` and `sensitive_text_path`, whose text it would reveal.",
+			},
+			"ascii_dark_char": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Character that dark modules are drawn with in `ascii`, such as `#`. When any of `ascii_dark_char`, `ascii_light_char` and `ascii_quiet_zone_char` is set, every module is drawn as two characters on a line per module row, instead of half blocks packing two module rows per line, for monospaced email templates and chat code blocks where block characters render poorly. Defaults to `%s`.", defaultASCIIDarkChar),
+				Validators: []validator.String{
+					stringvalidator.UTF8LengthBetween(1, 1),
+				},
+			},
+			"ascii_light_char": schema.StringAttribute{
+				Optional:    true,
+				Description: "Character that light modules are drawn with in `ascii`, such as `.`. See `ascii_dark_char`. Defaults to a space.",
+				Validators: []validator.String{
+					stringvalidator.UTF8LengthBetween(1, 1),
+				},
+			},
+			"ascii_quiet_zone_char": schema.StringAttribute{
+				Optional:    true,
+				Description: "Character that the quiet zone around the symbol is drawn with in `ascii`, so that the border stays visible where spaces are trimmed or blend into the background. See `ascii_dark_char`. Defaults to `ascii_light_char`.",
+				Validators: []validator.String{
+					stringvalidator.UTF8LengthBetween(1, 1),
+				},
+			},
+			"on_missing_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "What to do when the saved QR code image is missing on refresh: `remove` drops the resource from state so it is planned for creation, `recreate` keeps it in state and plans an update that writes the file again, and `error` fails the refresh. Defaults to `remove`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(onMissingFileRecreate, onMissingFileRemove, onMissingFileError),
+				},
+			},
+			"verify_on_read": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to decode the saved image on every refresh and check that it still encodes the text, so that an image swapped outside Terraform, such as a payment QR code pointing elsewhere, is planned to be written again. Only PNG images are verified, and not when `encrypt` or a `byte_charset` other than UTF-8 is set, or when text read from `sensitive_text_env`, `sensitive_text_path` or `content_file` is normalized.",
+			},
+			"follow_symlinks": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to write the image through `file` to its target when `file` is a symbolic link. By default the link is replaced with the image. Deleting the resource never follows links, so only the link itself is removed and its target is left untouched.",
+			},
+			"overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to allow replacing an existing file at `file` when the provider sets `fail_on_overwrite`.",
+			},
+			"force_delete": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to delete the files of the QR code on destroy even when their checksum no longer matches the state, such as when another process wrote its own file to the same path. By default, destroy fails rather than delete a file it did not write. Like other attributes, it must be applied before it takes effect on destroy.",
+			},
+			"error_correction": schema.StringAttribute{
+				Optional:    true,
+				Description: "Error correction level: L (low), M (medium, default), Q (high), H (highest), or `auto_max` for the highest level that still fits the content in the version that M needs, so that printed codes tolerate the most damage without growing. The level used is exported in `error_correction_used`.",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive("L", "M", "Q", "H", errorCorrectionAutoMax),
+				},
+			},
+			"optimize_encoding": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to split the text into the numeric, alphanumeric, byte and kanji mode segments with the shortest encoding, giving the smallest possible symbol. Kanji and other double-byte Shift_JIS characters are encoded in kanji mode, roughly halving the symbol size for Japanese payloads.",
+			},
+			"content_encoding": schema.StringAttribute{
+				Optional:    true,
+				Description: "Encoding applied to the bytes of the text, after `normalize`, before they are encoded in the QR code: `base45`, the Base45 encoding of RFC 9285 used by EU Digital COVID Certificates and other schemes that carry binary data in QR codes, which encodes in the compact alphanumeric mode, or `shc`, the SMART Health Card encoding of a compact JWS, such as a health card issued by your signing service or the JWS of `sign_jws`, as the `shc:/` prefix followed by two digits per character, which encodes in numeric mode as the specification requires. Only single-chunk cards are encoded, and the apply fails when the text contains characters that cannot appear in a JWS. Binary data can be read with `sensitive_text_path`. Set the `content_encoding` of the `qrcode_verify` data source to decode it.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(contentEncodingBase45, contentEncodingSHC),
+				},
+			},
+			"sign_jws": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to encode the text as a compact JWS signed with the provider `jws_signing_key`, so that scanning apps can verify that a QR code, such as a device provisioning code, was issued by you. The text is the JWS payload after `normalize`, and the JWS is compressed, encrypted and encoded as configured. ECDSA signatures are randomized, so the image changes every time it is written with a P-256 or P-384 key.",
+			},
+			"compress": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to compress the bytes of the text with zlib, after `normalize` and before `content_encoding`, so that larger text fits a single symbol, as EU Digital COVID Certificates do. Compressed data is binary, so `content_encoding` is required, and it cannot be combined with `reproducible`, since its output depends on the Go standard library. Set the `compress` of the `qrcode_verify` data source to decompress it.",
+			},
+			"idn_mode": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Form that the host name of a URL text, such as `https://bücher.example/`, is converted to before it is encoded: `%s`, the ASCII form `xn--bcher-kva.example` that every scanner opens, or `%s`, the form that browsers display. The host name is validated with the IDNA lookup rules that browsers apply, and the rest of the URL is encoded as written. Host names already in the form, IP addresses and text without a `scheme://` authority are left unchanged. Applied after `normalize`.", idnModePunycode, idnModeUnicode),
+				Validators: []validator.String{
+					stringvalidator.OneOf(idnModePunycode, idnModeUnicode),
+				},
+			},
+			"byte_charset": schema.StringAttribute{
+				Optional:    true,
+				Description: "Character set that byte mode data is transcoded to before encoding, without an ECI header: `UTF-8`, `Shift_JIS` or `ISO-8859-1`. Use it for legacy scanners that assume one of these character sets. The apply fails when the text contains characters the character set cannot represent. Defaults to `UTF-8`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(qrgen.ByteCharsetUTF8, qrgen.ByteCharsetShiftJIS, qrgen.ByteCharsetLatin1),
+				},
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Description: "Image format: `png`, `svg`, `pdf`, the label printer formats `zpl`, `epl` and `tspl`, or `escpos` for receipt printers. Defaults to `png`. PDF output is a single page of `size` points, or of the printed width when `dpi` is set, with the modules drawn as vector rectangles. Printer output can be sent to the printer as is: `zpl` is a ZPL II label for Zebra printers, `epl` an EPL2 label for Eltron and older Zebra printers, `tspl` a TSPL/TSPL2 label for TSC and compatible printers, and `escpos` the ESC/POS commands of point-of-sale receipt printers, printed as set by `escpos_mode` and followed by a paper cut. The code is drawn as a monochrome graphic of at most `size` dots, scaled by a whole number of dots per module, followed by the `captions`, and colors are ignored. The output is kept in `content_base64`, for sending to a printer at apply time without a file.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(imageFormatPNG, imageFormatSVG, imageFormatPDF, imageFormatZPL, imageFormatEPL, imageFormatTSPL, imageFormatESCPOS),
+				},
+			},
+			"alt_text": schema.StringAttribute{
+				Optional:    true,
+				Description: "Text alternative of the QR code, written to the SVG `<title>` element so that screen readers can announce the image. Describe what the code is for, such as `Guest WiFi login`. Defaults to `QR code`; the encoded content is never used, as it may be sensitive. Only used when `format` is `svg`.",
+			},
+			"logo_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a PNG or JPEG logo drawn over the center of the QR code on a box of `background_color`, read on the machine running Terraform when the QR code is generated. The box hides the modules under it, which the error correction restores, and the plan fails when it hides more than the `error_correction` level restores. Changes to the content of the file are not detected, so change `logo_file` or replace the resource to apply them. Can only be used with the `png` format, and cannot be combined with `interlaced`, `reproducible` or `background_image`.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"logo_size_percent": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Width of the box that `logo_file` is drawn on, as a share of the image width in percent, from %d to %d. Defaults to `%d`.", minLogoSizePercent, maxLogoSizePercent, defaultLogoSizePercent),
+				Validators: []validator.Int64{
+					int64validator.Between(minLogoSizePercent, maxLogoSizePercent),
+					int64validator.AlsoRequires(path.MatchRoot("logo_file")),
+				},
+			},
+			"captions": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Lines of text printed below the QR code in the printer's built-in font, such as an asset tag. Requires a printer format. EPL2 and ESC/POS captions must be printable ASCII.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(
+						stringvalidator.LengthAtLeast(1),
+						stringvalidator.RegexMatches(singleLinePattern, "must be a single line"),
+					),
+				},
+			},
+			"escpos_mode": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("How `escpos` output prints the QR code: `%s` has the printer encode the text with the `GS ( k` QR code commands, in modules of the dots per module that fit `size`, up to 16, and `%s` prints the modules of the symbol as a `GS v 0` raster image, for printers without QR code support. Printers may choose a different version and mask pattern than `content_sha256` describes, so `%s` cannot be combined with `rotation` or `byte_charset`. Defaults to `%s`.", escposModeQR, escposModeRaster, escposModeQR, escposModeQR),
+				Validators: []validator.String{
+					stringvalidator.OneOf(escposModeQR, escposModeRaster),
+				},
+			},
+			"svg_optimize": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to draw the SVG modules as a single path of merged rectangles instead of one square per module, which keeps large symbols to a few kilobytes. Only used when `format` is `svg`.",
+			},
+			"rotation": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Clockwise rotation of the QR code in degrees: `90`, `180` or `270`, for label printers that feed sideways. The modules are moved rather than resampled, so PNG, SVG and PDF output stays as crisp as upright images, unlike rotation in a printer driver. The eye colors follow their finder patterns. `ascii` and `content_sha256` describe the upright symbol.",
+				Validators: []validator.Int64{
+					int64validator.OneOf(90, 180, 270),
+				},
+			},
+			"interlaced": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to encode the PNG image with Adam7 interlacing, for progressive-loading systems that require interlaced images and would otherwise re-encode them, changing their checksums. Only used when `format` is `png`.",
+			},
+			"metadata": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Map of keyword to text written to the PNG image as text chunks, such as `Author` or an asset ID, in keyword order. Values in Latin-1 are written as `tEXt` chunks and others as UTF-8 `iTXt` chunks. Keywords are printable ASCII, from 1 to 79 characters without leading, trailing or consecutive spaces. The text is readable by anyone with the image, so do not include secrets. Only used when `format` is `png`.",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(
+						stringvalidator.LengthBetween(1, 79),
+						stringvalidator.RegexMatches(pngKeywordPattern, "must be printable ASCII without leading, trailing or consecutive spaces"),
+					),
+				},
+			},
+			"strip_metadata": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to remove all text, time and Exif chunks from the PNG image, so that it holds only what is needed to display it and its checksum depends on nothing else. Conflicts with `metadata`. Only used when `format` is `png`.",
+			},
+			"reproducible": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to guarantee that the same inputs give the same `sha256` on every machine and provider version. The QR code is encoded with the provider's own encoder, as with `optimize_encoding`, so that its segments and mask pattern do not depend on a library version, and PNG images are written by the provider with fixed chunk ordering and uncompressed image data, so that they do not depend on the Go version. Images carry no timestamps or encoder versions. Reproducible PNG images are larger, and cannot be combined with `background_image` or `annotation`. SVG and PDF output is reproducible without this setting.",
+			},
+			"sizes": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.Int64Type,
+				Description: fmt.Sprintf("Sizes in pixels, from %d to %d unless the provider sets `min_size` or `max_size`, of additional copies of the image written next to `file` for responsive web embedding, with the size appended to the file name, such as `qr-512.png` for `qr.png`. The copies are styled like the image and their checksums are kept in `sizes_sha256`. A copy that is deleted is written again on the next apply. Requires `file` and the png format, and cannot be combined with `background_image` or `encrypt`.", minSize, maxSize),
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+					listvalidator.ValueInt64sAre(int64validator.AtLeast(1)),
+					listvalidator.AlsoRequires(path.MatchRoot("file")),
+				},
+			},
+			"scaling": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("How modules are scaled to `size` in PNG images, always sampling the nearest module so that edges stay sharp: `%s` resamples them to exactly `size`, so that modules differ in width by a pixel when `size` is not a whole multiple of the modules, `%s` scales every module by the largest whole number of pixels that fits, shrinking the image to a multiple of the modules, and `%s` does the same and centers the symbol in an image of exactly `size`, widening the quiet zone. Defaults to `%s`. Only used when `format` is `png`.", scalingFill, scalingExact, scalingFit, scalingFill),
+				Validators: []validator.String{
+					stringvalidator.OneOf(scalingFill, scalingExact, scalingFit),
+				},
+			},
+			"print_profile": schema.StringAttribute{
+				Optional:    true,
+				Description: "Printing condition that PDF output is prepared for: `fogra39`, `gracol2013` or `swop2013`. When set, the colors are converted to CMYK, with black modules in black ink alone and a white background left unprinted, and the printing condition is embedded as the PDF output intent, for print vendors that reject RGB PDFs. Defaults to RGB output. Only used when `format` is `pdf`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(qrgen.PrintProfiles()...),
+				},
+			},
+			"filename": schema.StringAttribute{
+				Computed:    true,
+				Description: "Path of the saved QR code image. Equal to `file` unless `file` is a directory, and null when `file` is omitted.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the generated QR code image.",
+			},
+			"sizes_sha256": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Map of size in pixels to the SHA-256 checksum of the copy of the image written at that size, for each of `sizes`. Null unless `sizes` is set.",
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:    true,
+				Description: "Base64-encoded image of the QR code, in the configured `format`, for use by other resources without reading the file. Null when the text is read from `sensitive_text_env` or `sensitive_text_path`, unless `encrypt` is set.",
+			},
+			"ascii": schema.StringAttribute{
+				Computed:    true,
+				Description: "ASCII text representation of the QR code. Null when `encrypt` is set or the text is read from `sensitive_text_env` or `sensitive_text_path`.",
+			},
+			"ascii_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the ASCII QR code. Null when `encrypt` is set or the text is read from `sensitive_text_env` or `sensitive_text_path`.",
+			},
+			"qr_version": schema.Int64Attribute{
+				Computed:    true,
+				Description: "QR code version of the symbol, from 1 to 40. Each version adds 4 modules to the width of the symbol.",
+			},
+			"error_correction_used": schema.StringAttribute{
+				Computed:    true,
+				Description: "Error correction level of the symbol: L, M, Q or H. Differs from `error_correction` when it is `auto_max`.",
+			},
+			"content_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the modules of the symbol, rather than of the image, for downstream systems keyed on the content. It changes with the encoded data, the error correction and the encoding, but not with `format`, `size`, colors or the quiet zone. The modules are hashed as a line of `1` for dark and `0` for light modules per row, each ending in a newline, without the quiet zone.",
+			},
+			"module_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Width of the symbol in modules, without the quiet zone.",
+			},
+			"encoding_mode_used": schema.StringAttribute{
+				Computed:    true,
+				Description: "Data modes of the encoded segments in order, such as `byte` or `alphanumeric+numeric`.",
+			},
+			"capacity_used_percent": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Share of the data capacity of the largest QR code, version 40 at the same error correction level, that the text takes, in percent. Generation fails once it exceeds 100, and codes become hard to scan well before that, so it can be used to alert on payloads that keep growing.",
+			},
+			"ssh_fingerprint": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 fingerprint of the `ssh_key` public key, such as `SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s`, as shown by `ssh-keygen -lf` and on first connection. Null unless `ssh_key` is set.",
+			},
+			"style_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the settings of the provider `style` block that `style` references, whose changes plan a regeneration of the image. Null unless `style` is set.",
+			},
+			"jws": schema.StringAttribute{
+				Computed:    true,
+				Description: "Compact JWS encoded in the QR code, for apps that receive it without scanning. Null unless `sign_jws` is set, or when the text is read from `sensitive_text_env` or `sensitive_text_path`, since the JWS payload is the text itself.",
+			},
+			"print_job_id": schema.Int64Attribute{
+				Computed:    true,
+				Description: "ID of the job the printer of the `print` block created for the image when it was last generated. Null unless `print` is set.",
+			},
+			"encrypted_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the encrypted image, as written to `file` and kept in `content_base64`. Null unless `encrypt` is set. Encryption is randomized, so the checksum changes every time the image is written.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"normalize": schema.SingleNestedBlock{
+				Description: "Normalizes the text before it is encoded, so that invisible differences in interpolated content, such as a trailing newline from `file()` or Windows line endings, do not change the image and its checksums. `text` and `sensitive_text` are kept in state as configured.",
+				Attributes: map[string]schema.Attribute{
+					"trim_space": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Removes leading and trailing whitespace, including newlines.",
+					},
+					"line_endings": schema.StringAttribute{
+						Optional:    true,
+						Description: "Converts line endings to `lf` or `crlf`. Line endings are kept as they are when not set.",
+						Validators: []validator.String{
+							stringvalidator.OneOf(lineEndingsLF, lineEndingsCRLF),
+						},
+					},
+					"unicode_nfc": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Composes the text to Unicode Normalization Form C, so that accented characters typed as a letter and a combining mark encode the same as their precomposed form.",
+					},
+				},
+			},
+			"otpauth_migration": schema.SingleNestedBlock{
+				Description: "Encodes TOTP and HOTP accounts as a Google Authenticator `otpauth-migration://offline?data=` URI, so that scanning a single QR code imports all of them. The URI reveals the secrets, so keep the state, `content_base64` and `ascii` as protected as the secrets themselves.",
+				Blocks: map[string]schema.Block{
+					"account": schema.ListNestedBlock{
+						Description: "An account to import. At least one must be set.",
+						NestedObject: schema.NestedBlockObject{
+							Attributes: map[string]schema.Attribute{
+								"name": schema.StringAttribute{
+									Required:    true,
+									Description: "Account name shown in the app, such as `alice@example.com`.",
+								},
+								"issuer": schema.StringAttribute{
+									Optional:    true,
+									Description: "Service the account belongs to, such as `Example`.",
+								},
+								"secret": schema.StringAttribute{
+									Required:    true,
+									Sensitive:   true,
+									Description: "Shared secret in base32, as shown by authenticator apps. Case, spaces and padding are ignored.",
+								},
+								"algorithm": schema.StringAttribute{
+									Optional:    true,
+									Description: "HMAC algorithm: `SHA1`, `SHA256`, `SHA512` or `MD5`. Defaults to `SHA1`, the only one most apps support.",
+									Validators: []validator.String{
+										stringvalidator.OneOf(otpAlgorithmSHA1, otpAlgorithmSHA256, otpAlgorithmSHA512, otpAlgorithmMD5),
+									},
+								},
+								"digits": schema.Int64Attribute{
+									Optional:    true,
+									Description: "Number of digits of the codes: `6` or `8`. Defaults to `6`.",
+									Validators: []validator.Int64{
+										int64validator.OneOf(6, 8),
+									},
+								},
+								"type": schema.StringAttribute{
+									Optional:    true,
+									Description: "`totp` for time-based or `hotp` for counter-based codes. Defaults to `totp`.",
+									Validators: []validator.String{
+										stringvalidator.OneOf(otpTypeTOTP, otpTypeHOTP),
+									},
+								},
+								"counter": schema.Int64Attribute{
+									Optional:    true,
+									Description: "Initial counter of `hotp` accounts. Defaults to `0`.",
+									Validators: []validator.Int64{
+										int64validator.AtLeast(0),
+									},
+								},
+							},
+						},
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+				},
+			},
+			"ssh_key": schema.SingleNestedBlock{
+				Description: "Encodes an SSH public key as an `authorized_keys` line, or as a `known_hosts` line when `hosts` is set, so that bootstrap terminals can be provisioned by scanning the QR code. Options in front of the key are not encoded. The fingerprint of the key is exported in `ssh_fingerprint`.",
+				Attributes: map[string]schema.Attribute{
+					"public_key": schema.StringAttribute{
+						Required:    true,
+						Description: "SSH public key in `authorized_keys` format, such as the content of `~/.ssh/id_ed25519.pub` or the `public_key_openssh` of a `tls_private_key` resource.",
+					},
+					"hosts": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "Host names or addresses the key belongs to, such as `git.example.com` or `[git.example.com]:2222`. When set, the key is encoded as a `known_hosts` line for these hosts.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"strip_comment": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Set to true to leave out the comment after the key, which often holds a user or host name.",
+					},
+				},
+			},
+			"content_encryption": schema.SingleNestedBlock{
+				Description: "Encrypts the content before it is encoded, after `compress`, so that QR codes printed on physical media do not reveal secrets to anyone who scans them. The QR code then holds the binary ciphertext, so `content_encoding` is required. Encryption is randomized, so the QR code changes every time it is generated, and the symbol attributes, such as `qr_version`, are only known after apply. Exactly one of `age_recipients`, `aes_key_env` and `aes_key_path` must be set.",
+				Attributes: map[string]schema.Attribute{
+					"age_recipients": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "age X25519 recipients, such as `age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`, that can decrypt the content. The ciphertext is in the binary age format.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"aes_key_env": schema.StringAttribute{
+						Optional:    true,
+						Description: "Name of an environment variable holding a base64-encoded AES key of 128, 192 or 256 bits, read on the machine running Terraform, to encrypt the content with AES-GCM. The ciphertext is the random 12-byte nonce followed by the encrypted content and its 16-byte authentication tag.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"aes_key_path": schema.StringAttribute{
+						Optional:    true,
+						Description: "Path of a file holding a base64-encoded AES key, as an alternative to `aes_key_env`.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+				},
+			},
+			"encrypt": schema.SingleNestedBlock{
+				Description: "Encrypts the image before it is written, so that the QR code never sits unencrypted on disk. `file` and `content_base64` then hold the binary ciphertext, with `.age` or `.gpg` appended to content-addressed file names, and `ascii` is not kept in state. Exactly one of `age_recipients` and `pgp_public_keys` must be set.",
+				Attributes: map[string]schema.Attribute{
+					"age_recipients": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "age X25519 recipients, such as `age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`, that can decrypt the image.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"pgp_public_keys": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "ASCII-armored OpenPGP public keys that can decrypt the image, such as the output of `gpg --armor --export <key-id>`.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+				},
+			},
+			"kubernetes": schema.SingleNestedBlock{
+				Description: "Writes the image, base64-encoded, to a key of a Kubernetes ConfigMap or Secret, so that cluster dashboards can serve the QR code without an intermediate file. The cluster is configured in the provider `kubernetes` block. The key is written with server-side apply, so the ConfigMap or Secret is created when missing and its other keys are left untouched. On destroy only the key is removed. A key that is deleted or modified in the cluster is written again on the next apply. With `encrypt`, the ciphertext is written.",
+				Attributes: map[string]schema.Attribute{
+					"kind": schema.StringAttribute{
+						Required:    true,
+						Description: "Kind of the object to write to: `ConfigMap`, which holds the image in `binaryData`, or `Secret`.",
+						Validators: []validator.String{
+							stringvalidator.OneOf(kubernetesKindConfigMap, kubernetesKindSecret),
+						},
+					},
+					"namespace": schema.StringAttribute{
+						Optional:    true,
+						Description: "Namespace of the object. Defaults to the namespace of the kubeconfig context or service account, or `default`.",
+					},
+					"name": schema.StringAttribute{
+						Required:    true,
+						Description: "Name of the ConfigMap or Secret.",
+						Validators: []validator.String{
+							stringvalidator.LengthBetween(1, 253),
+						},
+					},
+					"key": schema.StringAttribute{
+						Required:    true,
+						Description: "Key to write the image to, such as `wifi.png`.",
+						Validators: []validator.String{
+							stringvalidator.LengthBetween(1, 253),
+							stringvalidator.RegexMatches(kubernetesKeyPattern, "must consist of alphanumeric characters, '-', '_' or '.'"),
+						},
+					},
+				},
+			},
+			"consul_kv": schema.SingleNestedBlock{
+				Description: "Writes the image to a Consul KV key, configured in the provider `consul` block, as a JSON object of the base64-encoded image in `content_base64` and its SHA-256 checksum in `sha256`, so that service bootstrap flows can read provisioning QR codes from Consul. A key that is deleted or modified in Consul is written again on the next apply, and the key is deleted on destroy. With `encrypt`, the ciphertext is written.",
+				Attributes: map[string]schema.Attribute{
+					"path": schema.StringAttribute{
+						Required:    true,
+						Description: "Key to write the image to, such as `provisioning/wifi`.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+				},
+			},
+			"annotation": schema.SingleNestedBlock{
+				Description: "Stamps small text, such as an asset ID or a generation date, in a corner of the image for audit traceability on printed QR codes. The text is drawn in `foreground_color` in a strip added above or below the image, so that the quiet zone stays clear, and scales with `size`. Only used when `format` is `png`, and cannot be combined with `interlaced` or `background_image`.",
+				Attributes: map[string]schema.Attribute{
+					"text": schema.StringAttribute{
+						Required:    true,
+						Description: "Text to stamp, in printable ASCII characters, such as `asset-0042 2026-10-17`. Generation dates must be given as strings, such as from `formatdate`, so that the image stays reproducible.",
+						Validators: []validator.String{
+							stringvalidator.LengthBetween(1, 64),
+							stringvalidator.RegexMatches(labelTextPattern, "must consist of printable ASCII characters"),
+						},
+					},
+					"corner": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Corner to stamp the text in: `%s`, `%s`, `%s` or `%s`. Defaults to `%s`.", annotationCornerTopLeft, annotationCornerTopRight, annotationCornerBottomLeft, annotationCornerBottomRight, annotationCornerBottomRight),
+						Validators: []validator.String{
+							stringvalidator.OneOf(annotationCornerTopLeft, annotationCornerTopRight, annotationCornerBottomLeft, annotationCornerBottomRight),
+						},
+					},
+				},
+			},
+			"background_image": schema.SingleNestedBlock{
+				Description: "Draws the QR code onto a PNG or JPEG background image, such as a badge or flyer template, in an opaque box of `quiet_zone_color` so that the background does not reach the quiet zone. The image has the size of the background, and `size` is the size of the QR code on it. Only used when `format` is `png`, and cannot be combined with `interlaced`.",
+				Attributes: map[string]schema.Attribute{
+					"path": schema.StringAttribute{
+						Required:    true,
+						Description: "Path of the PNG or JPEG background image, read on the machine running Terraform when the QR code is generated. Changes to the content of the file are not detected, so change `path` or replace the resource to apply them.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"x": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Distance of the box from the left edge of the background, in pixels. Defaults to `0`.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"y": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Distance of the box from the top edge of the background, in pixels. Defaults to `0`.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"padding": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Width of the box around the QR code, in pixels, in addition to its quiet zone. Defaults to `0`.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+				},
+			},
+			"vault_kv": schema.SingleNestedBlock{
+				Description: "Writes the image to a secret of a Vault KV version 2 secrets engine, configured in the provider `vault` block, with the base64-encoded image in the `content_base64` field and its SHA-256 checksum in the `sha256` field. Every write adds a version to the secret. A secret that is deleted or modified in Vault is written again on the next apply, and the latest version is deleted on destroy. With `encrypt`, the ciphertext is written.",
+				Attributes: map[string]schema.Attribute{
+					"mount": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Path the KV version 2 secrets engine is mounted at. Defaults to `%s`.", defaultVaultKVMount),
+					},
+					"path": schema.StringAttribute{
+						Required:    true,
+						Description: "Path of the secret within the secrets engine, such as `provisioning/wifi`.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+				},
+			},
+			"print": schema.SingleNestedBlock{
+				Description: "Prints the image with an IPP Print-Job request every time it is generated, for zero-touch label printing during provisioning, and records the job ID in `print_job_id`. PNG, SVG and PDF images are sent with their MIME type, which the printer or CUPS queue must accept, and printer formats such as `zpl` as raw data for the printer to read itself. Jobs are not canceled on destroy. Cannot be combined with `encrypt`.",
+				Attributes: map[string]schema.Attribute{
+					"uri": schema.StringAttribute{
+						Required:    true,
+						Description: "IPP URI of the printer or CUPS queue, such as `ipp://printer.local/ipp/print` or `ipps://cups.example.com/printers/labels`. `ipp` and `ipps` URIs default to port 631, and `http` and `https` URIs are accepted too.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"media": schema.StringAttribute{
+						Optional:    true,
+						Description: "Media size to print on, as a PWG 5101.1 media name such as `iso_a4_210x297mm` or `oe_4x6-label_4x6in`. Defaults to the media loaded in the printer.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"copies": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Number of copies to print. Defaults to `1`.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ConfigValidators returns the cross-attribute validations for the resource configuration.
+func (r *qrcodeResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		contentSourcesValidator{
+			attributes: []string{"text", "sensitive_text", "content_json", "content_file", "otpauth_migration", "ssh_key", "sensitive_text_env", "sensitive_text_path"},
+		},
+		resourcevalidator.Conflicting(
+			path.MatchRoot("size"),
+			path.MatchRoot("width_mm"),
+			path.MatchRoot("width_in"),
+			path.MatchRoot("pixels_per_module"),
+			path.MatchRoot("size_from_module_px"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("metadata"),
+			path.MatchRoot("strip_metadata"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("sizes"),
+			path.MatchRoot("background_image"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("rotation"),
+			path.MatchRoot("background_image"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("rotation"),
+			path.MatchRoot("annotation"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("sizes"),
+			path.MatchRoot("encrypt"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("print"),
+			path.MatchRoot("encrypt"),
+		),
+	}
+}
+
+// ValidateConfig requires otpauth_migration secrets to be valid base32, the ssh_key public key to
+// parse, background_image and annotation to be used with non-interlaced PNG images that are not
+// reproducible, metadata and sizes to be used with PNG images, compress and content_encryption to
+// be used with content_encoding, the encrypt and content_encryption blocks to set exactly one
+// kind of recipient, show_in_diagnostics not to reveal encrypted images or referenced text, size
+// not to be set for SVG images, and logos to be drawn on PNG images that are re-encoded.
+func (r *qrcodeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config qrcodeResourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.OTPAuthMigration != nil {
+		for i, account := range config.OTPAuthMigration.Accounts {
+			if account.Secret.IsUnknown() || account.Secret.IsNull() {
+				continue
+			}
+			if _, err := decodeOTPSecret(account.Secret.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("otpauth_migration").AtName("account").AtListIndex(i).AtName("secret"),
+					"Invalid OTP Secret",
+					err.Error(),
+				)
+			}
+		}
+	}
+
+	if config.SSHKey != nil && !config.SSHKey.PublicKey.IsUnknown() {
+		if _, _, err := config.SSHKey.entry(); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ssh_key").AtName("public_key"),
+				"Invalid SSH Public Key",
+				err.Error(),
+			)
+		}
+	}
+
+	if config.BackgroundImage != nil {
+		if format := config.Format.ValueString(); !config.Format.IsUnknown() && format != "" && format != imageFormatPNG {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("background_image"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("A background image can only be used with the png format, got %s.", format),
+			)
+		}
+		if config.Interlaced.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("background_image"),
+				"Invalid Attribute Combination",
+				"A background image cannot be combined with interlaced.",
+			)
+		}
+	}
+
+	if !config.Metadata.IsNull() || config.StripMetadata.ValueBool() {
+		if format := config.Format.ValueString(); !config.Format.IsUnknown() && format != "" && format != imageFormatPNG {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("metadata"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("Metadata can only be written to or stripped from the png format, got %s.", format),
+			)
+		}
+	}
+
+	if !config.Sizes.IsNull() {
+		if format := config.Format.ValueString(); !config.Format.IsUnknown() && format != "" && format != imageFormatPNG {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("sizes"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("Size variants can only be written in the png format, got %s.", format),
+			)
+		}
+	}
+
+	if config.Compress.ValueBool() {
+		if config.ContentEncoding.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("compress"),
+				"Invalid Attribute Combination",
+				"Compressed text is binary data, which requires content_encoding.",
+			)
+		}
+		if config.ContentEncoding.ValueString() == contentEncodingSHC {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("compress"),
+				"Invalid Attribute Combination",
+				"SMART Health Cards encode a compact JWS, which cannot be compressed. Compress the JWS payload when it is signed instead.",
+			)
+		}
+		if config.Reproducible.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("compress"),
+				"Invalid Attribute Combination",
+				"Compressed text cannot be reproducible, since the compressed data depends on the Go standard library.",
+			)
+		}
+	}
+
+	if config.ContentEncryption != nil {
+		encryption := config.ContentEncryption
+		if config.ContentEncoding.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_encryption"),
+				"Invalid Attribute Combination",
+				"Encrypted content is binary data, which requires content_encoding.",
+			)
+		}
+		if config.ContentEncoding.ValueString() == contentEncodingSHC {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_encryption"),
+				"Invalid Attribute Combination",
+				"SMART Health Cards encode a compact JWS, which cannot be encrypted.",
+			)
+		}
+		if config.Reproducible.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_encryption"),
+				"Invalid Attribute Combination",
+				"Encrypted content cannot be reproducible, since encryption is randomized.",
+			)
+		}
+
+		set, unknown := 0, false
+		for _, value := range []attr.Value{encryption.AgeRecipients, encryption.AESKeyEnv, encryption.AESKeyPath} {
+			unknown = unknown || value.IsUnknown()
+			if !value.IsNull() {
+				set++
+			}
+		}
+		if !unknown && set != 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_encryption"),
+				"Invalid Attribute Combination",
+				"Exactly one of age_recipients, aes_key_env and aes_key_path must be set.",
+			)
+		}
+	}
+
+	if !config.Captions.IsNull() {
+		if format := config.Format.ValueString(); !config.Format.IsUnknown() && !isPrinterFormat(format) {
+			if format == "" {
+				format = imageFormatPNG
+			}
+			resp.Diagnostics.AddAttributeError(
+				path.Root("captions"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("Captions can only be printed in the zpl, epl, tspl and escpos formats, got %s.", format),
+			)
+		}
+	}
+
+	if format := config.Format.ValueString(); !config.Format.IsUnknown() && !config.ESCPOSMode.IsUnknown() {
+		switch {
+		case !config.ESCPOSMode.IsNull() && format != imageFormatESCPOS:
+			resp.Diagnostics.AddAttributeError(
+				path.Root("escpos_mode"),
+				"Invalid Attribute Combination",
+				"escpos_mode can only be used with the escpos format.",
+			)
+		case format == imageFormatESCPOS && config.ESCPOSMode.ValueString() != escposModeRaster && (!config.Rotation.IsNull() || !config.ByteCharset.IsNull()):
+			resp.Diagnostics.AddAttributeError(
+				path.Root("escpos_mode"),
+				"Invalid Attribute Combination",
+				"QR codes encoded by ESC/POS printers cannot be combined with rotation or byte_charset. Set escpos_mode to raster to print the modules of the symbol.",
+			)
+		}
+	}
+
+	// Referenced text is only read at apply time, when create reports its host name
+	if !config.IDNMode.IsNull() && !config.IDNMode.IsUnknown() && config.textKnown() && config.Normalize.known() {
+		if _, err := config.convertedContent(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("idn_mode"), "Invalid Internationalized Domain Name", err.Error())
+		}
+	}
+
+	if config.Print != nil && !config.Print.URI.IsUnknown() && !config.Print.URI.IsNull() {
+		if _, err := printerURL(config.Print.URI.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("print").AtName("uri"), "Invalid Printer URI", err.Error())
+		}
+	}
+
+	if config.Reproducible.ValueBool() && (config.BackgroundImage != nil || config.Annotation != nil) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("reproducible"),
+			"Invalid Attribute Combination",
+			"Reproducible images cannot be combined with background_image or annotation, whose images are compressed by the Go standard library.",
+		)
+	}
+
+	if config.Annotation != nil {
+		if format := config.Format.ValueString(); !config.Format.IsUnknown() && format != "" && format != imageFormatPNG {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("annotation"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("An annotation can only be used with the png format, got %s.", format),
+			)
+		}
+		if config.Interlaced.ValueBool() || config.BackgroundImage != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("annotation"),
+				"Invalid Attribute Combination",
+				"An annotation cannot be combined with interlaced or background_image.",
+			)
+		}
+	}
+
+	if !config.Size.IsNull() && !config.Size.IsUnknown() && config.Format.ValueString() == imageFormatSVG {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("size"),
+			"Invalid Attribute Combination",
+			"SVG images are vector graphics that scale to the size they are shown at, so size cannot be set with the svg format.",
+		)
+	}
+
+	if !config.LogoFile.IsNull() {
+		if format := config.Format.ValueString(); !config.Format.IsUnknown() && format != "" && format != imageFormatPNG {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("logo_file"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("A logo can only be drawn on the png format, got %s.", format),
+			)
+		}
+		if config.Interlaced.ValueBool() || config.Reproducible.ValueBool() || config.BackgroundImage != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("logo_file"),
+				"Invalid Attribute Combination",
+				"A logo cannot be combined with interlaced, reproducible or background_image.",
+			)
+		}
+	}
+
+	if config.Encrypt != nil && config.ShowInDiagnostics.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("show_in_diagnostics"),
+			"Invalid Attribute Combination",
+			"The ASCII rendering shown in diagnostics is not encrypted, so it cannot be combined with encrypt.",
+		)
+	}
+
+	if config.hasTextReference() && config.ShowInDiagnostics.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("show_in_diagnostics"),
+			"Invalid Attribute Combination",
+			"The ASCII rendering shown in diagnostics reveals the text, so it cannot be combined with sensitive_text_env or sensitive_text_path.",
+		)
+	}
+
+	if config.Encrypt == nil || config.Encrypt.AgeRecipients.IsUnknown() || config.Encrypt.PGPPublicKeys.IsUnknown() {
+		return
+	}
+
+	if config.Encrypt.AgeRecipients.IsNull() == config.Encrypt.PGPPublicKeys.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("encrypt"),
+			"Invalid Attribute Combination",
+			"Exactly one of age_recipients and pgp_public_keys must be set.",
+		)
+	}
+}
+
+// IdentitySchema defines the resource identity schema.
+func (r *qrcodeResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"file": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Path of the generated QR code image.",
+			},
+		},
+	}
+}
+
+// ModifyPlan marks the rendered outputs unknown while the QR code content is unknown, deferring the
+// change entirely when Terraform supports deferred actions. A change of file alone is planned as a move of the
+// written files, keeping the outputs in state.
+func (r *qrcodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config, plan qrcodeResourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Referenced text is read at plan time only to detect changes by its checksum. When it is not
+	// available yet, the image is only regenerated on other changes.
+	resolved := true
+	if !config.ContentFile.IsNull() && !config.ContentFile.IsUnknown() {
+		checksum, err := config.readContentFile()
+		if err != nil {
+			resolved = false
+			resp.Diagnostics.AddWarning(
+				"Content File Not Available at Plan Time",
+				fmt.Sprintf("The content to encode could not be read, so changes to it are not detected: %s. It is read again when the image is generated.", err),
+			)
+		} else if !plan.ContentFileSHA256.Equal(types.StringValue(checksum)) {
+			if !req.State.Raw.IsNull() {
+				plan.markOutputsUnknown()
+			}
+			plan.ContentFileSHA256 = types.StringValue(checksum)
+		}
+	} else if config.ContentFile.IsNull() {
+		plan.ContentFileSHA256 = types.StringNull()
+	}
+	if config.hasTextReference() && config.contentKnown() {
+		checksum, err := config.resolveTextReference()
+		if err != nil {
+			resolved = false
+			resp.Diagnostics.AddWarning(
+				"Sensitive Text Not Available at Plan Time",
+				fmt.Sprintf("The text to encode could not be read, so changes to it are not detected: %s. It is read again when the image is generated.", err),
+			)
+		} else if !plan.SensitiveTextSHA256.Equal(types.StringValue(checksum)) {
+			if !req.State.Raw.IsNull() {
+				plan.markOutputsUnknown()
+			}
+			plan.SensitiveTextSHA256 = types.StringValue(checksum)
+		}
+	} else if !config.hasTextReference() {
+		plan.SensitiveTextSHA256 = types.StringNull()
+	}
+
+	// Errors that echo sensitive text are reported with its length and checksum instead
+	if config.textKnown() {
+		redactor := config.redactor()
+		defer func() {
+			resp.Diagnostics = redactor.diagnostics(resp.Diagnostics)
+		}()
+	}
+
+	plan.planSSHFingerprint(config)
+
+	// A refresh that found the file missing or replaced clears the checksum of the image, which
+	// only a new image sets again
+	if !req.State.Raw.IsNull() {
+		var stateSHA256 types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("sha256"), &stateSHA256)...)
+		if stateSHA256.IsNull() {
+			plan.markOutputsUnknown()
+		}
+	}
+
+	// The style fills in the colors and quiet zone that the configuration leaves unset, and a change
+	// to its settings regenerates the image
+	style, err := lookupStyle(r.styles, config.Style)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("style"), "Unknown Style", err.Error())
+		return
+	}
+	if !config.Style.IsUnknown() {
+		styleSHA256 := types.StringNull()
+		if style != nil {
+			styleSHA256 = types.StringValue(style.sha256())
+		}
+		if !req.State.Raw.IsNull() && !plan.StyleSHA256.Equal(styleSHA256) {
+			plan.markOutputsUnknown()
+		}
+		plan.StyleSHA256 = styleSHA256
+	}
+	config = style.apply(config)
+
+	if config.contentKnown() && resolved && !config.AllowEmpty.IsUnknown() {
+		resp.Diagnostics.Append(emptyContentDiagnostics(config.content(), config.AllowEmpty)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Sizing by pixels_per_module or size_from_module_px and the scannability checks depend on the encoded symbol, which is
+	// only known after apply when the content is encrypted or signed. Other encoding errors are left for the
+	// apply to report.
+	modules := 0
+	var symbol *qrgen.Symbol
+	if config.contentKnown() && resolved && config.ContentEncryption == nil && !config.SignJWS.ValueBool() {
+		symbol, err = config.symbol(ctx)
+		if err == nil {
+			modules = symbol.Modules()
+
+			// The metadata is planned whenever it would otherwise be known only after apply
+			if plan.QRVersion.IsUnknown() {
+				plan.setSymbolMetadata(symbol)
+			}
+
+			resp.Diagnostics.Append(capacityDiagnostics(symbol, config.CapacityWarningPercent)...)
+		} else if !config.PixelsPerModule.IsNull() || !config.SizeFromModulePx.IsNull() {
+			resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+			return
+		}
+	}
+
+	diags = plan.planPhysicalSize(config, modules, r.sizeLimits)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(sizeVariantDiagnostics(config.Sizes, r.sizeLimits)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(style.apply(plan).scannabilityDiagnostics(modules)...)
+	if symbol != nil {
+		resp.Diagnostics.Append(config.logoDiagnostics(ctx, symbol, plan.Size)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Plan.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A change of file alone moves the written files rather than rendering the image again, so
+	// that they keep their bytes even when encryption or signing would render them differently
+	if fileOnlyChange(req.State.Raw, resp.Plan.Raw) {
+		var state qrcodeResourceModel
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		written, diags := previousWrittenFiles(ctx, req.Private, state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if moved, ok := r.planMove(state, plan, written); ok {
+			tflog.Debug(ctx, "Planning to move QR code file", map[string]interface{}{
+				"from": written.Path,
+				"to":   moved.Filename.ValueString(),
+			})
+
+			diags = resp.Plan.Set(ctx, &moved)
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+	}
+
+	// A size that depends on the symbol of encrypted or signed content stays unknown until apply,
+	// as only unknown configuration defers the change
+	if plan.textKnown() && config.sizeKnown() && !plan.File.IsUnknown() {
+		// The output path is only unknown until apply when it is derived from the content hash
+		if !isDirectoryPath(r.fs, plan.File.ValueString()) && !plan.Filename.Equal(plan.File) {
+			plan.Filename = plan.File
+
+			diags = resp.Plan.Set(ctx, &plan)
+			resp.Diagnostics.Append(diags...)
+		}
+		return
+	}
+
+	if req.ClientCapabilities.DeferralAllowed {
+		tflog.Debug(ctx, "Deferring QR code generation until its content is known")
+		resp.Deferred = &resource.Deferred{
+			Reason: resource.DeferredReasonResourceConfigUnknown,
+		}
+		return
+	}
+
+	tflog.Debug(ctx, "QR code content is unknown, checksum will be known after apply")
+
+	plan.SHA256 = types.StringUnknown()
+
+	diags = resp.Plan.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Create generates a QR code and saves it to a file.
+func (r *qrcodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	r.create(ctx, req, resp, "")
+}
+
+// create generates a QR code and saves it to a file. previousPath is the file written by the
+// resource before, which is replaced even when the provider sets fail_on_overwrite.
+func (r *qrcodeResource) create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse, previousPath string) {
+	started := time.Now()
+
+	var plan qrcodeResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read referenced text on the machine running the apply, refusing text that changed since the
+	// plan recorded its checksum
+	planned := plan.SensitiveTextSHA256
+	plan.SensitiveTextSHA256 = types.StringNull()
+	if plan.hasTextReference() {
+		checksum, err := plan.resolveTextReference()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to Read Sensitive Text", err.Error())
+			return
+		}
+		if !planned.IsUnknown() && !planned.IsNull() && planned.ValueString() != checksum {
+			resp.Diagnostics.AddError(
+				"Sensitive Text Changed After Plan",
+				"The text read from sensitive_text_env or sensitive_text_path differs from the text that was planned. Run the plan again.",
+			)
+			return
+		}
+		plan.SensitiveTextSHA256 = types.StringValue(checksum)
+	}
+
+	// Read content_file likewise, refusing content that changed since the plan
+	plannedFile := plan.ContentFileSHA256
+	plan.ContentFileSHA256 = types.StringNull()
+	if !plan.ContentFile.IsNull() {
+		checksum, err := plan.readContentFile()
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content_file"), "Failed to Read Content File", err.Error())
+			return
+		}
+		if !plannedFile.IsUnknown() && !plannedFile.IsNull() && plannedFile.ValueString() != checksum {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_file"),
+				"Content File Changed After Plan",
+				"The content read from content_file differs from the content that was planned. Run the plan again.",
+			)
+			return
+		}
+		plan.ContentFileSHA256 = types.StringValue(checksum)
+	}
+
+	// Errors that echo sensitive text are reported with its length and checksum instead
+	redactor := plan.redactor()
+	defer func() {
+		resp.Diagnostics = redactor.diagnostics(resp.Diagnostics)
+	}()
+
+	if _, err := plan.convertedContent(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("idn_mode"), "Invalid Internationalized Domain Name", err.Error())
+		return
+	}
+
+	qrText := plan.content()
+	resp.Diagnostics.Append(emptyContentDiagnostics(qrText, plan.AllowEmpty)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.JWS = types.StringNull()
+	if plan.SignJWS.ValueBool() {
+		if r.jwsSigner == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("sign_jws"), "JWS Signing Not Configured", "Configure the key to sign the text with in the provider jws_signing_key attribute.")
+			return
+		}
+		jws, err := r.jwsSigner.sign([]byte(qrText))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("sign_jws"), "Failed to Sign QR Code Text", err.Error())
+			return
+		}
+		plan.JWS = types.StringValue(jws)
+	}
+
+	style, err := lookupStyle(r.styles, plan.Style)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("style"), "Unknown Style", err.Error())
+		return
+	}
+	plan.StyleSHA256 = types.StringNull()
+	if style != nil {
+		plan.StyleSHA256 = types.StringValue(style.sha256())
+	}
+	styled := style.apply(plan)
+
+	// Generate QR code
+	symbol, payload, err := styled.encode(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+		return
+	}
+
+	// Set size, scaling every module by the same number of pixels when sized by module, or growing
+	// the default size to fit the smallest module size. Sizes are checked at plan time, so this
+	// only fails for sizes that were not known then
+	size := defaultSize
+	if !plan.PixelsPerModule.IsNull() {
+		size = symbol.Modules() * int(plan.PixelsPerModule.ValueInt64())
+		plan.Size = types.Int64Value(int64(size))
+	} else if !plan.SizeFromModulePx.IsNull() {
+		size = int(sizeFromModulePx(symbol.Modules(), plan.SizeFromModulePx.ValueInt64()))
+		plan.Size = types.Int64Value(int64(size))
+	} else if !plan.Size.IsNull() {
+		size = int(plan.Size.ValueInt64())
+	}
+	// Images sized by module may be smaller than min_size, as their modules set their scale
+	if smallest, largest := r.sizeLimits.bounds(); int64(size) > largest || (plan.PixelsPerModule.IsNull() && plan.SizeFromModulePx.IsNull() && int64(size) < smallest) {
+		resp.Diagnostics.AddError("Invalid Size", fmt.Sprintf("Size must be between %d and %d pixels, got %d.", smallest, largest, size))
+		return
+	}
+
+	tflog.Debug(ctx, "Generating QR code", map[string]interface{}{
+		"content_length": len(qrText),
+		"size":           size,
+		"file":           plan.File.ValueString(),
+	})
+
+	format := plan.Format.ValueString()
+	if format == "" {
+		format = imageFormatPNG
+	}
+
+	colors, err := styled.colors()
+	if err != nil {
+		resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+		return
+	}
+
+	// Images are rendered from the rotated symbol, while ascii and the metadata describe it upright
+	rendered := symbol
+	if !plan.Rotation.IsNull() {
+		rendered = symbol.Rotate(int(plan.Rotation.ValueInt64()))
+	}
+
+	var imageData []byte
+	switch format {
+	case imageFormatSVG:
+		imageData = rendered.SVG(size, colors, plan.AltText.ValueString(), plan.SVGOptimize.ValueBool())
+	case imageFormatPDF:
+		// Size the page to the printed width when it is known, otherwise one point per pixel
+		pageSize := size
+		if !plan.DPI.IsNull() {
+			pageSize = int(math.Round(float64(size) * qrgen.PDFPointsPerInch / float64(plan.DPI.ValueInt64())))
+		}
+		imageData, err = rendered.PDF(pageSize, colors, plan.PrintProfile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+			return
+		}
+	case imageFormatZPL, imageFormatEPL, imageFormatTSPL, imageFormatESCPOS:
+		var captions []string
+		diags = plan.Captions.ElementsAs(ctx, &captions, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		switch format {
+		case imageFormatZPL:
+			imageData = rendered.ZPL(size, captions)
+		case imageFormatEPL:
+			imageData, err = rendered.EPL(size, captions)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("captions"), "QR Code Generation Failed", err.Error())
+				return
+			}
+		case imageFormatTSPL:
+			imageData = rendered.TSPL(size, captions)
+		default:
+			if plan.ESCPOSMode.ValueString() == escposModeRaster {
+				imageData, err = rendered.ESCPOS(size, captions)
+			} else {
+				imageData, err = qrgen.ESCPOSQRCode(payload, symbol.Level(), escposModuleDots(size, symbol), captions)
+			}
+			if err != nil {
+				resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+				return
+			}
+		}
+	default:
+		var renderDiags diag.Diagnostics
+		imageData, renderDiags = r.renderPNG(ctx, plan, rendered, size, colors)
+		resp.Diagnostics.Append(renderDiags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	asciiSymbol := symbol
+	if !plan.QuietZoneChars.IsNull() {
+		asciiSymbol = symbol.WithQuietZone(int(plan.QuietZoneChars.ValueInt64()))
+	}
+	asciiQR := renderASCII(asciiSymbol, plan.ASCIIDarkChar, plan.ASCIILightChar, plan.ASCIIQuietZoneChar, false)
+
+	// Compute SHA-256 checksum
+	hash := sha256.Sum256(imageData)
+	sha256Checksum := hex.EncodeToString(hash[:])
+
+	// Refuse to write an image that does not match the pinned checksum
+	if !plan.ExpectedSHA256.IsNull() && plan.ExpectedSHA256.ValueString() != sha256Checksum {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expected_sha256"),
+			"QR Code Checksum Mismatch",
+			fmt.Sprintf("The generated QR code image has SHA-256 checksum %s, expected %s.", sha256Checksum, plan.ExpectedSHA256.ValueString()),
+		)
+		return
+	}
+
+	// Encrypt the image, so that only the ciphertext is written and kept in state
+	fileData := imageData
+	extension := format
+	plan.EncryptedSHA256 = types.StringNull()
+	if plan.Encrypt != nil {
+		var ageRecipients, pgpPublicKeys []string
+
+		diags = plan.Encrypt.AgeRecipients.ElementsAs(ctx, &ageRecipients, false)
+		resp.Diagnostics.Append(diags...)
+		diags = plan.Encrypt.PGPPublicKeys.ElementsAs(ctx, &pgpPublicKeys, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var encryptedExtension string
+		fileData, encryptedExtension, err = encryptData(imageData, ageRecipients, pgpPublicKeys)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("encrypt"), "QR Code Encryption Failed", err.Error())
+			return
+		}
+		extension += encryptedExtension
+
+		plan.EncryptedSHA256 = types.StringValue(computeSHA256(string(fileData)))
+	}
+
+	// Save to file, naming it after the checksum when file is a directory
+	plan.Filename = types.StringNull()
+	plan.SizesSHA256 = types.MapNull(types.StringType)
+	if !plan.File.IsNull() {
+		filePath := plan.File.ValueString()
+		if isDirectoryPath(r.fs, filePath) {
+			filePath = contentAddressedFilePath(filePath, sha256Checksum, extension)
+		}
+
+		opts := r.writeOptions
+		if filePath != previousPath {
+			opts = opts.forNewFile(plan.Overwrite)
+		}
+
+		resp.Diagnostics.Append(r.replaceSymlink(ctx, plan, opts, filePath)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.Diagnostics.Append(saveQRCodeFile(ctx, r.fs, opts, filePath, fileData)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		plan.Filename = types.StringValue(filePath)
+
+		// Write the size variants next to the image, replacing them along with it
+		if !plan.Sizes.IsNull() {
+			var sizes []int64
+			resp.Diagnostics.Append(plan.Sizes.ElementsAs(ctx, &sizes, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			resp.Diagnostics.Append(sizeVariantDiagnostics(plan.Sizes, r.sizeLimits)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			checksums := map[string]attr.Value{}
+			for _, variantSize := range sizes {
+				variantData, renderDiags := r.renderPNG(ctx, plan, rendered, int(variantSize), colors)
+				resp.Diagnostics.Append(renderDiags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				variantPath := sizeVariantPath(filePath, variantSize)
+				resp.Diagnostics.Append(saveQRCodeFile(ctx, r.fs, opts, variantPath, variantData)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				checksums[strconv.FormatInt(variantSize, 10)] = types.StringValue(computeSHA256(string(variantData)))
+			}
+
+			plan.SizesSHA256, diags = types.MapValue(types.StringType, checksums)
+			resp.Diagnostics.Append(diags...)
+		}
+	}
+
+	if plan.Kubernetes != nil {
+		if r.kubernetes == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Kubernetes Not Configured", "Configure the cluster to write the QR code to in the provider kubernetes block.")
+			return
+		}
+		if err := r.kubernetes.Write(ctx, plan.Kubernetes, fileData); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Failed to Write QR Code to Kubernetes", err.Error())
+			return
+		}
+
+		tflog.Debug(ctx, "Wrote QR code to Kubernetes", map[string]interface{}{
+			"kind": plan.Kubernetes.Kind.ValueString(),
+			"name": plan.Kubernetes.Name.ValueString(),
+			"key":  plan.Kubernetes.Key.ValueString(),
+		})
+	}
+
+	if plan.ConsulKV != nil {
+		if r.consul == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul_kv"), "Consul Not Configured", "Configure the Consul agent to write the QR code to in the provider consul block.")
+			return
+		}
+		if err := r.consul.Write(ctx, plan.ConsulKV.Path.ValueString(), fileData); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul_kv"), "Failed to Write QR Code to Consul", err.Error())
+			return
+		}
+	}
+
+	if plan.VaultKV != nil {
+		if r.vault == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault_kv"), "Vault Not Configured", "Configure the Vault server to write the QR code to in the provider vault block.")
+			return
+		}
+		if err := r.vault.Write(ctx, plan.VaultKV, fileData); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault_kv"), "Failed to Write QR Code to Vault", err.Error())
+			return
+		}
+	}
 
-	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
-	"github.com/hashicorp/terraform-plugin-framework/resource"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
-	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/skip2/go-qrcode"
+	plan.PrintJobID = types.Int64Null()
+	if plan.Print != nil {
+		job := ippJob{
+			printerURI: plan.Print.URI.ValueString(),
+			media:      plan.Print.Media.ValueString(),
+			copies:     1,
+			format:     format,
+			name:       "QR code",
+		}
+		if !plan.Print.Copies.IsNull() {
+			job.copies = int(plan.Print.Copies.ValueInt64())
+		}
+		if !plan.Filename.IsNull() {
+			job.name = filepath.Base(plan.Filename.ValueString())
+		}
 
-	"path/filepath"
-)
+		jobID, err := submitPrintJob(ctx, r.writeOptions.retry, job, imageData)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("print"), "Failed to Print QR Code", err.Error())
+			return
+		}
+		plan.PrintJobID = types.Int64Value(jobID)
 
-// Ensure implementation satisfies the expected interfaces.
-var _ resource.Resource = &qrcodeResource{}
+		tflog.Debug(ctx, "Printed QR code", map[string]interface{}{
+			"uri":    job.printerURI,
+			"job_id": jobID,
+		})
+	}
 
-// qrcodeResource is the resource implementation.
-type qrcodeResource struct{}
+	target := "content_base64"
+	if !plan.Filename.IsNull() {
+		target = plan.Filename.ValueString()
+	}
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, target, 1, time.Since(started))...)
 
-// NewQRCodeResource creates a new QR code resource instance.
-func NewQRCodeResource() resource.Resource {
-	return &qrcodeResource{}
-}
+	// Set state
+	plan.SHA256 = types.StringValue(sha256Checksum)
+	plan.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(fileData))
+	plan.ASCII = types.StringValue(asciiQR)
+	plan.ASCIISHA256 = types.StringValue(computeSHA256(asciiQR))
+	plan.setSymbolMetadata(symbol)
+	plan.planSSHFingerprint(plan)
+	if plan.Encrypt != nil || plan.hasTextReference() {
+		plan.ASCII = types.StringNull()
+		plan.ASCIISHA256 = types.StringNull()
+	}
+	if plan.Encrypt == nil && plan.hasTextReference() {
+		plan.ContentBase64 = types.StringNull()
+	}
+	if plan.hasTextReference() {
+		plan.JWS = types.StringNull()
+	}
 
-// Metadata returns the resource type name.
-func (r *qrcodeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_generate"
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+
+	diags = resp.Identity.Set(ctx, &qrcodeResourceIdentityModel{
+		File: plan.Filename,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	// Record the files written for Update and Delete to clean up
+	if resp.Private != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, writtenFilesKey, newWrittenFiles(plan).marshal())...)
+	}
+
+	// The ASCII rendering is left out of state for encrypted and referenced text, which it reveals
+	if plan.ShowInDiagnostics.ValueBool() && !plan.ASCII.IsNull() {
+		resp.Diagnostics.AddWarning(
+			"Generated QR Code",
+			"Scan the QR code below:\n\n"+asciiQR,
+		)
+	}
 }
 
-// Schema defines the resource schema.
-func (r *qrcodeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
-	resp.Schema = schema.Schema{
-		MarkdownDescription: "The `qrcode_generate` resource allows you to create QR codes from text input. This is useful for encoding information such as URLs, authentication keys, or configuration details into a scannable format. The QR codes can be generated in PNG format and saved to a specified file path, or displayed in ASCII format for terminal-based use.",
-		Attributes: map[string]schema.Attribute{
-			"text": schema.StringAttribute{
-				Optional:    true,
-				Description: "The text content to encode in the QR code.",
-				Validators: []validator.String{
-					stringvalidator.ExactlyOneOf(
-						path.MatchRoot("text"),
-						path.MatchRoot("sensitive_text"),
-					),
-				},
-			},
-			"sensitive_text": schema.StringAttribute{
-				Optional:    true,
-				Sensitive:   true,
-				Description: "Sensitive text content to encode in the QR code.",
-			},
-			"size": schema.Int64Attribute{
-				Optional:    true,
-				Description: "Size of the QR code image in pixels.",
-			},
-			"file": schema.StringAttribute{
-				Required:    true,
-				Description: "Path to save the generated QR code image.",
-			},
-			"sha256": schema.StringAttribute{
-				Computed:    true,
-				Description: "SHA-256 checksum of the generated QR code image.",
-			},
-		},
+// replaceSymlink removes a symbolic link at filePath, for the image to replace the link itself
+// unless it should be written to its target. A link that may not be replaced is refused when
+// saving.
+func (r *qrcodeResource) replaceSymlink(ctx context.Context, plan qrcodeResourceModel, opts writeOptions, filePath string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if plan.FollowSymlinks.ValueBool() || opts.exclusive || !isSymlink(r.fs, filePath) {
+		return diags
 	}
+
+	tflog.Debug(ctx, "Replacing symbolic link with QR code file", map[string]interface{}{
+		"file": filePath,
+	})
+	if err := r.fs.Remove(hostPath(filePath)); err != nil {
+		diags.AddError("Failed to Replace Symbolic Link", err.Error())
+	}
+
+	return diags
 }
 
-// Create generates a QR code and saves it to a file.
-func (r *qrcodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan struct {
-		Text          types.String `tfsdk:"text"`
-		SensitiveText types.String `tfsdk:"sensitive_text"`
-		Size          types.Int64  `tfsdk:"size"`
-		File          types.String `tfsdk:"file"`
-		SHA256        types.String `tfsdk:"sha256"`
+// renderPNG renders the symbol as a PNG image of the given size, composited onto the background
+// image, annotated and with its metadata as configured.
+func (r *qrcodeResource) renderPNG(ctx context.Context, plan qrcodeResourceModel, symbol *qrgen.Symbol, size int, colors qrgen.Colors) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	start := time.Now()
+	var imageData []byte
+	var err error
+	pngOptions := qrgen.PNGOptions{
+		Scaling:      pngScalings[plan.Scaling.ValueString()],
+		Interlaced:   plan.Interlaced.ValueBool(),
+		Reproducible: plan.Reproducible.ValueBool(),
+	}
+	if plan.BackgroundImage != nil {
+		background, err := afero.ReadFile(r.fs, hostPath(plan.BackgroundImage.Path.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(path.Root("background_image").AtName("path"), "QR Code Generation Failed", fmt.Sprintf("Could not read background image: %s", err))
+			return nil, diags
+		}
+		imageData, err = symbol.CompositePNG(background, size, colors, pngOptions, plan.BackgroundImage.placement())
+		if err != nil {
+			diags.AddAttributeError(path.Root("background_image"), "QR Code Generation Failed", err.Error())
+			return nil, diags
+		}
+	} else {
+		imageData, err = symbol.PNGWithOptions(size, colors, pngOptions)
+		if err != nil {
+			diags.AddError("QR Code Generation Failed", err.Error())
+			return nil, diags
+		}
+	}
+	if !plan.LogoFile.IsNull() {
+		logo, err := afero.ReadFile(r.fs, hostPath(plan.LogoFile.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(path.Root("logo_file"), "QR Code Generation Failed", fmt.Sprintf("Could not read logo: %s", err))
+			return nil, diags
+		}
+		imageData, err = drawLogo(imageData, logo, plan.logoSizePercent(), colors)
+		if err != nil {
+			diags.AddAttributeError(path.Root("logo_file"), "QR Code Generation Failed", err.Error())
+			return nil, diags
+		}
+	}
+	if plan.Annotation != nil {
+		corner := plan.Annotation.Corner.ValueString()
+		if plan.Annotation.Corner.IsNull() {
+			corner = annotationCornerBottomRight
+		}
+		imageData, err = annotatePNG(imageData, plan.Annotation.Text.ValueString(), corner, colors)
+		if err != nil {
+			diags.AddAttributeError(path.Root("annotation").AtName("text"), "QR Code Generation Failed", err.Error())
+			return nil, diags
+		}
+	}
+	if plan.StripMetadata.ValueBool() {
+		imageData, err = qrgen.StripPNGMetadata(imageData)
+		if err != nil {
+			diags.AddError("QR Code Generation Failed", err.Error())
+			return nil, diags
+		}
 	}
+	if !plan.Metadata.IsNull() {
+		metadata := map[string]string{}
+		diags.Append(plan.Metadata.ElementsAs(ctx, &metadata, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		imageData, err = qrgen.WithPNGText(imageData, metadata)
+		if err != nil {
+			diags.AddAttributeError(path.Root("metadata"), "QR Code Generation Failed", err.Error())
+			return nil, diags
+		}
+	}
+	tflog.Debug(ctx, "Rendered QR code PNG", map[string]interface{}{
+		"version":        symbol.Version(),
+		"size":           size,
+		"png_bytes":      len(imageData),
+		"interlaced":     plan.Interlaced.ValueBool(),
+		"scaling":        plan.Scaling.ValueString(),
+		"render_time_ms": time.Since(start).Milliseconds(),
+	})
 
-	diags := req.Plan.Get(ctx, &plan)
+	return imageData, diags
+}
+
+// Read refreshes the state.
+func (r *qrcodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state qrcodeResourceModel
+
+	// Read the state
+	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Determine which text to use
-	qrText := ""
-	if !plan.Text.IsNull() {
-		qrText = plan.Text.ValueString()
-	} else {
-		qrText = plan.SensitiveText.ValueString()
+	// Errors that echo sensitive text are reported with its length and checksum instead
+	redactor := state.redactor()
+	defer func() {
+		resp.Diagnostics = redactor.diagnostics(resp.Diagnostics)
+	}()
+
+	// Plan writing the image to Kubernetes again when its key was deleted or modified
+	if state.Kubernetes != nil && r.kubernetes != nil {
+		data, found, err := r.kubernetes.Read(ctx, state.Kubernetes)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Failed to Read QR Code from Kubernetes", err.Error())
+			return
+		}
+
+		if !found || computeSHA256(string(data)) != state.outputSHA256() {
+			tflog.Debug(ctx, "QR code in Kubernetes is missing or modified", map[string]interface{}{
+				"kind": state.Kubernetes.Kind.ValueString(),
+				"name": state.Kubernetes.Name.ValueString(),
+				"key":  state.Kubernetes.Key.ValueString(),
+			})
+			state.Kubernetes = nil
+
+			diags = resp.State.Set(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+		}
 	}
 
-	// Set size
-	const defaultSize = 256
-	const minSize = 100
-	const maxSize = 2000
+	// Plan writing the image to Consul and Vault again when it was deleted or modified
+	if state.ConsulKV != nil && r.consul != nil {
+		payload, found, err := r.consul.Read(ctx, state.ConsulKV.Path.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul_kv"), "Failed to Read QR Code from Consul", err.Error())
+			return
+		}
+		if !found || !payload.matches(state.outputSHA256()) {
+			tflog.Debug(ctx, "QR code in Consul is missing or modified", map[string]interface{}{
+				"path": state.ConsulKV.Path.ValueString(),
+			})
+			state.ConsulKV = nil
+
+			diags = resp.State.Set(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+		}
+	}
 
-	size := defaultSize
-	if !plan.Size.IsNull() {
-		sizeVal := int(plan.Size.ValueInt64())
-		if sizeVal < minSize || sizeVal > maxSize {
-			resp.Diagnostics.AddError("Invalid Size", fmt.Sprintf("Size must be between %d and %d pixels.", minSize, maxSize))
+	if state.VaultKV != nil && r.vault != nil {
+		payload, found, err := r.vault.Read(ctx, state.VaultKV)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault_kv"), "Failed to Read QR Code from Vault", err.Error())
 			return
 		}
-		size = sizeVal
+		if !found || !payload.matches(state.outputSHA256()) {
+			tflog.Debug(ctx, "QR code in Vault is missing or modified", map[string]interface{}{
+				"path": state.VaultKV.Path.ValueString(),
+			})
+			state.VaultKV = nil
+
+			diags = resp.State.Set(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+		}
 	}
 
-	// Generate QR code
-	pngData, err := qrcode.Encode(qrText, qrcode.Medium, size)
-	if err != nil {
-		resp.Diagnostics.AddError("QR Code Generation Failed", err.Error())
+	// If the file path is not set, or the file was written to memory, the image only lives in
+	// state and there is nothing to check
+	filePath := state.outputPath()
+	if filePath == "" || isMemoryFilesystem(r.fs) {
 		return
 	}
 
-	// Compute SHA-256 checksum
-	hash := sha256.Sum256(pngData)
-	sha256Checksum := hex.EncodeToString(hash[:])
-
-	// Save to file
-	filePath := plan.File.ValueString()
-	dir := filepath.Dir(filePath)
+	// Check if the file exists
+	if _, err := r.fs.Stat(hostPath(filePath)); os.IsNotExist(err) {
+		switch state.OnMissingFile.ValueString() {
+		case onMissingFileError:
+			resp.Diagnostics.AddError(
+				"QR Code File Missing",
+				fmt.Sprintf("The QR code file %s no longer exists.", filePath),
+			)
+		case onMissingFileRecreate:
+			// Clear the checksum of the image so that the plan writes the file again
+			tflog.Debug(ctx, "QR code file is missing, planning to recreate it", map[string]interface{}{
+				"file": filePath,
+			})
+			state.SHA256 = types.StringNull()
 
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		resp.Diagnostics.AddError("Failed to Create Directory", err.Error())
+			diags = resp.State.Set(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+		default:
+			// File is missing, remove the resource from the state
+			tflog.Debug(ctx, "QR code file is missing, removing from state", map[string]interface{}{
+				"file": filePath,
+			})
+			resp.State.RemoveResource(ctx)
+		}
 		return
 	}
 
-	err = os.WriteFile(filePath, pngData, 0644)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to Save QR Code", err.Error())
-		return
+	// Plan to write the size variants again when one of them was deleted
+	for _, variantPath := range state.sizeVariantPaths() {
+		if _, err := r.fs.Stat(hostPath(variantPath)); os.IsNotExist(err) {
+			tflog.Debug(ctx, "QR code size variant is missing, planning to recreate it", map[string]interface{}{
+				"file": variantPath,
+			})
+			state.Sizes = types.ListNull(types.Int64Type)
+
+			diags = resp.State.Set(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+			break
+		}
 	}
 
-	// Set state
-	resp.State.Set(ctx, &struct {
-		Text          types.String `tfsdk:"text"`
-		SensitiveText types.String `tfsdk:"sensitive_text"`
-		Size          types.Int64  `tfsdk:"size"`
-		File          types.String `tfsdk:"file"`
-		SHA256        types.String `tfsdk:"sha256"`
-	}{
-		Text:          plan.Text,
-		SensitiveText: plan.SensitiveText,
-		Size:          plan.Size,
-		File:          plan.File,
-		SHA256:        types.StringValue(sha256Checksum),
+	// Plan to write the image again when it no longer encodes the text
+	if state.VerifyOnRead.ValueBool() {
+		matches, err := imageEncodesText(r.fs, state, filePath)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to Read QR Code", err.Error())
+			return
+		}
+		if !matches {
+			resp.Diagnostics.AddWarning(
+				"QR Code Image Replaced",
+				fmt.Sprintf("The QR code file %s no longer encodes the configured text. It will be written again on the next apply.", filePath),
+			)
+			state.SHA256 = types.StringNull()
+
+			diags = resp.State.Set(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+	}
+
+	// Imported resources and states from older provider versions only carry the file path,
+	// so fill in the rest from disk. Images of referenced text are never kept in state.
+	if state.SHA256.IsNull() || state.Filename.IsNull() || (state.ContentBase64.IsNull() && !state.hasTextReference()) {
+		imageData, err := afero.ReadFile(r.fs, hostPath(filePath))
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to Read QR Code", err.Error())
+			return
+		}
+
+		hash := sha256.Sum256(imageData)
+		state.SHA256 = types.StringValue(hex.EncodeToString(hash[:]))
+		if !state.hasTextReference() {
+			state.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(imageData))
+		}
+		state.Filename = types.StringValue(filePath)
+
+		diags = resp.State.Set(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	diags = resp.Identity.Set(ctx, &qrcodeResourceIdentityModel{
+		File: state.Filename,
 	})
+	resp.Diagnostics.Append(diags...)
 }
 
-// Read refreshes the state.
-func (r *qrcodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state struct {
-		Text          types.String `tfsdk:"text"`
-		SensitiveText types.String `tfsdk:"sensitive_text"`
-		Size          types.Int64  `tfsdk:"size"`
-		File          types.String `tfsdk:"file"`
-		SHA256        types.String `tfsdk:"sha256"`
-	}
+// Update regenerates the QR code like Create, since QR codes are immutable, and removes the
+// previous file when the output path changed, along with size variants that are no longer written.
+func (r *qrcodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state qrcodeResourceModel
 
-	// Read the state
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// If the file path is not set, nothing to check
-	if state.File.IsNull() || state.File.ValueString() == "" {
+	previous, diags := previousWrittenFiles(ctx, req.Private, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	filePath := state.File.ValueString()
-
-	// Check if the file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		// File is missing, remove the resource from the state
-		resp.State.RemoveResource(ctx)
+	// A plan from planMove keeps the image, which is only known in the plan when it is moved
+	var planned qrcodeResourceModel
+	diags = req.Plan.Get(ctx, &planned)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if fileOnlyChange(req.State.Raw, req.Plan.Raw) && !planned.SHA256.IsUnknown() && !planned.Filename.IsUnknown() {
+		r.move(ctx, planned, previous, resp)
+		return
 	}
-}
 
-// Update is identical to Create since QR codes are immutable.
-func (r *qrcodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	r.Create(ctx, resource.CreateRequest{
+	tflog.Debug(ctx, "Regenerating QR code on update", map[string]interface{}{
+		"previous_file":   previous.Path,
+		"previous_format": previous.Format,
+		"previous_size":   previous.Size,
+	})
+
+	r.create(ctx, resource.CreateRequest{
 		Plan: req.Plan,
-	}, (*resource.CreateResponse)(resp))
+	}, (*resource.CreateResponse)(resp), previous.Path)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan qrcodeResourceModel
+
+	diags = resp.State.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if previousPath := previous.Path; previousPath != "" && previousPath != plan.outputPath() {
+		if err := r.fs.Remove(hostPath(previousPath)); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError("Failed to Delete Previous QR Code", err.Error())
+			return
+		}
+
+		tflog.Debug(ctx, "Removed previous QR code file", map[string]interface{}{
+			"file": previousPath,
+		})
+	}
+
+	variantPaths := plan.sizeVariantPaths()
+	for size, previousPath := range previous.Variants {
+		if variantPaths[size] == previousPath {
+			continue
+		}
+		if err := r.fs.Remove(hostPath(previousPath)); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError("Failed to Delete Previous QR Code", err.Error())
+			return
+		}
+	}
+
+	if state.Kubernetes != nil && !state.Kubernetes.equal(plan.Kubernetes) && r.kubernetes != nil {
+		if err := r.kubernetes.Remove(ctx, state.Kubernetes); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Failed to Remove Previous QR Code from Kubernetes", err.Error())
+			return
+		}
+	}
+
+	if state.ConsulKV != nil && (plan.ConsulKV == nil || !state.ConsulKV.Path.Equal(plan.ConsulKV.Path)) && r.consul != nil {
+		if err := r.consul.Remove(ctx, state.ConsulKV.Path.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul_kv"), "Failed to Remove Previous QR Code from Consul", err.Error())
+			return
+		}
+	}
+
+	if state.VaultKV != nil && (plan.VaultKV == nil || !state.VaultKV.Mount.Equal(plan.VaultKV.Mount) || !state.VaultKV.Path.Equal(plan.VaultKV.Path)) && r.vault != nil {
+		if err := r.vault.Remove(ctx, state.VaultKV); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault_kv"), "Failed to Remove Previous QR Code from Vault", err.Error())
+			return
+		}
+	}
 }
 
 // Delete removes the QR code file and the resource from state.
 func (r *qrcodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state struct {
-		Text          types.String `tfsdk:"text"`
-		SensitiveText types.String `tfsdk:"sensitive_text"`
-		Size          types.Int64  `tfsdk:"size"`
-		File          types.String `tfsdk:"file"`
-		SHA256        types.String `tfsdk:"sha256"`
-	}
+	var state qrcodeResourceModel
 
 	// Read current state
 	diags := req.State.Get(ctx, &state)
@@ -206,16 +3037,76 @@ func (r *qrcodeResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	written, diags := previousWrittenFiles(ctx, req.Private, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Refuse to delete files that another process replaced since they were written, before
+	// anything is deleted, so that a failed destroy can be retried as is
+	if !state.ForceDelete.ValueBool() {
+		resp.Diagnostics.Append(r.replacedFileDiagnostics(written)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if state.Kubernetes != nil {
+		if r.kubernetes == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Kubernetes Not Configured", "Configure the cluster that the QR code was written to in the provider kubernetes block, or remove the key by hand.")
+			return
+		}
+		if err := r.kubernetes.Remove(ctx, state.Kubernetes); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("kubernetes"), "Failed to Remove QR Code from Kubernetes", err.Error())
+			return
+		}
+	}
+
+	if state.ConsulKV != nil {
+		if r.consul == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul_kv"), "Consul Not Configured", "Configure the Consul agent that the QR code was written to in the provider consul block, or delete the key by hand.")
+			return
+		}
+		if err := r.consul.Remove(ctx, state.ConsulKV.Path.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("consul_kv"), "Failed to Remove QR Code from Consul", err.Error())
+			return
+		}
+	}
+
+	if state.VaultKV != nil {
+		if r.vault == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault_kv"), "Vault Not Configured", "Configure the Vault server that the QR code was written to in the provider vault block, or delete the secret by hand.")
+			return
+		}
+		if err := r.vault.Remove(ctx, state.VaultKV); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vault_kv"), "Failed to Remove QR Code from Vault", err.Error())
+			return
+		}
+	}
+
 	// Remove the file if it exists
-	if state.File.IsNull() || state.File.ValueString() == "" {
+	if written.Path == "" {
 		return // No file to delete
 	}
 
-	filePath := state.File.ValueString()
+	filePath := written.Path
 
-	if _, err := os.Stat(filePath); err == nil {
+	// Lstat so that a symbolic link is removed rather than the file it points to
+	if _, err := lstat(r.fs, filePath); err == nil {
 		// File exists, attempt to delete
-		if err := os.Remove(filePath); err != nil {
+		if err := r.fs.Remove(hostPath(filePath)); err != nil {
+			resp.Diagnostics.AddError("Failed to Delete QR Code", err.Error())
+			return
+		}
+
+		tflog.Debug(ctx, "Deleted QR code file", map[string]interface{}{
+			"file": filePath,
+		})
+	}
+
+	for _, variantPath := range written.Variants {
+		if err := r.fs.Remove(hostPath(variantPath)); err != nil && !os.IsNotExist(err) {
 			resp.Diagnostics.AddError("Failed to Delete QR Code", err.Error())
 			return
 		}
@@ -224,3 +3115,91 @@ func (r *qrcodeResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	// Remove the resource from state
 	resp.State.RemoveResource(ctx)
 }
+
+// replacedFileDiagnostics reports the written files that no longer have the checksum they were
+// written with. Files that are gone, or that were written before their checksums were recorded,
+// are not reported.
+func (r *qrcodeResource) replacedFileDiagnostics(written writtenFiles) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if written.Path == "" {
+		return diags
+	}
+
+	files := map[string]string{written.Path: written.SHA256}
+	for size, variantPath := range written.Variants {
+		files[variantPath] = written.VariantsSHA256[size]
+	}
+
+	for filePath, checksum := range files {
+		if checksum == "" {
+			continue
+		}
+
+		actual, err := fileSHA256(r.fs, filePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			diags.AddError("Failed to Delete QR Code", err.Error())
+			continue
+		}
+
+		if actual != checksum {
+			diags.AddError(
+				"QR Code File Replaced",
+				fmt.Sprintf("The file %s has SHA-256 checksum %s, but was written with %s, so another process may have replaced it. It is not deleted. Move the file away, or set force_delete to true and apply before destroying to delete it anyway.", filePath, actual, checksum),
+			)
+		}
+	}
+
+	return diags
+}
+
+// ImportState imports an existing QR code file by its path.
+func (r *qrcodeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughWithIdentity(ctx, path.Root("file"), path.Root("file"), req, resp)
+}
+
+// imageEncodesText reports whether the image at filePath still encodes the text in state. Images
+// that cannot be verified are assumed to match: encrypted images and content, non-PNG images, text
+// transcoded to a legacy character set, which decoders may guess differently, and normalized or
+// IDN converted referenced text, of which state only keeps the checksum before conversion.
+func imageEncodesText(fs afero.Fs, state qrcodeResourceModel, filePath string) (bool, error) {
+	format := state.Format.ValueString()
+	byteCharset := state.ByteCharset.ValueString()
+	if state.Encrypt != nil || state.ContentEncryption != nil || (format != "" && format != imageFormatPNG) || (byteCharset != "" && byteCharset != qrgen.ByteCharsetUTF8) {
+		return true, nil
+	}
+	if (state.hasTextReference() || !state.ContentFile.IsNull()) && (state.Normalize != nil || !state.IDNMode.IsNull()) {
+		return true, nil
+	}
+
+	data, err := afero.ReadFile(fs, hostPath(filePath))
+	if err != nil {
+		return false, err
+	}
+
+	// An image without a readable QR code was replaced too
+	text, err := decodeQRCodeImage(data)
+	if err != nil {
+		return false, nil
+	}
+	decoded, err := decodeContent(text, state.ContentEncoding.ValueString(), state.Compress.ValueBool())
+	if err != nil {
+		return false, nil
+	}
+	if state.SignJWS.ValueBool() {
+		if decoded, err = jwsPayload(string(decoded)); err != nil {
+			return false, nil
+		}
+	}
+
+	if state.hasTextReference() {
+		return computeSHA256(string(decoded)) == state.SensitiveTextSHA256.ValueString(), nil
+	}
+	if !state.ContentFile.IsNull() {
+		return computeSHA256(string(decoded)) == state.ContentFileSHA256.ValueString(), nil
+	}
+
+	return string(decoded) == state.content(), nil
+}
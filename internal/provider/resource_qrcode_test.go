@@ -1,18 +1,35 @@
 package provider
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"filippo.io/age"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/spf13/afero"
+	"terraform-provider-qrcode/pkg/qrgen"
 )
 
 // randomTempFileName generates a random temporary file name.
@@ -83,9 +100,1925 @@ func TestAccQRCodeResource(t *testing.T) {
 					),
 				),
 			},
+			// Verify the QR code file can be imported by its path
+			{
+				ResourceName:                         "qrcode_generate.test",
+				ImportState:                          true,
+				ImportStateId:                        filePath,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "file",
+				ImportStateVerifyIgnore:              []string{"text", "ascii", "ascii_sha256"},
+			},
 		},
 	})
 
 	// Cleanup the test file
 	_ = os.Remove(filePath)
 }
+
+// TestQRCodeResourceModifyPlanUnknownContent verifies that unknown content is deferred or left unknown.
+func TestQRCodeResourceModifyPlanUnknownContent(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	planRaw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":   tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"file":   tftypes.NewValue(tftypes.String, "/tmp/qrcode.png"),
+		"sha256": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	})
+	configRaw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"file": tftypes.NewValue(tftypes.String, "/tmp/qrcode.png"),
+	})
+
+	for _, deferralAllowed := range []bool{true, false} {
+		req := fwresource.ModifyPlanRequest{
+			Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: configRaw},
+			Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: planRaw},
+			ClientCapabilities: fwresource.ModifyPlanClientCapabilities{
+				DeferralAllowed: deferralAllowed,
+			},
+		}
+		resp := &fwresource.ModifyPlanResponse{
+			Plan: req.Plan,
+		}
+
+		r.ModifyPlan(ctx, req, resp)
+
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		if deferralAllowed != (resp.Deferred != nil) {
+			t.Errorf("deferral allowed %t: expected deferred %t, got %v", deferralAllowed, deferralAllowed, resp.Deferred)
+		}
+
+		var plan qrcodeResourceModel
+		resp.Plan.Get(ctx, &plan)
+		if !plan.SHA256.IsUnknown() {
+			t.Errorf("deferral allowed %t: expected unknown sha256, got %s", deferralAllowed, plan.SHA256)
+		}
+	}
+}
+
+// TestQRCodeResourceModifyPlanSignedSize verifies that a size that depends on the symbol of signed
+// content is left unknown rather than deferred, as the configuration is known.
+func TestQRCodeResourceModifyPlanSignedSize(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	raw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":              tftypes.NewValue(tftypes.String, "https://example.com"),
+		"file":              tftypes.NewValue(tftypes.String, "/tmp/qrcode.png"),
+		"sign_jws":          tftypes.NewValue(tftypes.Bool, true),
+		"pixels_per_module": tftypes.NewValue(tftypes.Number, 8),
+	})
+	req := fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+		ClientCapabilities: fwresource.ModifyPlanClientCapabilities{
+			DeferralAllowed: true,
+		},
+	}
+	resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+
+	r.ModifyPlan(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if resp.Deferred != nil {
+		t.Errorf("expected the change not to be deferred, got %v", resp.Deferred)
+	}
+
+	var plan qrcodeResourceModel
+	resp.Plan.Get(ctx, &plan)
+	if !plan.Size.IsUnknown() {
+		t.Errorf("expected unknown size, got %s", plan.Size)
+	}
+}
+
+// TestQRCodeResourceModifyPlanPhysicalSize verifies that the size in pixels and the printed widths
+// are planned from whichever of them is configured.
+func TestQRCodeResourceModifyPlanPhysicalSize(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config          map[string]tftypes.Value
+		expectedSize    types.Int64
+		expectedWidthMM types.Float64
+		expectedWidthIn types.Float64
+		expectError     bool
+	}{
+		"size": {
+			config:          map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 300)},
+			expectedSize:    types.Int64Value(300),
+			expectedWidthMM: types.Float64Null(),
+			expectedWidthIn: types.Float64Null(),
+		},
+		"size and dpi": {
+			config: map[string]tftypes.Value{
+				"size": tftypes.NewValue(tftypes.Number, 600),
+				"dpi":  tftypes.NewValue(tftypes.Number, 300),
+			},
+			expectedSize:    types.Int64Value(600),
+			expectedWidthMM: types.Float64Value(50.8),
+			expectedWidthIn: types.Float64Value(2),
+		},
+		"default size and dpi": {
+			config:          map[string]tftypes.Value{"dpi": tftypes.NewValue(tftypes.Number, 256)},
+			expectedSize:    types.Int64Null(),
+			expectedWidthMM: types.Float64Value(25.4),
+			expectedWidthIn: types.Float64Value(1),
+		},
+		"width_mm": {
+			config: map[string]tftypes.Value{
+				"width_mm": tftypes.NewValue(tftypes.Number, 30),
+				"dpi":      tftypes.NewValue(tftypes.Number, 600),
+			},
+			expectedSize:    types.Int64Value(709),
+			expectedWidthMM: types.Float64Value(30),
+			expectedWidthIn: types.Float64Value(1.18),
+		},
+		"width_in": {
+			config: map[string]tftypes.Value{
+				"width_in": tftypes.NewValue(tftypes.Number, 1.5),
+				"dpi":      tftypes.NewValue(tftypes.Number, 300),
+			},
+			expectedSize:    types.Int64Value(450),
+			expectedWidthMM: types.Float64Value(38.1),
+			expectedWidthIn: types.Float64Value(1.5),
+		},
+		"pixels_per_module": {
+			config: map[string]tftypes.Value{
+				"pixels_per_module": tftypes.NewValue(tftypes.Number, 4),
+				"dpi":               tftypes.NewValue(tftypes.Number, 132),
+			},
+			expectedSize:    types.Int64Value(132),
+			expectedWidthMM: types.Float64Value(25.4),
+			expectedWidthIn: types.Float64Value(1),
+		},
+		"size_from_module_px within default size": {
+			config:          map[string]tftypes.Value{"size_from_module_px": tftypes.NewValue(tftypes.Number, 4)},
+			expectedSize:    types.Int64Value(256),
+			expectedWidthMM: types.Float64Null(),
+			expectedWidthIn: types.Float64Null(),
+		},
+		"size_from_module_px growing the size": {
+			config:          map[string]tftypes.Value{"size_from_module_px": tftypes.NewValue(tftypes.Number, 10)},
+			expectedSize:    types.Int64Value(330),
+			expectedWidthMM: types.Float64Null(),
+			expectedWidthIn: types.Float64Null(),
+		},
+		"size_from_module_px too large": {
+			config:      map[string]tftypes.Value{"size_from_module_px": tftypes.NewValue(tftypes.Number, 1000)},
+			expectError: true,
+		},
+		"width without dpi": {
+			config:      map[string]tftypes.Value{"width_mm": tftypes.NewValue(tftypes.Number, 30)},
+			expectError: true,
+		},
+		"width too large": {
+			config: map[string]tftypes.Value{
+				"width_in": tftypes.NewValue(tftypes.Number, 10),
+				"dpi":      tftypes.NewValue(tftypes.Number, 300),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testCase.config["text"] = tftypes.NewValue(tftypes.String, "https://example.com")
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{
+				Plan: req.Plan,
+			}
+
+			r.ModifyPlan(ctx, req, resp)
+
+			if testCase.expectError {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var plan qrcodeResourceModel
+			resp.Plan.Get(ctx, &plan)
+			if !plan.Size.Equal(testCase.expectedSize) {
+				t.Errorf("expected size %s, got %s", testCase.expectedSize, plan.Size)
+			}
+			if !plan.WidthMM.Equal(testCase.expectedWidthMM) {
+				t.Errorf("expected width_mm %s, got %s", testCase.expectedWidthMM, plan.WidthMM)
+			}
+			if !plan.WidthIn.Equal(testCase.expectedWidthIn) {
+				t.Errorf("expected width_in %s, got %s", testCase.expectedWidthIn, plan.WidthIn)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceModifyPlanSizeLimits verifies that sizes outside the provider size limits
+// fail the plan rather than the apply.
+func TestQRCodeResourceModifyPlanSizeLimits(t *testing.T) {
+	ctx := context.Background()
+
+	schemaResp := &fwresource.SchemaResponse{}
+	(&qrcodeResource{}).Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	posters := sizeLimits{min: 300, max: 4000}
+
+	testCases := map[string]struct {
+		limits      sizeLimits
+		config      map[string]tftypes.Value
+		expectError bool
+	}{
+		"default limits": {
+			config: map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 2000)},
+		},
+		"above default limits": {
+			config:      map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 3000)},
+			expectError: true,
+		},
+		"below default limits": {
+			config:      map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 50)},
+			expectError: true,
+		},
+		"within provider limits": {
+			limits: posters,
+			config: map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 4000)},
+		},
+		"banner": {
+			limits: sizeLimits{min: minSize, max: sizeCeiling},
+			config: map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 6000)},
+		},
+		"below provider limits": {
+			limits:      posters,
+			config:      map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 256)},
+			expectError: true,
+		},
+		"default size below provider limits": {
+			limits:      posters,
+			config:      map[string]tftypes.Value{},
+			expectError: true,
+		},
+		"width within provider limits": {
+			limits: posters,
+			config: map[string]tftypes.Value{
+				"width_in": tftypes.NewValue(tftypes.Number, 10),
+				"dpi":      tftypes.NewValue(tftypes.Number, 300),
+			},
+		},
+		"size variant above provider limits": {
+			limits: posters,
+			config: map[string]tftypes.Value{
+				"size":     tftypes.NewValue(tftypes.Number, 1000),
+				"filename": tftypes.NewValue(tftypes.String, "/out/code.png"),
+				"sizes": tftypes.NewValue(tftypes.List{ElementType: tftypes.Number}, []tftypes.Value{
+					tftypes.NewValue(tftypes.Number, 512),
+					tftypes.NewValue(tftypes.Number, 5000),
+				}),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := &qrcodeResource{fs: afero.NewMemMapFs(), sizeLimits: testCase.limits}
+
+			testCase.config["text"] = tftypes.NewValue(tftypes.String, "https://example.com")
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{
+				Plan: req.Plan,
+			}
+
+			r.ModifyPlan(ctx, req, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Errorf("expected error %t, got diagnostics: %v", testCase.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceModifyPlanSymbolMetadata verifies that the symbol metadata is known at plan
+// time once the text is known.
+func TestQRCodeResourceModifyPlanSymbolMetadata(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		text             tftypes.Value
+		expectedVersion  types.Int64
+		expectedModules  types.Int64
+		expectedMode     types.String
+		expectedCapacity types.Float64
+	}{
+		"known text": {
+			text:             tftypes.NewValue(tftypes.String, "HTTPS://EXAMPLE.COM/0123456789012345678901234567890123456789"),
+			expectedVersion:  types.Int64Value(3),
+			expectedModules:  types.Int64Value(29),
+			expectedMode:     types.StringValue("alphanumeric+numeric"),
+			expectedCapacity: types.Float64Value(1.49),
+		},
+		"unknown text": {
+			text:             tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+			expectedVersion:  types.Int64Unknown(),
+			expectedModules:  types.Int64Unknown(),
+			expectedMode:     types.StringUnknown(),
+			expectedCapacity: types.Float64Unknown(),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+					"text": testCase.text,
+				})},
+				Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+					"text":                  testCase.text,
+					"qr_version":            tftypes.NewValue(tftypes.Number, tftypes.UnknownValue),
+					"module_count":          tftypes.NewValue(tftypes.Number, tftypes.UnknownValue),
+					"encoding_mode_used":    tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+					"capacity_used_percent": tftypes.NewValue(tftypes.Number, tftypes.UnknownValue),
+				})},
+			}
+			resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+
+			r.ModifyPlan(ctx, req, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var plan qrcodeResourceModel
+			resp.Plan.Get(ctx, &plan)
+			if !plan.QRVersion.Equal(testCase.expectedVersion) || !plan.ModuleCount.Equal(testCase.expectedModules) {
+				t.Errorf("expected version %s with %s modules, got %s with %s", testCase.expectedVersion, testCase.expectedModules, plan.QRVersion, plan.ModuleCount)
+			}
+			if !plan.EncodingModeUsed.Equal(testCase.expectedMode) {
+				t.Errorf("expected encoding_mode_used %s, got %s", testCase.expectedMode, plan.EncodingModeUsed)
+			}
+			if !plan.CapacityUsedPercent.Equal(testCase.expectedCapacity) {
+				t.Errorf("expected capacity_used_percent %s, got %s", testCase.expectedCapacity, plan.CapacityUsedPercent)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceModifyPlanCapacityWarning verifies that plans warn when the content nears
+// the capacity of the largest QR code.
+func TestQRCodeResourceModifyPlanCapacityWarning(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	// 2200 bytes take 94.37% of a version 40 symbol at the Medium level
+	longText := tftypes.NewValue(tftypes.String, strings.Repeat("a", 2200))
+
+	testCases := map[string]struct {
+		config          map[string]tftypes.Value
+		expectedWarning bool
+	}{
+		"short text": {
+			config: map[string]tftypes.Value{"text": tftypes.NewValue(tftypes.String, "https://example.com")},
+		},
+		"long text": {
+			config:          map[string]tftypes.Value{"text": longText},
+			expectedWarning: true,
+		},
+		"long text below threshold": {
+			config: map[string]tftypes.Value{
+				"text":                     longText,
+				"capacity_warning_percent": tftypes.NewValue(tftypes.Number, 95),
+			},
+		},
+		"short text above threshold": {
+			config: map[string]tftypes.Value{
+				"text":                     tftypes.NewValue(tftypes.String, "https://example.com"),
+				"capacity_warning_percent": tftypes.NewValue(tftypes.Number, 0),
+			},
+			expectedWarning: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Size the image so that the modules are large enough to scan
+			testCase.config["size"] = tftypes.NewValue(tftypes.Number, 1000)
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+
+			r.ModifyPlan(ctx, req, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			warned := false
+			for _, d := range resp.Diagnostics.Warnings() {
+				warned = warned || d.Summary() == "QR Code Near Capacity"
+			}
+			if warned != testCase.expectedWarning {
+				t.Errorf("expected warning %t, got %v", testCase.expectedWarning, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceModifyPlanScannability verifies that plans warn about modules below the thresholds.
+func TestQRCodeResourceModifyPlanScannability(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	// 500 bytes encode as a version 17 symbol of 93 modules including the quiet zone
+	longText := tftypes.NewValue(tftypes.String, strings.Repeat("a", 500))
+
+	testCases := map[string]struct {
+		config      map[string]tftypes.Value
+		expected    []path.Path
+		expectError bool
+	}{
+		"large modules": {
+			config: map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 1000)},
+		},
+		"small modules": {
+			config:   map[string]tftypes.Value{"size": tftypes.NewValue(tftypes.Number, 200)},
+			expected: []path.Path{path.Root("size")},
+		},
+		"lower threshold": {
+			config: map[string]tftypes.Value{
+				"size":              tftypes.NewValue(tftypes.Number, 200),
+				"min_module_pixels": tftypes.NewValue(tftypes.Number, 2),
+			},
+		},
+		"vector format": {
+			config: map[string]tftypes.Value{
+				"width_mm": tftypes.NewValue(tftypes.Number, 40),
+				"dpi":      tftypes.NewValue(tftypes.Number, 72),
+				"format":   tftypes.NewValue(tftypes.String, "svg"),
+			},
+		},
+		"small printed modules": {
+			config: map[string]tftypes.Value{
+				"width_mm": tftypes.NewValue(tftypes.Number, 25),
+				"dpi":      tftypes.NewValue(tftypes.Number, 1200),
+			},
+			expected: []path.Path{path.Root("dpi")},
+		},
+		"large printed modules": {
+			config: map[string]tftypes.Value{
+				"width_mm": tftypes.NewValue(tftypes.Number, 40),
+				"dpi":      tftypes.NewValue(tftypes.Number, 600),
+			},
+		},
+		"narrow quiet zone": {
+			config: map[string]tftypes.Value{
+				"size":       tftypes.NewValue(tftypes.Number, 1000),
+				"quiet_zone": tftypes.NewValue(tftypes.Number, 1),
+			},
+			expected: []path.Path{path.Root("quiet_zone")},
+		},
+		"wide quiet zone": {
+			config: map[string]tftypes.Value{
+				"size":       tftypes.NewValue(tftypes.Number, 1000),
+				"quiet_zone": tftypes.NewValue(tftypes.Number, 6),
+			},
+		},
+		"low contrast": {
+			config: map[string]tftypes.Value{
+				"size":             tftypes.NewValue(tftypes.Number, 1000),
+				"foreground_color": tftypes.NewValue(tftypes.String, "#7f7f7f"),
+				"background_color": tftypes.NewValue(tftypes.String, "#ffffff"),
+			},
+			expected: []path.Path{path.Root("foreground_color")},
+		},
+		"low quiet zone contrast": {
+			config: map[string]tftypes.Value{
+				"size":             tftypes.NewValue(tftypes.Number, 1000),
+				"background_color": tftypes.NewValue(tftypes.String, "#ffffff"),
+				"quiet_zone_color": tftypes.NewValue(tftypes.String, "#3f3f3f"),
+			},
+			expected: []path.Path{path.Root("quiet_zone_color")},
+		},
+		"white quiet zone on a colored background": {
+			config: map[string]tftypes.Value{
+				"size":             tftypes.NewValue(tftypes.Number, 1000),
+				"background_color": tftypes.NewValue(tftypes.String, "#f4c430"),
+				"quiet_zone_color": tftypes.NewValue(tftypes.String, "#ffffff"),
+			},
+		},
+		"low eye contrast": {
+			config: map[string]tftypes.Value{
+				"size":               tftypes.NewValue(tftypes.Number, 1000),
+				"eye_color":          tftypes.NewValue(tftypes.String, "#1a237e"),
+				"eye_color_top_left": tftypes.NewValue(tftypes.String, "#ffd54f"),
+			},
+			expected: []path.Path{path.Root("eye_color_top_left")},
+		},
+		"lower contrast threshold": {
+			config: map[string]tftypes.Value{
+				"size":               tftypes.NewValue(tftypes.Number, 1000),
+				"foreground_color":   tftypes.NewValue(tftypes.String, "#7f7f7f"),
+				"min_contrast_ratio": tftypes.NewValue(tftypes.Number, 3),
+			},
+		},
+		"strict": {
+			config: map[string]tftypes.Value{
+				"size":       tftypes.NewValue(tftypes.Number, 1000),
+				"quiet_zone": tftypes.NewValue(tftypes.Number, 0),
+				"strict":     tftypes.NewValue(tftypes.Bool, true),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testCase.config["text"] = longText
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{
+				Plan: req.Plan,
+			}
+
+			r.ModifyPlan(ctx, req, resp)
+
+			if testCase.expectError {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			warnings := resp.Diagnostics.Warnings()
+			if len(warnings) != len(testCase.expected) {
+				t.Fatalf("expected %d warnings, got %v", len(testCase.expected), warnings)
+			}
+			for i, expected := range testCase.expected {
+				withPath, ok := warnings[i].(diag.DiagnosticWithPath)
+				if !ok || !withPath.Path().Equal(expected) {
+					t.Errorf("expected a warning on %s, got %v", expected, warnings[i])
+				}
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceValidateConfigSVGSize verifies that size is rejected for SVG images, which
+// scale to the size they are shown at.
+func TestQRCodeResourceValidateConfigSVGSize(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		format      string
+		expectError bool
+	}{
+		imageFormatPNG: {},
+		imageFormatPDF: {},
+		imageFormatSVG: {expectError: true},
+	}
+
+	for format, testCase := range testCases {
+		t.Run(format, func(t *testing.T) {
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"text":   tftypes.NewValue(tftypes.String, "qrcode"),
+				"size":   tftypes.NewValue(tftypes.Number, 300),
+				"format": tftypes.NewValue(tftypes.String, format),
+			})
+
+			resp := &fwresource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+			}, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Fatalf("expected error %t, got %v", testCase.expectError, resp.Diagnostics)
+			}
+			if testCase.expectError {
+				withPath, ok := resp.Diagnostics.Errors()[0].(diag.DiagnosticWithPath)
+				if !ok || !withPath.Path().Equal(path.Root("size")) {
+					t.Errorf("expected the error on size, got %v", resp.Diagnostics.Errors()[0])
+				}
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceValidateConfigLogo verifies that logos are only drawn on PNG images that are
+// not re-encoded with interlacing, reproducibly or over a background image.
+func TestQRCodeResourceValidateConfigLogo(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		config      map[string]tftypes.Value
+		expectError bool
+	}{
+		"png": {
+			config: map[string]tftypes.Value{"format": tftypes.NewValue(tftypes.String, imageFormatPNG)},
+		},
+		"level L": {
+			config: map[string]tftypes.Value{"error_correction": tftypes.NewValue(tftypes.String, "L")},
+		},
+		"svg": {
+			config:      map[string]tftypes.Value{"format": tftypes.NewValue(tftypes.String, imageFormatSVG)},
+			expectError: true,
+		},
+		"reproducible": {
+			config:      map[string]tftypes.Value{"reproducible": tftypes.NewValue(tftypes.Bool, true)},
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testCase.config["text"] = tftypes.NewValue(tftypes.String, "qrcode")
+			testCase.config["logo_file"] = tftypes.NewValue(tftypes.String, "logo.png")
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), testCase.config)
+
+			resp := &fwresource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+			}, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Fatalf("expected error %t, got %v", testCase.expectError, resp.Diagnostics)
+			}
+			if testCase.expectError {
+				withPath, ok := resp.Diagnostics.Errors()[0].(diag.DiagnosticWithPath)
+				if !ok || !withPath.Path().Equal(path.Root("logo_file")) {
+					t.Errorf("expected the error on logo_file, got %v", resp.Diagnostics.Errors()[0])
+				}
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceModifyPlanLogo verifies that plans fail when the logo hides more codewords
+// than the error correction level restores, suggesting the lowest level that restores them.
+func TestQRCodeResourceModifyPlanLogo(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		level              string
+		percent            int64
+		expectedSuggestion string
+	}{
+		"default logo at level M": {level: "M", percent: defaultLogoSizePercent},
+		"default logo at level L": {level: "L", percent: defaultLogoSizePercent, expectedSuggestion: "Set error_correction to M"},
+		"large logo at level M":   {level: "M", percent: 20, expectedSuggestion: "Set error_correction to Q"},
+		"large logo at level Q":   {level: "Q", percent: 25, expectedSuggestion: "Set error_correction to H"},
+		"largest logo":            {level: "M", percent: maxLogoSizePercent, expectedSuggestion: "reduce logo_size_percent"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"text":              tftypes.NewValue(tftypes.String, "https://example.com"),
+				"error_correction":  tftypes.NewValue(tftypes.String, testCase.level),
+				"logo_file":         tftypes.NewValue(tftypes.String, "/in/logo.png"),
+				"logo_size_percent": tftypes.NewValue(tftypes.Number, testCase.percent),
+			})
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+
+			r.ModifyPlan(ctx, req, resp)
+			if testCase.expectedSuggestion == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+				}
+				return
+			}
+
+			if len(resp.Diagnostics.Errors()) != 1 {
+				t.Fatalf("expected an error, got %v", resp.Diagnostics)
+			}
+			err := resp.Diagnostics.Errors()[0]
+			if withPath, ok := err.(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("logo_file")) {
+				t.Errorf("expected the error on logo_file, got %v", err)
+			}
+			if !strings.Contains(err.Detail(), testCase.expectedSuggestion) {
+				t.Errorf("expected %q, got %q", testCase.expectedSuggestion, err.Detail())
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceRenderLogo verifies that logo_file is read and drawn over the image, which
+// still decodes, and that a missing logo is reported on logo_file.
+func TestQRCodeResourceRenderLogo(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+	if err := afero.WriteFile(r.fs, "/in/logo.png", testLogoPNG(t), 0o644); err != nil {
+		t.Fatalf("failed to write the logo: %s", err)
+	}
+
+	symbol, err := qrgen.Encode("https://example.com", qrgen.Options{Level: qrgen.Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	plan := qrcodeResourceModel{LogoFile: types.StringValue("/in/logo.png")}
+	data, diags := r.renderPNG(ctx, plan, symbol, defaultSize, qrgen.DefaultColors)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if bytes.Equal(data, testRenderPNG(t, "https://example.com")) {
+		t.Errorf("expected the logo to be drawn")
+	}
+	if text, err := decodeQRCodeImage(data); err != nil || text != "https://example.com" {
+		t.Errorf("expected the QR code with a logo to decode, got %q: %v", text, err)
+	}
+
+	plan.LogoFile = types.StringValue("/in/missing.png")
+	_, diags = r.renderPNG(ctx, plan, symbol, defaultSize, qrgen.DefaultColors)
+	if len(diags.Errors()) != 1 {
+		t.Fatalf("expected an error for a missing logo, got %v", diags)
+	}
+	if withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("logo_file")) {
+		t.Errorf("expected the error on logo_file, got %v", diags.Errors()[0])
+	}
+}
+
+// TestAccQRCodeResourceContentAddressed verifies that a directory file path produces a content-addressed file name.
+func TestAccQRCodeResourceContentAddressed(t *testing.T) {
+	dir := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text = "qrcode"
+						file = "` + dir + `/"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"qrcode_generate.test", "filename",
+						regexp.MustCompile(regexp.QuoteMeta(dir)+`.[0-9a-f]{16}\.png$`),
+					),
+					// Verify the file is named after its checksum
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["qrcode_generate.test"].Primary.Attributes
+						expected := contentAddressedFilePath(dir, attributes["sha256"], imageFormatPNG)
+						if attributes["filename"] != expected {
+							return fmt.Errorf("expected filename %s, got %s", expected, attributes["filename"])
+						}
+						if _, err := os.Stat(expected); err != nil {
+							return err
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+
+	// Cleanup the test directory
+	_ = os.RemoveAll(dir)
+}
+
+// TestAccQRCodeResourceWithoutFile verifies that the image can be kept only in state.
+func TestAccQRCodeResourceWithoutFile(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text = "qrcode"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("qrcode_generate.test", "filename"),
+					resource.TestCheckResourceAttrSet("qrcode_generate.test", "content_base64"),
+					// The ASCII rendering matches the data source for the same content
+					resource.TestCheckResourceAttr(
+						"qrcode_generate.test", "ascii_sha256",
+						"1008c2f94d40f67e0f9f212284e9535aff2919fb256d512ad5edfa02929b55a5",
+					),
+					// Verify the encoded image matches the checksum
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["qrcode_generate.test"].Primary.Attributes
+						pngData, err := base64.StdEncoding.DecodeString(attributes["content_base64"])
+						if err != nil {
+							return err
+						}
+						hash := sha256.Sum256(pngData)
+						if actual := hex.EncodeToString(hash[:]); actual != attributes["sha256"] {
+							return fmt.Errorf("expected SHA-256 checksum %s, got %s", attributes["sha256"], actual)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// TestAccQRCodeResourceExpectedSHA256 verifies that a mismatching pinned checksum fails the apply.
+func TestAccQRCodeResourceExpectedSHA256(t *testing.T) {
+	filePath := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text            = "qrcode"
+						file            = "` + filePath + `"
+						expected_sha256 = "0000000000000000000000000000000000000000000000000000000000000000"
+					}
+				`,
+				ExpectError: regexp.MustCompile("QR Code Checksum Mismatch"),
+			},
+		},
+	})
+
+	// Verify the mismatching image was not written
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Errorf("file %s should not exist", filePath)
+		_ = os.Remove(filePath)
+	}
+}
+
+// TestQRCodeResourceReadMissingFile verifies the on_missing_file behaviors.
+func TestQRCodeResourceReadMissingFile(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+	filePath := randomTempFileName()
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	for _, onMissingFile := range []string{"", onMissingFileRemove, onMissingFileRecreate, onMissingFileError} {
+		values := map[string]tftypes.Value{
+			"text":     tftypes.NewValue(tftypes.String, "qrcode"),
+			"file":     tftypes.NewValue(tftypes.String, filePath),
+			"filename": tftypes.NewValue(tftypes.String, filePath),
+			"sha256":   tftypes.NewValue(tftypes.String, "0000000000000000000000000000000000000000000000000000000000000000"),
+		}
+		if onMissingFile != "" {
+			values["on_missing_file"] = tftypes.NewValue(tftypes.String, onMissingFile)
+		}
+
+		state := tfsdk.State{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+		resp := &fwresource.ReadResponse{State: state}
+
+		r.Read(ctx, fwresource.ReadRequest{State: state}, resp)
+
+		switch onMissingFile {
+		case onMissingFileError:
+			if !resp.Diagnostics.HasError() {
+				t.Errorf("on_missing_file %q: expected an error", onMissingFile)
+			}
+		case onMissingFileRecreate:
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("on_missing_file %q: unexpected diagnostics: %v", onMissingFile, resp.Diagnostics)
+			}
+			var model qrcodeResourceModel
+			resp.State.Get(ctx, &model)
+			if !model.SHA256.IsNull() || model.File.ValueString() != filePath {
+				t.Errorf("on_missing_file %q: expected null sha256 and file kept, got %s and %s", onMissingFile, model.SHA256, model.File)
+			}
+		default:
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("on_missing_file %q: unexpected diagnostics: %v", onMissingFile, resp.Diagnostics)
+			}
+			if !resp.State.Raw.IsNull() {
+				t.Errorf("on_missing_file %q: expected the resource to be removed from state", onMissingFile)
+			}
+		}
+	}
+}
+
+// TestAccQRCodeResourceSymlink verifies that a symbolic link is replaced by default and its target is left untouched.
+func TestAccQRCodeResourceSymlink(t *testing.T) {
+	dir := randomTempFileName()
+	target := filepath.Join(dir, "target.png")
+	link := filepath.Join(dir, "link.png")
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("target"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symbolic links are not supported: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text = "qrcode"
+						file = "` + link + `"
+					}
+				`,
+				Check: func(s *terraform.State) error {
+					if isSymlink(afero.NewOsFs(), link) {
+						return fmt.Errorf("expected %s to be replaced with a regular file", link)
+					}
+					data, err := os.ReadFile(target)
+					if err != nil {
+						return err
+					}
+					if string(data) != "target" {
+						return fmt.Errorf("expected the symbolic link target to be left untouched")
+					}
+					return nil
+				},
+			},
+		},
+	})
+
+	// Cleanup the test directory
+	_ = os.RemoveAll(dir)
+}
+
+// TestAccQRCodeResourceSVG verifies that the image can be rendered as SVG.
+func TestAccQRCodeResourceSVG(t *testing.T) {
+	dir := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text     = "qrcode"
+						file     = "` + dir + `/"
+						format   = "svg"
+						alt_text = "Test code"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"qrcode_generate.test", "filename",
+						regexp.MustCompile(`[0-9a-f]{16}\.svg$`),
+					),
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["qrcode_generate.test"].Primary.Attributes
+						data, err := os.ReadFile(attributes["filename"])
+						if err != nil {
+							return err
+						}
+						if !strings.Contains(string(data), "<title id=\"qrcode-title\">Test code</title>") {
+							return fmt.Errorf("expected SVG title in %s", attributes["filename"])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+
+	// Cleanup the test directory
+	_ = os.RemoveAll(dir)
+}
+
+// TestAccQRCodeResourceEncrypt verifies that only the encrypted image is written and kept in state.
+func TestAccQRCodeResourceEncrypt(t *testing.T) {
+	dir := randomTempFileName()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_generate" "test" {
+						text = "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP"
+						file = "` + dir + `/"
+
+						encrypt {
+							age_recipients = ["` + identity.Recipient().String() + `"]
+						}
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"qrcode_generate.test", "filename",
+						regexp.MustCompile(`[0-9a-f]{16}\.png\.age$`),
+					),
+					resource.TestCheckNoResourceAttr("qrcode_generate.test", "ascii"),
+					resource.TestCheckResourceAttrSet("qrcode_generate.test", "encrypted_sha256"),
+					func(s *terraform.State) error {
+						attributes := s.RootModule().Resources["qrcode_generate.test"].Primary.Attributes
+						file, err := os.Open(attributes["filename"])
+						if err != nil {
+							return err
+						}
+						defer file.Close()
+
+						r, err := age.Decrypt(file, identity)
+						if err != nil {
+							return err
+						}
+						data, err := io.ReadAll(r)
+						if err != nil {
+							return err
+						}
+						if checksum := sha256.Sum256(data); hex.EncodeToString(checksum[:]) != attributes["sha256"] {
+							return fmt.Errorf("decrypted image does not match sha256 %s", attributes["sha256"])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+
+	// Cleanup the test directory
+	_ = os.RemoveAll(dir)
+}
+
+// TestQRCodeResourceSensitiveTextEnv verifies that text read from an environment variable is
+// tracked by its checksum only: a changed variable plans a regeneration, the apply keeps neither
+// the text nor renderings that reveal it in state, and the rendering cannot be shown in diagnostics.
+func TestQRCodeResourceSensitiveTextEnv(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	t.Setenv("QRCODE_TEST_SECRET", "new secret")
+
+	configValues := map[string]tftypes.Value{
+		"sensitive_text_env": tftypes.NewValue(tftypes.String, "QRCODE_TEST_SECRET"),
+		"file":               tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+	}
+	stateValues := map[string]tftypes.Value{
+		"sensitive_text_env":    tftypes.NewValue(tftypes.String, "QRCODE_TEST_SECRET"),
+		"sensitive_text_sha256": tftypes.NewValue(tftypes.String, computeSHA256("old secret")),
+		"file":                  tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+		"filename":              tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+		"size":                  tftypes.NewValue(tftypes.Number, defaultSize),
+		"sha256":                tftypes.NewValue(tftypes.String, "0000000000000000000000000000000000000000000000000000000000000000"),
+	}
+	stateRaw := testObjectValue(ctx, schemaResp.Schema.Type(), stateValues)
+
+	req := fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), configValues)},
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: stateRaw},
+		State:  tfsdk.State{Schema: schemaResp.Schema, Raw: stateRaw},
+	}
+	resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+
+	r.ModifyPlan(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var plan qrcodeResourceModel
+	resp.Plan.Get(ctx, &plan)
+	if expected := computeSHA256("new secret"); plan.SensitiveTextSHA256.ValueString() != expected {
+		t.Errorf("expected sensitive_text_sha256 %s, got %s", expected, plan.SensitiveTextSHA256)
+	}
+	if !plan.SHA256.IsUnknown() || plan.Filename.ValueString() != "/out/qrcode.png" {
+		t.Errorf("expected an unknown sha256 and a known filename, got %s and %s", plan.SHA256, plan.Filename)
+	}
+	if plan.QRVersion.ValueInt64() != 1 || plan.EncodingModeUsed.ValueString() != "byte" {
+		t.Errorf("expected a version 1 byte mode symbol, got version %s in %s mode", plan.QRVersion, plan.EncodingModeUsed)
+	}
+
+	createResp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: resp.Plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.Create(ctx, fwresource.CreateRequest{Plan: resp.Plan}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", createResp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	createResp.State.Get(ctx, &state)
+	if !state.ContentBase64.IsNull() || !state.ASCII.IsNull() {
+		t.Errorf("expected no content_base64 or ascii in state")
+	}
+	if state.ModuleCount.ValueInt64() != 21 || state.CapacityUsedPercent.ValueFloat64() <= 0 {
+		t.Errorf("expected 21 modules and a capacity used, got %s and %s", state.ModuleCount, state.CapacityUsedPercent)
+	}
+	data, err := afero.ReadFile(r.fs, "/out/qrcode.png")
+	if err != nil {
+		t.Fatalf("failed to read the image: %s", err)
+	}
+	if text, err := decodeQRCodeImage(data); err != nil || text != "new secret" {
+		t.Errorf("expected the image to encode the variable, got %q: %v", text, err)
+	}
+
+	// The apply refuses text that changed after the plan
+	t.Setenv("QRCODE_TEST_SECRET", "newer secret")
+	createResp.Diagnostics = nil
+	r.Create(ctx, fwresource.CreateRequest{Plan: resp.Plan}, createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Errorf("expected an error for text that changed after the plan")
+	}
+
+	configValues["show_in_diagnostics"] = tftypes.NewValue(tftypes.Bool, true)
+	validateResp := &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), configValues)},
+	}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected an error for show_in_diagnostics with sensitive_text_env")
+	}
+}
+
+// TestQRCodeResourceReadVerifyOnRead verifies that a refresh with verify_on_read plans to write
+// the image again when it was replaced by one encoding other text.
+func TestQRCodeResourceReadVerifyOnRead(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+	filePath := "/out/qrcode.png"
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":           tftypes.NewValue(tftypes.String, "https://pay.example.com/invoice/42"),
+		"file":           tftypes.NewValue(tftypes.String, filePath),
+		"filename":       tftypes.NewValue(tftypes.String, filePath),
+		"verify_on_read": tftypes.NewValue(tftypes.Bool, true),
+		"sha256":         tftypes.NewValue(tftypes.String, "0000000000000000000000000000000000000000000000000000000000000000"),
+		"content_base64": tftypes.NewValue(tftypes.String, ""),
+	})}
+
+	for text, expectReplaced := range map[string]bool{
+		"https://pay.example.com/invoice/42":  false,
+		"https://pay.example.net/attacker/42": true,
+	} {
+		pngData := testRenderPNG(t, text)
+		if err := afero.WriteFile(r.fs, filePath, pngData, 0644); err != nil {
+			t.Fatalf("failed to write the image: %s", err)
+		}
+
+		resp := &fwresource.ReadResponse{
+			State:    state,
+			Identity: newIdentity(),
+		}
+		r.Read(ctx, fwresource.ReadRequest{State: state}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%q: unexpected diagnostics: %v", text, resp.Diagnostics)
+		}
+
+		var model qrcodeResourceModel
+		resp.State.Get(ctx, &model)
+		if replaced := model.SHA256.IsNull(); replaced != expectReplaced {
+			t.Errorf("%q: expected replaced %t, got %t", text, expectReplaced, replaced)
+		}
+		if replaced := resp.Diagnostics.WarningsCount() > 0; replaced != expectReplaced {
+			t.Errorf("%q: expected a warning %t, got %v", text, expectReplaced, resp.Diagnostics)
+		}
+	}
+}
+
+// TestQRCodeResourceFailOnOverwrite verifies that fail_on_overwrite refuses to replace an existing
+// file unless overwrite is set or the resource wrote the file before.
+func TestQRCodeResourceFailOnOverwrite(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	r := &qrcodeResource{fs: fs, writeOptions: writeOptions{failOnOverwrite: true}}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	if err := afero.WriteFile(fs, "/out/qrcode.png", []byte("other workspace"), 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	testCases := map[string]struct {
+		overwrite    bool
+		previousPath string
+		expectError  bool
+	}{
+		"existing file":      {expectError: true},
+		"overwrite":          {overwrite: true},
+		"written previously": {previousPath: "/out/qrcode.png"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			values := map[string]tftypes.Value{
+				"text": tftypes.NewValue(tftypes.String, "qrcode"),
+				"file": tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+			}
+			if testCase.overwrite {
+				values["overwrite"] = tftypes.NewValue(tftypes.Bool, true)
+			}
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+			resp := &fwresource.CreateResponse{
+				State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+				Identity: newIdentity(),
+			}
+			r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, testCase.previousPath)
+
+			data, _ := afero.ReadFile(fs, "/out/qrcode.png")
+			if testCase.expectError {
+				if !resp.Diagnostics.HasError() || string(data) != "other workspace" {
+					t.Errorf("expected the existing file to be kept, got %q: %v", data, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+			if string(data) == "other workspace" {
+				t.Errorf("expected the file to be replaced")
+			}
+
+			// Restore the file of the other workspace for the next case
+			if err := afero.WriteFile(fs, "/out/qrcode.png", []byte("other workspace"), 0644); err != nil {
+				t.Fatalf("failed to write file: %s", err)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceBackgroundImage verifies that the QR code is drawn onto the background image,
+// and that background images are rejected for interlaced images.
+func TestQRCodeResourceBackgroundImage(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	r := &qrcodeResource{fs: fs}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	var background bytes.Buffer
+	if err := png.Encode(&background, image.NewGray(image.Rect(0, 0, 640, 400))); err != nil {
+		t.Fatalf("failed to encode background: %s", err)
+	}
+	if err := afero.WriteFile(fs, "/templates/badge.png", background.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	backgroundImageType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object).AttributeTypes["background_image"].(tftypes.Object)
+	if !ok {
+		t.Fatalf("background_image is not an object")
+	}
+	values := map[string]tftypes.Value{
+		"text": tftypes.NewValue(tftypes.String, "qrcode"),
+		"file": tftypes.NewValue(tftypes.String, "/out/badge.png"),
+		"size": tftypes.NewValue(tftypes.Number, 200),
+		"background_image": tftypes.NewValue(backgroundImageType, map[string]tftypes.Value{
+			"path":    tftypes.NewValue(tftypes.String, "/templates/badge.png"),
+			"x":       tftypes.NewValue(tftypes.Number, 400),
+			"y":       tftypes.NewValue(tftypes.Number, 100),
+			"padding": tftypes.NewValue(tftypes.Number, 16),
+		}),
+	}
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	data, err := afero.ReadFile(fs, "/out/badge.png")
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width != 640 || config.Height != 400 {
+		t.Errorf("expected an image the size of the background, got %+v: %v", config, err)
+	}
+
+	// The QR code would leave the background at x 400 with a padded width of 232 pixels
+	values["size"] = tftypes.NewValue(tftypes.Number, 240)
+	plan.Raw = testObjectValue(ctx, schemaResp.Schema.Type(), values)
+	resp = &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if !resp.Diagnostics.HasError() {
+		t.Errorf("expected a QR code that does not fit the background to fail")
+	}
+
+	values["interlaced"] = tftypes.NewValue(tftypes.Bool, true)
+	validateResp := &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)},
+	}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected background_image and interlaced to conflict")
+	}
+}
+
+// TestQRCodeResourceMetadata verifies that metadata is written to the PNG image as text chunks,
+// and that it is rejected for other formats.
+func TestQRCodeResourceMetadata(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	r := &qrcodeResource{fs: fs}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	values := map[string]tftypes.Value{
+		"text": tftypes.NewValue(tftypes.String, "qrcode"),
+		"file": tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+		"metadata": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"Author":   tftypes.NewValue(tftypes.String, "Platform Team"),
+			"Asset ID": tftypes.NewValue(tftypes.String, "LBL-0042"),
+		}),
+	}
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	data, err := afero.ReadFile(fs, "/out/qrcode.png")
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+	for _, expected := range []string{"tEXtAsset ID\x00LBL-0042", "tEXtAuthor\x00Platform Team"} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("expected the image to contain %q", expected)
+		}
+	}
+	if text, err := decodeQRCodeImage(data); err != nil || text != "qrcode" {
+		t.Errorf("expected the QR code to decode, got %q: %v", text, err)
+	}
+
+	values["format"] = tftypes.NewValue(tftypes.String, imageFormatSVG)
+	validateResp := &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)},
+	}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected metadata to be rejected for SVG images")
+	}
+}
+
+// TestQRCodeResourceReproducible verifies that reproducible images match the checksum pinned by
+// the qrgen tests, and that they are rejected with an annotation.
+func TestQRCodeResourceReproducible(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	r := &qrcodeResource{fs: fs}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	values := map[string]tftypes.Value{
+		"text":         tftypes.NewValue(tftypes.String, "https://example.com"),
+		"file":         tftypes.NewValue(tftypes.String, "/out/qrcode.png"),
+		"reproducible": tftypes.NewValue(tftypes.Bool, true),
+	}
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	data, err := afero.ReadFile(fs, "/out/qrcode.png")
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+	hash := sha256.Sum256(data)
+	if expected := "12008f9f34e7724bf5f15a2687ac5d2b79c2636ab5d9ad9d30600f3716b5bb88"; hex.EncodeToString(hash[:]) != expected {
+		t.Errorf("expected checksum %s, got %x", expected, hash)
+	}
+
+	annotationType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object).AttributeTypes["annotation"].(tftypes.Object)
+	if !ok {
+		t.Fatalf("annotation is not an object")
+	}
+	values["annotation"] = tftypes.NewValue(annotationType, map[string]tftypes.Value{
+		"text":   tftypes.NewValue(tftypes.String, "A-1"),
+		"corner": tftypes.NewValue(tftypes.String, nil),
+	})
+	validateResp := &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)},
+	}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected reproducible and annotation to conflict")
+	}
+}
+
+// TestQRCodeResourceContentSHA256 verifies that the content checksum does not change with the
+// image format or size.
+func TestQRCodeResourceContentSHA256(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	var checksums []string
+	for _, values := range []map[string]tftypes.Value{
+		{"format": tftypes.NewValue(tftypes.String, imageFormatPNG)},
+		{"format": tftypes.NewValue(tftypes.String, imageFormatSVG), "size": tftypes.NewValue(tftypes.Number, 512)},
+	} {
+		values["text"] = tftypes.NewValue(tftypes.String, "https://example.com")
+		plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+		resp := &fwresource.CreateResponse{
+			State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+			Identity: newIdentity(),
+		}
+		r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		var state qrcodeResourceModel
+		resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+		checksums = append(checksums, state.ContentSHA256.ValueString())
+	}
+
+	if checksums[0] == "" || checksums[0] != checksums[1] {
+		t.Errorf("expected the same content checksum for both formats, got %v", checksums)
+	}
+}
+
+// TestQRCodeResourceRotation verifies that rotated images still encode the text, while ascii and
+// content_sha256 describe the upright symbol.
+func TestQRCodeResourceRotation(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	var states []qrcodeResourceModel
+	for _, rotation := range []tftypes.Value{tftypes.NewValue(tftypes.Number, nil), tftypes.NewValue(tftypes.Number, 90)} {
+		plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+			"text":     tftypes.NewValue(tftypes.String, "https://example.com"),
+			"rotation": rotation,
+		})}
+
+		resp := &fwresource.CreateResponse{
+			State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+			Identity: newIdentity(),
+		}
+		r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		var state qrcodeResourceModel
+		resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+		states = append(states, state)
+	}
+
+	upright, rotated := states[0], states[1]
+	if rotated.SHA256.Equal(upright.SHA256) {
+		t.Errorf("expected the rotated image to differ from the upright one")
+	}
+	if !rotated.ContentSHA256.Equal(upright.ContentSHA256) || !rotated.ASCII.Equal(upright.ASCII) {
+		t.Errorf("expected content_sha256 and ascii to describe the upright symbol")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(rotated.ContentBase64.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode content_base64: %s", err)
+	}
+	if text, err := decodeQRCodeImage(data); err != nil || text != "https://example.com" {
+		t.Errorf("expected the rotated image to encode the text, got %q, %v", text, err)
+	}
+}
+
+// TestQRCodeResourcePrinterFormats verifies that printer output carries the code and the captions,
+// and that captions need a printer format.
+func TestQRCodeResourcePrinterFormats(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	captions := func(captions ...string) tftypes.Value {
+		values := make([]tftypes.Value, len(captions))
+		for i, caption := range captions {
+			values[i] = tftypes.NewValue(tftypes.String, caption)
+		}
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, values)
+	}
+
+	testCases := map[string]struct {
+		captions   tftypes.Value
+		escposMode string
+		prefix     string
+		caption    string
+		error      bool
+	}{
+		imageFormatZPL: {
+			captions: captions("Asset 42"),
+			prefix:   "~DGR:QRCODE.GRF,",
+			caption:  "^FDAsset 42^FS",
+		},
+		imageFormatEPL: {
+			captions: captions("Asset 42"),
+			prefix:   "\nN\nGW0,0,",
+			caption:  `N,"Asset 42"`,
+		},
+		imageFormatTSPL: {
+			captions: captions("Größe 42"),
+			prefix:   "CODEPAGE UTF-8\r\n",
+			caption:  `"Größe 42"`,
+		},
+		"epl without ascii": {
+			captions: captions("Größe 42"),
+			error:    true,
+		},
+		imageFormatESCPOS: {
+			captions: captions("Asset 42"),
+			prefix:   "\x1b@\x1ba\x01\x1d(k",
+			caption:  "1P0https://example.com",
+		},
+		"escpos raster": {
+			captions:   captions("Asset 42"),
+			escposMode: escposModeRaster,
+			prefix:     "\x1b@\x1ba\x01\x1dv0",
+			caption:    "\nAsset 42\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			format, _, _ := strings.Cut(name, " ")
+			values := map[string]tftypes.Value{
+				"text":     tftypes.NewValue(tftypes.String, "https://example.com"),
+				"file":     tftypes.NewValue(tftypes.String, "/out/"),
+				"format":   tftypes.NewValue(tftypes.String, format),
+				"captions": testCase.captions,
+			}
+			if testCase.escposMode != "" {
+				values["escpos_mode"] = tftypes.NewValue(tftypes.String, testCase.escposMode)
+			}
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+			resp := &fwresource.CreateResponse{
+				State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+				Identity: newIdentity(),
+			}
+			r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+			if testCase.error {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var state qrcodeResourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			if filename := state.Filename.ValueString(); !strings.HasSuffix(filename, "."+format) {
+				t.Errorf("expected a .%s file name, got %q", format, filename)
+			}
+			data, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+			if err != nil {
+				t.Fatalf("failed to decode content_base64: %s", err)
+			}
+			if !bytes.HasPrefix(data, []byte(testCase.prefix)) || !bytes.Contains(data, []byte(testCase.caption)) {
+				t.Errorf("expected a label with the caption, got %q", data)
+			}
+		})
+	}
+
+	validateResp := &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+			"text":     tftypes.NewValue(tftypes.String, "https://example.com"),
+			"format":   tftypes.NewValue(tftypes.String, imageFormatSVG),
+			"captions": captions("Asset 42"),
+		})},
+	}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected captions to require a printer format")
+	}
+
+	validateResp = &fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+			"text":     tftypes.NewValue(tftypes.String, "https://example.com"),
+			"format":   tftypes.NewValue(tftypes.String, imageFormatESCPOS),
+			"rotation": tftypes.NewValue(tftypes.Number, 90),
+		})},
+	}, validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Errorf("expected QR codes encoded by the printer not to be rotated")
+	}
+}
+
+// TestQRCodeResourceSizes verifies that a copy of the image is written at each of sizes next to
+// file, with its checksum kept in sizes_sha256, and that the copies are removed with the image.
+func TestQRCodeResourceSizes(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text": tftypes.NewValue(tftypes.String, "https://example.com"),
+		"file": tftypes.NewValue(tftypes.String, "/out/qr.png"),
+		"sizes": tftypes.NewValue(tftypes.List{ElementType: tftypes.Number}, []tftypes.Value{
+			tftypes.NewValue(tftypes.Number, 128),
+			tftypes.NewValue(tftypes.Number, 512),
+		}),
+	})}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	checksums := map[string]string{}
+	resp.Diagnostics.Append(state.SizesSHA256.ElementsAs(ctx, &checksums, false)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	for size, file := range map[string]string{"128": "/out/qr-128.png", "512": "/out/qr-512.png"} {
+		data, err := afero.ReadFile(r.fs, file)
+		if err != nil {
+			t.Fatalf("expected the %s pixel copy to be written: %s", size, err)
+		}
+		if checksums[size] != computeSHA256(string(data)) {
+			t.Errorf("expected the checksum of %s in sizes_sha256, got %q", file, checksums[size])
+		}
+
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode %s: %s", file, err)
+		}
+		if width := strconv.Itoa(img.Bounds().Dx()); width != size {
+			t.Errorf("expected %s to be %s pixels wide, got %s", file, size, width)
+		}
+		if text, err := decodeQRCodeImage(data); err != nil || text != "https://example.com" {
+			t.Errorf("expected %s to decode, got %q: %v", file, text, err)
+		}
+	}
+
+	deleteResp := &fwresource.DeleteResponse{State: resp.State}
+	r.Delete(ctx, fwresource.DeleteRequest{State: resp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", deleteResp.Diagnostics)
+	}
+	for _, file := range []string{"/out/qr.png", "/out/qr-128.png", "/out/qr-512.png"} {
+		if _, err := r.fs.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", file, err)
+		}
+	}
+}
+
+// TestQRCodeResourceContentEncoding verifies that content_encoding encodes the text in Base45
+// before it is encoded, in alphanumeric mode.
+func TestQRCodeResourceContentEncoding(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+		"text":             tftypes.NewValue(tftypes.String, "ietf!"),
+		"content_encoding": tftypes.NewValue(tftypes.String, contentEncodingBase45),
+	})}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+		Identity: newIdentity(),
+	}
+	r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state qrcodeResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode content_base64: %s", err)
+	}
+	if text, err := decodeQRCodeImage(data); err != nil || text != "QED8WEX0" {
+		t.Errorf("expected the QR code to encode the Base45 text, got %q: %v", text, err)
+	}
+	if mode := state.EncodingModeUsed.ValueString(); mode != "alphanumeric" {
+		t.Errorf("expected alphanumeric mode, got %s", mode)
+	}
+}
+
+// TestQRCodeResourceDeleteReplacedFile verifies that destroy keeps a file that another process
+// replaced since it was written, unless force_delete is set.
+func TestQRCodeResourceDeleteReplacedFile(t *testing.T) {
+	ctx := context.Background()
+
+	for name, forceDelete := range map[string]bool{"kept": false, "force_delete": true} {
+		t.Run(name, func(t *testing.T) {
+			r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+			schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"text":         tftypes.NewValue(tftypes.String, "https://example.com"),
+				"file":         tftypes.NewValue(tftypes.String, "/out/qr.png"),
+				"force_delete": tftypes.NewValue(tftypes.Bool, forceDelete),
+			})}
+
+			resp := &fwresource.CreateResponse{
+				State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+				Identity: newIdentity(),
+			}
+			r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			if err := afero.WriteFile(r.fs, "/out/qr.png", []byte("written by another process"), 0o644); err != nil {
+				t.Fatalf("failed to replace the file: %s", err)
+			}
+
+			deleteResp := &fwresource.DeleteResponse{State: resp.State}
+			r.Delete(ctx, fwresource.DeleteRequest{State: resp.State}, deleteResp)
+			if deleteResp.Diagnostics.HasError() == forceDelete {
+				t.Errorf("unexpected diagnostics: %v", deleteResp.Diagnostics)
+			}
+
+			_, err := r.fs.Stat("/out/qr.png")
+			if forceDelete && !os.IsNotExist(err) {
+				t.Errorf("expected the file to be removed, got %v", err)
+			}
+			if !forceDelete && err != nil {
+				t.Errorf("expected the file to be kept, got %v", err)
+			}
+		})
+	}
+}
+
+// TestQRCodeResourceErrorCorrection verifies that error_correction sets the level of the symbol,
+// including in ESC/POS printer commands, and that auto_max raises it as far as the version allows.
+func TestQRCodeResourceErrorCorrection(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeResource{fs: afero.NewMemMapFs()}
+
+	schemaResp, newIdentity := testResourceSchema(ctx, r)
+
+	testCases := map[string]struct {
+		errorCorrection tftypes.Value
+		format          string
+		expected        string
+	}{
+		"default":         {errorCorrection: tftypes.NewValue(tftypes.String, nil), expected: "M"},
+		"low":             {errorCorrection: tftypes.NewValue(tftypes.String, "L"), expected: "L"},
+		"auto_max":        {errorCorrection: tftypes.NewValue(tftypes.String, errorCorrectionAutoMax), expected: "Q"},
+		"escpos highest":  {errorCorrection: tftypes.NewValue(tftypes.String, "H"), format: imageFormatESCPOS, expected: "H"},
+		"escpos auto_max": {errorCorrection: tftypes.NewValue(tftypes.String, errorCorrectionAutoMax), format: imageFormatESCPOS, expected: "Q"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			values := map[string]tftypes.Value{
+				"text":             tftypes.NewValue(tftypes.String, "https://example.com"),
+				"error_correction": testCase.errorCorrection,
+			}
+			if testCase.format != "" {
+				values["format"] = tftypes.NewValue(tftypes.String, testCase.format)
+			}
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+
+			resp := &fwresource.CreateResponse{
+				State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+				Identity: newIdentity(),
+			}
+			r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var state qrcodeResourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+			if used := state.ErrorCorrectionUsed.ValueString(); used != testCase.expected {
+				t.Errorf("expected error_correction_used %s, got %s", testCase.expected, used)
+			}
+
+			if testCase.format == imageFormatESCPOS {
+				data, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+				if err != nil {
+					t.Fatalf("failed to decode content_base64: %s", err)
+				}
+				level := map[string]byte{"L": '0', "M": '1', "Q": '2', "H": '3'}[testCase.expected]
+				if command := []byte{0x1d, '(', 'k', 0x03, 0x00, '1', 'E', level}; !bytes.Contains(data, command) {
+					t.Errorf("expected the printer commands to select level %s", testCase.expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,577 @@
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/spf13/afero"
+	"terraform-provider-qrcode/pkg/qrgen"
+)
+
+// Ensure implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &qrcodeStructuredAppendResource{}
+	_ resource.ResourceWithConfigure      = &qrcodeStructuredAppendResource{}
+	_ resource.ResourceWithValidateConfig = &qrcodeStructuredAppendResource{}
+)
+
+// structuredAppendIndexFileName is the name of the index that the qrcode_structured_append
+// resource writes next to its images.
+const structuredAppendIndexFileName = "index.json"
+
+// structuredAppendAnimationFileName is the name of the animated GIF that the
+// qrcode_structured_append resource writes next to its images when animation_interval is set.
+const structuredAppendAnimationFileName = "animation.gif"
+
+// minStructuredAppendAnimationInterval is the shortest animation_interval. Browsers show frames
+// with shorter delays for a tenth of a second instead.
+const minStructuredAppendAnimationInterval = 20 * time.Millisecond
+
+// defaultStructuredAppendMaxVersion is the largest symbol version used when max_version is not
+// set. Larger symbols hold more data, but are harder to scan from a screen or a printout.
+const defaultStructuredAppendMaxVersion = 20
+
+// structuredAppendPixelsPerModule is the scale of images when size is not set.
+const structuredAppendPixelsPerModule = 4
+
+// qrcodeStructuredAppendResource is the resource implementation.
+type qrcodeStructuredAppendResource struct {
+	fs afero.Fs
+
+	// writeOptions control how files are written.
+	writeOptions writeOptions
+}
+
+// qrcodeStructuredAppendResourceModel maps the qrcode_structured_append resource schema data.
+type qrcodeStructuredAppendResourceModel struct {
+	Directory         types.String `tfsdk:"directory"`
+	Content           types.String `tfsdk:"content"`
+	ContentBase64     types.String `tfsdk:"content_base64"`
+	MaxVersion        types.Int64  `tfsdk:"max_version"`
+	Size              types.Int64  `tfsdk:"size"`
+	Overwrite         types.Bool   `tfsdk:"overwrite"`
+	AnimationInterval types.String `tfsdk:"animation_interval"`
+	SymbolCount       types.Int64  `tfsdk:"symbol_count"`
+	Manifest          types.Map    `tfsdk:"manifest"`
+	ContentSHA256     types.String `tfsdk:"content_sha256"`
+	IndexSHA256       types.String `tfsdk:"index_sha256"`
+	ManifestJSON      types.String `tfsdk:"manifest_json"`
+	AnimationSHA256   types.String `tfsdk:"animation_sha256"`
+}
+
+// structuredAppendIndex describes a structured append series, so that the blob can be
+// reassembled and verified from the scanned symbols.
+type structuredAppendIndex struct {
+	Compression      string                        `json:"compression"`
+	ContentSHA256    string                        `json:"content_sha256"`
+	CompressedSHA256 string                        `json:"compressed_sha256"`
+	CompressedSize   int                           `json:"compressed_size"`
+	Parity           int                           `json:"parity"`
+	Symbols          []structuredAppendIndexSymbol `json:"symbols"`
+}
+
+// structuredAppendIndexSymbol is a symbol of a structured append series listed in its index.
+type structuredAppendIndexSymbol struct {
+	Path     string `json:"path"`
+	Position int    `json:"position"`
+	Version  int    `json:"version"`
+	Bytes    int    `json:"bytes"`
+	SHA256   string `json:"sha256"`
+}
+
+// NewQRCodeStructuredAppendResource creates a new QR code structured append resource instance.
+func NewQRCodeStructuredAppendResource() resource.Resource {
+	return &qrcodeStructuredAppendResource{
+		fs: afero.NewOsFs(),
+	}
+}
+
+// Metadata returns the resource type name.
+func (r *qrcodeStructuredAppendResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_structured_append"
+}
+
+// Configure receives the provider-level filesystem.
+func (r *qrcodeStructuredAppendResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*qrcodeProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *qrcodeProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.fs = data.Filesystem
+	r.writeOptions = data.WriteOptions
+}
+
+// Schema defines the resource schema.
+func (r *qrcodeStructuredAppendResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The `qrcode_structured_append` resource splits a blob too large for a single QR code, such as a PEM certificate chain, into a structured append series for air-gapped transfer. The blob is gzip-compressed and written as up to 16 images, `part-01.png` to `part-16.png`, that scanners supporting structured append reassemble, next to an `index.json` listing the checksums of the blob, the compressed blob and every image, and optionally an `animation.gif` cycling through the images. Files that are deleted or modified outside Terraform are regenerated on the next apply.",
+		Attributes: map[string]schema.Attribute{
+			"directory": schema.StringAttribute{
+				Required:    true,
+				Description: "Path of the directory to write the QR code images and index to. Changing this forces a new resource.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"content": schema.StringAttribute{
+				Optional:    true,
+				Description: "Text to split into a structured append series, such as a PEM certificate. Exactly one of `content` and `content_base64` must be set.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("content"), path.MatchRoot("content_base64")),
+				},
+			},
+			"content_base64": schema.StringAttribute{
+				Optional:    true,
+				Description: "Base64-encoded binary blob to split into a structured append series, such as a DER certificate or the output of `filebase64()`.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"max_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Largest QR code version, from 1 to 40, of the symbols in the series. Larger symbols need fewer images, but are harder to scan. Defaults to %d.", defaultStructuredAppendMaxVersion),
+				Validators: []validator.Int64{
+					int64validator.Between(1, 40),
+				},
+			},
+			"size": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Size of each QR code image in pixels. Defaults to %d pixels per module.", structuredAppendPixelsPerModule),
+				Validators: []validator.Int64{
+					int64validator.Between(minSize, maxSize),
+				},
+			},
+			"overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set to true to allow replacing existing files in `directory` that the resource did not write when the provider sets `fail_on_overwrite`.",
+			},
+			"animation_interval": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Also writes `%s` to the directory, an animated GIF that cycles through the QR codes of the series, showing each for this long, as a duration such as `500ms` or `2s`, so that a single screen can present the whole series to a scanner. Must be at least `%s`, and is rounded down to hundredths of a second. The frames are `size` pixels wide, or %d pixels per module of the largest symbol.", structuredAppendAnimationFileName, minStructuredAppendAnimationInterval, structuredAppendPixelsPerModule),
+			},
+			"symbol_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Number of QR codes in the series.",
+			},
+			"manifest": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Map of image file name to the SHA-256 checksum of the image.",
+			},
+			"content_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the blob, before compression, to verify the reassembled blob against.",
+			},
+			"index_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of `index.json`.",
+			},
+			"manifest_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON manifest listing the `path`, `sha256` checksum, `size` in bytes and QR code `version` of every image, in the same format as the `manifest_json` of `qrcode_directory`, for downstream automation.",
+			},
+			"animation_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of `" + structuredAppendAnimationFileName + "`, or null without `animation_interval`.",
+			},
+		},
+	}
+}
+
+// ValidateConfig rejects an animation_interval that is not a duration or is too short.
+func (r *qrcodeStructuredAppendResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config qrcodeStructuredAppendResourceModel
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.AnimationInterval.IsNull() || config.AnimationInterval.IsUnknown() {
+		return
+	}
+	_, diags = config.frameInterval()
+	resp.Diagnostics.Append(diags...)
+}
+
+// Create writes the series and its index.
+func (r *qrcodeStructuredAppendResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan qrcodeStructuredAppendResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	started := time.Now()
+	r.write(ctx, &plan, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, plan.Directory.ValueString(), int(plan.SymbolCount.ValueInt64()), time.Since(started))...)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read drops the content when an image, the index or the animation was deleted or modified outside
+// Terraform, so the series is rewritten.
+func (r *qrcodeStructuredAppendResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state qrcodeStructuredAppendResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Files written to memory do not outlive the provider process
+	if isMemoryFilesystem(r.fs) {
+		return
+	}
+
+	manifest := map[string]string{}
+	resp.Diagnostics.Append(state.Manifest.ElementsAs(ctx, &manifest, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	manifest[structuredAppendIndexFileName] = state.IndexSHA256.ValueString()
+	if !state.AnimationSHA256.IsNull() {
+		manifest[structuredAppendAnimationFileName] = state.AnimationSHA256.ValueString()
+	}
+
+	dir := state.Directory.ValueString()
+	for name, expected := range manifest {
+		actual, err := fileSHA256(r.fs, filepath.Join(dir, name))
+		if err == nil && actual == expected {
+			continue
+		}
+
+		tflog.Debug(ctx, "Structured append file is missing or modified", map[string]interface{}{
+			"directory": dir,
+			"name":      name,
+		})
+		state.Content = types.StringNull()
+		state.ContentBase64 = types.StringNull()
+		break
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update rewrites the series and prunes images that are no longer part of it.
+func (r *qrcodeStructuredAppendResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state qrcodeStructuredAppendResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := map[string]string{}
+	resp.Diagnostics.Append(state.Manifest.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	started := time.Now()
+	r.write(ctx, &plan, previous, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.writeOptions.metrics.record(ctx, plan.Directory.ValueString(), int(plan.SymbolCount.ValueInt64()), time.Since(started))...)
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the images, the index and the animation, and the directory itself when it is left
+// empty.
+func (r *qrcodeStructuredAppendResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state qrcodeStructuredAppendResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	manifest := map[string]string{}
+	resp.Diagnostics.Append(state.Manifest.ElementsAs(ctx, &manifest, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	manifest[structuredAppendIndexFileName] = state.IndexSHA256.ValueString()
+	manifest[structuredAppendAnimationFileName] = state.AnimationSHA256.ValueString()
+
+	dir := state.Directory.ValueString()
+	for name := range manifest {
+		if err := r.fs.Remove(hostPath(filepath.Join(dir, name))); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError("Failed to Delete QR Code", err.Error())
+		}
+	}
+
+	// Only an empty directory is removed, the same as for qrcode_directory
+	removeEmptyDirectory(ctx, r.fs, dir)
+}
+
+// write compresses the blob in plan, saves its structured append series, index and animation,
+// prunes images listed in previous that are no longer part of the series, and sets the computed
+// attributes.
+func (r *qrcodeStructuredAppendResource) write(ctx context.Context, plan *qrcodeStructuredAppendResourceModel, previous map[string]string, diags *diag.Diagnostics) {
+	blob := []byte(plan.Content.ValueString())
+	if !plan.ContentBase64.IsNull() {
+		var err error
+		if blob, err = base64.StdEncoding.DecodeString(plan.ContentBase64.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("content_base64"), "Invalid Base64 Content", err.Error())
+			return
+		}
+	}
+
+	compressed, err := gzipCompress(blob)
+	if err != nil {
+		diags.AddError("Failed to Compress Content", err.Error())
+		return
+	}
+
+	maxVersion := defaultStructuredAppendMaxVersion
+	if !plan.MaxVersion.IsNull() {
+		maxVersion = int(plan.MaxVersion.ValueInt64())
+	}
+
+	symbols, err := qrgen.EncodeStructuredAppend(compressed, qrgen.Medium, maxVersion)
+	if err != nil {
+		diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not split %d compressed bytes into a structured append series: %s. Raise max_version or reduce the content.", len(compressed), err))
+		return
+	}
+
+	tflog.Debug(ctx, "Encoded structured append series", map[string]interface{}{
+		"content_length":    len(blob),
+		"compressed_length": len(compressed),
+		"symbols":           len(symbols),
+	})
+
+	dir := plan.Directory.ValueString()
+	manifest := make(map[string]string, len(symbols))
+	files := make([]manifestFile, 0, len(symbols))
+	index := structuredAppendIndex{
+		Compression:      "gzip",
+		ContentSHA256:    computeSHA256(string(blob)),
+		CompressedSHA256: computeSHA256(string(compressed)),
+		CompressedSize:   len(compressed),
+		Parity:           int(qrgen.StructuredAppendParity(compressed)),
+		Symbols:          make([]structuredAppendIndexSymbol, 0, len(symbols)),
+	}
+
+	chunkSize := (len(compressed) + len(symbols) - 1) / len(symbols)
+	for i, symbol := range symbols {
+		size := structuredAppendPixelsPerModule * symbol.Modules()
+		if !plan.Size.IsNull() {
+			size = int(plan.Size.ValueInt64())
+		}
+
+		pngData, err := symbol.PNG(size, qrgen.DefaultColors)
+		if err != nil {
+			diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not render QR code %d: %s", i+1, err))
+			return
+		}
+
+		name := structuredAppendFileName(i)
+		opts := r.writeOptions
+		if _, ok := previous[name]; !ok {
+			opts = opts.forNewFile(plan.Overwrite)
+		}
+		diags.Append(saveQRCodeFile(ctx, r.fs, opts, filepath.Join(dir, name), pngData)...)
+		if diags.HasError() {
+			return
+		}
+
+		manifest[name] = computeSHA256(string(pngData))
+		files = append(files, manifestFile{
+			Path:    name,
+			SHA256:  manifest[name],
+			Size:    len(pngData),
+			Version: symbol.Version(),
+		})
+		index.Symbols = append(index.Symbols, structuredAppendIndexSymbol{
+			Path:     name,
+			Position: i + 1,
+			Version:  symbol.Version(),
+			Bytes:    min(chunkSize, len(compressed)-i*chunkSize),
+			SHA256:   manifest[name],
+		})
+	}
+
+	for name := range previous {
+		if _, ok := manifest[name]; ok {
+			continue
+		}
+
+		filePath := filepath.Join(dir, name)
+		if err := r.fs.Remove(hostPath(filePath)); err != nil && !os.IsNotExist(err) {
+			diags.AddError("Failed to Delete QR Code", err.Error())
+			return
+		}
+
+		tflog.Debug(ctx, "Pruned QR code file", map[string]interface{}{
+			"file": filePath,
+		})
+	}
+
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		diags.AddError("Failed to Write Index", err.Error())
+		return
+	}
+	indexData = append(indexData, '\n')
+
+	// The index is new when the resource is created
+	indexOpts := r.writeOptions
+	if previous == nil {
+		indexOpts = indexOpts.forNewFile(plan.Overwrite)
+	}
+
+	diags.Append(saveQRCodeFile(ctx, r.fs, indexOpts, filepath.Join(dir, structuredAppendIndexFileName), indexData)...)
+	if diags.HasError() {
+		return
+	}
+
+	diags.Append(r.writeAnimation(ctx, plan, symbols, previous == nil)...)
+	if diags.HasError() {
+		return
+	}
+
+	manifestData, err := buildManifest(files)
+	if err != nil {
+		diags.AddError("Failed to Build Manifest", err.Error())
+		return
+	}
+
+	var d diag.Diagnostics
+	plan.Manifest, d = types.MapValueFrom(ctx, types.StringType, manifest)
+	diags.Append(d...)
+
+	plan.SymbolCount = types.Int64Value(int64(len(symbols)))
+	plan.ContentSHA256 = types.StringValue(index.ContentSHA256)
+	plan.IndexSHA256 = types.StringValue(computeSHA256(string(indexData)))
+	plan.ManifestJSON = types.StringValue(string(manifestData))
+}
+
+// writeAnimation saves the symbols as an animated GIF in the plan directory and sets the plan
+// checksum, or removes the animation without animation_interval. created is true when the
+// resource is created, so that the animation is a new file.
+func (r *qrcodeStructuredAppendResource) writeAnimation(ctx context.Context, plan *qrcodeStructuredAppendResourceModel, symbols []*qrgen.Symbol, created bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	animationPath := filepath.Join(plan.Directory.ValueString(), structuredAppendAnimationFileName)
+	plan.AnimationSHA256 = types.StringNull()
+	if plan.AnimationInterval.IsNull() {
+		if err := r.fs.Remove(hostPath(animationPath)); err != nil && !os.IsNotExist(err) {
+			diags.AddError("Failed to Delete Animation", err.Error())
+		}
+		return diags
+	}
+
+	interval, d := plan.frameInterval()
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// Frames are as large as the largest symbol of the series is drawn
+	size := 0
+	for _, symbol := range symbols {
+		size = max(size, structuredAppendPixelsPerModule*symbol.Modules())
+	}
+	if !plan.Size.IsNull() {
+		size = int(plan.Size.ValueInt64())
+	}
+
+	animation, err := qrgen.AnimatedGIF(symbols, size, qrgen.DefaultColors, interval)
+	if err != nil {
+		diags.AddError("QR Code Generation Failed", fmt.Sprintf("Could not generate animation: %s", err))
+		return diags
+	}
+
+	opts := r.writeOptions
+	if created {
+		opts = opts.forNewFile(plan.Overwrite)
+	}
+	diags.Append(saveQRCodeFile(ctx, r.fs, opts, animationPath, animation)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	plan.AnimationSHA256 = types.StringValue(computeSHA256(string(animation)))
+
+	return diags
+}
+
+// frameInterval parses animation_interval, which must be at least
+// minStructuredAppendAnimationInterval.
+func (m *qrcodeStructuredAppendResourceModel) frameInterval() (time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	interval, err := time.ParseDuration(m.AnimationInterval.ValueString())
+	if err != nil || interval < minStructuredAppendAnimationInterval {
+		diags.AddAttributeError(path.Root("animation_interval"), "Invalid Animation Interval", fmt.Sprintf("Expected a duration of at least %s such as 500ms, got %q.", minStructuredAppendAnimationInterval, m.AnimationInterval.ValueString()))
+	}
+
+	return interval, diags
+}
+
+// structuredAppendFileName returns the name of the image of the symbol at a zero-based position.
+func structuredAppendFileName(position int) string {
+	return fmt.Sprintf("part-%02d.png", position+1)
+}
+
+// gzipCompress compresses data as gzip without a modification time or file name, so that the same
+// data always compresses the same.
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
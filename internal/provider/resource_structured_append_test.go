@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/spf13/afero"
+)
+
+// TestAccQRCodeStructuredAppendResource verifies that a blob is written as a series of images,
+// an index and an animation.
+func TestAccQRCodeStructuredAppendResource(t *testing.T) {
+	dir := randomTempFileName()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				return fmt.Errorf("directory %s still exists", dir)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "qrcode" {}
+
+					resource "qrcode_structured_append" "test" {
+						directory   = "` + dir + `"
+						content     = join("\n", [for i in range(40) : sha256(tostring(i))])
+						max_version = 10
+
+						animation_interval = "1s"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("qrcode_structured_append.test", "manifest.part-01.png"),
+					resource.TestCheckResourceAttrSet("qrcode_structured_append.test", "index_sha256"),
+					resource.TestCheckResourceAttrSet("qrcode_structured_append.test", "content_sha256"),
+					resource.TestCheckResourceAttrSet("qrcode_structured_append.test", "animation_sha256"),
+				),
+			},
+		},
+	})
+}
+
+// TestStructuredAppendWrite verifies that the series, its index and the computed attributes are
+// written, and that images no longer part of the series are pruned.
+func TestStructuredAppendWrite(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeStructuredAppendResource{fs: afero.NewMemMapFs()}
+
+	// Random data does not compress, so it needs several symbols
+	blob := make([]byte, 1500)
+	if _, err := rand.Read(blob); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := qrcodeStructuredAppendResourceModel{
+		Directory:     types.StringValue("/series"),
+		Content:       types.StringNull(),
+		ContentBase64: types.StringValue(base64.StdEncoding.EncodeToString(blob)),
+		MaxVersion:    types.Int64Value(10),
+		Size:          types.Int64Null(),
+	}
+
+	var diags diag.Diagnostics
+	r.write(ctx, &plan, nil, &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if plan.SymbolCount.ValueInt64() < 2 || int(plan.SymbolCount.ValueInt64()) != len(plan.Manifest.Elements()) {
+		t.Fatalf("expected a series of several symbols, got %d and manifest %v", plan.SymbolCount.ValueInt64(), plan.Manifest)
+	}
+	if plan.ContentSHA256.ValueString() != computeSHA256(string(blob)) {
+		t.Errorf("unexpected content checksum %s", plan.ContentSHA256.ValueString())
+	}
+
+	indexData, err := afero.ReadFile(r.fs, filepath.Join("/series", structuredAppendIndexFileName))
+	if err != nil {
+		t.Fatalf("failed to read index: %s", err)
+	}
+	if computeSHA256(string(indexData)) != plan.IndexSHA256.ValueString() {
+		t.Errorf("expected index_sha256 to match the index")
+	}
+	var index structuredAppendIndex
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		t.Fatalf("failed to parse index: %s", err)
+	}
+
+	total := 0
+	for i, symbol := range index.Symbols {
+		checksum, err := fileSHA256(r.fs, filepath.Join("/series", symbol.Path))
+		if err != nil || checksum != symbol.SHA256 {
+			t.Errorf("symbol %d: expected %s to match the index: %v", i, symbol.Path, err)
+		}
+		total += symbol.Bytes
+	}
+	if total != index.CompressedSize {
+		t.Errorf("expected symbols to hold %d bytes, got %d", index.CompressedSize, total)
+	}
+
+	var manifest qrcodeManifest
+	if err := json.Unmarshal([]byte(plan.ManifestJSON.ValueString()), &manifest); err != nil {
+		t.Fatalf("failed to parse manifest_json: %s", err)
+	}
+	if len(manifest.Files) != len(index.Symbols) {
+		t.Fatalf("expected %d files in manifest_json, got %d", len(index.Symbols), len(manifest.Files))
+	}
+	for i, file := range manifest.Files {
+		if file.Path != index.Symbols[i].Path || file.SHA256 != index.Symbols[i].SHA256 || file.Version != index.Symbols[i].Version || file.Size == 0 {
+			t.Errorf("file %d: expected manifest_json to match the index, got %v", i, file)
+		}
+	}
+
+	previous := map[string]string{}
+	diags.Append(plan.Manifest.ElementsAs(ctx, &previous, false)...)
+
+	plan.ContentBase64 = types.StringNull()
+	plan.Content = types.StringValue("-----BEGIN CERTIFICATE-----")
+	r.write(ctx, &plan, previous, &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if plan.SymbolCount.ValueInt64() != 1 {
+		t.Errorf("expected a single symbol, got %d", plan.SymbolCount.ValueInt64())
+	}
+	if _, err := r.fs.Stat(filepath.Join("/series", structuredAppendFileName(1))); !os.IsNotExist(err) {
+		t.Errorf("expected the second image to be pruned")
+	}
+}
+
+// TestStructuredAppendAnimation verifies that animation_interval writes an animated GIF with a
+// frame per symbol, and that the animation is removed when it is unset.
+func TestStructuredAppendAnimation(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeStructuredAppendResource{fs: afero.NewMemMapFs()}
+
+	blob := make([]byte, 1500)
+	if _, err := rand.Read(blob); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := qrcodeStructuredAppendResourceModel{
+		Directory:         types.StringValue("/series"),
+		Content:           types.StringNull(),
+		ContentBase64:     types.StringValue(base64.StdEncoding.EncodeToString(blob)),
+		MaxVersion:        types.Int64Value(10),
+		Size:              types.Int64Null(),
+		AnimationInterval: types.StringValue("500ms"),
+	}
+
+	var diags diag.Diagnostics
+	r.write(ctx, &plan, nil, &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	animationPath := filepath.Join("/series", structuredAppendAnimationFileName)
+	data, err := afero.ReadFile(r.fs, animationPath)
+	if err != nil {
+		t.Fatalf("failed to read the animation: %s", err)
+	}
+	if plan.AnimationSHA256.ValueString() != computeSHA256(string(data)) {
+		t.Errorf("expected animation_sha256 to match the animation")
+	}
+	animation, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode the animation: %s", err)
+	}
+	if int64(len(animation.Image)) != plan.SymbolCount.ValueInt64() || animation.Delay[0] != 50 {
+		t.Errorf("expected %d frames of 50 hundredths of a second, got %d of %d", plan.SymbolCount.ValueInt64(), len(animation.Image), animation.Delay[0])
+	}
+
+	previous := map[string]string{}
+	diags.Append(plan.Manifest.ElementsAs(ctx, &previous, false)...)
+
+	plan.AnimationInterval = types.StringNull()
+	r.write(ctx, &plan, previous, &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, err := r.fs.Stat(animationPath); !os.IsNotExist(err) || !plan.AnimationSHA256.IsNull() {
+		t.Errorf("expected the animation to be removed, got checksum %s: %v", plan.AnimationSHA256, err)
+	}
+}
+
+// TestStructuredAppendValidateConfig verifies that animation_interval must be a duration of at
+// least the shortest interval browsers honor.
+func TestStructuredAppendValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := &qrcodeStructuredAppendResource{}
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	testCases := map[string]struct {
+		interval    tftypes.Value
+		expectError bool
+	}{
+		"unset":        {interval: tftypes.NewValue(tftypes.String, nil)},
+		"unknown":      {interval: tftypes.NewValue(tftypes.String, tftypes.UnknownValue)},
+		"valid":        {interval: tftypes.NewValue(tftypes.String, "1.5s")},
+		"too short":    {interval: tftypes.NewValue(tftypes.String, "10ms"), expectError: true},
+		"not duration": {interval: tftypes.NewValue(tftypes.String, "slow"), expectError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			raw := testObjectValue(ctx, schemaResp.Schema.Type(), map[string]tftypes.Value{
+				"directory":          tftypes.NewValue(tftypes.String, "/series"),
+				"content":            tftypes.NewValue(tftypes.String, "-----BEGIN CERTIFICATE-----"),
+				"animation_interval": testCase.interval,
+			})
+
+			resp := &fwresource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+			}, resp)
+
+			if resp.Diagnostics.HasError() != testCase.expectError {
+				t.Errorf("expected error %t, got %v", testCase.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
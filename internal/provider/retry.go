@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Defaults of the write_max_attempts and write_retry_backoff provider attributes.
+const (
+	defaultWriteMaxAttempts  = 3
+	defaultWriteRetryBackoff = "200ms"
+)
+
+// writeOptions control how resources write files.
+type writeOptions struct {
+	// retry controls how failed writes are retried.
+	retry retryPolicy
+
+	// lockTimeout is how long a write waits for the lock on its directory.
+	lockTimeout time.Duration
+
+	// failOnOverwrite makes resources refuse to replace files they did not write, unless they set
+	// overwrite.
+	failOnOverwrite bool
+
+	// exclusive refuses the write when the file already exists.
+	exclusive bool
+
+	// metrics counts the files written, or is nil when they are not counted.
+	metrics *generationMetrics
+}
+
+// forNewFile returns the options for writing a file that the resource did not write before, which
+// refuse to replace an existing file when the provider sets fail_on_overwrite and overwrite is not
+// true.
+func (o writeOptions) forNewFile(overwrite types.Bool) writeOptions {
+	o.exclusive = o.failOnOverwrite && !overwrite.ValueBool()
+	return o
+}
+
+// retryPolicy controls how file writes and uploads that fail with a transient error, such as an
+// NFS server not responding or a server error response, are retried.
+type retryPolicy struct {
+	// maxAttempts is the number of times an operation is tried. Zero tries it once.
+	maxAttempts int
+
+	// backoff is the wait before the first retry, doubled before each further retry.
+	backoff time.Duration
+}
+
+// do calls fn until it succeeds, fails with a permanent error, runs out of attempts or ctx is
+// done. It returns the number of attempts made and the last error.
+func (p retryPolicy) do(ctx context.Context, fn func() error) (int, error) {
+	backoff := p.backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.maxAttempts || !isTransientError(err) {
+			return attempt, err
+		}
+
+		tflog.Debug(ctx, "Retrying after transient error", map[string]interface{}{
+			"attempt": attempt,
+			"backoff": backoff.String(),
+			"error":   err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// errRenderFailed wraps errors of rendering a file that is streamed to disk, which writing it again
+// does not fix.
+var errRenderFailed = errors.New("rendering failed")
+
+// httpStatusError is the error of a request that a server answered with an error status.
+type httpStatusError struct {
+	status  int
+	message string
+}
+
+func (e *httpStatusError) Error() string {
+	return e.message
+}
+
+// transient reports whether the server may answer the request when it is sent again, after a
+// server error or being told to slow down.
+func (e *httpStatusError) transient() bool {
+	return e.status >= http.StatusInternalServerError || e.status == http.StatusTooManyRequests
+}
+
+// transientErrnos are the system errors that retrying an operation may fix, such as an NFS
+// server not responding in time or a file handle going stale.
+var transientErrnos = []syscall.Errno{syscall.EIO, syscall.EAGAIN, syscall.ETIMEDOUT, syscall.ESTALE, syscall.EINTR}
+
+// isTransientError reports whether an operation that failed with err may succeed when retried:
+// the system errors in transientErrnos, and server error responses. Other errors, such as missing
+// permissions, a lock timeout, a failure to render or a client error response, are permanent.
+func isTransientError(err error) bool {
+	if errors.Is(err, errRenderFailed) {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.transient()
+	}
+
+	for _, errno := range transientErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/spf13/afero"
+)
+
+// flakyFilesystem fails the first failures attempts to open a file for writing.
+type flakyFilesystem struct {
+	afero.Fs
+	failures int
+	err      error
+}
+
+func (f *flakyFilesystem) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&os.O_WRONLY != 0 && f.failures > 0 {
+		f.failures--
+		return nil, f.err
+	}
+
+	return f.Fs.OpenFile(name, flag, perm)
+}
+
+// flakyHandler answers the first failures requests with status and passes the others to Handler.
+type flakyHandler struct {
+	http.Handler
+	failures int
+	status   int
+
+	mutex    sync.Mutex
+	requests int
+}
+
+func (f *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	f.requests++
+	fail := f.requests <= f.failures
+	f.mutex.Unlock()
+
+	if fail {
+		http.Error(w, http.StatusText(f.status), f.status)
+		return
+	}
+	f.Handler.ServeHTTP(w, r)
+}
+
+// TestRetryPolicy verifies that transient errors are retried up to the maximum attempts, and
+// permanent errors are not.
+func TestRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := retryPolicy{maxAttempts: 3}
+	transient := &fs.PathError{Op: "open", Path: "x", Err: syscall.ESTALE}
+
+	testCases := map[string]struct {
+		errs             []error
+		expectedAttempts int
+		expectError      bool
+	}{
+		"success":         {expectedAttempts: 1},
+		"transient":       {errs: []error{transient, transient}, expectedAttempts: 3},
+		"exhausted":       {errs: []error{transient, transient, transient, transient}, expectedAttempts: 3, expectError: true},
+		"permission":      {errs: []error{fs.ErrPermission}, expectedAttempts: 1, expectError: true},
+		"unknown":         {errs: []error{errors.New("no space left on device")}, expectedAttempts: 1, expectError: true},
+		"wrapped invalid": {errs: []error{&fs.PathError{Op: "open", Path: "x", Err: fs.ErrInvalid}}, expectedAttempts: 1, expectError: true},
+		"server error":    {errs: []error{&httpStatusError{status: http.StatusServiceUnavailable}}, expectedAttempts: 2},
+		"too many":        {errs: []error{&httpStatusError{status: http.StatusTooManyRequests}}, expectedAttempts: 2},
+		"client error":    {errs: []error{&httpStatusError{status: http.StatusConflict}}, expectedAttempts: 1, expectError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			attempts, err := policy.do(ctx, func() error {
+				calls++
+				if calls <= len(testCase.errs) {
+					return testCase.errs[calls-1]
+				}
+				return nil
+			})
+			if attempts != testCase.expectedAttempts || calls != attempts {
+				t.Errorf("expected %d attempts, got %d with %d calls", testCase.expectedAttempts, attempts, calls)
+			}
+			if (err != nil) != testCase.expectError {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestSaveQRCodeFileRetry verifies that a write which succeeds after a retry is saved and reported
+// as a warning, and one that keeps failing reports the number of attempts.
+func TestSaveQRCodeFileRetry(t *testing.T) {
+	ctx := context.Background()
+	opts := writeOptions{retry: retryPolicy{maxAttempts: 3}}
+
+	flaky := &flakyFilesystem{Fs: afero.NewMemMapFs(), failures: 1, err: syscall.EIO}
+	diags := saveQRCodeFile(ctx, flaky, opts, "/out/qrcode.png", []byte("png"))
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+	if data, err := afero.ReadFile(flaky, "/out/qrcode.png"); err != nil || string(data) != "png" {
+		t.Errorf("expected the file to be saved, got %q: %v", data, err)
+	}
+
+	broken := &flakyFilesystem{Fs: afero.NewMemMapFs(), failures: 5, err: syscall.EIO}
+	diags = saveQRCodeFile(ctx, broken, opts, "/out/qrcode.png", []byte("png"))
+	if !diags.HasError() || !strings.Contains(diagnosticDetails(diags), "after 3 attempts") {
+		t.Errorf("expected an error after 3 attempts, got %v", diags)
+	}
+}
+
+// TestStreamQRCodeFile verifies that streamed files are written whole with the checksum of what was
+// written, rendered again when the write is retried, and that rendering errors are not retried.
+func TestStreamQRCodeFile(t *testing.T) {
+	ctx := context.Background()
+	opts := writeOptions{retry: retryPolicy{maxAttempts: 3}}
+
+	renders := 0
+	render := func(w io.Writer) error {
+		renders++
+		for i := 0; i < 1000; i++ {
+			if _, err := fmt.Fprintf(w, "page %d\n", i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	flaky := &flakyFilesystem{Fs: afero.NewMemMapFs(), failures: 1, err: syscall.EIO}
+	checksum, diags := streamQRCodeFile(ctx, flaky, opts, "/out/sheet.pdf", render)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+	data, err := afero.ReadFile(flaky, "/out/sheet.pdf")
+	if err != nil || !strings.HasSuffix(string(data), "page 999\n") {
+		t.Fatalf("expected the whole file to be saved, got %d bytes: %v", len(data), err)
+	}
+	if checksum != computeSHA256(string(data)) {
+		t.Errorf("expected the checksum of the file, got %s", checksum)
+	}
+	if renders != 1 {
+		t.Errorf("expected a render for the attempt that opened the file, got %d", renders)
+	}
+
+	renders = 0
+	_, diags = streamQRCodeFile(ctx, afero.NewMemMapFs(), opts, "/out/sheet.pdf", func(w io.Writer) error {
+		renders++
+		return errors.New("a PDF needs at least one page")
+	})
+	if !diags.HasError() || renders != 1 {
+		t.Errorf("expected a single failed render, got %d renders: %v", renders, diags)
+	}
+}
+
+// diagnosticDetails joins the details of diags.
+func diagnosticDetails(diags diag.Diagnostics) string {
+	details := make([]string, 0, len(diags))
+	for _, d := range diags {
+		details = append(details, d.Detail())
+	}
+
+	return strings.Join(details, "\n")
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// qrcodeSSHKeyModel maps the ssh_key block of the qrcode_generate resource schema data.
+type qrcodeSSHKeyModel struct {
+	PublicKey    types.String `tfsdk:"public_key"`
+	Hosts        types.List   `tfsdk:"hosts"`
+	StripComment types.Bool   `tfsdk:"strip_comment"`
+}
+
+// known reports whether the key and every option is known.
+func (m *qrcodeSSHKeyModel) known() bool {
+	if m == nil {
+		return true
+	}
+	if m.PublicKey.IsUnknown() || m.Hosts.IsUnknown() || m.StripComment.IsUnknown() {
+		return false
+	}
+
+	for _, host := range m.Hosts.Elements() {
+		if host.IsUnknown() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// entry returns the public key as an authorized_keys line, or as a known_hosts line when hosts
+// are set, and the SHA-256 fingerprint of the key. Options in front of the key are not encoded.
+func (m *qrcodeSSHKeyModel) entry() (string, string, error) {
+	key, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(m.PublicKey.ValueString()))
+	if err != nil {
+		return "", "", fmt.Errorf("public key is not in authorized_keys format: %w", err)
+	}
+
+	fields := []string{strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(key)), "\n")}
+
+	if !m.Hosts.IsNull() {
+		hosts := make([]string, 0, len(m.Hosts.Elements()))
+		for _, host := range m.Hosts.Elements() {
+			if host, ok := host.(types.String); ok {
+				hosts = append(hosts, host.ValueString())
+			}
+		}
+		fields = append([]string{strings.Join(hosts, ",")}, fields...)
+	}
+
+	if comment != "" && !m.StripComment.ValueBool() {
+		fields = append(fields, comment)
+	}
+
+	return strings.Join(fields, " "), ssh.FingerprintSHA256(key), nil
+}
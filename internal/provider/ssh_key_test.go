@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// TestSSHKeyEntry verifies that public keys encode as authorized_keys and known_hosts lines.
+func TestSSHKeyEntry(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	key, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to convert key: %s", err)
+	}
+	authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(key)), "\n")
+
+	testCases := map[string]struct {
+		model    qrcodeSSHKeyModel
+		expected string
+	}{
+		"authorized key": {
+			model:    qrcodeSSHKeyModel{PublicKey: types.StringValue(authorizedKey + " alice@laptop\n"), Hosts: types.ListNull(types.StringType)},
+			expected: authorizedKey + " alice@laptop",
+		},
+		"options and stripped comment": {
+			model: qrcodeSSHKeyModel{
+				PublicKey:    types.StringValue(`from="10.0.0.0/8" ` + authorizedKey + " alice@laptop"),
+				Hosts:        types.ListNull(types.StringType),
+				StripComment: types.BoolValue(true),
+			},
+			expected: authorizedKey,
+		},
+		"known hosts": {
+			model: qrcodeSSHKeyModel{
+				PublicKey:    types.StringValue(authorizedKey + " root@git"),
+				Hosts:        types.ListValueMust(types.StringType, []attr.Value{types.StringValue("git.example.com"), types.StringValue("[git.example.com]:2222")}),
+				StripComment: types.BoolValue(true),
+			},
+			expected: "git.example.com,[git.example.com]:2222 " + authorizedKey,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			entry, fingerprint, err := testCase.model.entry()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if entry != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, entry)
+			}
+			if fingerprint != ssh.FingerprintSHA256(key) {
+				t.Errorf("expected fingerprint %s, got %s", ssh.FingerprintSHA256(key), fingerprint)
+			}
+		})
+	}
+
+	invalid := qrcodeSSHKeyModel{PublicKey: types.StringValue("ssh-ed25519 not-a-key"), Hosts: types.ListNull(types.StringType)}
+	if _, _, err := invalid.entry(); err == nil {
+		t.Errorf("expected an invalid key to fail")
+	}
+}
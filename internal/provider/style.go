@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// qrcodeStyleModel maps a style block of the provider schema data: the look of QR codes that
+// qrcode_generate resources reference by name with their style attribute.
+type qrcodeStyleModel struct {
+	Name               types.String `tfsdk:"name"`
+	ForegroundColor    types.String `tfsdk:"foreground_color"`
+	BackgroundColor    types.String `tfsdk:"background_color"`
+	QuietZoneColor     types.String `tfsdk:"quiet_zone_color"`
+	EyeColor           types.String `tfsdk:"eye_color"`
+	EyeColorTopLeft    types.String `tfsdk:"eye_color_top_left"`
+	EyeColorTopRight   types.String `tfsdk:"eye_color_top_right"`
+	EyeColorBottomLeft types.String `tfsdk:"eye_color_bottom_left"`
+	QuietZone          types.Int64  `tfsdk:"quiet_zone"`
+}
+
+// styleAttributes returns the attributes of a style block in the provider schema, which default
+// the qrcode_generate resource attributes of the same name.
+func styleAttributes() map[string]schema.Attribute {
+	colorAttribute := func(description string) schema.StringAttribute {
+		return schema.StringAttribute{
+			Optional:    true,
+			Description: description,
+			Validators: []validator.String{
+				stringvalidator.RegexMatches(hexColorPattern, "must be a #RRGGBB hex color"),
+			},
+		}
+	}
+
+	return map[string]schema.Attribute{
+		"name": schema.StringAttribute{
+			Required:    true,
+			Description: "Name that resources reference the style by, such as `brand_dark`.",
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"foreground_color":      colorAttribute("Color of the dark modules, as a `#RRGGBB` hex color."),
+		"background_color":      colorAttribute("Color of the light modules, and of the quiet zone unless `quiet_zone_color` is set, as a `#RRGGBB` hex color."),
+		"quiet_zone_color":      colorAttribute("Color of the quiet zone, as a `#RRGGBB` hex color."),
+		"eye_color":             colorAttribute("Color of the dark modules of the three finder patterns, as a `#RRGGBB` hex color."),
+		"eye_color_top_left":    colorAttribute("Color of the dark modules of the top left finder pattern, as a `#RRGGBB` hex color."),
+		"eye_color_top_right":   colorAttribute("Color of the dark modules of the top right finder pattern, as a `#RRGGBB` hex color."),
+		"eye_color_bottom_left": colorAttribute("Color of the dark modules of the bottom left finder pattern, as a `#RRGGBB` hex color."),
+		"quiet_zone": schema.Int64Attribute{
+			Optional:    true,
+			Description: "Width of the light border around the QR code, in modules.",
+			Validators: []validator.Int64{
+				int64validator.AtLeast(0),
+			},
+		},
+	}
+}
+
+// lookupStyle returns the style named by the style attribute of a resource, or nil when the
+// attribute is not set or not known yet.
+func lookupStyle(styles map[string]qrcodeStyleModel, name types.String) (*qrcodeStyleModel, error) {
+	if name.IsNull() || name.IsUnknown() {
+		return nil, nil
+	}
+
+	style, ok := styles[name.ValueString()]
+	if !ok {
+		return nil, fmt.Errorf("style %q is not defined by a style block of the provider", name.ValueString())
+	}
+
+	return &style, nil
+}
+
+// apply returns the resource with the attributes it leaves unset taken from the style. The
+// attributes the resource sets win, so that a resource can adjust a shared style.
+func (s *qrcodeStyleModel) apply(m qrcodeResourceModel) qrcodeResourceModel {
+	if s == nil {
+		return m
+	}
+
+	for _, attribute := range []struct {
+		value *types.String
+		style types.String
+	}{
+		{&m.ForegroundColor, s.ForegroundColor},
+		{&m.BackgroundColor, s.BackgroundColor},
+		{&m.QuietZoneColor, s.QuietZoneColor},
+		{&m.EyeColor, s.EyeColor},
+		{&m.EyeColorTopLeft, s.EyeColorTopLeft},
+		{&m.EyeColorTopRight, s.EyeColorTopRight},
+		{&m.EyeColorBottomLeft, s.EyeColorBottomLeft},
+	} {
+		if attribute.value.IsNull() {
+			*attribute.value = attribute.style
+		}
+	}
+	if m.QuietZone.IsNull() {
+		m.QuietZone = s.QuietZone
+	}
+
+	return m
+}
+
+// sha256 returns the checksum of the settings of the style, which changes whenever the style
+// would change the image. Renaming a style does not change it.
+func (s *qrcodeStyleModel) sha256() string {
+	settings := map[string]interface{}{}
+	for name, value := range map[string]types.String{
+		"foreground_color":      s.ForegroundColor,
+		"background_color":      s.BackgroundColor,
+		"quiet_zone_color":      s.QuietZoneColor,
+		"eye_color":             s.EyeColor,
+		"eye_color_top_left":    s.EyeColorTopLeft,
+		"eye_color_top_right":   s.EyeColorTopRight,
+		"eye_color_bottom_left": s.EyeColorBottomLeft,
+	} {
+		if !value.IsNull() {
+			settings[name] = value.ValueString()
+		}
+	}
+	if !s.QuietZone.IsNull() {
+		settings["quiet_zone"] = s.QuietZone.ValueInt64()
+	}
+
+	// Maps are marshaled with sorted keys, so the checksum does not depend on the iteration order
+	data, _ := json.Marshal(settings)
+	return computeSHA256(string(data))
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/spf13/afero"
+)
+
+func TestStyleApply(t *testing.T) {
+	style := &qrcodeStyleModel{
+		ForegroundColor: types.StringValue("#112233"),
+		BackgroundColor: types.StringValue("#ffffff"),
+		QuietZone:       types.Int64Value(2),
+	}
+
+	m := style.apply(qrcodeResourceModel{
+		ForegroundColor: types.StringValue("#000000"),
+		BackgroundColor: types.StringNull(),
+		QuietZone:       types.Int64Null(),
+		EyeColor:        types.StringNull(),
+	})
+	if m.ForegroundColor.ValueString() != "#000000" {
+		t.Errorf("expected the resource foreground_color to win, got %s", m.ForegroundColor)
+	}
+	if m.BackgroundColor.ValueString() != "#ffffff" {
+		t.Errorf("expected background_color from the style, got %s", m.BackgroundColor)
+	}
+	if m.QuietZone.ValueInt64() != 2 {
+		t.Errorf("expected quiet_zone from the style, got %s", m.QuietZone)
+	}
+	if !m.EyeColor.IsNull() {
+		t.Errorf("expected eye_color to stay null, got %s", m.EyeColor)
+	}
+
+	var none *qrcodeStyleModel
+	if m := none.apply(qrcodeResourceModel{ForegroundColor: types.StringNull()}); !m.ForegroundColor.IsNull() {
+		t.Errorf("expected no style to leave the resource unchanged, got %s", m.ForegroundColor)
+	}
+}
+
+func TestLookupStyle(t *testing.T) {
+	styles := map[string]qrcodeStyleModel{
+		"brand": {ForegroundColor: types.StringValue("#112233")},
+	}
+
+	style, err := lookupStyle(styles, types.StringValue("brand"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if style.ForegroundColor.ValueString() != "#112233" {
+		t.Errorf("expected the brand style, got %+v", style)
+	}
+
+	if style, err := lookupStyle(styles, types.StringNull()); style != nil || err != nil {
+		t.Errorf("expected no style for a null name, got %+v, %v", style, err)
+	}
+	if _, err := lookupStyle(styles, types.StringValue("missing")); err == nil {
+		t.Error("expected an error for an undefined style")
+	}
+}
+
+func TestStyleSHA256(t *testing.T) {
+	a := qrcodeStyleModel{ForegroundColor: types.StringValue("#112233"), QuietZone: types.Int64Null()}
+	b := qrcodeStyleModel{ForegroundColor: types.StringValue("#112233"), QuietZone: types.Int64Value(4)}
+
+	if a.sha256() == b.sha256() {
+		t.Error("expected a change of the style settings to change the checksum")
+	}
+	if a.sha256() != a.sha256() {
+		t.Error("expected the checksum to be stable")
+	}
+}
+
+func TestQRCodeResourceStyle(t *testing.T) {
+	ctx := context.Background()
+
+	schemaResp, newIdentity := testResourceSchema(ctx, &qrcodeResource{})
+
+	create := func(r *qrcodeResource, values map[string]tftypes.Value) qrcodeResourceModel {
+		t.Helper()
+
+		values["text"] = tftypes.NewValue(tftypes.String, "https://example.com")
+		values["filename"] = tftypes.NewValue(tftypes.String, "/out/code.png")
+		plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: testObjectValue(ctx, schemaResp.Schema.Type(), values)}
+		resp := &fwresource.CreateResponse{
+			State:    tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw},
+			Identity: newIdentity(),
+		}
+		r.create(ctx, fwresource.CreateRequest{Plan: plan}, resp, "")
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		var state qrcodeResourceModel
+		resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+		return state
+	}
+
+	styled := create(&qrcodeResource{
+		fs: afero.NewMemMapFs(),
+		styles: map[string]qrcodeStyleModel{
+			"brand": {
+				ForegroundColor: types.StringValue("#112233"),
+				QuietZone:       types.Int64Value(2),
+			},
+		},
+	}, map[string]tftypes.Value{
+		"style": tftypes.NewValue(tftypes.String, "brand"),
+	})
+	explicit := create(&qrcodeResource{fs: afero.NewMemMapFs()}, map[string]tftypes.Value{
+		"foreground_color": tftypes.NewValue(tftypes.String, "#112233"),
+		"quiet_zone":       tftypes.NewValue(tftypes.Number, 2),
+	})
+	plain := create(&qrcodeResource{fs: afero.NewMemMapFs()}, map[string]tftypes.Value{})
+
+	if styled.SHA256.ValueString() != explicit.SHA256.ValueString() {
+		t.Errorf("expected the style to render like the same attributes set on the resource, got %s and %s", styled.SHA256, explicit.SHA256)
+	}
+	if styled.SHA256.ValueString() == plain.SHA256.ValueString() {
+		t.Error("expected the style to change the image")
+	}
+	if styled.StyleSHA256.IsNull() || !explicit.StyleSHA256.IsNull() {
+		t.Errorf("expected style_sha256 only for the styled resource, got %s and %s", styled.StyleSHA256, explicit.StyleSHA256)
+	}
+	if !styled.ForegroundColor.IsNull() {
+		t.Errorf("expected the style not to be written to foreground_color, got %s", styled.ForegroundColor)
+	}
+}
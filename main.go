@@ -5,7 +5,13 @@ import (
 	"flag"
 	"log"
 
+	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6to5server"
+	"google.golang.org/grpc"
 	"terraform-provider-qrcode/internal/provider"
 )
 
@@ -14,21 +20,68 @@ var (
 	version = "dev"
 )
 
+const (
+	// providerAddress is the registry address the provider is served under.
+	// Temporary development address. Update before publishing.
+	providerAddress = "registry.terraform.io/jackivanov/qrcode"
+
+	// grpcMaxMessageSize matches the limit of tf5server and tf6server, as
+	// rendered images are returned in content_base64.
+	grpcMaxMessageSize = 256 << 20
+)
+
 func main() {
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := providerserver.ServeOpts{
-		// Temporary development address. Update before publishing.
-		Address: "registry.terraform.io/jackivanov/qrcode",
-		Debug:   debug,
-	}
+	ctx := context.Background()
+	providerServer := providerserver.NewProtocol6(provider.New(version)())
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	if debug {
+		err := tf6server.Serve(providerAddress, providerServer, tf6server.WithManagedDebug())
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
 
+	// The provider is implemented against protocol v6. It is also offered
+	// over protocol v5, downgraded with tf6to5server, and the Terraform CLI
+	// picks the highest version both sides support during the handshake, so
+	// CLI releases without protocol v6 can still use it.
+	downgradedServer, err := tf6to5server.DowngradeServer(ctx, providerServer)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: plugin.HandshakeConfig{
+			MagicCookieKey:   "TF_PLUGIN_MAGIC_COOKIE",
+			MagicCookieValue: "d602bf8f470bc67ca7faa0386276bbdd4330efaf76d1a219cb4d6991ca9872b2",
+		},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			5: {
+				"provider": &tf5server.GRPCProviderPlugin{
+					GRPCProvider: func() tfprotov5.ProviderServer {
+						return downgradedServer
+					},
+					Name: providerAddress,
+				},
+			},
+			6: {
+				"provider": &tf6server.GRPCProviderPlugin{
+					GRPCProvider: providerServer,
+					Name:         providerAddress,
+				},
+			},
+		},
+		GRPCServer: func(opts []grpc.ServerOption) *grpc.Server {
+			opts = append(opts, grpc.MaxRecvMsgSize(grpcMaxMessageSize))
+			opts = append(opts, grpc.MaxSendMsgSize(grpcMaxMessageSize))
+
+			return grpc.NewServer(opts...)
+		},
+	})
 }
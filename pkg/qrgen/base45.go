@@ -0,0 +1,71 @@
+package qrgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// base45Alphabet is the Base45 alphabet of RFC 9285, which is the character set of alphanumeric
+// mode, so that Base45 text encodes in alphanumeric mode.
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// EncodeBase45 encodes data in Base45 as specified by RFC 9285, as used by EU Digital COVID
+// Certificates to carry binary data in alphanumeric mode: every two bytes become three characters
+// and a trailing byte becomes two.
+func EncodeBase45(data []byte) string {
+	var b strings.Builder
+	b.Grow((len(data)*3 + 1) / 2)
+
+	for i := 0; i < len(data); i += 2 {
+		if i+1 == len(data) {
+			n := int(data[i])
+			b.WriteByte(base45Alphabet[n%45])
+			b.WriteByte(base45Alphabet[n/45])
+			break
+		}
+
+		n := int(data[i])<<8 | int(data[i+1])
+		b.WriteByte(base45Alphabet[n%45])
+		b.WriteByte(base45Alphabet[n/45%45])
+		b.WriteByte(base45Alphabet[n/(45*45)])
+	}
+
+	return b.String()
+}
+
+// DecodeBase45 decodes Base45 text as specified by RFC 9285. It fails on characters outside the
+// alphabet, on a trailing single character and on groups that do not encode bytes.
+func DecodeBase45(text string) ([]byte, error) {
+	if len(text)%3 == 1 {
+		return nil, fmt.Errorf("invalid Base45 length %d", len(text))
+	}
+
+	data := make([]byte, 0, len(text)*2/3)
+	for i := 0; i < len(text); i += 3 {
+		group := text[i:min(i+3, len(text))]
+
+		n := 0
+		for j := len(group) - 1; j >= 0; j-- {
+			digit := strings.IndexByte(base45Alphabet, group[j])
+			if digit < 0 {
+				return nil, fmt.Errorf("invalid Base45 character %q at offset %d", group[j], i+j)
+			}
+			n = n*45 + digit
+		}
+
+		if len(group) == 2 {
+			if n > 0xff {
+				return nil, fmt.Errorf("invalid Base45 group %q at offset %d", group, i)
+			}
+			data = append(data, byte(n))
+			continue
+		}
+
+		if n > 0xffff {
+			return nil, fmt.Errorf("invalid Base45 group %q at offset %d", group, i)
+		}
+		data = append(data, byte(n>>8), byte(n))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,39 @@
+package qrgen
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBase45 verifies Base45 encoding and decoding against the examples of RFC 9285, and that
+// invalid text is rejected.
+func TestBase45(t *testing.T) {
+	testCases := map[string]string{
+		"":         "",
+		"AB":       "BB8",
+		"Hello!!":  "%69 VD92EX0",
+		"base-45":  "UJCLQE7W581",
+		"ietf!":    "QED8WEX0",
+		"\x00":     "00",
+		"\xff\xff": "FGW",
+	}
+
+	for data, text := range testCases {
+		if encoded := EncodeBase45([]byte(data)); encoded != text {
+			t.Errorf("%q: expected %q, got %q", data, text, encoded)
+		}
+		decoded, err := DecodeBase45(text)
+		if err != nil {
+			t.Fatalf("%q: failed to decode: %s", text, err)
+		}
+		if !bytes.Equal(decoded, []byte(data)) {
+			t.Errorf("%q: expected %q, got %q", text, data, decoded)
+		}
+	}
+
+	for _, text := range []string{"GGW", "GGWA", "ZZ", "a12", "QED8WEX"} {
+		if _, err := DecodeBase45(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package qrgen
+
+import (
+	"strings"
+
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+)
+
+// maxVersion is the largest QR code version.
+const maxVersion = 40
+
+// autoSegments splits data, already transcoded to its byte mode charset, into segments the way
+// go-qrcode does at the given version: runs of numeric, alphanumeric and byte characters, merged
+// into the preceding run when its mode can encode them in fewer bits, or a single segment in the
+// highest mode needed when that is shorter still.
+func autoSegments(data string, version *decoder.Version) []qrSegment {
+	if data == "" {
+		return nil
+	}
+
+	// Modes are ranked from numeric to byte, so that a run can absorb runs of a lower rank
+	ranked := []*decoder.Mode{decoder.Mode_NUMERIC, decoder.Mode_ALPHANUMERIC, decoder.Mode_BYTE}
+	rank := func(b byte) int {
+		switch {
+		case b >= '0' && b <= '9':
+			return 0
+		case b < 0x80 && strings.IndexByte(alphanumericCharset, b) >= 0:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	type run struct {
+		rank int
+		data string
+	}
+
+	var runs []run
+	highest, start := 0, 0
+	for i := 1; i <= len(data); i++ {
+		if i == len(data) || rank(data[i]) != rank(data[start]) {
+			runs = append(runs, run{rank: rank(data[start]), data: data[start:i]})
+			highest = max(highest, rank(data[start]))
+			start = i
+		}
+	}
+
+	var segments []qrSegment
+	optimizedBits := 0
+	for i := 0; i < len(runs); {
+		mode := ranked[runs[i].rank]
+		n := len(runs[i].data)
+
+		j := i + 1
+		for ; j < len(runs) && runs[j].rank <= runs[i].rank; j++ {
+			next := len(runs[j].data)
+			separate := segmentBits(mode, n, version) + segmentBits(ranked[runs[j].rank], next, version)
+			if segmentBits(mode, n+next, version) >= separate {
+				break
+			}
+			n += next
+		}
+
+		var text strings.Builder
+		for k := i; k < j; k++ {
+			text.WriteString(runs[k].data)
+		}
+		segments = append(segments, qrSegment{mode: mode, text: text.String()})
+		optimizedBits += segmentBits(mode, n, version)
+
+		i = j
+	}
+
+	if segmentBits(ranked[highest], len(data), version) <= optimizedBits {
+		return []qrSegment{{mode: ranked[highest], text: data}}
+	}
+
+	return segments
+}
+
+// segmentBits returns the number of bits of a segment of n characters in mode, including its mode
+// indicator and character count.
+func segmentBits(mode *decoder.Mode, n int, version *decoder.Version) int {
+	bits := 4 + mode.GetCharacterCountBits(version)
+
+	switch mode {
+	case decoder.Mode_NUMERIC:
+		bits += 10 * (n / 3)
+		if n%3 != 0 {
+			bits += 1 + 3*(n%3)
+		}
+	case decoder.Mode_ALPHANUMERIC:
+		bits += 11*(n/2) + 6*(n%2)
+	case decoder.Mode_KANJI:
+		bits += 13 * n
+	default:
+		bits += 8 * n
+	}
+
+	return bits
+}
+
+// segmentModeNames describes the modes of segments, such as "byte+numeric".
+func segmentModeNames(segments []qrSegment) string {
+	var modes []string
+	for _, segment := range segments {
+		mode := strings.ToLower(segment.mode.String())
+		if len(modes) == 0 || modes[len(modes)-1] != mode {
+			modes = append(modes, mode)
+		}
+	}
+
+	return strings.Join(modes, "+")
+}
+
+// capacityUsed returns the share of the data capacity of the largest QR code version at level
+// that the segments split by split take, in percent.
+func capacityUsed(split func(version *decoder.Version) []qrSegment, level Level, byteCharset string) float64 {
+	// Version numbers from 1 to 40 never fail
+	version, _ := decoder.Version_GetVersionForNumber(maxVersion)
+
+	bits := encodeSegments(split(version), version, byteCharset).GetSize()
+
+	return 100 * float64(bits) / float64(dataCodewords(version, ecLevels[level])*8)
+}
@@ -0,0 +1,74 @@
+package qrgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSymbolMode verifies that symbols describe the segment modes go-qrcode and the optimized
+// encoder chose.
+func TestSymbolMode(t *testing.T) {
+	testCases := map[string]struct {
+		text     string
+		optimize bool
+		expected string
+	}{
+		"numeric":            {text: "0123456789", expected: "numeric"},
+		"alphanumeric":       {text: "HTTPS://EXAMPLE.COM", expected: "alphanumeric"},
+		"byte":               {text: "https://example.com", expected: "byte"},
+		"merged runs":        {text: "ORDER-0042", expected: "alphanumeric"},
+		"separate runs":      {text: "ORDER 0123456789012345678901234567890123456789", expected: "alphanumeric+numeric"},
+		"optimized segments": {text: "abc0123456789012345", optimize: true, expected: "byte+numeric"},
+		"optimized kanji":    {text: "日本語", optimize: true, expected: "kanji"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			symbol, err := Encode(testCase.text, Options{Level: Medium, Optimize: testCase.optimize})
+			if err != nil {
+				t.Fatalf("failed to encode: %s", err)
+			}
+			if symbol.Mode() != testCase.expected {
+				t.Errorf("expected mode %q, got %q", testCase.expected, symbol.Mode())
+			}
+		})
+	}
+}
+
+// TestSymbolCapacityUsedPercent verifies that the capacity used approaches 100 percent for the
+// longest text that can be encoded, and grows with the error correction level.
+func TestSymbolCapacityUsedPercent(t *testing.T) {
+	// A version 40 symbol at the Medium level holds 2331 bytes
+	for _, optimize := range []bool{false, true} {
+		symbol, err := Encode(strings.Repeat("a", 2331), Options{Level: Medium, Optimize: optimize})
+		if err != nil {
+			t.Fatalf("failed to encode: %s", err)
+		}
+		if used := symbol.CapacityUsedPercent(); used < 99.9 || used > 100 {
+			t.Errorf("optimize %t: expected nearly 100 percent used, got %f", optimize, used)
+		}
+		if symbol.SymbolModules() != 177 || symbol.WithQuietZone(0).Modules() != 177 {
+			t.Errorf("optimize %t: expected 177 modules, got %d", optimize, symbol.SymbolModules())
+		}
+
+		if _, err := Encode(strings.Repeat("a", 2332), Options{Level: Medium, Optimize: optimize}); err == nil {
+			t.Errorf("optimize %t: expected text over capacity to fail", optimize)
+		}
+	}
+
+	previous := 0.0
+	for _, level := range []Level{Low, Medium, High, Highest} {
+		symbol, err := Encode("https://example.com", Options{Level: level})
+		if err != nil {
+			t.Fatalf("failed to encode: %s", err)
+		}
+		if used := symbol.CapacityUsedPercent(); used <= previous {
+			t.Errorf("level %d: expected more than %f percent used, got %f", level, previous, used)
+		} else {
+			previous = used
+		}
+		if symbol.WithQuietZone(1).CapacityUsedPercent() != symbol.CapacityUsedPercent() {
+			t.Errorf("level %d: expected the quiet zone not to change the capacity used", level)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package qrgen
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// hexColorPattern matches a #RRGGBB hex color.
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Colors are the colors QR code modules are rendered in.
+type Colors struct {
+	// Dark is the color of dark modules.
+	Dark color.RGBA
+
+	// Light is the color of light modules, and of the quiet zone unless QuietZone is set.
+	Light color.RGBA
+
+	// QuietZone is the color of the quiet zone. The zero value means Light.
+	QuietZone color.RGBA
+
+	// Eyes are the colors of the dark modules of the finder patterns, indexed by EyeTopLeft,
+	// EyeTopRight and EyeBottomLeft. Zero values mean Dark.
+	Eyes [3]color.RGBA
+}
+
+// Corners of the finder patterns, the "eyes" of a QR code, as indexes of Colors.Eyes.
+const (
+	EyeTopLeft = iota
+	EyeTopRight
+	EyeBottomLeft
+)
+
+// DefaultColors renders black modules on a white background.
+var DefaultColors = Colors{
+	Dark:  color.RGBA{A: 0xff},
+	Light: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+}
+
+// quietZone returns the color of the quiet zone.
+func (c Colors) quietZone() color.RGBA {
+	if c.QuietZone == (color.RGBA{}) {
+		return c.Light
+	}
+
+	return c.QuietZone
+}
+
+// distinctQuietZone reports whether the quiet zone is drawn in another color than light modules.
+func (c Colors) distinctQuietZone() bool {
+	return c.quietZone() != c.Light
+}
+
+// eye returns the color of the dark modules of the finder pattern in the given corner.
+func (c Colors) eye(corner int) color.RGBA {
+	if c.Eyes[corner] == (color.RGBA{}) {
+		return c.Dark
+	}
+
+	return c.Eyes[corner]
+}
+
+// ParseHexColor parses a #RRGGBB hex color.
+func ParseHexColor(hexColor string) (color.RGBA, error) {
+	if !hexColorPattern.MatchString(hexColor) {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected #RRGGBB", hexColor)
+	}
+
+	value, err := strconv.ParseUint(hexColor[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}, nil
+}
+
+// HexColor formats c as a #rrggbb hex color.
+func HexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// relativeLuminance returns the WCAG relative luminance of c, from 0 for black to 1 for white.
+func relativeLuminance(c color.RGBA) float64 {
+	linear := func(channel uint8) float64 {
+		v := float64(channel) / 0xff
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colors, from 1 for equal colors to 21
+// for black and white.
+func ContrastRatio(a, b color.RGBA) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// cmyk converts c to CMYK fractions for print output, using the full black replacement that
+// keeps pure black in the black ink alone.
+func cmyk(c color.RGBA) (cyan, magenta, yellow, black float64) {
+	r, g, b := float64(c.R)/0xff, float64(c.G)/0xff, float64(c.B)/0xff
+
+	black = 1 - math.Max(r, math.Max(g, b))
+	if black == 1 {
+		return 0, 0, 0, 1
+	}
+
+	return (1 - r - black) / (1 - black), (1 - g - black) / (1 - black), (1 - b - black) / (1 - black), black
+}
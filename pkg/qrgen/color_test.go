@@ -0,0 +1,62 @@
+package qrgen
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestContrastRatio verifies the WCAG contrast ratio of common color pairs.
+func TestContrastRatio(t *testing.T) {
+	testCases := map[string]struct {
+		a, b     string
+		expected float64
+	}{
+		"black on white": {a: "#000000", b: "#FFFFFF", expected: 21},
+		"white on black": {a: "#ffffff", b: "#000000", expected: 21},
+		"equal":          {a: "#3366cc", b: "#3366cc", expected: 1},
+		"grey on white":  {a: "#767676", b: "#ffffff", expected: 4.54},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			a, err := ParseHexColor(testCase.a)
+			if err != nil {
+				t.Fatalf("failed to parse %s: %s", testCase.a, err)
+			}
+			b, err := ParseHexColor(testCase.b)
+			if err != nil {
+				t.Fatalf("failed to parse %s: %s", testCase.b, err)
+			}
+
+			if ratio := ContrastRatio(a, b); math.Abs(ratio-testCase.expected) > 0.01 {
+				t.Errorf("expected contrast ratio %.2f, got %.2f", testCase.expected, ratio)
+			}
+		})
+	}
+
+	if _, err := ParseHexColor("red"); err == nil {
+		t.Errorf("expected an error for a named color")
+	}
+}
+
+// TestCMYK verifies that black is printed in black ink alone and white is left unprinted.
+func TestCMYK(t *testing.T) {
+	testCases := map[string]struct {
+		color    color.RGBA
+		expected [4]float64
+	}{
+		"black": {color: DefaultColors.Dark, expected: [4]float64{0, 0, 0, 1}},
+		"white": {color: DefaultColors.Light, expected: [4]float64{0, 0, 0, 0}},
+		"red":   {color: color.RGBA{R: 0xff, A: 0xff}, expected: [4]float64{0, 1, 1, 0}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cyan, magenta, yellow, black := cmyk(testCase.color)
+			if actual := [4]float64{cyan, magenta, yellow, black}; actual != testCase.expected {
+				t.Errorf("expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
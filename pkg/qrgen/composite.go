@@ -0,0 +1,53 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // Register the JPEG decoder for background images.
+	_ "image/png"  // Register the PNG decoder for background images.
+)
+
+// Placement positions a symbol on a background image.
+type Placement struct {
+	// X and Y are the position of the top left corner of the padding box on the background, in
+	// pixels.
+	X, Y int
+
+	// Padding is the width of the opaque box drawn around the symbol in the quiet zone color, in
+	// pixels, so that the background does not reach the quiet zone.
+	Padding int
+}
+
+// CompositePNG renders the symbol as PNGWithOptions does and draws it in an opaque padding box onto
+// a PNG or JPEG background image, returning a PNG image the size of the background. Interlaced
+// images cannot be composited.
+func (s *Symbol) CompositePNG(background []byte, size int, colors Colors, opts PNGOptions, placement Placement) ([]byte, error) {
+	if opts.Interlaced {
+		return nil, errors.New("interlaced images cannot be composited onto a background")
+	}
+
+	bg, _, err := image.Decode(bytes.NewReader(background))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode background image: %w", err)
+	}
+
+	symbol := s.image(size, colors, opts.Scaling)
+	origin := image.Pt(placement.X+placement.Padding, placement.Y+placement.Padding)
+	symbolRect := symbol.Bounds().Add(origin)
+	box := symbolRect.Inset(-placement.Padding)
+
+	img := image.NewRGBA(image.Rect(0, 0, bg.Bounds().Dx(), bg.Bounds().Dy()))
+	if !box.In(img.Bounds()) {
+		return nil, fmt.Errorf("the QR code and its padding of %d by %d pixels at %d, %d do not fit the background image of %d by %d pixels", box.Dx(), box.Dy(), box.Min.X, box.Min.Y, img.Bounds().Dx(), img.Bounds().Dy())
+	}
+
+	draw.Draw(img, img.Bounds(), bg, bg.Bounds().Min, draw.Src)
+	draw.Draw(img, box, image.NewUniform(colors.quietZone()), image.Point{}, draw.Src)
+	draw.Draw(img, symbolRect, symbol, image.Point{}, draw.Src)
+	releasePalettedImage(symbol)
+
+	return EncodePNG(img)
+}
@@ -0,0 +1,73 @@
+package qrgen
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// TestSymbolCompositePNG verifies that the symbol is drawn in its padding box onto PNG and JPEG
+// backgrounds, which show around it, and that symbols that do not fit are rejected.
+func TestSymbolCompositePNG(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	card := color.RGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff}
+	bg := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	draw.Draw(bg, bg.Bounds(), image.NewUniform(card), image.Point{}, draw.Src)
+
+	var pngBackground, jpegBackground bytes.Buffer
+	if err := png.Encode(&pngBackground, bg); err != nil {
+		t.Fatalf("failed to encode background: %s", err)
+	}
+	if err := jpeg.Encode(&jpegBackground, bg, nil); err != nil {
+		t.Fatalf("failed to encode background: %s", err)
+	}
+
+	placement := Placement{X: 150, Y: 40, Padding: 10}
+	for name, background := range map[string][]byte{"png": pngBackground.Bytes(), "jpeg": jpegBackground.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			data, err := symbol.CompositePNG(background, 200, DefaultColors, PNGOptions{}, placement)
+			if err != nil {
+				t.Fatalf("failed to composite: %s", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+
+			if img.Bounds() != bg.Bounds() {
+				t.Fatalf("expected the bounds of the background %v, got %v", bg.Bounds(), img.Bounds())
+			}
+			if r, _, _, _ := img.At(10, 10).RGBA(); r>>8 > 0x30 {
+				t.Errorf("expected the background outside the padding box")
+			}
+			if actual := color.RGBAModel.Convert(img.At(placement.X, placement.Y)); actual != DefaultColors.Light {
+				t.Errorf("expected the padding box in the quiet zone color, got %v", actual)
+			}
+
+			cropped := image.NewRGBA(image.Rect(0, 0, 200, 200))
+			draw.Draw(cropped, cropped.Bounds(), img, image.Pt(placement.X+placement.Padding, placement.Y+placement.Padding), draw.Src)
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, cropped); err != nil {
+				t.Fatalf("failed to encode: %s", err)
+			}
+			if text, err := decodeTestImage(buf.Bytes()); err != nil || text != "https://example.com" {
+				t.Errorf("expected the composited QR code to decode, got %q: %v", text, err)
+			}
+		})
+	}
+
+	if _, err := symbol.CompositePNG(pngBackground.Bytes(), 200, DefaultColors, PNGOptions{}, Placement{X: 250}); err == nil {
+		t.Errorf("expected a QR code that does not fit to be rejected")
+	}
+	if _, err := symbol.CompositePNG([]byte("not an image"), 200, DefaultColors, PNGOptions{}, Placement{}); err == nil {
+		t.Errorf("expected an invalid background to be rejected")
+	}
+}
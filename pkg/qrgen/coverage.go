@@ -0,0 +1,184 @@
+package qrgen
+
+import (
+	"image"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+	"github.com/makiuchi-d/gozxing/qrcode/encoder"
+)
+
+// Obscured describes the data of a symbol that an obscured area, such as a logo, hides.
+type Obscured struct {
+	// DataModulesPercent is the share of the modules holding data and error correction codewords
+	// that are in the area, in percent.
+	DataModulesPercent float64
+
+	// Codewords is the number of codewords with a module in the area, in the error correction
+	// block with the most of them for what it can correct.
+	Codewords int
+
+	// Correctable is the number of codewords that block can correct, half its error correction
+	// codewords other than those reserved to detect misdecodes.
+	Correctable int
+}
+
+// misdecodeCodewords are the error correction codewords that the smallest symbols reserve to
+// detect misdecodes rather than to correct errors, by version and level, as the QR code
+// specification lists them.
+var misdecodeCodewords = map[int]map[Level]int{
+	1: {Low: 3, Medium: 2, High: 1, Highest: 1},
+	2: {Low: 2},
+	3: {Low: 1},
+}
+
+// Recoverable reports whether every block can correct the codewords that the area hides.
+func (o Obscured) Recoverable() bool {
+	return o.Codewords <= o.Correctable
+}
+
+// Obscured returns the data of the symbol that the modules in area hide, in module coordinates
+// including the quiet zone. A codeword counts as lost when any of its modules is in the area,
+// whatever the color the area is drawn in, so the result errs on the safe side.
+func (s *Symbol) Obscured(area image.Rectangle) (Obscured, error) {
+	version, err := decoder.Version_GetVersionForNumber(s.version)
+	if err != nil {
+		return Obscured{}, err
+	}
+	ecLevel := ecLevels[s.level]
+
+	positions, err := codewordPositions(version, ecLevel)
+	if err != nil {
+		return Obscured{}, err
+	}
+	blocks := codewordBlocks(version, ecLevel)
+
+	// Modules are placed in the upright symbol, and the area is given in the rotated bitmap
+	n, border := len(s.bitmap), s.quietZone()
+	hidden := make([]bool, len(blocks))
+	dataModules, hiddenModules := 0, 0
+	for codeword, modules := range positions {
+		for _, module := range modules {
+			dataModules++
+			x, y := module.X+border, module.Y+border
+			for i := 0; i < s.turns; i++ {
+				x, y = n-1-y, x
+			}
+			if image.Pt(x, y).In(area) {
+				hiddenModules++
+				hidden[codeword] = true
+			}
+		}
+	}
+
+	ecBlocks := version.GetECBlocksForLevel(ecLevel)
+	correctable := (ecBlocks.GetECCodewordsPerBlock() - misdecodeCodewords[s.version][s.level]) / 2
+	perBlock := make([]int, ecBlocks.GetNumBlocks())
+	worst := 0
+	for codeword, block := range blocks {
+		if hidden[codeword] {
+			perBlock[block]++
+			worst = max(worst, perBlock[block])
+		}
+	}
+
+	// Every block of a symbol has as many error correction codewords, so the block with the most
+	// hidden codewords is the one closest to its limit
+	return Obscured{
+		DataModulesPercent: 100 * float64(hiddenModules) / float64(dataModules),
+		Codewords:          worst,
+		Correctable:        correctable,
+	}, nil
+}
+
+// ObscuredCenter returns the data of the symbol that a square at the center of a PNG image of the
+// given size and scaling hides, percent of the width of the image wide, as a logo drawn over the
+// image does.
+func (s *Symbol) ObscuredCenter(size int, scaling Scaling, percent int) (Obscured, error) {
+	canvas, offset, modules := s.pixelModules(size, scaling)
+	box := canvas * percent / 100
+	start := (canvas-box)/2 - offset
+	end := start + box
+
+	// Boxes that reach past the symbol, into the margin of fit scaling, are clipped to it
+	start, end = max(start, 0), min(end, len(modules))
+	if start >= end {
+		return s.Obscured(image.Rectangle{})
+	}
+	first, last := modules[start], modules[end-1]
+
+	return s.Obscured(image.Rect(first, first, last+1, last+1))
+}
+
+// codewordPositions returns the module positions of each codeword of a version, in the order the
+// codewords are interleaved, without the quiet zone. Data modules are told apart from function
+// patterns by placing all zero and all one bits, and are then walked in the two column zigzag
+// order that codewords are placed in.
+func codewordPositions(version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) ([][]image.Point, error) {
+	totalBits := version.GetTotalCodewords() * 8
+	dimension := version.GetDimensionForVersion()
+
+	zeros, ones := gozxing.NewBitArray(totalBits), gozxing.NewBitArray(totalBits)
+	ones.SetRange(0, totalBits)
+	zeroMatrix, oneMatrix := encoder.NewByteMatrix(dimension, dimension), encoder.NewByteMatrix(dimension, dimension)
+	if err := encoder.MatrixUtil_buildMatrix(zeros, ecLevel, version, 0, zeroMatrix); err != nil {
+		return nil, err
+	}
+	if err := encoder.MatrixUtil_buildMatrix(ones, ecLevel, version, 0, oneMatrix); err != nil {
+		return nil, err
+	}
+
+	positions := make([][]image.Point, version.GetTotalCodewords())
+	bit := 0
+	direction := -1
+	y := dimension - 1
+	for x := dimension - 1; x > 0; x -= 2 {
+		// The vertical timing pattern is skipped as a whole column
+		if x == 6 {
+			x--
+		}
+		for ; y >= 0 && y < dimension; y += direction {
+			for i := 0; i < 2; i++ {
+				if zeroMatrix.Get(x-i, y) == oneMatrix.Get(x-i, y) || bit >= totalBits {
+					continue
+				}
+				positions[bit/8] = append(positions[bit/8], image.Pt(x-i, y))
+				bit++
+			}
+		}
+		direction = -direction
+		y += direction
+	}
+
+	return positions, nil
+}
+
+// codewordBlocks returns the error correction block of each codeword of a version, in the order
+// the codewords are interleaved: the data codewords of the blocks in turn, then their error
+// correction codewords.
+func codewordBlocks(version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) []int {
+	ecBlocks := version.GetECBlocksForLevel(ecLevel)
+
+	var dataLengths []int
+	for _, group := range ecBlocks.GetECBlocks() {
+		for i := 0; i < group.GetCount(); i++ {
+			dataLengths = append(dataLengths, group.GetDataCodewords())
+		}
+	}
+
+	blocks := make([]int, 0, version.GetTotalCodewords())
+	for i := 0; i < dataLengths[len(dataLengths)-1]; i++ {
+		for block, length := range dataLengths {
+			if i < length {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	for i := 0; i < ecBlocks.GetECCodewordsPerBlock(); i++ {
+		for block := range dataLengths {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
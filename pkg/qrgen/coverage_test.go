@@ -0,0 +1,137 @@
+package qrgen
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+)
+
+// TestCodewordPositions verifies that every codeword of every version and level gets eight
+// distinct modules, and that the blocks cover every codeword.
+func TestCodewordPositions(t *testing.T) {
+	for number := 1; number <= 40; number++ {
+		version, err := decoder.Version_GetVersionForNumber(number)
+		if err != nil {
+			t.Fatalf("version %d: %s", number, err)
+		}
+		for level, ecLevel := range ecLevels {
+			positions, err := codewordPositions(version, ecLevel)
+			if err != nil {
+				t.Fatalf("version %d level %d: %s", number, level, err)
+			}
+			if len(positions) != version.GetTotalCodewords() {
+				t.Fatalf("version %d level %d: expected %d codewords, got %d", number, level, version.GetTotalCodewords(), len(positions))
+			}
+			seen := make(map[image.Point]bool)
+			for codeword, modules := range positions {
+				if len(modules) != 8 {
+					t.Fatalf("version %d level %d: codeword %d has %d modules", number, level, codeword, len(modules))
+				}
+				for _, module := range modules {
+					if seen[module] {
+						t.Fatalf("version %d level %d: module %v placed twice", number, level, module)
+					}
+					seen[module] = true
+				}
+			}
+
+			if blocks := codewordBlocks(version, ecLevel); len(blocks) != version.GetTotalCodewords() {
+				t.Fatalf("version %d level %d: expected %d block entries, got %d", number, level, version.GetTotalCodewords(), len(blocks))
+			}
+		}
+	}
+}
+
+// TestSymbolObscured verifies that symbols whose obscured area is reported recoverable still
+// decode with every module in the area inverted, the worst an overlay can do, and that a growing
+// area eventually is reported lost.
+func TestSymbolObscured(t *testing.T) {
+	text := strings.Repeat("https://example.com/", 3)
+	for _, level := range []Level{Low, Medium, High, Highest} {
+		for _, degrees := range []int{0, 90} {
+			t.Run(fmt.Sprintf("level %d rotated %d", level, degrees), func(t *testing.T) {
+				symbol, err := Encode(text, Options{Level: level})
+				if err != nil {
+					t.Fatalf("failed to encode: %s", err)
+				}
+				symbol = symbol.Rotate(degrees)
+
+				n := symbol.Modules()
+				lost := false
+				for side := 1; side < n/2; side++ {
+					area := image.Rect((n-side)/2, (n-side)/2, (n+side)/2, (n+side)/2)
+					obscured, err := symbol.Obscured(area)
+					if err != nil {
+						t.Fatalf("side %d: %s", side, err)
+					}
+					if !obscured.Recoverable() {
+						lost = true
+						break
+					}
+
+					damaged := &Symbol{bitmap: symbol.Bitmap(), version: symbol.version, level: symbol.level, turns: symbol.turns}
+					for y := area.Min.Y; y < area.Max.Y; y++ {
+						for x := area.Min.X; x < area.Max.X; x++ {
+							damaged.bitmap[y][x] = !damaged.bitmap[y][x]
+						}
+					}
+					data, err := damaged.PNG(testSize*2, DefaultColors)
+					if err != nil {
+						t.Fatalf("side %d: failed to render: %s", side, err)
+					}
+					decoded, err := decodeTestImage(data)
+					if err != nil {
+						t.Fatalf("side %d: reported recoverable (%d of %d codewords) but failed to decode: %s", side, obscured.Codewords, obscured.Correctable, err)
+					}
+					if decoded != text {
+						t.Fatalf("side %d: decoded %q", side, decoded)
+					}
+				}
+				if !lost {
+					t.Errorf("expected a large enough area to be reported lost")
+				}
+			})
+		}
+	}
+}
+
+// TestSymbolObscuredCenter verifies that a centered box hides more of the symbol as it grows, and
+// more with fit scaling, which draws the symbol smaller than the image.
+func TestSymbolObscuredCenter(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	none, err := symbol.ObscuredCenter(testSize, ScalingFill, 0)
+	if err != nil {
+		t.Fatalf("failed to compute: %s", err)
+	}
+	if none.Codewords != 0 || none.DataModulesPercent != 0 {
+		t.Errorf("expected an empty box to hide nothing, got %+v", none)
+	}
+
+	small, err := symbol.ObscuredCenter(testSize, ScalingFill, 10)
+	if err != nil {
+		t.Fatalf("failed to compute: %s", err)
+	}
+	large, err := symbol.ObscuredCenter(testSize, ScalingFill, 30)
+	if err != nil {
+		t.Fatalf("failed to compute: %s", err)
+	}
+	if small.DataModulesPercent == 0 || large.DataModulesPercent <= small.DataModulesPercent {
+		t.Errorf("expected a larger box to hide more, got %.1f%% and %.1f%%", small.DataModulesPercent, large.DataModulesPercent)
+	}
+
+	// The symbol is drawn 232 pixels wide in the 256 pixel image, so the box covers more of it
+	fit, err := symbol.ObscuredCenter(testSize, ScalingFit, 30)
+	if err != nil {
+		t.Fatalf("failed to compute: %s", err)
+	}
+	if fit.DataModulesPercent < large.DataModulesPercent {
+		t.Errorf("expected fit scaling to hide at least %.1f%%, got %.1f%%", large.DataModulesPercent, fit.DataModulesPercent)
+	}
+}
@@ -0,0 +1,28 @@
+// Package qrgen encodes and renders QR codes exactly as the qrcode_generate resource of the
+// Terraform provider does, so that images generated outside Terraform match the checksums
+// recorded in state byte for byte.
+//
+// The resource encodes at the level of its error_correction attribute, Medium by default, with
+// EncodeMaxLevel when it is auto_max. Set Options and the quiet zone from its error_correction,
+// optimize_encoding, byte_charset, reproducible and quiet_zone attributes:
+//
+//	symbol, err := qrgen.Encode("https://example.com", qrgen.Options{Level: qrgen.Medium})
+//	if err != nil {
+//		return err
+//	}
+//	data, err := symbol.WithQuietZone(2).PNG(256, qrgen.DefaultColors)
+//
+// The text of resources with compress set is first compressed with compress/zlib at
+// zlib.BestCompression, and that of resources with content_encoding set to base45 is then
+// encoded with EncodeBase45, or with EncodeSHC when it is set to shc. Content encrypted by content_encryption is randomized, so those
+// images cannot be reproduced.
+// Images of resources with scaling, interlaced or reproducible set are rendered with
+// PNGWithOptions instead, and the metadata of resources with metadata set is added with
+// WithPNGText. Images of resources with rotation set are rendered from Symbol.Rotate, and the
+// labels of resources with format zpl, epl or tspl are rendered with ZPL, EPL or TSPL, from the
+// resource's captions. Those with format escpos are rendered with ESCPOS when escpos_mode is
+// raster, and otherwise with ESCPOSQRCode at the level of the symbol, from the payload.
+//
+// The images of the qrcode_directory resource are encoded at the Medium level and rendered with
+// PNG in DefaultColors.
+package qrgen
@@ -0,0 +1,47 @@
+package qrgen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"time"
+)
+
+// AnimatedGIF renders symbols, such as a structured append series, as the frames of a GIF image
+// of the given size that loops through them forever, showing each for interval. Every frame is
+// drawn at the same size, raised to the width in modules of the largest symbol, so that the image
+// does not change size between frames. GIF images time frames in hundredths of a second, so
+// interval is rounded down to them, and to at least one.
+func AnimatedGIF(symbols []*Symbol, size int, colors Colors, interval time.Duration) ([]byte, error) {
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to animate")
+	}
+
+	for _, symbol := range symbols {
+		size = max(size, symbol.Modules())
+	}
+	delay := max(int(interval/(10*time.Millisecond)), 1)
+
+	animation := &gif.GIF{
+		Image:  make([]*image.Paletted, 0, len(symbols)),
+		Delay:  make([]int, 0, len(symbols)),
+		Config: image.Config{Width: size, Height: size},
+	}
+	for _, symbol := range symbols {
+		animation.Image = append(animation.Image, symbol.image(size, colors, ScalingFill))
+		animation.Delay = append(animation.Delay, delay)
+	}
+	defer func() {
+		for _, frame := range animation.Image {
+			releasePalettedImage(frame)
+		}
+	}()
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
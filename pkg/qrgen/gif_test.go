@@ -0,0 +1,87 @@
+package qrgen
+
+import (
+	"bytes"
+	"image/gif"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqrcode "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// TestAnimatedGIF verifies that every symbol of a series is a frame of the same size, shown for
+// the interval, that decodes to its position in the series, and that the animation loops.
+func TestAnimatedGIF(t *testing.T) {
+	data := make([]byte, 600)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	symbols, err := EncodeStructuredAppend(data, Medium, 10)
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if len(symbols) < 2 {
+		t.Fatalf("expected several symbols, got %d", len(symbols))
+	}
+
+	modules := 0
+	for _, symbol := range symbols {
+		modules = max(modules, symbol.Modules())
+	}
+	size := 4 * modules
+
+	gifData, err := AnimatedGIF(symbols, size, DefaultColors, 750*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	animation, err := gif.DecodeAll(bytes.NewReader(gifData))
+	if err != nil {
+		t.Fatalf("failed to decode GIF: %s", err)
+	}
+
+	if len(animation.Image) != len(symbols) || animation.LoopCount != 0 {
+		t.Fatalf("expected %d looping frames, got %d with loop count %d", len(symbols), len(animation.Image), animation.LoopCount)
+	}
+	if animation.Config.Width != size || animation.Config.Height != size {
+		t.Errorf("expected %d pixels, got %dx%d", size, animation.Config.Width, animation.Config.Height)
+	}
+
+	for position, frame := range animation.Image {
+		if animation.Delay[position] != 75 {
+			t.Errorf("frame %d: expected a delay of 75, got %d", position, animation.Delay[position])
+		}
+		if frame.Bounds().Dx() != size || frame.Bounds().Dy() != size {
+			t.Errorf("frame %d: expected the size of the image, got %v", position, frame.Bounds())
+		}
+
+		bitmap, err := gozxing.NewBinaryBitmapFromImage(frame)
+		if err != nil {
+			t.Fatalf("frame %d: failed to binarize: %s", position, err)
+		}
+		result, err := zxingqrcode.NewQRCodeReader().Decode(bitmap, nil)
+		if err != nil {
+			t.Fatalf("frame %d: failed to decode: %s", position, err)
+		}
+		if sequence := result.GetResultMetadata()[gozxing.ResultMetadataType_STRUCTURED_APPEND_SEQUENCE]; sequence != position<<4|(len(symbols)-1) {
+			t.Errorf("frame %d: unexpected sequence %v", position, sequence)
+		}
+	}
+
+	// Sizes smaller than the widest symbol are raised to its width in modules
+	gifData, err = AnimatedGIF(symbols, 1, DefaultColors, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	animation, err = gif.DecodeAll(bytes.NewReader(gifData))
+	if err != nil {
+		t.Fatalf("failed to decode GIF: %s", err)
+	}
+	if animation.Config.Width != modules || animation.Delay[0] != 1 {
+		t.Errorf("expected %d pixels and a delay of 1, got %d pixels and %d", modules, animation.Config.Width, animation.Delay[0])
+	}
+
+	if _, err := AnimatedGIF(nil, 100, DefaultColors, time.Second); err == nil {
+		t.Errorf("expected an animation without symbols to fail")
+	}
+}
@@ -0,0 +1,377 @@
+package qrgen
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Print profiles that PDF output can target. Each names a characterized printing condition
+// from the ICC registry, which is embedded as the PDF output intent.
+const (
+	PrintProfileFOGRA39    = "fogra39"
+	PrintProfileGRACoL2013 = "gracol2013"
+	PrintProfileSWOP2013   = "swop2013"
+)
+
+// PDFPointsPerInch is the resolution of PDF user space, for converting a print size to points.
+const PDFPointsPerInch = 72
+
+// pdfOutputCondition describes a registered printing condition.
+type pdfOutputCondition struct {
+	identifier string
+	info       string
+}
+
+// pdfOutputConditions maps print profiles to their registered printing conditions.
+var pdfOutputConditions = map[string]pdfOutputCondition{
+	PrintProfileFOGRA39:    {identifier: "FOGRA39", info: "Coated FOGRA39 (ISO 12647-2:2004)"},
+	PrintProfileGRACoL2013: {identifier: "CGATS21_CRPC6", info: "GRACoL2013 (CGATS 21-2, CRPC6)"},
+	PrintProfileSWOP2013:   {identifier: "CGATS21_CRPC5", info: "SWOP2013 (CGATS 21-2, CRPC5)"},
+}
+
+// PrintProfiles returns the supported print profiles in order.
+func PrintProfiles() []string {
+	profiles := make([]string, 0, len(pdfOutputConditions))
+	for profile := range pdfOutputConditions {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+
+	return profiles
+}
+
+// PDF renders the symbol as a single page PDF of the given size in points, with the dark modules
+// drawn as vector rectangles. Without a print profile the colors are drawn in RGB. With a print
+// profile they are converted to CMYK, so that black modules use black ink alone and a white
+// background is left unprinted, and the profile's printing condition is embedded as the output
+// intent, as print vendors expect.
+func (s *Symbol) PDF(size int, colors Colors, printProfile string) ([]byte, error) {
+	condition, err := pdfOutputConditionFor(printProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	content := s.pdfContent(size, colors, condition != nil)
+
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	if condition != nil {
+		catalog = "<< /Type /Catalog /Pages 2 0 R /OutputIntents [5 0 R] >>"
+	}
+
+	objects := []string{
+		catalog,
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /TrimBox [0 0 %d %d] /Contents 4 0 R /Resources << >> >>", size, size, size, size),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+	}
+	if condition != nil {
+		objects = append(objects, condition.outputIntent())
+	}
+
+	return pdfDocument(objects), nil
+}
+
+// PDFPage is a page of a multi-page PDF.
+type PDFPage struct {
+	// Symbol is the QR code drawn at the top of the page.
+	Symbol *Symbol
+
+	// Caption is printed centered below the symbol, or empty for no caption. Characters other
+	// than printable ASCII are printed as question marks.
+	Caption string
+}
+
+// PDFPages renders one page per symbol as a single PDF, for printing a batch of QR codes from one
+// file. Each page is size points wide, with the symbol drawn as PDF does and, for pages with a
+// caption, a band below it for the caption in the dark color. Colors and print profiles apply to
+// every page as they do for PDF.
+func PDFPages(pages []PDFPage, size int, colors Colors, printProfile string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WritePDFPages(&buf, pages, size, colors, printProfile); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WritePDFPages writes the PDF of PDFPages to w page by page, so that documents of thousands of
+// pages are streamed instead of built in memory. Nothing is written when the pages or the print
+// profile are invalid.
+func WritePDFPages(w io.Writer, pages []PDFPage, size int, colors Colors, printProfile string) error {
+	if len(pages) == 0 {
+		return errors.New("a PDF needs at least one page")
+	}
+
+	condition, err := pdfOutputConditionFor(printProfile)
+	if err != nil {
+		return err
+	}
+
+	// Objects are the catalog, the page tree and the caption font, then a page and its content
+	// stream for each page, then the output intent
+	firstPage := 4
+	outputIntent := firstPage + 2*len(pages)
+
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	if condition != nil {
+		catalog = fmt.Sprintf("<< /Type /Catalog /Pages 2 0 R /OutputIntents [%d 0 R] >>", outputIntent)
+	}
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	pdf := newPDFWriter(w)
+	pdf.object(catalog)
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	pdf.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		content := page.Symbol.pdfContent(size, colors, condition != nil)
+		height := size
+
+		if page.Caption != "" {
+			caption := pdfCaptionText(page.Caption)
+
+			// Courier glyphs are 0.6 em wide, so the caption is centered without font metrics
+			fontSize := math.Min(float64(size)/12, 0.9*float64(size)/(0.6*float64(len(caption))))
+			band := int(math.Ceil(2 * fontSize))
+			height += band
+
+			var buf bytes.Buffer
+			if condition == nil || colors.quietZone() != DefaultColors.Light {
+				fmt.Fprintf(&buf, "%s 0 0 %d %d re f\n", pdfFillColor(colors.quietZone(), condition != nil), size, band)
+			}
+			fmt.Fprintf(&buf, "q 1 0 0 1 0 %d cm\n%sQ\n", band, content)
+			fmt.Fprintf(&buf, "BT %s /F1 %s Tf %s %s Td %s Tj ET\n",
+				pdfFillColor(colors.Dark, condition != nil),
+				pdfNumber(fontSize),
+				pdfNumber((float64(size)-0.6*fontSize*float64(len(caption)))/2),
+				pdfNumber(0.75*fontSize),
+				pdfString(caption),
+			)
+			content = buf.Bytes()
+		}
+
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /TrimBox [0 0 %d %d] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>", size, height, size, height, firstPage+2*i+1))
+		pdf.object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+	if condition != nil {
+		pdf.object(condition.outputIntent())
+	}
+
+	return pdf.close()
+}
+
+// pdfContent returns the content stream that draws the symbol on a page of the given size in
+// points, in CMYK or RGB.
+func (s *Symbol) pdfContent(size int, colors Colors, useCMYK bool) []byte {
+	modules := len(s.bitmap)
+
+	// Draw in module units, with the origin at the top left like the other formats
+	var content bytes.Buffer
+	if !useCMYK || colors.quietZone() != DefaultColors.Light {
+		fmt.Fprintf(&content, "%s 0 0 %d %d re f\n", pdfFillColor(colors.quietZone(), useCMYK), size, size)
+	}
+	fmt.Fprintf(&content, "q %s 0 0 %s 0 %d cm\n", pdfNumber(float64(size)/float64(modules)), pdfNumber(-float64(size)/float64(modules)), size)
+	if colors.distinctQuietZone() {
+		border := s.quietZone()
+		fmt.Fprintf(&content, "%s %d %d %d %d re f\n", pdfFillColor(colors.Light, useCMYK), border, border, modules-2*border, modules-2*border)
+	}
+	fmt.Fprintf(&content, "%s\n", pdfFillColor(colors.Dark, useCMYK))
+	for _, rect := range s.darkRects() {
+		fmt.Fprintf(&content, "%d %d %d %d re\n", rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+	}
+	content.WriteString("f")
+
+	// Eyes in their own colors are drawn over the dark modules
+	for corner, eye := range s.eyes() {
+		if colors.eye(corner) == colors.Dark {
+			continue
+		}
+		fmt.Fprintf(&content, "\n%s\n", pdfFillColor(colors.eye(corner), useCMYK))
+		for _, rect := range s.darkRectsIn(eye) {
+			fmt.Fprintf(&content, "%d %d %d %d re\n", rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+		}
+		content.WriteString("f")
+	}
+	content.WriteString(" Q\n")
+
+	return content.Bytes()
+}
+
+// pdfOutputConditionFor returns the printing condition of a print profile, or nil without one.
+func pdfOutputConditionFor(printProfile string) (*pdfOutputCondition, error) {
+	if printProfile == "" {
+		return nil, nil
+	}
+
+	condition, ok := pdfOutputConditions[printProfile]
+	if !ok {
+		return nil, fmt.Errorf("unsupported print profile %q", printProfile)
+	}
+
+	return &condition, nil
+}
+
+// outputIntent returns the output intent object that embeds the printing condition.
+func (c *pdfOutputCondition) outputIntent() string {
+	return fmt.Sprintf(
+		"<< /Type /OutputIntent /S /GTS_PDFX /OutputConditionIdentifier %s /RegistryName (http://www.color.org) /Info %s >>",
+		pdfString(c.identifier),
+		pdfString(c.info),
+	)
+}
+
+// ImagePDF renders img as a single page PDF of the given size in points, with the image stretched
+// over the page, for raster compositions that have no vector form.
+func ImagePDF(img image.Image, width, height int) ([]byte, error) {
+	bounds := img.Bounds()
+
+	// Image data is 8-bit RGB, compressed with the Flate filter
+	var data bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&data, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	row := make([]byte, 3*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for i, x := 0, bounds.Min.X; x < bounds.Max.X; i, x = i+1, x+1 {
+			r, g, b, _ := img.At(x, y).RGBA()
+			row[3*i], row[3*i+1], row[3*i+2] = byte(r>>8), byte(g>>8), byte(b>>8)
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	content := fmt.Sprintf("q %d 0 0 %d 0 0 cm /Im0 Do Q\n", width, height)
+
+	return pdfDocument([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /TrimBox [0 0 %d %d] /Contents 4 0 R /Resources << /XObject << /Im0 5 0 R >> >> >>", width, height, width, height),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", bounds.Dx(), bounds.Dy(), data.Len(), data.String()),
+	}), nil
+}
+
+// pdfDocument writes objects, numbered from 1 with the catalog first, as a PDF file with a
+// cross-reference table.
+func pdfDocument(objects []string) []byte {
+	var buf bytes.Buffer
+	pdf := newPDFWriter(&buf)
+	for _, object := range objects {
+		pdf.object(object)
+	}
+
+	// Writes to a bytes.Buffer do not fail
+	_ = pdf.close()
+
+	return buf.Bytes()
+}
+
+// pdfWriter writes a PDF file to w one object at a time, numbering the objects from 1 in the order
+// they are written and recording their offsets for the cross-reference table. The first error
+// stops all further writes and is returned by close.
+type pdfWriter struct {
+	w       io.Writer
+	offset  int
+	offsets []int
+	err     error
+}
+
+// newPDFWriter writes the PDF header to w.
+func newPDFWriter(w io.Writer) *pdfWriter {
+	pdf := &pdfWriter{w: w}
+	pdf.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	return pdf
+}
+
+// printf writes formatted text, keeping track of the offset.
+func (p *pdfWriter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.offset += n
+	p.err = err
+}
+
+// object writes the next object.
+func (p *pdfWriter) object(object string) {
+	p.offsets = append(p.offsets, p.offset)
+	p.printf("%d 0 obj\n%s\nendobj\n", len(p.offsets), object)
+}
+
+// close writes the cross-reference table and the trailer, and returns the first error.
+func (p *pdfWriter) close() error {
+	xref := p.offset
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+
+	return p.err
+}
+
+// pdfNumber formats a real number for a PDF content stream.
+func pdfNumber(n float64) string {
+	return fmt.Sprintf("%.4f", n)
+}
+
+// pdfFillColor returns the operator that sets the fill color to c, in CMYK or RGB.
+func pdfFillColor(c color.RGBA, useCMYK bool) string {
+	component := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+	}
+
+	if useCMYK {
+		cyan, magenta, yellow, black := cmyk(c)
+		return fmt.Sprintf("%s %s %s %s k", component(cyan), component(magenta), component(yellow), component(black))
+	}
+
+	return fmt.Sprintf("%s %s %s rg", component(float64(c.R)/0xff), component(float64(c.G)/0xff), component(float64(c.B)/0xff))
+}
+
+// pdfCaptionText replaces the characters of text that the caption font cannot print with question
+// marks.
+func pdfCaptionText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, text)
+}
+
+// pdfString formats text as a PDF literal string.
+func pdfString(text string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for _, c := range []byte(text) {
+		if c == '(' || c == ')' || c == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	buf.WriteByte(')')
+
+	return buf.String()
+}
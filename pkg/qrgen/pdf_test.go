@@ -0,0 +1,208 @@
+package qrgen
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// TestSymbolPDF verifies the colour space and output intent of PDF output, and that the
+// cross-reference table points at the objects.
+func TestSymbolPDF(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	testCases := map[string]struct {
+		printProfile string
+		expected     []string
+		unexpected   []string
+	}{
+		"rgb": {
+			expected:   []string{"0 0 0 rg", "1 1 1 rg"},
+			unexpected: []string{" k\n", "/OutputIntent"},
+		},
+		"cmyk": {
+			printProfile: PrintProfileFOGRA39,
+			expected:     []string{"0 0 0 1 k", "/OutputIntents [5 0 R]", "/S /GTS_PDFX", "/OutputConditionIdentifier (FOGRA39)"},
+			unexpected:   []string{" rg"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pdf, err := symbol.PDF(testSize, DefaultColors, testCase.printProfile)
+			if err != nil {
+				t.Fatalf("failed to render: %s", err)
+			}
+
+			for _, expected := range testCase.expected {
+				if !bytes.Contains(pdf, []byte(expected)) {
+					t.Errorf("expected PDF to contain %q", expected)
+				}
+			}
+			for _, unexpected := range testCase.unexpected {
+				if bytes.Contains(pdf, []byte(unexpected)) {
+					t.Errorf("expected PDF not to contain %q", unexpected)
+				}
+			}
+
+			startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+			if startxref == nil {
+				t.Fatalf("missing startxref")
+			}
+			xref, _ := strconv.Atoi(string(startxref[1]))
+			if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+				t.Fatalf("startxref %d does not point at the cross-reference table", xref)
+			}
+
+			for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1) {
+				offset, _ := strconv.Atoi(string(entry[1]))
+				if object := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(object)) {
+					t.Errorf("cross-reference entry %d does not point at object %d", i, i+1)
+				}
+			}
+		})
+	}
+
+	if _, err := symbol.PDF(testSize, DefaultColors, "unknown"); err == nil {
+		t.Errorf("expected an error for an unknown print profile")
+	}
+}
+
+// TestImagePDF verifies that raster images are embedded as RGB image data stretched over the page.
+func TestImagePDF(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(2, 1, color.RGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff})
+
+	pdf, err := ImagePDF(img, 300, 200)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	for _, expected := range []string{"/MediaBox [0 0 300 200]", "q 300 0 0 200 0 0 cm /Im0 Do Q", "/Width 3 /Height 2 /ColorSpace /DeviceRGB"} {
+		if !bytes.Contains(pdf, []byte(expected)) {
+			t.Errorf("expected PDF to contain %q", expected)
+		}
+	}
+
+	stream := regexp.MustCompile(`(?s)/FlateDecode /Length (\d+) >>\nstream\n`).FindSubmatchIndex(pdf)
+	if stream == nil {
+		t.Fatalf("missing image stream")
+	}
+	length, _ := strconv.Atoi(string(pdf[stream[2]:stream[3]]))
+	zr, err := zlib.NewReader(bytes.NewReader(pdf[stream[1] : stream[1]+length]))
+	if err != nil {
+		t.Fatalf("failed to read image data: %s", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("failed to read image data: %s", err)
+	}
+	if len(data) != 3*3*2 || !bytes.Equal(data[15:], []byte{0x1a, 0x23, 0x7e}) {
+		t.Errorf("unexpected image data %x", data)
+	}
+}
+
+// TestPDFPages verifies that each symbol gets its own page, that captions extend the page below
+// the symbol, and that the cross-reference table points at the objects.
+func TestPDFPages(t *testing.T) {
+	first, err := Encode("https://example.com/1", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	second, err := Encode("https://example.com/2", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	pdf, err := PDFPages([]PDFPage{{Symbol: first, Caption: "Table (1)"}, {Symbol: second}}, testSize, DefaultColors, PrintProfileFOGRA39)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	for _, expected := range []string{
+		"/Kids [4 0 R 6 0 R] /Count 2",
+		"/BaseFont /Courier",
+		"/MediaBox [0 0 256 299]",
+		"/MediaBox [0 0 256 256]",
+		"(Table \\(1\\)) Tj",
+		"/OutputIntents [8 0 R]",
+	} {
+		if !bytes.Contains(pdf, []byte(expected)) {
+			t.Errorf("expected PDF to contain %q", expected)
+		}
+	}
+
+	xref, _ := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	if len(entries) != 8 {
+		t.Fatalf("expected 8 objects, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if object := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(object)) {
+			t.Errorf("cross-reference entry %d does not point at object %d", i, i+1)
+		}
+	}
+
+	if _, err := PDFPages(nil, testSize, DefaultColors, ""); err == nil {
+		t.Errorf("expected an error for a PDF without pages")
+	}
+}
+
+// failingWriter fails every write after the first limit bytes.
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, io.ErrShortWrite
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+// TestWritePDFPages verifies that streamed PDFs are the same as PDFPages renders them, that nothing
+// is written for invalid pages, and that write errors are returned.
+func TestWritePDFPages(t *testing.T) {
+	var pages []PDFPage
+	for i := 0; i < 50; i++ {
+		symbol, err := Encode(fmt.Sprintf("https://example.com/%d", i), Options{Level: Medium})
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		pages = append(pages, PDFPage{Symbol: symbol, Caption: strconv.Itoa(i)})
+	}
+
+	expected, err := PDFPages(pages, 200, DefaultColors, PrintProfileFOGRA39)
+	if err != nil {
+		t.Fatalf("PDFPages failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WritePDFPages(&buf, pages, 200, DefaultColors, PrintProfileFOGRA39); err != nil {
+		t.Fatalf("WritePDFPages failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Error("expected the streamed PDF to be the same as PDFPages renders it")
+	}
+
+	buf.Reset()
+	if err := WritePDFPages(&buf, nil, 200, DefaultColors, ""); err == nil || buf.Len() != 0 {
+		t.Errorf("expected an error without writing for no pages, got %d bytes: %v", buf.Len(), err)
+	}
+
+	if err := WritePDFPages(&failingWriter{limit: 1000}, pages, 200, DefaultColors, ""); err != io.ErrShortWrite {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
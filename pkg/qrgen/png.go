@@ -0,0 +1,301 @@
+package qrgen
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"image"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// pngSignature starts every PNG file.
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// pngMetadataChunks are the chunk types that carry metadata rather than image data.
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"iTXt": true,
+	"tEXt": true,
+	"tIME": true,
+	"zTXt": true,
+}
+
+// pngKeywordPattern matches text chunk keywords that are printable ASCII, from 1 to 79 characters
+// without leading, trailing or consecutive spaces.
+var pngKeywordPattern = regexp.MustCompile(`^[!-~]+( [!-~]+)*$`)
+
+// pngPass is the first pixel and the spacing of the pixels of a pass over an image.
+type pngPass struct {
+	x, y, dx, dy int
+}
+
+// adam7Passes are the passes of Adam7 interlacing.
+var adam7Passes = []pngPass{
+	{0, 0, 8, 8},
+	{4, 0, 8, 8},
+	{0, 4, 4, 8},
+	{2, 0, 4, 4},
+	{0, 2, 2, 4},
+	{1, 0, 2, 2},
+	{0, 1, 1, 2},
+}
+
+// encodePalettedPNG encodes a paletted image as a PNG with the smallest bit depth that holds its
+// palette, 1 bit for two colors, optionally with Adam7 interlacing, which image/png cannot write.
+// Rows are not filtered. Stored images keep the image data in uncompressed deflate blocks, so that
+// their bytes are fixed by this function alone.
+func encodePalettedPNG(img *image.Paletted, interlaced, stored bool) ([]byte, error) {
+	if len(img.Palette) > 256 {
+		return nil, fmt.Errorf("paletted PNG images support up to 256 colors, got %d", len(img.Palette))
+	}
+
+	bitDepth := 1
+	for 1<<bitDepth < len(img.Palette) {
+		bitDepth *= 2
+	}
+	pixelsPerByte := 8 / bitDepth
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var buf bytes.Buffer
+	buf.WriteString(pngSignature)
+
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], uint32(width))
+	binary.BigEndian.PutUint32(header[4:], uint32(height))
+	header[8] = byte(bitDepth)
+	header[9] = 3 // palette color type
+	passes := []pngPass{{0, 0, 1, 1}}
+	if interlaced {
+		header[12] = 1 // Adam7 interlacing
+		passes = adam7Passes
+	}
+	writePNGChunk(&buf, "IHDR", header)
+
+	palette := make([]byte, 0, 3*len(img.Palette))
+	alpha := make([]byte, 0, len(img.Palette))
+	opaque := true
+	for _, c := range img.Palette {
+		r, g, b, a := c.RGBA()
+		palette = append(palette, byte(r>>8), byte(g>>8), byte(b>>8))
+		alpha = append(alpha, byte(a>>8))
+		opaque = opaque && a == 0xffff
+	}
+	writePNGChunk(&buf, "PLTE", palette)
+	if !opaque {
+		writePNGChunk(&buf, "tRNS", alpha)
+	}
+
+	// Every pass is a reduced image of its own, with rows starting with a filter type of none
+	var raw bytes.Buffer
+	for _, pass := range passes {
+		if width <= pass.x || height <= pass.y {
+			continue
+		}
+
+		passWidth := (width - pass.x + pass.dx - 1) / pass.dx
+		row := make([]byte, 1+(passWidth+pixelsPerByte-1)/pixelsPerByte)
+		for y := pass.y; y < height; y += pass.dy {
+			clear(row)
+			for i, x := 0, pass.x; x < width; i, x = i+1, x+pass.dx {
+				index := img.Pix[img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)]
+				row[1+i/pixelsPerByte] |= index << (8 - bitDepth*(1+i%pixelsPerByte))
+			}
+			raw.Write(row)
+		}
+	}
+
+	if stored {
+		writePNGChunk(&buf, "IDAT", zlibStored(raw.Bytes()))
+	} else {
+		var data bytes.Buffer
+		zw, err := zlib.NewWriterLevel(&data, zlib.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(raw.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		writePNGChunk(&buf, "IDAT", data.Bytes())
+	}
+
+	writePNGChunk(&buf, "IEND", nil)
+
+	return buf.Bytes(), nil
+}
+
+// zlibStored wraps data in a zlib stream of uncompressed deflate blocks.
+func zlibStored(data []byte) []byte {
+	var checksum [4]byte
+	binary.BigEndian.PutUint32(checksum[:], adler32.Checksum(data))
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0x78, 0x01}) // deflate with a 32 KiB window, no preset dictionary
+
+	for {
+		n := min(len(data), 0xffff)
+		final := byte(0)
+		if n == len(data) {
+			final = 1
+		}
+
+		var header [5]byte
+		header[0] = final // block type 00, stored
+		binary.LittleEndian.PutUint16(header[1:], uint16(n))
+		binary.LittleEndian.PutUint16(header[3:], ^uint16(n))
+		buf.Write(header[:])
+		buf.Write(data[:n])
+
+		data = data[n:]
+		if final == 1 {
+			break
+		}
+	}
+
+	buf.Write(checksum[:])
+
+	return buf.Bytes()
+}
+
+// writePNGChunk appends a chunk of the given type to buf.
+func writePNGChunk(buf *bytes.Buffer, chunkType string, data []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	buf.Write(length[:])
+
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(chunkType))
+	crc.Write(data)
+
+	buf.WriteString(chunkType)
+	buf.Write(data)
+
+	var checksum [4]byte
+	binary.BigEndian.PutUint32(checksum[:], crc.Sum32())
+	buf.Write(checksum[:])
+}
+
+// pngChunk is a chunk of a PNG file.
+type pngChunk struct {
+	chunkType string
+	data      []byte
+}
+
+// readPNGChunks splits a PNG file into its chunks, checking the signature and the chunk lengths
+// but not the checksums.
+func readPNGChunks(data []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, errors.New("not a PNG image")
+	}
+
+	var chunks []pngChunk
+	for rest := data[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		length := binary.BigEndian.Uint32(rest)
+		if uint64(len(rest)) < 12+uint64(length) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		chunks = append(chunks, pngChunk{chunkType: string(rest[4:8]), data: rest[8 : 8+length]})
+		rest = rest[12+length:]
+	}
+
+	return chunks, nil
+}
+
+// writePNGChunks joins chunks into a PNG file.
+func writePNGChunks(chunks []pngChunk) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(pngSignature)
+	for _, chunk := range chunks {
+		writePNGChunk(&buf, chunk.chunkType, chunk.data)
+	}
+
+	return buf.Bytes()
+}
+
+// WithPNGText adds a text chunk for every keyword and value to a PNG image, in keyword order
+// after the header so that readers find them without decoding the image. Values in Latin-1 are
+// written as tEXt chunks, and others as uncompressed UTF-8 iTXt chunks. Keywords must be printable
+// ASCII, from 1 to 79 characters without leading, trailing or consecutive spaces.
+func WithPNGText(data []byte, text map[string]string) ([]byte, error) {
+	chunks, err := readPNGChunks(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 || chunks[0].chunkType != "IHDR" {
+		return nil, errors.New("PNG image does not start with a header")
+	}
+
+	keywords := make([]string, 0, len(text))
+	for keyword := range text {
+		if len(keyword) > 79 || !pngKeywordPattern.MatchString(keyword) {
+			return nil, fmt.Errorf("invalid PNG text keyword %q", keyword)
+		}
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	textChunks := make([]pngChunk, 0, len(keywords))
+	for _, keyword := range keywords {
+		value := text[keyword]
+		if !utf8.ValidString(value) || strings.ContainsRune(value, 0) {
+			return nil, fmt.Errorf("PNG text %q must be UTF-8 without NUL characters", keyword)
+		}
+
+		if latin1, ok := toLatin1(value); ok {
+			textChunks = append(textChunks, pngChunk{chunkType: "tEXt", data: []byte(keyword + "\x00" + latin1)})
+			continue
+		}
+
+		// Keyword, null separator, no compression, and empty language tag and translated keyword
+		textChunks = append(textChunks, pngChunk{chunkType: "iTXt", data: []byte(keyword + "\x00\x00\x00\x00\x00" + value)})
+	}
+
+	chunks = append(chunks[:1], append(textChunks, chunks[1:]...)...)
+
+	return writePNGChunks(chunks), nil
+}
+
+// StripPNGMetadata removes the text, time and Exif chunks from a PNG image, leaving only the
+// chunks needed to display it.
+func StripPNGMetadata(data []byte) ([]byte, error) {
+	chunks, err := readPNGChunks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := chunks[:0]
+	for _, chunk := range chunks {
+		if !pngMetadataChunks[chunk.chunkType] {
+			kept = append(kept, chunk)
+		}
+	}
+
+	return writePNGChunks(kept), nil
+}
+
+// toLatin1 encodes text in Latin-1, reporting whether every character has a Latin-1 encoding.
+func toLatin1(text string) (string, bool) {
+	latin1 := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r > 0xff {
+			return "", false
+		}
+		latin1 = append(latin1, byte(r))
+	}
+
+	return string(latin1), true
+}
@@ -0,0 +1,156 @@
+package qrgen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// TestSymbolInterlacedPNG verifies that interlaced images are Adam7 interlaced and decode to the
+// same pixels as non-interlaced images.
+func TestSymbolInterlacedPNG(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	colors := Colors{Dark: color.RGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff}, Light: DefaultColors.Light}
+	for _, s := range []*Symbol{symbol, symbol.WithQuietZone(0)} {
+		for _, size := range []int{s.Modules(), testSize, 301} {
+			data, err := s.InterlacedPNG(size, colors)
+			if err != nil {
+				t.Fatalf("size %d: failed to render: %s", size, err)
+			}
+			if len(data) < 29 || data[28] != 1 {
+				t.Fatalf("size %d: expected the header to declare Adam7 interlacing", size)
+			}
+
+			interlaced, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("size %d: failed to decode: %s", size, err)
+			}
+
+			plainData, err := s.PNG(size, colors)
+			if err != nil {
+				t.Fatalf("size %d: failed to render: %s", size, err)
+			}
+			plain, err := png.Decode(bytes.NewReader(plainData))
+			if err != nil {
+				t.Fatalf("size %d: failed to decode: %s", size, err)
+			}
+
+			if interlaced.Bounds() != plain.Bounds() {
+				t.Fatalf("size %d: expected bounds %v, got %v", size, plain.Bounds(), interlaced.Bounds())
+			}
+			bounds := plain.Bounds()
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					if color.RGBAModel.Convert(interlaced.At(x, y)) != color.RGBAModel.Convert(plain.At(x, y)) {
+						t.Fatalf("size %d: pixel (%d, %d) differs", size, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestWithPNGText verifies that text chunks are added after the header in keyword order, in
+// tEXt or iTXt chunks by character set, that the image still decodes, and that they are stripped.
+func TestWithPNGText(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	data, err := symbol.PNG(testSize, DefaultColors)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	tagged, err := WithPNGText(data, map[string]string{"Title": "Café", "Author": "テスト"})
+	if err != nil {
+		t.Fatalf("failed to add text: %s", err)
+	}
+
+	chunks, err := readPNGChunks(tagged)
+	if err != nil {
+		t.Fatalf("failed to read chunks: %s", err)
+	}
+	if len(chunks) < 3 || chunks[1].chunkType != "iTXt" || chunks[2].chunkType != "tEXt" {
+		t.Fatalf("expected iTXt and tEXt chunks after the header, got %v", chunks)
+	}
+	if expected := "Author\x00\x00\x00\x00\x00テスト"; string(chunks[1].data) != expected {
+		t.Errorf("expected iTXt data %q, got %q", expected, chunks[1].data)
+	}
+	if expected := "Title\x00Caf\xe9"; string(chunks[2].data) != expected {
+		t.Errorf("expected tEXt data %q, got %q", expected, chunks[2].data)
+	}
+	if _, err := png.Decode(bytes.NewReader(tagged)); err != nil {
+		t.Errorf("failed to decode: %s", err)
+	}
+
+	stripped, err := StripPNGMetadata(tagged)
+	if err != nil {
+		t.Fatalf("failed to strip: %s", err)
+	}
+	if !bytes.Equal(stripped, data) {
+		t.Errorf("expected stripping to restore the original image")
+	}
+
+	for _, keyword := range []string{"", " Title", "Two  spaces", string(make([]byte, 80))} {
+		if _, err := WithPNGText(data, map[string]string{keyword: "value"}); err == nil {
+			t.Errorf("expected keyword %q to be rejected", keyword)
+		}
+	}
+}
+
+// TestReproduciblePNG pins the checksum of reproducible images, so that a change to their bytes,
+// from this package or a dependency, fails here rather than changing checksums for users. It also
+// verifies that images whose data spans several stored deflate blocks decode to the same pixels as
+// compressed images.
+func TestReproduciblePNG(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium, Reproducible: true})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	data, err := symbol.PNGWithOptions(testSize, DefaultColors, PNGOptions{Reproducible: true})
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	hash := sha256.Sum256(data)
+	if expected := "12008f9f34e7724bf5f15a2687ac5d2b79c2636ab5d9ad9d30600f3716b5bb88"; hex.EncodeToString(hash[:]) != expected {
+		t.Errorf("expected checksum %s, got %x", expected, hash)
+	}
+
+	for _, interlaced := range []bool{false, true} {
+		data, err := symbol.PNGWithOptions(2048, DefaultColors, PNGOptions{Interlaced: interlaced, Reproducible: true})
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		reproducible, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+
+		plainData, err := symbol.PNG(2048, DefaultColors)
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		plain, err := png.Decode(bytes.NewReader(plainData))
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+
+		bounds := plain.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if color.RGBAModel.Convert(reproducible.At(x, y)) != color.RGBAModel.Convert(plain.At(x, y)) {
+					t.Fatalf("interlaced %t: pixel (%d, %d) differs", interlaced, x, y)
+				}
+			}
+		}
+	}
+}
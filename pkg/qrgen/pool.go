@@ -0,0 +1,87 @@
+package qrgen
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+)
+
+// pngEncoderBufferPool pools the compression state of image/png encoders: the zlib writer and the
+// row buffers, close to a megabyte at the best compression, which would otherwise be allocated
+// and collected for every image of a batch.
+type pngEncoderBufferPool struct {
+	pool sync.Pool
+}
+
+// Get returns a pooled encoder buffer, or nil for the encoder to allocate one.
+func (p *pngEncoderBufferPool) Get() *png.EncoderBuffer {
+	buffer, _ := p.pool.Get().(*png.EncoderBuffer)
+	return buffer
+}
+
+// Put returns an encoder buffer to the pool once an image is encoded.
+func (p *pngEncoderBufferPool) Put(buffer *png.EncoderBuffer) {
+	p.pool.Put(buffer)
+}
+
+var (
+	// pngEncoderBuffers is shared by every PNG encoder of the package.
+	pngEncoderBuffers = &pngEncoderBufferPool{}
+
+	// outputBuffers pools the buffers that images are encoded into, so that they are grown to
+	// the size of an image once per batch rather than once per image.
+	outputBuffers = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	// pixelBuffers pools the pixels of the paletted images that symbols are drawn into before
+	// they are encoded, as *[]uint8.
+	pixelBuffers sync.Pool
+)
+
+// EncodePNG encodes img as a PNG image at the best compression, reusing the encoder state and
+// buffers of earlier images, so that rendering many images spends little time collecting
+// garbage. It is safe for concurrent use.
+func EncodePNG(img image.Image) ([]byte, error) {
+	buf, ok := outputBuffers.Get().(*bytes.Buffer)
+	if !ok {
+		buf = new(bytes.Buffer)
+	}
+	buf.Reset()
+	defer outputBuffers.Put(buf)
+
+	pngEncoder := png.Encoder{
+		CompressionLevel: png.BestCompression,
+		BufferPool:       pngEncoderBuffers,
+	}
+	if err := pngEncoder.Encode(buf, img); err != nil {
+		return nil, err
+	}
+
+	// The buffer goes back to the pool, so the image is returned in a slice of its own
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// newPalettedImage returns a paletted image of the given bounds with all pixels at palette index
+// 0, reusing the pixels of an image released by releasePalettedImage when they are large enough.
+func newPalettedImage(r image.Rectangle, palette color.Palette) *image.Paletted {
+	n := r.Dx() * r.Dy()
+	if pixels, ok := pixelBuffers.Get().(*[]uint8); ok && cap(*pixels) >= n {
+		pix := (*pixels)[:n]
+		clear(pix)
+		return &image.Paletted{Pix: pix, Stride: r.Dx(), Rect: r, Palette: palette}
+	}
+
+	return image.NewPaletted(r, palette)
+}
+
+// releasePalettedImage returns the pixels of an image from newPalettedImage to the pool. The image
+// must not be used afterwards.
+func releasePalettedImage(img *image.Paletted) {
+	pix := img.Pix
+	pixelBuffers.Put(&pix)
+}
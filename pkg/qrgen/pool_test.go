@@ -0,0 +1,62 @@
+package qrgen
+
+import (
+	"bytes"
+	"image/png"
+	"sync"
+	"testing"
+)
+
+// TestPNGReusesBuffers verifies that images rendered with pooled buffers, after larger and smaller
+// images and concurrently, are the same as images rendered with fresh buffers.
+func TestPNGReusesBuffers(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	colors := Colors{Dark: DefaultColors.Dark, Light: DefaultColors.Light, QuietZone: DefaultColors.Dark}
+
+	expected := map[int][]byte{}
+	for _, size := range []int{testSize, 1000, 100} {
+		var buf bytes.Buffer
+		pngEncoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := pngEncoder.Encode(&buf, symbol.image(size, colors, ScalingFit)); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		expected[size] = buf.Bytes()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, size := range []int{1000, 100, testSize, 1000} {
+				data, err := symbol.PNGWithOptions(size, colors, PNGOptions{Scaling: ScalingFit})
+				if err != nil {
+					t.Errorf("PNG failed: %v", err)
+					return
+				}
+				if !bytes.Equal(data, expected[size]) {
+					t.Errorf("image of %d pixels differs from an image rendered with fresh buffers", size)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkSymbolPNG renders a batch of images, reporting the allocations per image.
+func BenchmarkSymbolPNG(b *testing.B) {
+	symbol, err := Encode("https://example.com/device/0123456789", Options{Level: Medium})
+	if err != nil {
+		b.Fatalf("Encode failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := symbol.PNG(512, DefaultColors); err != nil {
+			b.Fatalf("PNG failed: %v", err)
+		}
+	}
+}
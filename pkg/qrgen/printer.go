@@ -0,0 +1,266 @@
+package qrgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Captions are printed below the QR code in the printer's built-in font, one line per caption.
+const (
+	printerCaptionHeight = 30
+	printerCaptionGap    = 10
+)
+
+// monochromeRaster is the symbol scaled by a whole number of printer dots per module, with each
+// row packed eight dots to a byte, most significant bit first, as label and receipt printers take
+// bitmaps. Set bits are dark dots.
+type monochromeRaster struct {
+	width       int
+	height      int
+	bytesPerRow int
+	rows        [][]byte
+}
+
+// monochromeRaster scales the symbol to at most size dots, and to at least one dot per module.
+// Printers cannot resample, so the modules are never stretched by a fraction of a dot.
+func (s *Symbol) monochromeRaster(size int) monochromeRaster {
+	scale := max(size/s.Modules(), 1)
+	width := s.Modules() * scale
+	raster := monochromeRaster{
+		width:       width,
+		height:      width,
+		bytesPerRow: (width + 7) / 8,
+	}
+
+	for _, modules := range s.bitmap {
+		row := make([]byte, raster.bytesPerRow)
+		for x := 0; x < width; x++ {
+			if modules[x/scale] {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		for i := 0; i < scale; i++ {
+			raster.rows = append(raster.rows, row)
+		}
+	}
+
+	return raster
+}
+
+// inverted returns the rows with set bits for light dots, for printers that print cleared bits.
+// The padding bits at the end of each row are set too, so that nothing is printed past the code.
+func (r monochromeRaster) inverted() [][]byte {
+	rows := make([][]byte, len(r.rows))
+	for y, row := range r.rows {
+		rows[y] = make([]byte, len(row))
+		for i, b := range row {
+			rows[y][i] = ^b
+		}
+	}
+
+	return rows
+}
+
+// captionY returns the vertical position in dots of the i-th caption below the raster.
+func (r monochromeRaster) captionY(i int) int {
+	return r.height + printerCaptionGap + i*(printerCaptionHeight+printerCaptionGap)
+}
+
+// printerLanguage serializes a label in the command language of a family of printers: the
+// commands that start the label and place the code, one command per caption, and the commands
+// that print the label.
+type printerLanguage struct {
+	graphic func(buf *bytes.Buffer, raster monochromeRaster)
+	caption func(buf *bytes.Buffer, y int, text string)
+	print   string
+}
+
+// printerLabel renders the symbol as a label at at most size dots, with the captions below it.
+func (s *Symbol) printerLabel(size int, captions []string, language printerLanguage) []byte {
+	raster := s.monochromeRaster(size)
+
+	var buf bytes.Buffer
+	language.graphic(&buf, raster)
+	for i, caption := range captions {
+		language.caption(&buf, raster.captionY(i), caption)
+	}
+	buf.WriteString(language.print)
+
+	return buf.Bytes()
+}
+
+// The code is sent to printers as a bitmap rather than as a QR code command, so that the printer
+// does not encode the text again and prints exactly the modules of the symbol.
+var (
+	zplLanguage = printerLanguage{
+		graphic: func(buf *bytes.Buffer, raster monochromeRaster) {
+			fmt.Fprintf(buf, "~DGR:QRCODE.GRF,%d,%d,\n", raster.bytesPerRow*raster.height, raster.bytesPerRow)
+			for _, row := range raster.rows {
+				fmt.Fprintf(buf, "%X\n", row)
+			}
+			buf.WriteString("^XA\n^CI28\n^FO0,0^XGR:QRCODE.GRF,1,1^FS\n")
+		},
+		caption: func(buf *bytes.Buffer, y int, text string) {
+			fmt.Fprintf(buf, "^FO0,%d^A0N,%d,%d^FH^FD%s^FS\n", y, printerCaptionHeight, printerCaptionHeight, zplFieldData(text))
+		},
+		print: "^XZ\n",
+	}
+
+	eplLanguage = printerLanguage{
+		graphic: func(buf *bytes.Buffer, raster monochromeRaster) {
+			fmt.Fprintf(buf, "\nN\nGW0,0,%d,%d,", raster.bytesPerRow, raster.height)
+			buf.Write(bytes.Join(raster.inverted(), nil))
+			buf.WriteString("\n")
+		},
+		caption: func(buf *bytes.Buffer, y int, text string) {
+			fmt.Fprintf(buf, "A0,%d,0,4,1,1,N,\"%s\"\n", y, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text))
+		},
+		print: "P1\n",
+	}
+
+	tsplLanguage = printerLanguage{
+		graphic: func(buf *bytes.Buffer, raster monochromeRaster) {
+			fmt.Fprintf(buf, "CODEPAGE UTF-8\r\nCLS\r\nBITMAP 0,0,%d,%d,0,", raster.bytesPerRow, raster.height)
+			buf.Write(bytes.Join(raster.inverted(), nil))
+			buf.WriteString("\r\n")
+		},
+		caption: func(buf *bytes.Buffer, y int, text string) {
+			fmt.Fprintf(buf, "TEXT 0,%d,\"3\",0,1,1,\"%s\"\r\n", y, strings.ReplaceAll(text, `"`, `\["]`))
+		},
+		print: "PRINT 1\r\n",
+	}
+)
+
+// ZPL renders the symbol as a ZPL II label for Zebra printers, at at most size dots. The code is
+// downloaded with ~DG as a graphic and recalled with ^XG. The captions are printed below the code
+// in the scalable font, with UTF-8 enabled by ^CI28.
+func (s *Symbol) ZPL(size int, captions []string) []byte {
+	return s.printerLabel(size, captions, zplLanguage)
+}
+
+// EPL renders the symbol as an EPL2 label for Eltron and older Zebra printers, at at most size
+// dots. The code is drawn with GW as a binary graphic and the captions are printed below it in
+// font 4. EPL2 has no UTF-8 code page, so captions must be printable ASCII.
+func (s *Symbol) EPL(size int, captions []string) ([]byte, error) {
+	for _, caption := range captions {
+		if err := checkPrintableASCII(caption, "EPL2"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.printerLabel(size, captions, eplLanguage), nil
+}
+
+// TSPL renders the symbol as a TSPL/TSPL2 label for TSC and compatible printers, at at most size
+// dots. The code is drawn with BITMAP as a binary graphic and the captions are printed below it
+// in font 3, with UTF-8 enabled by CODEPAGE. The label size configured in the printer is kept.
+func (s *Symbol) TSPL(size int, captions []string) []byte {
+	return s.printerLabel(size, captions, tsplLanguage)
+}
+
+// ESC/POS commands of receipt printers.
+const (
+	escposInitialize = "\x1b@"
+	escposCenter     = "\x1ba\x01"
+	escposFeedAndCut = "\x1bd\x03\x1dVB\x00"
+)
+
+// ESCPOSMaxModuleDots is the largest module size that GS ( k can print, in dots.
+const ESCPOSMaxModuleDots = 16
+
+// ESCPOS renders the symbol as ESC/POS commands for receipt printers, at at most size dots. The
+// code is printed centered as a GS v 0 raster image, which printers without QR code support
+// print too, followed by the captions, and the paper is fed and cut.
+func (s *Symbol) ESCPOS(size int, captions []string) ([]byte, error) {
+	raster := s.monochromeRaster(size)
+
+	var buf bytes.Buffer
+	buf.WriteString(escposInitialize + escposCenter)
+	fmt.Fprintf(&buf, "\x1dv0\x00%s%s", uint16LE(raster.bytesPerRow), uint16LE(raster.height))
+	buf.Write(bytes.Join(raster.rows, nil))
+
+	if err := writeESCPOSCaptions(&buf, captions); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ESCPOSQRCode renders text as ESC/POS commands that have the printer encode it with GS ( k as a
+// model 2 QR code of the given error correction level and module size in dots, from 1 to 16,
+// centered and followed by the captions. The printer chooses the version and mask pattern, so
+// the printed modules may differ from those of Encode, while encoding the same text.
+func ESCPOSQRCode(text string, level Level, moduleDots int, captions []string) ([]byte, error) {
+	if _, ok := recoveryLevels[level]; !ok {
+		return nil, fmt.Errorf("invalid error correction level %d", level)
+	}
+	if moduleDots < 1 || moduleDots > ESCPOSMaxModuleDots {
+		return nil, fmt.Errorf("module size must be between 1 and %d dots, got %d", ESCPOSMaxModuleDots, moduleDots)
+	}
+	if len(text)+3 > 0xffff {
+		return nil, fmt.Errorf("text of %d bytes is too long for GS ( k", len(text))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(escposInitialize + escposCenter)
+	buf.WriteString("\x1d(k\x04\x001A2\x00")
+	fmt.Fprintf(&buf, "\x1d(k\x03\x001C%c", byte(moduleDots))
+	fmt.Fprintf(&buf, "\x1d(k\x03\x001E%c", byte('0'+level))
+	fmt.Fprintf(&buf, "\x1d(k%s1P0%s", uint16LE(len(text)+3), text)
+	buf.WriteString("\x1d(k\x03\x001Q0")
+
+	if err := writeESCPOSCaptions(&buf, captions); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeESCPOSCaptions writes the captions below the code as lines of text, and feeds and cuts
+// the paper. Code pages differ between ESC/POS printers, so captions must be printable ASCII.
+func writeESCPOSCaptions(buf *bytes.Buffer, captions []string) error {
+	buf.WriteString("\n")
+	for _, caption := range captions {
+		if err := checkPrintableASCII(caption, "ESC/POS"); err != nil {
+			return err
+		}
+		buf.WriteString(caption + "\n")
+	}
+	buf.WriteString(escposFeedAndCut)
+
+	return nil
+}
+
+// checkPrintableASCII returns an error when a caption contains characters other than printable
+// ASCII, for printer languages without a UTF-8 code page.
+func checkPrintableASCII(caption, language string) error {
+	for _, r := range caption {
+		if r < ' ' || r > '~' {
+			return fmt.Errorf("caption %q contains %q, which %s cannot print: only printable ASCII is supported", caption, r, language)
+		}
+	}
+
+	return nil
+}
+
+// uint16LE returns n as the two little-endian bytes that ESC/POS takes lengths in.
+func uint16LE(n int) string {
+	return string([]byte{byte(n), byte(n >> 8)})
+}
+
+// zplFieldData escapes the characters that ZPL would read as command prefixes, and the ^FH
+// escape character itself, as hexadecimal escapes.
+func zplFieldData(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch r {
+		case '^', '~', '_':
+			fmt.Fprintf(&b, "_%02X", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package qrgen
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestSymbolMonochromeRaster verifies that modules are scaled by whole dots and packed most
+// significant bit first.
+func TestSymbolMonochromeRaster(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	modules := symbol.Modules()
+	raster := symbol.monochromeRaster(3*modules + 2)
+	if raster.width != 3*modules || raster.height != 3*modules {
+		t.Fatalf("expected a raster of %d dots, got %dx%d", 3*modules, raster.width, raster.height)
+	}
+	if raster.bytesPerRow != (3*modules+7)/8 || len(raster.rows) != raster.height {
+		t.Fatalf("unexpected raster layout: %d bytes per row, %d rows", raster.bytesPerRow, len(raster.rows))
+	}
+
+	bitmap := symbol.Bitmap()
+	for y := 0; y < raster.height; y++ {
+		for x := 0; x < raster.width; x++ {
+			dark := raster.rows[y][x/8]&(0x80>>(x%8)) != 0
+			if dark != bitmap[y/3][x/3] {
+				t.Fatalf("dot %d,%d does not match module %d,%d", x, y, x/3, y/3)
+			}
+		}
+	}
+
+	if tiny := symbol.monochromeRaster(1); tiny.width != modules {
+		t.Errorf("expected at least one dot per module, got a width of %d", tiny.width)
+	}
+}
+
+// TestSymbolZPL verifies the graphic download and the escaping of caption field data.
+func TestSymbolZPL(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	zpl := symbol.ZPL(testSize, []string{"Asset 42", "a^b~c_d"})
+
+	download := regexp.MustCompile(`^~DGR:QRCODE\.GRF,(\d+),(\d+),\n`).FindSubmatch(zpl)
+	if download == nil {
+		t.Fatalf("expected a ~DG graphic download, got %q", zpl[:min(len(zpl), 40)])
+	}
+	total, _ := strconv.Atoi(string(download[1]))
+	bytesPerRow, _ := strconv.Atoi(string(download[2]))
+	raster := symbol.monochromeRaster(testSize)
+	if total != raster.bytesPerRow*raster.height || bytesPerRow != raster.bytesPerRow {
+		t.Errorf("unexpected graphic size %d and row length %d", total, bytesPerRow)
+	}
+
+	lines := strings.Split(string(zpl), "\n")
+	for _, row := range lines[1 : 1+raster.height] {
+		if len(row) != 2*bytesPerRow {
+			t.Fatalf("expected rows of %d hex digits, got %q", 2*bytesPerRow, row)
+		}
+	}
+
+	for _, expected := range []string{
+		"^XA\n^CI28\n^FO0,0^XGR:QRCODE.GRF,1,1^FS\n",
+		"^FO0," + strconv.Itoa(raster.height+printerCaptionGap) + "^A0N,30,30^FH^FDAsset 42^FS\n",
+		"^FDa_5Eb_7Ec_5Fd^FS\n",
+	} {
+		if !bytes.Contains(zpl, []byte(expected)) {
+			t.Errorf("expected ZPL to contain %q", expected)
+		}
+	}
+	if !bytes.HasSuffix(zpl, []byte("^XZ\n")) {
+		t.Errorf("expected ZPL to end the label format")
+	}
+}
+
+// TestSymbolEPLAndTSPL verifies that EPL2 and TSPL labels carry the code as an inverted binary
+// graphic of the raster, and that their captions are escaped.
+func TestSymbolEPLAndTSPL(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	raster := symbol.monochromeRaster(testSize)
+	graphic := bytes.Join(raster.inverted(), nil)
+	if len(graphic) != raster.bytesPerRow*raster.height || graphic[0] != ^raster.rows[0][0] {
+		t.Fatalf("expected an inverted copy of the raster")
+	}
+
+	epl, err := symbol.EPL(testSize, []string{`say "hi" \o/`})
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	tspl := symbol.TSPL(testSize, []string{`say "hi"`, "Größe"})
+
+	testCases := map[string]struct {
+		label    []byte
+		expected []string
+	}{
+		"epl": {
+			label: epl,
+			expected: []string{
+				"\nN\nGW0,0," + strconv.Itoa(raster.bytesPerRow) + "," + strconv.Itoa(raster.height) + "," + string(graphic) + "\n",
+				"A0," + strconv.Itoa(raster.height+printerCaptionGap) + `,0,4,1,1,N,"say \"hi\" \\o/"` + "\n",
+				"P1\n",
+			},
+		},
+		"tspl": {
+			label: tspl,
+			expected: []string{
+				"CODEPAGE UTF-8\r\nCLS\r\nBITMAP 0,0," + strconv.Itoa(raster.bytesPerRow) + "," + strconv.Itoa(raster.height) + ",0," + string(graphic) + "\r\n",
+				`TEXT 0,` + strconv.Itoa(raster.captionY(0)) + `,"3",0,1,1,"say \["]hi\["]"` + "\r\n",
+				`TEXT 0,` + strconv.Itoa(raster.captionY(1)) + `,"3",0,1,1,"Größe"` + "\r\n",
+				"PRINT 1\r\n",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, expected := range testCase.expected {
+				if !bytes.Contains(testCase.label, []byte(expected)) {
+					t.Errorf("expected label to contain %q", expected)
+				}
+			}
+		})
+	}
+
+	if _, err := symbol.EPL(testSize, []string{"Größe"}); err == nil {
+		t.Errorf("expected an error for a caption that is not ASCII")
+	}
+}
+
+// TestSymbolESCPOS verifies the raster image and GS ( k commands of ESC/POS output.
+func TestSymbolESCPOS(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	raster := symbol.monochromeRaster(testSize)
+	escpos, err := symbol.ESCPOS(testSize, []string{"Pair me"})
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	header := []byte{0x1b, '@', 0x1b, 'a', 1, 0x1d, 'v', '0', 0, byte(raster.bytesPerRow), 0, byte(raster.height), 0}
+	if !bytes.HasPrefix(escpos, header) {
+		t.Fatalf("expected a GS v 0 raster header, got %q", escpos[:min(len(escpos), len(header))])
+	}
+	if !bytes.HasPrefix(escpos[len(header):], bytes.Join(raster.rows, nil)) {
+		t.Errorf("expected the raster to follow the header")
+	}
+	if !bytes.HasSuffix(escpos, []byte("\nPair me\n\x1bd\x03\x1dVB\x00")) {
+		t.Errorf("expected the caption followed by a feed and cut, got %q", escpos[len(escpos)-20:])
+	}
+
+	native, err := ESCPOSQRCode("https://example.com", Medium, 6, nil)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	for _, expected := range [][]byte{
+		{0x1d, '(', 'k', 4, 0, '1', 'A', '2', 0},
+		{0x1d, '(', 'k', 3, 0, '1', 'C', 6},
+		{0x1d, '(', 'k', 3, 0, '1', 'E', '1'},
+		append([]byte{0x1d, '(', 'k', 22, 0, '1', 'P', '0'}, "https://example.com"...),
+		{0x1d, '(', 'k', 3, 0, '1', 'Q', '0'},
+	} {
+		if !bytes.Contains(native, expected) {
+			t.Errorf("expected GS ( k output to contain %q", expected)
+		}
+	}
+
+	if _, err := ESCPOSQRCode("https://example.com", Medium, 17, nil); err == nil {
+		t.Errorf("expected an error for a module size above 16 dots")
+	}
+	if _, err := symbol.ESCPOS(testSize, []string{"Größe"}); err == nil {
+		t.Errorf("expected an error for a caption that is not ASCII")
+	}
+}
@@ -0,0 +1,130 @@
+package qrgen
+
+import (
+	"image"
+	"image/color"
+)
+
+// streamingSize is the largest size in pixels of PNG images that are drawn in memory before they
+// are encoded. Larger images, such as those printed on banners, are encoded from their raster a
+// row at a time, so that a 10000 pixel image does not hold 100 MB of pixels.
+const streamingSize = 2000
+
+// raster is a symbol rendered at a size in pixels, whose pixels are looked up from the modules
+// they fall on when they are read rather than held in memory. It implements image.PalettedImage,
+// so that image/png encodes it with the palette of the symbol.
+type raster struct {
+	rect    image.Rectangle
+	palette color.Palette
+
+	// indexes are the palette indexes of the modules, by row and column.
+	indexes [][]uint8
+
+	// size is the width of the symbol in pixels, drawn offset pixels from the edges of the image,
+	// and modules maps each pixel of that width to the module it falls on.
+	size    int
+	offset  int
+	modules []int
+
+	// quietZone is the palette index of the margin around the symbol.
+	quietZone uint8
+}
+
+// raster renders the symbol at the given size, with light modules at palette index 0, dark modules
+// at index 1, and any other colors in use, such as a distinct quiet zone color, after them.
+func (s *Symbol) raster(size int, colors Colors, scaling Scaling) *raster {
+	realSize := len(s.bitmap)
+	canvas, offset, modules := s.pixelModules(size, scaling)
+
+	palette := color.Palette{colors.Light, colors.Dark}
+	paletteIndex := func(c color.RGBA) uint8 {
+		for i, p := range palette {
+			if p == color.Color(c) {
+				return uint8(i)
+			}
+		}
+		palette = append(palette, c)
+		return uint8(len(palette) - 1)
+	}
+
+	// The palette indexes of all modules share one allocation
+	cells := make([]uint8, realSize*realSize)
+	indexes := make([][]uint8, realSize)
+	for y := range indexes {
+		indexes[y] = cells[y*realSize : (y+1)*realSize]
+		for x := range indexes[y] {
+			indexes[y][x] = paletteIndex(s.moduleColor(x, y, colors))
+		}
+	}
+	quietZone := paletteIndex(colors.quietZone())
+
+	return &raster{
+		rect:      image.Rect(0, 0, canvas, canvas),
+		palette:   palette,
+		indexes:   indexes,
+		size:      len(modules),
+		offset:    offset,
+		modules:   modules,
+		quietZone: quietZone,
+	}
+}
+
+// pixelModules returns the width of an image of the symbol at the given size, the offset of the
+// symbol from its edges, and the module that each pixel of the width of the symbol falls on.
+func (s *Symbol) pixelModules(size int, scaling Scaling) (canvas, offset int, modules []int) {
+	realSize := len(s.bitmap)
+
+	// Automatically increase the image size if it's not large enough
+	if size < realSize {
+		size = realSize
+	}
+
+	// Exact and fit scaling render the modules at whole pixels per module, the largest that fits
+	canvas = size
+	if scaling != ScalingFill {
+		size = size / realSize * realSize
+		if scaling == ScalingFit {
+			offset = (canvas - size) / 2
+		} else {
+			canvas = size
+		}
+	}
+
+	// Map each image pixel to the nearest QR code module. Whole pixels per module are mapped with
+	// integer arithmetic, so that rounding never shifts a module boundary.
+	modulesPerPixel := float64(realSize) / float64(size)
+	modules = make([]int, size)
+	for pixel := range modules {
+		if size%realSize == 0 {
+			modules[pixel] = pixel / (size / realSize)
+		} else {
+			modules[pixel] = int(float64(pixel) * modulesPerPixel)
+		}
+	}
+
+	return canvas, offset, modules
+}
+
+// ColorModel returns the palette of the raster.
+func (r *raster) ColorModel() color.Model {
+	return r.palette
+}
+
+// Bounds returns the bounds of the image.
+func (r *raster) Bounds() image.Rectangle {
+	return r.rect
+}
+
+// At returns the color of the pixel at x, y.
+func (r *raster) At(x, y int) color.Color {
+	return r.palette[r.ColorIndexAt(x, y)]
+}
+
+// ColorIndexAt returns the palette index of the pixel at x, y.
+func (r *raster) ColorIndexAt(x, y int) uint8 {
+	x, y = x-r.offset, y-r.offset
+	if x < 0 || y < 0 || x >= r.size || y >= r.size {
+		return r.quietZone
+	}
+	return r.indexes[r.modules[y]][r.modules[x]]
+}
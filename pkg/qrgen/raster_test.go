@@ -0,0 +1,34 @@
+package qrgen
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPNGStreamsLargeImages verifies that images larger than streamingSize, which are encoded from
+// their raster, are the same as images drawn in memory first.
+func TestPNGStreamsLargeImages(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	colors := Colors{Dark: DefaultColors.Dark, Light: DefaultColors.Light, QuietZone: DefaultColors.Dark}
+
+	for name, scaling := range map[string]Scaling{"fill": ScalingFill, "exact": ScalingExact, "fit": ScalingFit} {
+		t.Run(name, func(t *testing.T) {
+			size := streamingSize + 1
+			data, err := symbol.PNGWithOptions(size, colors, PNGOptions{Scaling: scaling})
+			if err != nil {
+				t.Fatalf("PNG failed: %v", err)
+			}
+
+			expected, err := EncodePNG(symbol.image(size, colors, scaling))
+			if err != nil {
+				t.Fatalf("EncodePNG failed: %v", err)
+			}
+			if !bytes.Equal(data, expected) {
+				t.Errorf("streamed image of %d pixels differs from the image drawn in memory", size)
+			}
+		})
+	}
+}
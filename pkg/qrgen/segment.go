@@ -0,0 +1,345 @@
+package qrgen
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/common/reedsolomon"
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+	"github.com/makiuchi-d/gozxing/qrcode/encoder"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// alphanumericCharset lists the characters of QR code alphanumeric mode, in code order.
+const alphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// segmentModes are the data modes considered when optimizing segments.
+var segmentModes = []*decoder.Mode{
+	decoder.Mode_BYTE,
+	decoder.Mode_ALPHANUMERIC,
+	decoder.Mode_NUMERIC,
+	decoder.Mode_KANJI,
+}
+
+// qrSegment is a run of text encoded in a single data mode.
+type qrSegment struct {
+	mode *decoder.Mode
+	text string
+}
+
+// ecLevels maps error correction levels to the error correction levels of the segment encoder.
+var ecLevels = map[Level]decoder.ErrorCorrectionLevel{
+	Low:     decoder.ErrorCorrectionLevel_L,
+	Medium:  decoder.ErrorCorrectionLevel_M,
+	High:    decoder.ErrorCorrectionLevel_Q,
+	Highest: decoder.ErrorCorrectionLevel_H,
+}
+
+// encodeOptimizedSymbol encodes text as a QR code symbol split into the numeric, alphanumeric,
+// byte and kanji segments that need the fewest bits, and so the smallest version.
+func encodeOptimizedSymbol(text string, level Level, byteCharset string) (*Symbol, error) {
+	ecLevel := ecLevels[level]
+
+	// Fail early on characters that the byte mode charset cannot represent
+	if _, err := transcodeBytes(text, byteCharset); err != nil {
+		return nil, err
+	}
+
+	var (
+		version  *decoder.Version
+		segments []qrSegment
+		dataBits *gozxing.BitArray
+	)
+
+	for number := 1; number <= maxVersion; number++ {
+		v, err := decoder.Version_GetVersionForNumber(number)
+		if err != nil {
+			return nil, err
+		}
+
+		// Character count field widths only change at versions 10 and 27
+		if number == 1 || number == 10 || number == 27 {
+			segments = optimalSegments(text, v, byteCharset)
+		}
+
+		bits := encodeSegments(segments, v, byteCharset)
+		if bits.GetSize() <= dataCodewords(v, ecLevel)*8 {
+			version = v
+			dataBits = bits
+			break
+		}
+	}
+
+	if version == nil {
+		return nil, fmt.Errorf("content too long to encode in a QR code")
+	}
+
+	symbol, err := symbolFromBits(dataBits, version, ecLevel, segmentModeNames(segments))
+	if err != nil {
+		return nil, err
+	}
+	symbol.capacityUsed = capacityUsed(func(v *decoder.Version) []qrSegment {
+		return optimalSegments(text, v, byteCharset)
+	}, level, byteCharset)
+
+	return symbol, nil
+}
+
+// symbolFromBits completes the data bits of a version with error correction and places them in a
+// symbol.
+func symbolFromBits(dataBits *gozxing.BitArray, version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel, mode string) (*Symbol, error) {
+	finalBits, err := interleaveCodewords(dataBits, version, ecLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix, err := buildMaskedMatrix(finalBits, version, ecLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return symbolFromMatrix(matrix, version.GetVersionNumber(), mode), nil
+}
+
+// optimalSegments splits text into the segments with the shortest encoding at the given version
+// and byte mode charset, using dynamic programming over the mode each character is encoded in. Costs are counted in
+// sixths of a bit, so that numeric (10 bits per 3 digits) and alphanumeric (11 bits per 2
+// characters) groups are exact.
+func optimalSegments(text string, version *decoder.Version, byteCharset string) []qrSegment {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return nil
+	}
+
+	numModes := len(segmentModes)
+	headCosts := make([]int, numModes)
+	for j, mode := range segmentModes {
+		headCosts[j] = (4 + mode.GetCharacterCountBits(version)) * 6
+	}
+
+	// charModes[i][j] is the mode of character i on the cheapest path ending in mode j, or -1
+	charModes := make([][]int, len(runes))
+	prevCosts := append([]int(nil), headCosts...)
+
+	for i, r := range runes {
+		curCosts := make([]int, numModes)
+		charModes[i] = make([]int, numModes)
+		for j, mode := range segmentModes {
+			curCosts[j] = math.MaxInt32
+			charModes[i][j] = -1
+			if cost, ok := segmentCharCost(mode, r, byteCharset); ok {
+				curCosts[j] = prevCosts[j] + cost
+				charModes[i][j] = j
+			}
+		}
+
+		// Start a new segment after this character to switch modes
+		for j := range segmentModes {
+			for k := range segmentModes {
+				if charModes[i][k] == -1 {
+					continue
+				}
+				newCost := (curCosts[k]+5)/6*6 + headCosts[j]
+				if charModes[i][j] == -1 || newCost < curCosts[j] {
+					curCosts[j] = newCost
+					charModes[i][j] = k
+				}
+			}
+		}
+
+		prevCosts = curCosts
+	}
+
+	// Trace back the mode of each character from the cheapest ending mode
+	current := 0
+	for j := range segmentModes {
+		if prevCosts[j] < prevCosts[current] {
+			current = j
+		}
+	}
+
+	modes := make([]int, len(runes))
+	for i := len(runes) - 1; i >= 0; i-- {
+		current = charModes[i][current]
+		modes[i] = current
+	}
+
+	var segments []qrSegment
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || modes[i] != modes[start] {
+			segments = append(segments, qrSegment{
+				mode: segmentModes[modes[start]],
+				text: string(runes[start:i]),
+			})
+			start = i
+		}
+	}
+
+	return segments
+}
+
+// segmentCharCost returns the cost of encoding r in mode, in sixths of a bit, and whether mode
+// can encode r at all.
+func segmentCharCost(mode *decoder.Mode, r rune, byteCharset string) (int, bool) {
+	switch mode {
+	case decoder.Mode_NUMERIC:
+		return 20, r >= '0' && r <= '9'
+	case decoder.Mode_ALPHANUMERIC:
+		return 33, r < utf8.RuneSelf && strings.ContainsRune(alphanumericCharset, r)
+	case decoder.Mode_KANJI:
+		return 78, isShiftJISKanji(string(r))
+	default:
+		encoded, err := transcodeBytes(string(r), byteCharset)
+		return len(encoded) * 8 * 6, err == nil
+	}
+}
+
+// encodeSegments appends the mode indicator, character count and data of every segment, with byte
+// mode data transcoded to byteCharset.
+func encodeSegments(segments []qrSegment, version *decoder.Version, byteCharset string) *gozxing.BitArray {
+	bits := gozxing.NewEmptyBitArray()
+
+	for _, segment := range segments {
+		_ = bits.AppendBits(segment.mode.GetBits(), 4)
+
+		switch segment.mode {
+		case decoder.Mode_NUMERIC:
+			_ = bits.AppendBits(len(segment.text), segment.mode.GetCharacterCountBits(version))
+			for i := 0; i < len(segment.text); i += 3 {
+				group := segment.text[i:min(i+3, len(segment.text))]
+				value := 0
+				for _, digit := range group {
+					value = value*10 + int(digit-'0')
+				}
+				_ = bits.AppendBits(value, len(group)*3+1)
+			}
+		case decoder.Mode_ALPHANUMERIC:
+			_ = bits.AppendBits(len(segment.text), segment.mode.GetCharacterCountBits(version))
+			for i := 0; i < len(segment.text); i += 2 {
+				value := strings.IndexByte(alphanumericCharset, segment.text[i])
+				if i+1 < len(segment.text) {
+					_ = bits.AppendBits(value*45+strings.IndexByte(alphanumericCharset, segment.text[i+1]), 11)
+				} else {
+					_ = bits.AppendBits(value, 6)
+				}
+			}
+		case decoder.Mode_KANJI:
+			// Segments only hold kanji mode characters, so the conversion cannot fail
+			encoded, _ := japanese.ShiftJIS.NewEncoder().String(segment.text)
+			_ = bits.AppendBits(len(encoded)/2, segment.mode.GetCharacterCountBits(version))
+			for i := 0; i < len(encoded); i += 2 {
+				code := int(encoded[i])<<8 | int(encoded[i+1])
+				if code <= 0x9ffc {
+					code -= 0x8140
+				} else {
+					code -= 0xc140
+				}
+				_ = bits.AppendBits((code>>8)*0xc0+(code&0xff), 13)
+			}
+		default:
+			// The text was checked to be representable up front
+			encoded, _ := transcodeBytes(segment.text, byteCharset)
+			_ = bits.AppendBits(len(encoded), segment.mode.GetCharacterCountBits(version))
+			for i := 0; i < len(encoded); i++ {
+				_ = bits.AppendBits(int(encoded[i]), 8)
+			}
+		}
+	}
+
+	return bits
+}
+
+// dataCodewords returns the number of data codewords of a version at an error correction level.
+func dataCodewords(version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) int {
+	return version.GetTotalCodewords() - version.GetECBlocksForLevel(ecLevel).GetTotalECCodewords()
+}
+
+// interleaveCodewords terminates and pads the data bits to the capacity of the version, computes
+// the Reed-Solomon error correction codewords of each block and interleaves the blocks.
+func interleaveCodewords(bits *gozxing.BitArray, version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) (*gozxing.BitArray, error) {
+	capacity := dataCodewords(version, ecLevel)
+
+	// Terminator, then zero bits up to a byte boundary, then alternating pad codewords
+	_ = bits.AppendBits(0, min(4, capacity*8-bits.GetSize()))
+	if remainder := bits.GetSize() % 8; remainder != 0 {
+		_ = bits.AppendBits(0, 8-remainder)
+	}
+	for pad := 0xec; bits.GetSizeInBytes() < capacity; pad ^= 0xec ^ 0x11 {
+		_ = bits.AppendBits(pad, 8)
+	}
+
+	data := make([]byte, capacity)
+	bits.ToBytes(0, data, 0, capacity)
+
+	ecBlocks := version.GetECBlocksForLevel(ecLevel)
+	numECCodewords := ecBlocks.GetECCodewordsPerBlock()
+	rs := reedsolomon.NewReedSolomonEncoder(reedsolomon.GenericGF_QR_CODE_FIELD_256)
+
+	var dataBlocks, ecBlocksData [][]int
+	offset := 0
+	for _, group := range ecBlocks.GetECBlocks() {
+		for i := 0; i < group.GetCount(); i++ {
+			block := make([]int, group.GetDataCodewords()+numECCodewords)
+			for j := 0; j < group.GetDataCodewords(); j++ {
+				block[j] = int(data[offset+j])
+			}
+			offset += group.GetDataCodewords()
+
+			if err := rs.Encode(block, numECCodewords); err != nil {
+				return nil, err
+			}
+
+			dataBlocks = append(dataBlocks, block[:group.GetDataCodewords()])
+			ecBlocksData = append(ecBlocksData, block[group.GetDataCodewords():])
+		}
+	}
+
+	result := gozxing.NewEmptyBitArray()
+	for _, blocks := range [][][]int{dataBlocks, ecBlocksData} {
+		longest := 0
+		for _, block := range blocks {
+			longest = max(longest, len(block))
+		}
+		for i := 0; i < longest; i++ {
+			for _, block := range blocks {
+				if i < len(block) {
+					_ = result.AppendBits(block[i], 8)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// buildMaskedMatrix places the codewords in a matrix with the mask pattern of lowest penalty.
+func buildMaskedMatrix(bits *gozxing.BitArray, version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) (*encoder.ByteMatrix, error) {
+	dimension := version.GetDimensionForVersion()
+	bestPattern, bestPenalty := 0, math.MaxInt32
+
+	for pattern := 0; pattern < encoder.QRCode_NUM_MASK_PATERNS; pattern++ {
+		matrix := encoder.NewByteMatrix(dimension, dimension)
+		if err := encoder.MatrixUtil_buildMatrix(bits, ecLevel, version, pattern, matrix); err != nil {
+			return nil, err
+		}
+
+		penalty := encoder.MaskUtil_applyMaskPenaltyRule1(matrix) +
+			encoder.MaskUtil_applyMaskPenaltyRule2(matrix) +
+			encoder.MaskUtil_applyMaskPenaltyRule3(matrix) +
+			encoder.MaskUtil_applyMaskPenaltyRule4(matrix)
+		if penalty < bestPenalty {
+			bestPattern, bestPenalty = pattern, penalty
+		}
+	}
+
+	matrix := encoder.NewByteMatrix(dimension, dimension)
+	if err := encoder.MatrixUtil_buildMatrix(bits, ecLevel, version, bestPattern, matrix); err != nil {
+		return nil, err
+	}
+
+	return matrix, nil
+}
@@ -0,0 +1,82 @@
+package qrgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+	"github.com/skip2/go-qrcode"
+)
+
+// TestEncodeOptimizedSymbol verifies that optimized symbols decode to their content and are never larger than go-qrcode's.
+func TestEncodeOptimizedSymbol(t *testing.T) {
+	texts := []string{
+		"qrcode",
+		"0123456789012345678901234567890123456789",
+		"HTTPS://EXAMPLE.COM/ORDER/12345678901234567890",
+		"https://example.com/order/12345678901234567890?ref=ABCDEFGHIJ",
+		"東京都千代田区丸の内一丁目",
+		"東京タワー 333m, open 09:00-23:00",
+		strings.Repeat("Label 0042 ", 40),
+	}
+
+	for _, text := range texts {
+		for _, level := range []Level{Low, Medium, High, Highest} {
+			symbol, err := encodeOptimizedSymbol(text, level, "")
+			if err != nil {
+				t.Fatalf("%q: failed to encode: %s", text, err)
+			}
+
+			qr, err := qrcode.New(text, recoveryLevels[level])
+			if err != nil {
+				t.Fatalf("%q: failed to encode: %s", text, err)
+			}
+			if symbol.version > qr.VersionNumber {
+				t.Errorf("%q level %d: expected at most version %d, got %d", text, level, qr.VersionNumber, symbol.version)
+			}
+
+			pngData, err := symbol.PNG(4*len(symbol.bitmap), DefaultColors)
+			if err != nil {
+				t.Fatalf("%q: failed to render: %s", text, err)
+			}
+			decoded, err := decodeTestImage(pngData)
+			if err != nil {
+				t.Fatalf("%q level %d (%s): failed to decode: %s", text, level, symbol.mode, err)
+			}
+			if decoded != text {
+				t.Errorf("%q level %d (%s): decoded %q", text, level, symbol.mode, decoded)
+			}
+		}
+	}
+}
+
+// TestOptimalSegments verifies that text is split where a mode switch saves bits.
+func TestOptimalSegments(t *testing.T) {
+	version, err := decoder.Version_GetVersionForNumber(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string][]string{
+		"0123456789":                {"NUMERIC"},
+		"ABC":                       {"ALPHANUMERIC"},
+		"abc":                       {"BYTE"},
+		"abc0123456789012345":       {"BYTE", "NUMERIC"},
+		"ORDER-0123456789012345678": {"ALPHANUMERIC", "NUMERIC"},
+		"a1":                        {"BYTE"},
+		"日本語":                       {"KANJI"},
+	}
+
+	for text, expected := range testCases {
+		segments := optimalSegments(text, version, "")
+
+		var modes []string
+		for _, segment := range segments {
+			modes = append(modes, segment.mode.String())
+		}
+
+		if strings.Join(modes, ",") != strings.Join(expected, ",") {
+			t.Errorf("%q: expected segments %v, got %v", text, expected, modes)
+		}
+	}
+}
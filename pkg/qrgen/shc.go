@@ -0,0 +1,64 @@
+package qrgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SHCPrefix starts the text of SMART Health Card QR codes, encoded in byte mode ahead of the
+// numeric mode digits.
+const SHCPrefix = "shc:/"
+
+// shcOffset is subtracted from every character of a SMART Health Card JWS, so that the characters
+// of compact JWS, from '-' to 'z', become two digits each.
+const shcOffset = '-'
+
+// EncodeSHC encodes a compact JWS as the text of a single-chunk SMART Health Card QR code: the
+// shc:/ prefix followed by two digits per character of the JWS, so that it encodes in numeric
+// mode. It fails on characters that cannot appear in a compact JWS.
+func EncodeSHC(jws string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(SHCPrefix) + 2*len(jws))
+	b.WriteString(SHCPrefix)
+
+	for i := 0; i < len(jws); i++ {
+		c := jws[i]
+		if c < shcOffset || c > 'z' {
+			return "", fmt.Errorf("invalid SMART Health Card JWS character %q at offset %d", c, i)
+		}
+		fmt.Fprintf(&b, "%02d", c-shcOffset)
+	}
+
+	return b.String(), nil
+}
+
+// DecodeSHC decodes the text of a single-chunk SMART Health Card QR code to its JWS. It fails on
+// text without the shc:/ prefix, on chunked text and on digit pairs that do not encode JWS
+// characters.
+func DecodeSHC(text string) (string, error) {
+	digits, ok := strings.CutPrefix(text, SHCPrefix)
+	if !ok {
+		return "", fmt.Errorf("SMART Health Card text does not start with %s", SHCPrefix)
+	}
+	if strings.Contains(digits, "/") {
+		return "", fmt.Errorf("chunked SMART Health Card text is not supported")
+	}
+	if len(digits)%2 != 0 {
+		return "", fmt.Errorf("invalid SMART Health Card length %d", len(digits))
+	}
+
+	var b strings.Builder
+	b.Grow(len(digits) / 2)
+	for i := 0; i < len(digits); i += 2 {
+		if digits[i] < '0' || digits[i] > '9' || digits[i+1] < '0' || digits[i+1] > '9' {
+			return "", fmt.Errorf("invalid SMART Health Card digits %q at offset %d", digits[i:i+2], len(SHCPrefix)+i)
+		}
+		n := int(digits[i]-'0')*10 + int(digits[i+1]-'0')
+		if n > 'z'-shcOffset {
+			return "", fmt.Errorf("invalid SMART Health Card digits %q at offset %d", digits[i:i+2], len(SHCPrefix)+i)
+		}
+		b.WriteByte(byte(n + shcOffset))
+	}
+
+	return b.String(), nil
+}
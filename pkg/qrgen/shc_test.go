@@ -0,0 +1,51 @@
+package qrgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSHC verifies SMART Health Card encoding and decoding against the example of the
+// specification, that the digits encode in numeric mode and that invalid text is rejected.
+func TestSHC(t *testing.T) {
+	testCases := map[string]string{
+		"":            "shc:/",
+		"eyJ":         "shc:/567629",
+		"a.b-c_z":     "shc:/52015300545077",
+		"eyJ6aXAiOiJ": "shc:/5676290952432060346029",
+	}
+
+	for jws, text := range testCases {
+		encoded, err := EncodeSHC(jws)
+		if err != nil {
+			t.Fatalf("%q: failed to encode: %s", jws, err)
+		}
+		if encoded != text {
+			t.Errorf("%q: expected %q, got %q", jws, text, encoded)
+		}
+		decoded, err := DecodeSHC(text)
+		if err != nil {
+			t.Fatalf("%q: failed to decode: %s", text, err)
+		}
+		if decoded != jws {
+			t.Errorf("%q: expected %q, got %q", text, jws, decoded)
+		}
+	}
+
+	if _, err := EncodeSHC("a b"); err == nil {
+		t.Errorf("expected an error for a character outside compact JWS")
+	}
+	for _, text := range []string{"567629", "shc:/5676290", "shc:/78", "shc:/5a", "shc:/2/1/5676"} {
+		if _, err := DecodeSHC(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+
+	symbol, err := Encode("shc:/"+strings.Repeat("5676290952432060346029", 20), Options{Level: Low})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if symbol.Mode() != "byte+numeric" {
+		t.Errorf("expected byte+numeric segments, got %s", symbol.Mode())
+	}
+}
@@ -0,0 +1,103 @@
+package qrgen
+
+import (
+	"fmt"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+)
+
+// MaxStructuredAppendSymbols is the largest number of symbols in a structured append series.
+const MaxStructuredAppendSymbols = 16
+
+// structuredAppendHeaderBits is the length of the structured append header of every symbol in a
+// series: the mode indicator, the symbol position, the last position and the parity.
+const structuredAppendHeaderBits = 4 + 4 + 4 + 8
+
+// EncodeStructuredAppend splits data into a structured append series of byte mode symbols of at
+// most largestVersion, which scanners that support structured append reassemble into data. The
+// data is split evenly, so that the symbols are about the same size, and must fit in
+// MaxStructuredAppendSymbols symbols.
+func EncodeStructuredAppend(data []byte, level Level, largestVersion int) ([]*Symbol, error) {
+	ecLevel, ok := ecLevels[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid error correction level %d", level)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no data to encode")
+	}
+
+	largest, err := decoder.Version_GetVersionForNumber(largestVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	perSymbol := structuredAppendCapacity(largest, ecLevel)
+	count := (len(data) + perSymbol - 1) / perSymbol
+	if count > MaxStructuredAppendSymbols {
+		return nil, fmt.Errorf("%d bytes need %d symbols of version %d, more than the %d of a structured append series", len(data), count, largestVersion, MaxStructuredAppendSymbols)
+	}
+	chunkSize := (len(data) + count - 1) / count
+
+	// Version numbers from 1 to 40 never fail
+	version40, _ := decoder.Version_GetVersionForNumber(maxVersion)
+
+	parity := StructuredAppendParity(data)
+	symbols := make([]*Symbol, 0, count)
+
+	for position := 0; position < count; position++ {
+		chunk := data[position*chunkSize : min((position+1)*chunkSize, len(data))]
+
+		// Chunks are never larger than a symbol of the largest version holds
+		version := largest
+		for number := 1; number < largestVersion; number++ {
+			v, err := decoder.Version_GetVersionForNumber(number)
+			if err != nil {
+				return nil, err
+			}
+			if structuredAppendCapacity(v, ecLevel) >= len(chunk) {
+				version = v
+				break
+			}
+		}
+
+		bits := gozxing.NewEmptyBitArray()
+		_ = bits.AppendBits(decoder.Mode_STRUCTURED_APPEND.GetBits(), 4)
+		_ = bits.AppendBits(position, 4)
+		_ = bits.AppendBits(count-1, 4)
+		_ = bits.AppendBits(int(parity), 8)
+		bits.AppendBitArray(encodeSegments([]qrSegment{{mode: decoder.Mode_BYTE, text: string(chunk)}}, version, ""))
+
+		// The share of the largest version is taken before the bits are padded
+		used := 100 * float64(bits.GetSize()) / float64(dataCodewords(version40, ecLevel)*8)
+
+		symbol, err := symbolFromBits(bits, version, ecLevel, "structured_append+byte")
+		if err != nil {
+			return nil, err
+		}
+		symbol.capacityUsed = used
+
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols, nil
+}
+
+// StructuredAppendParity returns the parity of a structured append series, the exclusive or of
+// every byte of its data, which scanners use to check that symbols belong to the same series.
+func StructuredAppendParity(data []byte) byte {
+	var parity byte
+	for _, b := range data {
+		parity ^= b
+	}
+
+	return parity
+}
+
+// structuredAppendCapacity returns the number of bytes a structured append symbol of a version
+// holds at an error correction level.
+func structuredAppendCapacity(version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) int {
+	bits := dataCodewords(version, ecLevel)*8 - structuredAppendHeaderBits - 4 - decoder.Mode_BYTE.GetCharacterCountBits(version)
+
+	return bits / 8
+}
@@ -0,0 +1,76 @@
+package qrgen
+
+import (
+	"bytes"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqrcode "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// TestEncodeStructuredAppend verifies that a series decodes to the data, with every symbol
+// carrying its position, the symbol count and the parity of the data.
+func TestEncodeStructuredAppend(t *testing.T) {
+	data := make([]byte, 1500)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	symbols, err := EncodeStructuredAppend(data, Medium, 10)
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if len(symbols) != 8 {
+		t.Fatalf("expected 8 symbols, got %d", len(symbols))
+	}
+
+	parity := StructuredAppendParity(data)
+	var decoded []byte
+
+	for position, symbol := range symbols {
+		if symbol.Version() > 10 {
+			t.Errorf("symbol %d: expected at most version 10, got %d", position, symbol.Version())
+		}
+
+		pngData, err := symbol.PNG(4*symbol.Modules(), DefaultColors)
+		if err != nil {
+			t.Fatalf("symbol %d: failed to render: %s", position, err)
+		}
+		img, err := png.Decode(bytes.NewReader(pngData))
+		if err != nil {
+			t.Fatalf("symbol %d: failed to decode PNG: %s", position, err)
+		}
+		bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+		if err != nil {
+			t.Fatalf("symbol %d: failed to binarize: %s", position, err)
+		}
+		result, err := zxingqrcode.NewQRCodeReader().Decode(bitmap, nil)
+		if err != nil {
+			t.Fatalf("symbol %d: failed to decode: %s", position, err)
+		}
+
+		metadata := result.GetResultMetadata()
+		if sequence := metadata[gozxing.ResultMetadataType_STRUCTURED_APPEND_SEQUENCE]; sequence != position<<4|(len(symbols)-1) {
+			t.Errorf("symbol %d: unexpected sequence %v", position, sequence)
+		}
+		if got := metadata[gozxing.ResultMetadataType_STRUCTURED_APPEND_PARITY]; got != int(parity) {
+			t.Errorf("symbol %d: expected parity %d, got %v", position, parity, got)
+		}
+
+		segments, _ := metadata[gozxing.ResultMetadataType_BYTE_SEGMENTS].([][]byte)
+		for _, segment := range segments {
+			decoded = append(decoded, segment...)
+		}
+	}
+
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("expected the series to decode to the data")
+	}
+
+	if _, err := EncodeStructuredAppend(make([]byte, 20000), Medium, 10); err == nil {
+		t.Errorf("expected data larger than 16 symbols to fail")
+	}
+	if _, err := EncodeStructuredAppend(nil, Medium, 10); err == nil {
+		t.Errorf("expected empty data to fail")
+	}
+}
@@ -0,0 +1,137 @@
+package qrgen
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// DefaultAltText is the SVG title used when no alt text is given. The encoded content is never
+// used, as it may be sensitive.
+const DefaultAltText = "QR code"
+
+// SVG renders the symbol as an SVG image of the given size in pixels. With optimize set, the dark
+// modules are drawn as a single path of merged rectangles, otherwise as one square per module.
+// The image has the img role and is labelled by a title and description, so that it is
+// accessible when embedded in documents.
+func (s *Symbol) SVG(size int, colors Colors, altText string, optimize bool) []byte {
+	if altText == "" {
+		altText = DefaultAltText
+	}
+
+	modules := len(s.bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges" role="img" aria-labelledby="qrcode-title qrcode-desc">`+"\n", size, size, modules, modules)
+
+	buf.WriteString(`<title id="qrcode-title">`)
+	_ = xml.EscapeText(&buf, []byte(altText))
+	buf.WriteString("</title>\n")
+	fmt.Fprintf(&buf, `<desc id="qrcode-desc">QR code, version %d, %d by %d modules</desc>`+"\n", s.version, modules, modules)
+
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`+"\n", modules, modules, HexColor(colors.quietZone()))
+	if colors.distinctQuietZone() {
+		border := s.quietZone()
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", border, border, modules-2*border, modules-2*border, HexColor(colors.Light))
+	}
+
+	if optimize {
+		writeSVGPath(&buf, colors.Dark, s.darkRects())
+
+		// Eyes in their own colors are drawn over the dark modules
+		for corner, eye := range s.eyes() {
+			if colors.eye(corner) != colors.Dark {
+				writeSVGPath(&buf, colors.eye(corner), s.darkRectsIn(eye))
+			}
+		}
+		buf.WriteString("</svg>\n")
+
+		return buf.Bytes()
+	}
+
+	for y, row := range s.bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="1" height="1" fill="%s"/>`+"\n", x, y, HexColor(s.moduleColor(x, y, colors)))
+			}
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes()
+}
+
+// writeSVGPath writes a path filling rects in the given color.
+func writeSVGPath(buf *bytes.Buffer, fill color.RGBA, rects []image.Rectangle) {
+	fmt.Fprintf(buf, `<path fill="%s" d="`, HexColor(fill))
+	for _, rect := range rects {
+		fmt.Fprintf(buf, "M%d %dh%dv%dh-%dz", rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), rect.Dx())
+	}
+	buf.WriteString(`"/>` + "\n")
+}
+
+// darkRects covers the dark modules with rectangles, merging horizontal runs of dark modules and
+// then identical runs on consecutive rows, in row order.
+func (s *Symbol) darkRects() []image.Rectangle {
+	return s.darkRectsIn(image.Rect(0, 0, len(s.bitmap), len(s.bitmap)))
+}
+
+// darkRectsIn covers the dark modules within bounds with rectangles, as darkRects does.
+func (s *Symbol) darkRectsIn(bounds image.Rectangle) []image.Rectangle {
+	var done []image.Rectangle
+
+	// open holds the rectangles that end on the previous row, keyed by their horizontal extent
+	open := map[[2]int]image.Rectangle{}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := s.bitmap[y]
+		next := map[[2]int]image.Rectangle{}
+
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !row[x] {
+				continue
+			}
+
+			start := x
+			for x < bounds.Max.X && row[x] {
+				x++
+			}
+
+			key := [2]int{start, x}
+			if rect, ok := open[key]; ok {
+				rect.Max.Y = y + 1
+				next[key] = rect
+				delete(open, key)
+			} else {
+				next[key] = image.Rect(start, y, x, y+1)
+			}
+		}
+
+		done = append(done, sortedRects(open)...)
+		open = next
+	}
+
+	return append(done, sortedRects(open)...)
+}
+
+// sortedRects returns the rectangles of rects ordered by their top left corner, so that the
+// output is deterministic.
+func sortedRects(rects map[[2]int]image.Rectangle) []image.Rectangle {
+	sorted := make([]image.Rectangle, 0, len(rects))
+	for _, rect := range rects {
+		sorted = append(sorted, rect)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Min.Y != sorted[j].Min.Y {
+			return sorted[i].Min.Y < sorted[j].Min.Y
+		}
+		return sorted[i].Min.X < sorted[j].Min.X
+	})
+
+	return sorted
+}
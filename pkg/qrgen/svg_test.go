@@ -0,0 +1,79 @@
+package qrgen
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// TestSymbolSVG verifies that SVG output is accessible and never includes the encoded content.
+func TestSymbolSVG(t *testing.T) {
+	text := "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP"
+
+	symbol, err := Encode(text, Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	testCases := map[string]struct {
+		altText  string
+		expected string
+	}{
+		"default":  {altText: "", expected: `<title id="qrcode-title">QR code</title>`},
+		"escaped":  {altText: "MFA <admin> & ops", expected: `<title id="qrcode-title">MFA &lt;admin&gt; &amp; ops</title>`},
+		"alt text": {altText: "Guest WiFi login", expected: `<title id="qrcode-title">Guest WiFi login</title>`},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			svg := string(symbol.SVG(testSize, DefaultColors, testCase.altText, false))
+
+			if err := xml.Unmarshal([]byte(svg), new(struct{})); err != nil {
+				t.Fatalf("invalid SVG: %s", err)
+			}
+			for _, expected := range []string{testCase.expected, `role="img"`, `aria-labelledby="qrcode-title qrcode-desc"`, `<desc id="qrcode-desc">`} {
+				if !strings.Contains(svg, expected) {
+					t.Errorf("expected SVG to contain %s", expected)
+				}
+			}
+			if strings.Contains(svg, "JBSWY3DPEHPK3PXP") {
+				t.Errorf("SVG must not contain the encoded content")
+			}
+		})
+	}
+}
+
+// TestSymbolSVGOptimize verifies that the merged path covers exactly the dark modules and is smaller.
+func TestSymbolSVGOptimize(t *testing.T) {
+	symbol, err := Encode(strings.Repeat("https://example.com/", 50), Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	covered := make([][]int, len(symbol.bitmap))
+	for y := range covered {
+		covered[y] = make([]int, len(symbol.bitmap))
+	}
+	for _, rect := range symbol.darkRects() {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				covered[y][x]++
+			}
+		}
+	}
+	for y, row := range symbol.bitmap {
+		for x, dark := range row {
+			if expected := map[bool]int{true: 1, false: 0}[dark]; covered[y][x] != expected {
+				t.Fatalf("module (%d, %d): expected to be covered %d times, got %d", x, y, expected, covered[y][x])
+			}
+		}
+	}
+
+	optimized := symbol.SVG(testSize, DefaultColors, "", true)
+	if err := xml.Unmarshal(optimized, new(struct{})); err != nil {
+		t.Fatalf("invalid SVG: %s", err)
+	}
+	if unoptimized := symbol.SVG(testSize, DefaultColors, "", false); len(optimized)*4 > len(unoptimized) {
+		t.Errorf("expected the optimized SVG (%d bytes) to be at least 4 times smaller than %d bytes", len(optimized), len(unoptimized))
+	}
+}
@@ -0,0 +1,564 @@
+package qrgen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+	"github.com/makiuchi-d/gozxing/qrcode/encoder"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// QuietZoneModules is the width of the border around a QR code symbol, in modules.
+const QuietZoneModules = 4
+
+// Level is the error correction level of a QR code symbol.
+type Level int
+
+// Error correction levels, from the smallest symbols to the most damage tolerant.
+const (
+	// Low recovers from 7% data loss.
+	Low Level = iota
+
+	// Medium recovers from 15% data loss.
+	Medium
+
+	// High recovers from 25% data loss.
+	High
+
+	// Highest recovers from 30% data loss.
+	Highest
+)
+
+// recoveryLevels maps error correction levels to go-qrcode recovery levels.
+var recoveryLevels = map[Level]qrcode.RecoveryLevel{
+	Low:     qrcode.Low,
+	Medium:  qrcode.Medium,
+	High:    qrcode.High,
+	Highest: qrcode.Highest,
+}
+
+// Symbol is an encoded QR code symbol, independent of the encoder that produced it.
+type Symbol struct {
+	// bitmap holds the dark modules of the symbol, including the quiet zone.
+	bitmap [][]bool
+
+	// version is the QR code version, from 1 to 40.
+	version int
+
+	// mode describes the data encoding used.
+	mode string
+
+	// level is the error correction level the symbol was encoded at.
+	level Level
+
+	// capacityUsed is the share of the data capacity of the largest version that the data
+	// takes, in percent.
+	capacityUsed float64
+
+	// turns is the number of quarter turns clockwise that the bitmap is rotated by.
+	turns int
+}
+
+// Character sets that byte mode data can be transcoded to.
+const (
+	ByteCharsetUTF8     = "UTF-8"
+	ByteCharsetShiftJIS = "Shift_JIS"
+	ByteCharsetLatin1   = "ISO-8859-1"
+)
+
+// Options control how text is encoded as a QR code symbol. The zero value encodes at the Low
+// error correction level.
+type Options struct {
+	// Level is the error correction level.
+	Level Level
+
+	// Optimize splits the text into the numeric, alphanumeric, byte and kanji mode segments with
+	// the shortest encoding. Kanji mode takes 13 bits per double-byte Shift_JIS character instead
+	// of the 24 bits of UTF-8 in byte mode.
+	Optimize bool
+
+	// ByteCharset is the character set byte mode data is transcoded to, without an ECI header,
+	// for legacy scanners that assume it. Empty means UTF-8.
+	ByteCharset string
+
+	// Reproducible encodes with this package's own encoder, as Optimize does, rather than with
+	// go-qrcode, so that the segments and the mask pattern of the symbol do not depend on the
+	// version of go-qrcode. The tests of this package pin reproducible symbols by checksum.
+	Reproducible bool
+}
+
+// Encode encodes text as a QR code symbol with a quiet zone of QuietZoneModules.
+func Encode(text string, opts Options) (*Symbol, error) {
+	if _, ok := recoveryLevels[opts.Level]; !ok {
+		return nil, fmt.Errorf("invalid error correction level %d", opts.Level)
+	}
+
+	// go-qrcode refuses empty text, which this package's own encoder encodes without any segment
+	if opts.Optimize || opts.Reproducible || text == "" {
+		symbol, err := encodeOptimizedSymbol(text, opts.Level, opts.ByteCharset)
+		if err != nil {
+			return nil, err
+		}
+		symbol.level = opts.Level
+		return symbol, nil
+	}
+
+	// go-qrcode encodes the bytes of the string as is
+	encoded, err := transcodeBytes(text, opts.ByteCharset)
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err := qrcode.New(encoded, recoveryLevels[opts.Level])
+	if err != nil {
+		return nil, err
+	}
+
+	// go-qrcode does not expose its segments, so split the data again the same way
+	version, err := decoder.Version_GetVersionForNumber(qr.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Symbol{
+		bitmap:  qr.Bitmap(),
+		version: qr.VersionNumber,
+		mode:    segmentModeNames(autoSegments(encoded, version)),
+		level:   opts.Level,
+		capacityUsed: capacityUsed(func(v *decoder.Version) []qrSegment {
+			return autoSegments(encoded, v)
+		}, opts.Level, ""),
+	}, nil
+}
+
+// EncodeMaxLevel encodes text at the highest error correction level that still fits the version
+// that opts.Level needs, so that the symbol gains damage tolerance without growing. It returns the
+// level used, which is opts.Level when no higher level fits.
+func EncodeMaxLevel(text string, opts Options) (*Symbol, Level, error) {
+	symbol, err := Encode(text, opts)
+	if err != nil {
+		return nil, opts.Level, err
+	}
+
+	for level := Highest; level > opts.Level; level-- {
+		higher := opts
+		higher.Level = level
+		candidate, err := Encode(text, higher)
+		if err == nil && candidate.Version() <= symbol.Version() {
+			return candidate, level, nil
+		}
+	}
+
+	return symbol, opts.Level, nil
+}
+
+// Version returns the QR code version of the symbol, from 1 to 40.
+func (s *Symbol) Version() int {
+	return s.version
+}
+
+// Level returns the error correction level the symbol was encoded at.
+func (s *Symbol) Level() Level {
+	return s.level
+}
+
+// Mode describes the data modes of the symbol's segments in order, such as "byte" or
+// "alphanumeric+numeric".
+func (s *Symbol) Mode() string {
+	return s.mode
+}
+
+// CapacityUsedPercent returns the share of the data capacity of a version 40 symbol, the largest,
+// at the same error correction level that the data takes, in percent. Encoding fails once it
+// exceeds 100.
+func (s *Symbol) CapacityUsedPercent() float64 {
+	return s.capacityUsed
+}
+
+// Modules returns the width of the symbol in modules, including the quiet zone.
+func (s *Symbol) Modules() int {
+	return len(s.bitmap)
+}
+
+// SymbolModules returns the width of the symbol in modules, without the quiet zone.
+func (s *Symbol) SymbolModules() int {
+	return 17 + 4*s.version
+}
+
+// Bitmap returns a copy of the modules of the symbol, including the quiet zone, indexed by row
+// and then column. True is a dark module.
+func (s *Symbol) Bitmap() [][]bool {
+	bitmap := make([][]bool, len(s.bitmap))
+	for y, row := range s.bitmap {
+		bitmap[y] = append([]bool(nil), row...)
+	}
+
+	return bitmap
+}
+
+// ContentSHA256 returns the hex SHA-256 checksum of the modules of the symbol without the quiet
+// zone, written as a line of 1 for dark and 0 for light modules per row, each ending in a newline.
+// It changes with the encoded data but not with the quiet zone, colors, size or image format.
+func (s *Symbol) ContentSHA256() string {
+	border := s.quietZone()
+
+	var buf bytes.Buffer
+	for _, row := range s.bitmap[border : len(s.bitmap)-border] {
+		for _, dark := range row[border : len(row)-border] {
+			if dark {
+				buf.WriteByte('1')
+			} else {
+				buf.WriteByte('0')
+			}
+		}
+		buf.WriteByte('\n')
+	}
+
+	hash := sha256.Sum256(buf.Bytes())
+
+	return hex.EncodeToString(hash[:])
+}
+
+// transcodeBytes converts text to the bytes of charset, returned as a string. It fails when text
+// contains characters that charset cannot represent.
+func transcodeBytes(text, charset string) (string, error) {
+	var enc *encoding.Encoder
+	switch charset {
+	case ByteCharsetShiftJIS:
+		enc = japanese.ShiftJIS.NewEncoder()
+	case ByteCharsetLatin1:
+		enc = charmap.ISO8859_1.NewEncoder()
+	default:
+		return text, nil
+	}
+
+	encoded, err := enc.String(text)
+	if err != nil {
+		return "", fmt.Errorf("text cannot be represented in %s: %w", charset, err)
+	}
+
+	return encoded, nil
+}
+
+// isShiftJISKanji reports whether text is non-empty and every character is a double-byte
+// Shift_JIS character that QR code kanji mode can encode.
+func isShiftJISKanji(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(text)
+	if err != nil || len(encoded) != 2*len([]rune(text)) {
+		return false
+	}
+
+	for i := 0; i < len(encoded); i += 2 {
+		b := encoded[i]
+		if (b < 0x81 || b > 0x9f) && (b < 0xe0 || b > 0xeb) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// symbolFromMatrix converts an encoded matrix into a symbol, adding the quiet zone.
+func symbolFromMatrix(matrix *encoder.ByteMatrix, version int, mode string) *Symbol {
+	size := matrix.GetWidth() + 2*QuietZoneModules
+	bitmap := make([][]bool, size)
+	for y := range bitmap {
+		bitmap[y] = make([]bool, size)
+	}
+	for y := 0; y < matrix.GetHeight(); y++ {
+		for x := 0; x < matrix.GetWidth(); x++ {
+			bitmap[y+QuietZoneModules][x+QuietZoneModules] = matrix.Get(x, y) == 1
+		}
+	}
+
+	return &Symbol{
+		bitmap:  bitmap,
+		version: version,
+		mode:    mode,
+	}
+}
+
+// WithQuietZone returns the symbol with a quiet zone of the given width in modules, replacing its
+// current quiet zone.
+func (s *Symbol) WithQuietZone(modules int) *Symbol {
+	shift := modules - s.quietZone()
+	if shift == 0 {
+		return s
+	}
+
+	size := len(s.bitmap) + 2*shift
+	bitmap := make([][]bool, size)
+	for y := range bitmap {
+		bitmap[y] = make([]bool, size)
+		for x := range bitmap[y] {
+			// Modules outside the original bitmap are part of the quiet zone
+			y2, x2 := y-shift, x-shift
+			if y2 >= 0 && y2 < len(s.bitmap) && x2 >= 0 && x2 < len(s.bitmap) {
+				bitmap[y][x] = s.bitmap[y2][x2]
+			}
+		}
+	}
+
+	resized := *s
+	resized.bitmap = bitmap
+
+	return &resized
+}
+
+// Rotate returns the symbol rotated clockwise by the given number of degrees, which is rounded
+// down to a multiple of 90, for label printers that feed sideways. Modules are moved rather than
+// resampled, so rotated images are as crisp as upright ones, and the eye colors stay with their
+// finder patterns.
+func (s *Symbol) Rotate(degrees int) *Symbol {
+	turns := (degrees/90%4 + 4) % 4
+
+	rotated := *s
+	rotated.turns = (s.turns + turns) % 4
+	for i := 0; i < turns; i++ {
+		rotated.bitmap = rotateBitmap(rotated.bitmap)
+	}
+
+	return &rotated
+}
+
+// rotateBitmap returns a square bitmap rotated a quarter turn clockwise, so that the module at
+// column x of row y moves to column n-1-y of row x.
+func rotateBitmap(bitmap [][]bool) [][]bool {
+	n := len(bitmap)
+	rotated := make([][]bool, n)
+	for y := range rotated {
+		rotated[y] = make([]bool, n)
+		for x := range rotated[y] {
+			rotated[y][x] = bitmap[n-1-x][y]
+		}
+	}
+
+	return rotated
+}
+
+// Scaling controls how modules are scaled to the requested image size.
+type Scaling int
+
+const (
+	// ScalingFill resamples the modules to exactly the requested size with nearest neighbor
+	// sampling, so that modules differ in width by a pixel when the size is not a whole multiple
+	// of the modules.
+	ScalingFill Scaling = iota
+
+	// ScalingExact scales every module by the largest whole number of pixels that fits the
+	// requested size, shrinking the image to a whole multiple of the modules.
+	ScalingExact
+
+	// ScalingFit scales every module by the largest whole number of pixels that fits the
+	// requested size and centers the symbol in an image of that size, widening the quiet zone.
+	ScalingFit
+)
+
+// PNGOptions control how a symbol is rendered as a PNG image. The zero value renders as PNG does.
+type PNGOptions struct {
+	// Scaling controls how modules are scaled to the image size.
+	Scaling Scaling
+
+	// Interlaced encodes the image with Adam7 interlacing, so that viewers can show a coarse
+	// image while it loads.
+	Interlaced bool
+
+	// Reproducible encodes the image with this package's own encoder, with the image data in
+	// uncompressed deflate blocks, so that its bytes do not depend on the compress/flate package
+	// of the Go version. Images are larger, but the same symbol always gives the same bytes.
+	Reproducible bool
+}
+
+// PNG renders the symbol as a PNG image of the given size in the given colors. Black on white
+// images are pixel for pixel the same as go-qrcode renders them.
+func (s *Symbol) PNG(size int, colors Colors) ([]byte, error) {
+	return s.PNGWithOptions(size, colors, PNGOptions{})
+}
+
+// InterlacedPNG renders the symbol as PNG does, encoded with Adam7 interlacing, so that viewers
+// can show a coarse image while it loads.
+func (s *Symbol) InterlacedPNG(size int, colors Colors) ([]byte, error) {
+	return s.PNGWithOptions(size, colors, PNGOptions{Interlaced: true})
+}
+
+// PNGWithOptions renders the symbol as a PNG image of the given size in the given colors.
+func (s *Symbol) PNGWithOptions(size int, colors Colors, opts PNGOptions) ([]byte, error) {
+	// Large images are encoded a row at a time from the modules, rather than drawn in memory first
+	if size > streamingSize && !opts.Interlaced && !opts.Reproducible {
+		return EncodePNG(s.raster(size, colors, opts.Scaling))
+	}
+
+	img := s.image(size, colors, opts.Scaling)
+	defer releasePalettedImage(img)
+
+	if opts.Interlaced || opts.Reproducible {
+		return encodePalettedPNG(img, opts.Interlaced, opts.Reproducible)
+	}
+
+	return EncodePNG(img)
+}
+
+// image renders the symbol as a paletted image of the given size, with light modules at palette
+// index 0, dark modules at index 1, and any other colors in use, such as a distinct quiet zone
+// color, after them. Callers that are done with the image may release it with
+// releasePalettedImage.
+func (s *Symbol) image(size int, colors Colors, scaling Scaling) *image.Paletted {
+	r := s.raster(size, colors, scaling)
+	img := newPalettedImage(r.rect, r.palette)
+
+	// The margin that fit scaling leaves around the symbol widens the quiet zone
+	if r.rect.Dx() > r.size && r.quietZone != 0 {
+		for i := range img.Pix {
+			img.Pix[i] = r.quietZone
+		}
+	}
+
+	for y := 0; y < r.size; y++ {
+		row := r.indexes[r.modules[y]]
+		for x := 0; x < r.size; x++ {
+			img.Pix[img.PixOffset(r.offset+x, r.offset+y)] = row[r.modules[x]]
+		}
+	}
+
+	return img
+}
+
+// moduleColor returns the color of the module at x, y, counted from the top left corner of the
+// quiet zone.
+func (s *Symbol) moduleColor(x, y int, colors Colors) color.RGBA {
+	if s.bitmap[y][x] {
+		for corner, eye := range s.eyes() {
+			if image.Pt(x, y).In(eye) {
+				return colors.eye(corner)
+			}
+		}
+		return colors.Dark
+	}
+
+	border := s.quietZone()
+	if y < border || y >= len(s.bitmap)-border || x < border || x >= len(s.bitmap)-border {
+		return colors.quietZone()
+	}
+
+	return colors.Light
+}
+
+// finderPatternModules is the width of a finder pattern in modules.
+const finderPatternModules = 7
+
+// eyes returns the finder patterns of the symbol, in module coordinates including the quiet zone,
+// indexed by EyeTopLeft, EyeTopRight and EyeBottomLeft of the upright symbol, so that they follow
+// the symbol when it is rotated.
+func (s *Symbol) eyes() [3]image.Rectangle {
+	n := len(s.bitmap)
+	near, far := s.quietZone(), n-s.quietZone()-finderPatternModules
+	eye := func(x, y int) image.Rectangle {
+		r := image.Rect(x, y, x+finderPatternModules, y+finderPatternModules)
+		for i := 0; i < s.turns; i++ {
+			r = image.Rect(n-r.Max.Y, r.Min.X, n-r.Min.Y, r.Max.X)
+		}
+		return r
+	}
+
+	return [3]image.Rectangle{
+		EyeTopLeft:    eye(near, near),
+		EyeTopRight:   eye(far, near),
+		EyeBottomLeft: eye(near, far),
+	}
+}
+
+// SmallString renders the symbol as text using half block characters, two module rows per line,
+// the same as go-qrcode renders it.
+func (s *Symbol) SmallString(inverseColor bool) string {
+	var buf strings.Builder
+
+	for y := 0; y < len(s.bitmap); y += 2 {
+		for x := range s.bitmap[y] {
+			top := s.bitmap[y][x] != inverseColor
+
+			// The last row stands alone when there is an odd number of rows
+			if y+1 == len(s.bitmap) {
+				if top {
+					buf.WriteString(" ")
+				} else {
+					buf.WriteString("▀")
+				}
+				continue
+			}
+
+			bottom := s.bitmap[y+1][x] != inverseColor
+			switch {
+			case top && bottom:
+				buf.WriteString(" ")
+			case top:
+				buf.WriteString("▄")
+			case bottom:
+				buf.WriteString("▀")
+			default:
+				buf.WriteString("█")
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// quietZone returns the width of the quiet zone of the symbol in modules.
+func (s *Symbol) quietZone() int {
+	return (len(s.bitmap) - s.SymbolModules()) / 2
+}
+
+// TextGlyphs are the characters that Text draws modules with.
+type TextGlyphs struct {
+	// Dark draws dark modules.
+	Dark string
+
+	// Light draws light modules of the symbol.
+	Light string
+
+	// QuietZone draws the quiet zone. Empty means Light.
+	QuietZone string
+}
+
+// Text renders the symbol as text in the given glyphs, one line per module row. Every module is
+// drawn twice, so that modules are about square in monospaced fonts.
+func (s *Symbol) Text(glyphs TextGlyphs) string {
+	quietZone := glyphs.QuietZone
+	if quietZone == "" {
+		quietZone = glyphs.Light
+	}
+
+	var buf strings.Builder
+
+	border := s.quietZone()
+	for y, row := range s.bitmap {
+		for x, dark := range row {
+			glyph := glyphs.Light
+			switch {
+			case dark:
+				glyph = glyphs.Dark
+			case y < border || y >= len(s.bitmap)-border || x < border || x >= len(row)-border:
+				glyph = quietZone
+			}
+			buf.WriteString(glyph)
+			buf.WriteString(glyph)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,569 @@
+package qrgen
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqrcode "github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/skip2/go-qrcode"
+)
+
+// testSize is the image size used by tests, the provider default.
+const testSize = 256
+
+// decodeTestImage decodes the text of the QR code in a PNG image.
+func decodeTestImage(data []byte) (string, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := zxingqrcode.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return result.GetText(), nil
+}
+
+// TestSymbolMatchesGoQRCode verifies that symbols render exactly like go-qrcode, so checksums are stable.
+func TestSymbolMatchesGoQRCode(t *testing.T) {
+	for _, text := range []string{"qrcode", "https://example.com/a?b=c", "0123456789", "日本語テキスト"} {
+		qr, err := qrcode.New(text, qrcode.Medium)
+		if err != nil {
+			t.Fatalf("failed to encode %q: %s", text, err)
+		}
+		symbol, err := Encode(text, Options{Level: Medium})
+		if err != nil {
+			t.Fatalf("failed to encode %q: %s", text, err)
+		}
+
+		for _, size := range []int{10, testSize, 333} {
+			expected, err := qr.PNG(size)
+			if err != nil {
+				t.Fatalf("failed to render %q: %s", text, err)
+			}
+			actual, err := symbol.PNG(size, DefaultColors)
+			if err != nil {
+				t.Fatalf("failed to render %q: %s", text, err)
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("%q at size %d: PNG differs from go-qrcode", text, size)
+			}
+		}
+
+		for _, inverse := range []bool{false, true} {
+			if expected, actual := qr.ToSmallString(inverse), symbol.SmallString(inverse); expected != actual {
+				t.Errorf("%q inverse %t: expected\n%s\ngot\n%s", text, inverse, expected, actual)
+			}
+		}
+	}
+}
+
+// TestSymbolPNGPixelsPerModule verifies that whole pixels per module render every module as an
+// exact square.
+func TestSymbolPNGPixelsPerModule(t *testing.T) {
+	symbol, err := Encode("https://example.com/a?b=c", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	for _, pixelsPerModule := range []int{1, 3, 7, 10} {
+		data, err := symbol.PNG(len(symbol.bitmap)*pixelsPerModule, DefaultColors)
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+
+		bounds := img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, _, _, _ := img.At(x, y).RGBA()
+				if dark := r == 0; dark != symbol.bitmap[y/pixelsPerModule][x/pixelsPerModule] {
+					t.Fatalf("%d pixels per module: pixel (%d, %d) does not match its module", pixelsPerModule, x, y)
+				}
+			}
+		}
+	}
+}
+
+// TestSymbolPNGScaling verifies that exact and fit scaling render whole pixels per module, in an
+// image of the largest multiple of the modules or of the requested size.
+func TestSymbolPNGScaling(t *testing.T) {
+	symbol, err := Encode("https://example.com/a?b=c", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	modules := len(symbol.bitmap)
+
+	testCases := map[string]struct {
+		scaling         Scaling
+		expectedSize    int
+		expectedOffset  int
+		pixelsPerModule int
+	}{
+		"exact": {scaling: ScalingExact, expectedSize: 7 * modules, pixelsPerModule: 7},
+		"fit":   {scaling: ScalingFit, expectedSize: 7*modules + 5, expectedOffset: 2, pixelsPerModule: 7},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := symbol.PNGWithOptions(7*modules+5, DefaultColors, PNGOptions{Scaling: testCase.scaling})
+			if err != nil {
+				t.Fatalf("failed to render: %s", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+
+			bounds := img.Bounds()
+			if bounds.Dx() != testCase.expectedSize || bounds.Dy() != testCase.expectedSize {
+				t.Fatalf("expected a %d pixel image, got %v", testCase.expectedSize, bounds)
+			}
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					r, _, _, _ := img.At(x, y).RGBA()
+					mx, my := x-testCase.expectedOffset, y-testCase.expectedOffset
+					inSymbol := mx >= 0 && my >= 0 && mx < modules*testCase.pixelsPerModule && my < modules*testCase.pixelsPerModule
+					if dark := r == 0; dark != (inSymbol && symbol.bitmap[my/testCase.pixelsPerModule][mx/testCase.pixelsPerModule]) {
+						t.Fatalf("pixel (%d, %d) does not match its module", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestSymbolQuietZoneColor verifies that a distinct quiet zone color fills the quiet zone and the
+// margin of fit scaling, in plain and interlaced images, while light modules keep their color.
+func TestSymbolQuietZoneColor(t *testing.T) {
+	symbol, err := Encode("https://example.com/a?b=c", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	modules, border := len(symbol.bitmap), symbol.quietZone()
+
+	quietZone := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	colors := Colors{Dark: DefaultColors.Dark, Light: color.RGBA{R: 0xf4, G: 0xc4, B: 0x30, A: 0xff}, QuietZone: quietZone}
+
+	for _, interlaced := range []bool{false, true} {
+		data, err := symbol.PNGWithOptions(4*modules+3, colors, PNGOptions{Scaling: ScalingFit, Interlaced: interlaced})
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				mx, my := (x-1)/4, (y-1)/4
+				expected := quietZone
+				switch {
+				case x < 1 || y < 1 || mx >= modules || my >= modules:
+				case symbol.bitmap[my][mx]:
+					expected = colors.Dark
+				case mx >= border && my >= border && mx < modules-border && my < modules-border:
+					expected = colors.Light
+				}
+				if actual := color.RGBAModel.Convert(img.At(x, y)); actual != expected {
+					t.Fatalf("interlaced %t: expected pixel (%d, %d) to be %v, got %v", interlaced, x, y, expected, actual)
+				}
+			}
+		}
+	}
+
+	svg := string(symbol.SVG(testSize, colors, "", false))
+	for _, expected := range []string{
+		fmt.Sprintf(`<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules),
+		fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="#f4c430"/>`, border, border, modules-2*border, modules-2*border),
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("expected SVG to contain %s", expected)
+		}
+	}
+}
+
+// TestSymbolEyeColors verifies that the dark modules of every finder pattern are drawn in the color
+// of its corner, and all other dark modules in the dark color, in every format.
+func TestSymbolEyeColors(t *testing.T) {
+	symbol, err := Encode("https://example.com/a?b=c", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	modules, border := len(symbol.bitmap), symbol.quietZone()
+
+	colors := DefaultColors
+	colors.QuietZone = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	colors.Eyes[EyeTopLeft] = color.RGBA{R: 0xc0, A: 0xff}
+	colors.Eyes[EyeBottomLeft] = color.RGBA{B: 0xc0, A: 0xff}
+
+	// The center of each finder pattern, the top right one in the dark color
+	centers := map[[2]int]color.RGBA{
+		{border + 3, border + 3}:           colors.Eyes[EyeTopLeft],
+		{modules - border - 4, border + 3}: colors.Dark,
+		{border + 3, modules - border - 4}: colors.Eyes[EyeBottomLeft],
+	}
+
+	for _, interlaced := range []bool{false, true} {
+		data, err := symbol.PNGWithOptions(4*modules, colors, PNGOptions{Interlaced: interlaced})
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+
+		for center, expected := range centers {
+			if actual := color.RGBAModel.Convert(img.At(4*center[0], 4*center[1])); actual != expected {
+				t.Errorf("interlaced %t: expected the eye at module %v to be %v, got %v", interlaced, center, expected, actual)
+			}
+		}
+
+		// Timing patterns run between the finder patterns in the dark color
+		if actual := color.RGBAModel.Convert(img.At(4*(border+8), 4*(border+6))); actual != colors.Dark {
+			t.Errorf("interlaced %t: expected the timing pattern to be %v, got %v", interlaced, colors.Dark, actual)
+		}
+	}
+
+	for _, optimize := range []bool{false, true} {
+		svg := string(symbol.SVG(testSize, colors, "", optimize))
+		for _, expected := range []string{`fill="#c00000"`, `fill="#0000c0"`} {
+			if !strings.Contains(svg, expected) {
+				t.Errorf("optimize %t: expected SVG to contain %s", optimize, expected)
+			}
+		}
+	}
+
+	pdf, err := symbol.PDF(testSize, colors, "")
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	for _, expected := range []string{"0.7529 0 0 rg", "0 0 0.7529 rg"} {
+		if !bytes.Contains(pdf, []byte(expected)) {
+			t.Errorf("expected PDF to contain %q", expected)
+		}
+	}
+}
+
+// TestSymbolRotate verifies that rotated symbols still decode, that the eye colors follow their
+// finder patterns and that four quarter turns give back the upright symbol.
+func TestSymbolRotate(t *testing.T) {
+	symbol, err := Encode("https://example.com/a?b=c", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	modules, border := len(symbol.bitmap), symbol.quietZone()
+
+	colors := DefaultColors
+	colors.Eyes[EyeTopLeft] = color.RGBA{R: 0xc0, A: 0xff}
+	colors.Eyes[EyeBottomLeft] = color.RGBA{B: 0xc0, A: 0xff}
+
+	// A quarter turn clockwise moves the top left finder pattern to the top right corner, the top
+	// right one to the bottom right corner and the bottom left one to the top left corner
+	rotated := symbol.Rotate(90)
+	centers := map[[2]int]color.RGBA{
+		{modules - border - 4, border + 3}:           colors.Eyes[EyeTopLeft],
+		{modules - border - 4, modules - border - 4}: colors.Dark,
+		{border + 3, border + 3}:                     colors.Eyes[EyeBottomLeft],
+	}
+
+	data, err := rotated.PNG(4*modules, colors)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	for center, expected := range centers {
+		if actual := color.RGBAModel.Convert(img.At(4*center[0], 4*center[1])); actual != expected {
+			t.Errorf("expected the eye at module %v to be %v, got %v", center, expected, actual)
+		}
+	}
+
+	for _, degrees := range []int{90, 180, 270} {
+		data, err := symbol.Rotate(degrees).PNG(testSize, DefaultColors)
+		if err != nil {
+			t.Fatalf("%d degrees: failed to render: %s", degrees, err)
+		}
+		text, err := decodeTestImage(data)
+		if err != nil {
+			t.Fatalf("%d degrees: failed to decode: %s", degrees, err)
+		}
+		if text != "https://example.com/a?b=c" {
+			t.Errorf("%d degrees: expected the encoded text, got %q", degrees, text)
+		}
+	}
+
+	turned := symbol.Rotate(90).Rotate(180).Rotate(90)
+	if fmt.Sprint(turned.bitmap) != fmt.Sprint(symbol.bitmap) || turned.eyes() != symbol.eyes() {
+		t.Errorf("expected four quarter turns to give back the upright symbol")
+	}
+	if fmt.Sprint(symbol.Rotate(180).bitmap) == fmt.Sprint(symbol.bitmap) {
+		t.Errorf("expected a half turn to move the modules")
+	}
+}
+
+// TestSymbolWithQuietZone verifies that the quiet zone is resized around an unchanged symbol.
+func TestSymbolWithQuietZone(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	// The symbol itself starts with the dark finder pattern in the top left corner
+	for _, modules := range []int{0, 1, QuietZoneModules, 10} {
+		resized := symbol.WithQuietZone(modules)
+
+		if expected := len(symbol.bitmap) + 2*(modules-QuietZoneModules); len(resized.bitmap) != expected {
+			t.Fatalf("quiet zone %d: expected %d modules, got %d", modules, expected, len(resized.bitmap))
+		}
+		for y := range resized.bitmap {
+			for x := range resized.bitmap[y] {
+				inQuietZone := x < modules || y < modules || x >= len(resized.bitmap)-modules || y >= len(resized.bitmap)-modules
+				if inQuietZone && resized.bitmap[y][x] {
+					t.Fatalf("quiet zone %d: dark module (%d, %d) in the quiet zone", modules, x, y)
+				}
+				if !inQuietZone && resized.bitmap[y][x] != symbol.bitmap[y-modules+QuietZoneModules][x-modules+QuietZoneModules] {
+					t.Fatalf("quiet zone %d: module (%d, %d) differs from the symbol", modules, x, y)
+				}
+			}
+		}
+		if !resized.bitmap[modules][modules] {
+			t.Errorf("quiet zone %d: expected the finder pattern at (%d, %d)", modules, modules, modules)
+		}
+		if again := resized.WithQuietZone(2); len(again.bitmap) != symbol.SymbolModules()+4 || !again.bitmap[2][2] {
+			t.Errorf("quiet zone %d: expected resizing again to replace the quiet zone", modules)
+		}
+	}
+}
+
+// TestSymbolContentSHA256 verifies that the content checksum ignores the quiet zone and changes
+// with the encoded data.
+func TestSymbolContentSHA256(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	other, err := Encode("https://example.org", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	checksum := symbol.ContentSHA256()
+	if len(checksum) != 64 {
+		t.Fatalf("expected a hex SHA-256 checksum, got %q", checksum)
+	}
+	if actual := symbol.WithQuietZone(0).ContentSHA256(); actual != checksum {
+		t.Errorf("expected the checksum without a quiet zone to be %s, got %s", checksum, actual)
+	}
+	if other.ContentSHA256() == checksum {
+		t.Errorf("expected different data to change the checksum")
+	}
+}
+
+// TestSymbolText verifies that text renderings draw every module twice in its glyph, with the
+// quiet zone in its own glyph.
+func TestSymbolText(t *testing.T) {
+	symbol, err := Encode("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	symbol = symbol.WithQuietZone(1)
+
+	lines := strings.Split(strings.TrimSuffix(symbol.Text(TextGlyphs{Dark: "#", Light: ".", QuietZone: "~"}), "\n"), "\n")
+	if len(lines) != symbol.Modules() {
+		t.Fatalf("expected %d lines, got %d", symbol.Modules(), len(lines))
+	}
+	if expected := strings.Repeat("~", 2*symbol.Modules()); lines[0] != expected {
+		t.Errorf("expected the first line in the quiet zone glyph, got %q", lines[0])
+	}
+	if expected := "~~" + strings.Repeat("#", 14) + ".."; !strings.HasPrefix(lines[1], expected) {
+		t.Errorf("expected the finder pattern, got %q", lines[1])
+	}
+	if expected := "~~##" + strings.Repeat(".", 10) + "##"; !strings.HasPrefix(lines[2], expected) {
+		t.Errorf("expected the inside of the finder pattern, got %q", lines[2])
+	}
+
+	if text := symbol.Text(TextGlyphs{Dark: "#", Light: "."}); strings.Contains(text, "~") || !strings.HasPrefix(text, "....") {
+		t.Errorf("expected the quiet zone to default to the light glyph, got\n%s", text)
+	}
+}
+
+// TestEncodeSymbolKanji verifies that kanji-only text is encoded in kanji mode when optimizing.
+func TestEncodeSymbolKanji(t *testing.T) {
+	text := "東京都千代田区丸の内一丁目"
+
+	symbol, err := Encode(text, Options{Level: Medium, Optimize: true})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if symbol.mode != "kanji" {
+		t.Errorf("expected kanji mode, got %s", symbol.mode)
+	}
+
+	unoptimized, err := Encode(text, Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if symbol.version >= unoptimized.version {
+		t.Errorf("expected a smaller version than %d, got %d", unoptimized.version, symbol.version)
+	}
+
+	pngData, err := symbol.PNG(testSize, DefaultColors)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	decoded, err := decodeTestImage(pngData)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if decoded != text {
+		t.Errorf("expected %q, got %q", text, decoded)
+	}
+}
+
+// TestIsShiftJISKanji verifies kanji mode detection.
+func TestIsShiftJISKanji(t *testing.T) {
+	testCases := map[string]bool{
+		"":         false,
+		"日本語":      true,
+		"ひらがな":     true,
+		"日本語 text": false,
+		"ｶﾀｶﾅ":     false,
+		"qrcode":   false,
+		"😀":        false,
+	}
+
+	for text, expected := range testCases {
+		if actual := isShiftJISKanji(text); actual != expected {
+			t.Errorf("isShiftJISKanji(%q): expected %t, got %t", text, expected, actual)
+		}
+	}
+}
+
+// TestEncodeSymbolByteCharset verifies that byte mode data is transcoded to the configured charset.
+func TestEncodeSymbolByteCharset(t *testing.T) {
+	testCases := []struct {
+		text        string
+		byteCharset string
+		expectError bool
+	}{
+		{text: "Café crème", byteCharset: ByteCharsetLatin1},
+		{text: "ｶﾀｶﾅ and 漢字", byteCharset: ByteCharsetShiftJIS},
+		{text: "日本語", byteCharset: ByteCharsetLatin1, expectError: true},
+		{text: "😀", byteCharset: ByteCharsetShiftJIS, expectError: true},
+	}
+
+	for _, testCase := range testCases {
+		for _, optimize := range []bool{false, true} {
+			symbol, err := Encode(testCase.text, Options{
+				Level:       Medium,
+				Optimize:    optimize,
+				ByteCharset: testCase.byteCharset,
+			})
+			if testCase.expectError {
+				if err == nil {
+					t.Errorf("%q in %s: expected an error", testCase.text, testCase.byteCharset)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%q in %s: failed to encode: %s", testCase.text, testCase.byteCharset, err)
+			}
+
+			pngData, err := symbol.PNG(testSize, DefaultColors)
+			if err != nil {
+				t.Fatalf("failed to render: %s", err)
+			}
+			decoded, err := decodeTestImage(pngData)
+			if err != nil {
+				t.Fatalf("%q in %s: failed to decode: %s", testCase.text, testCase.byteCharset, err)
+			}
+			if decoded != testCase.text {
+				t.Errorf("%q in %s (optimize %t): decoded %q", testCase.text, testCase.byteCharset, optimize, decoded)
+			}
+		}
+	}
+}
+
+// TestEncodeMaxLevel verifies that the highest error correction level that fits the version of
+// the requested level is used, and that the requested level is kept when no higher level fits.
+func TestEncodeMaxLevel(t *testing.T) {
+	for _, text := range []string{"https://example.com", "WIFI:T:WPA;S:office;P:correct horse battery staple;;", strings.Repeat("A", 2000)} {
+		baseline, err := Encode(text, Options{Level: Medium})
+		if err != nil {
+			t.Fatalf("%q: failed to encode: %s", text, err)
+		}
+
+		symbol, level, err := EncodeMaxLevel(text, Options{Level: Medium})
+		if err != nil {
+			t.Fatalf("%q: failed to encode: %s", text, err)
+		}
+		if level < Medium {
+			t.Errorf("%q: expected at least the Medium level, got %d", text, level)
+		}
+		if symbol.Level() != level {
+			t.Errorf("%q: expected the symbol to report level %d, got %d", text, level, symbol.Level())
+		}
+		if symbol.Version() > baseline.Version() {
+			t.Errorf("%q: expected at most version %d, got %d", text, baseline.Version(), symbol.Version())
+		}
+
+		if level < Highest {
+			higher, err := Encode(text, Options{Level: level + 1})
+			if err == nil && higher.Version() <= baseline.Version() {
+				t.Errorf("%q: expected level %d not to fit version %d", text, level+1, baseline.Version())
+			}
+		}
+	}
+
+	_, level, err := EncodeMaxLevel("https://example.com", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if level != High {
+		t.Errorf("expected the High level for a 19-byte URL in version 2, got %d", level)
+	}
+}
+
+// TestEncodeEmpty verifies that empty text, which go-qrcode refuses, encodes as the smallest
+// symbol that scans as empty text.
+func TestEncodeEmpty(t *testing.T) {
+	symbol, err := Encode("", Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if symbol.version != 1 {
+		t.Errorf("expected version 1, got %d", symbol.version)
+	}
+
+	pngData, err := symbol.PNG(testSize, DefaultColors)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	decoded, err := decodeTestImage(pngData)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if decoded != "" {
+		t.Errorf("expected empty text, got %q", decoded)
+	}
+}